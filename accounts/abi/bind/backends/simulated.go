@@ -18,6 +18,7 @@ package backends
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"errors"
 	"fmt"
 	"math/big"
@@ -35,6 +36,8 @@ import (
 	"github.com/vntchain/go-vnt/core/state"
 	"github.com/vntchain/go-vnt/core/types"
 	"github.com/vntchain/go-vnt/core/vm"
+	"github.com/vntchain/go-vnt/core/vm/election"
+	"github.com/vntchain/go-vnt/crypto"
 	"github.com/vntchain/go-vnt/event"
 	"github.com/vntchain/go-vnt/params"
 	"github.com/vntchain/go-vnt/rpc"
@@ -48,39 +51,156 @@ var _ bind.ContractBackend = (*SimulatedBackend)(nil)
 var errBlockNumberUnsupported = errors.New("SimulatedBackend cannot access blocks other than the latest block")
 var errGasEstimationFailed = errors.New("gas required exceeds allowance or always failing transaction")
 
+const (
+	simulatedPeriod       = 2          // Seconds between two simulated blocks
+	simulatedWitnessesNum = 4          // Number of witnesses of the default simulated chain
+	simulatedGenesisTime  = 1546300800 // Timestamp of the simulated genesis, fixed to keep block hashes stable
+)
+
 // SimulatedBackend implements bind.ContractBackend, simulating a blockchain in
 // the background. Its main purpose is to allow easily testing contract bindings.
+//
+// Blocks are produced by a local witness set: each block is sealed by the
+// witness in turn and carries the commit messages of all the known witnesses,
+// so the simulated chain passes the same DPoS and BFT checks as a real network.
 type SimulatedBackend struct {
 	database   vntdb.Database   // In memory database to store our testing data
 	blockchain *core.BlockChain // VNT blockchain to handle the consensus
+	engine     *dpos.Dpos       // DPoS engine preparing the consensus fields of blocks
 
 	mu           sync.Mutex
 	pendingBlock *types.Block   // Currently pending block that will be imported on request
 	pendingState *state.StateDB // Currently pending state that will be the active on on request
 
+	witnesses map[common.Address]*ecdsa.PrivateKey // Keys of witnesses, sealing and committing blocks
+
 	events *filters.EventSystem // Event system for filtering log events live
 
 	config *params.ChainConfig
 }
 
+// NewWitnessKeys returns n deterministic private keys, which can be used as
+// the witness set of a simulated backend.
+func NewWitnessKeys(n int) []*ecdsa.PrivateKey {
+	keys := make([]*ecdsa.PrivateKey, n)
+	for i := 0; i < n; i++ {
+		key, err := crypto.ToECDSA(crypto.Keccak256([]byte(fmt.Sprintf("simulated witness %d", i))))
+		if err != nil {
+			panic(err)
+		}
+		keys[i] = key
+	}
+	return keys
+}
+
 // NewSimulatedBackend creates a new binding backend using a simulated blockchain
-// for testing purposes.
+// for testing purposes. The chain is produced by the witnesses of NewWitnessKeys.
 func NewSimulatedBackend(alloc core.GenesisAlloc) *SimulatedBackend {
+	return NewSimulatedBackendWithWitnesses(alloc, NewWitnessKeys(simulatedWitnessesNum))
+}
+
+// NewSimulatedBackendWithWitnesses creates a new binding backend using a
+// simulated blockchain, whose genesis witness list is made up of the given keys.
+func NewSimulatedBackendWithWitnesses(alloc core.GenesisAlloc, witnesses []*ecdsa.PrivateKey) *SimulatedBackend {
+	return NewSimulatedBackendWithCandidates(alloc, witnesses, nil)
+}
+
+// NewSimulatedBackendWithCandidates creates a new binding backend using a
+// simulated blockchain, whose genesis witness list is made up of witnesses.
+// Both witnesses and candidates are registered as active candidates in the
+// election contract, so votes cast on the simulated chain rotate the witness
+// set once the DPoS update interval has passed.
+func NewSimulatedBackendWithCandidates(alloc core.GenesisAlloc, witnesses, candidates []*ecdsa.PrivateKey) *SimulatedBackend {
+	if len(witnesses) == 0 {
+		panic("simulated backend requires at least one witness")
+	}
+	config := *params.TestChainConfig
+	config.Dpos = &params.DposConfig{
+		Period:       simulatedPeriod,
+		WitnessesNum: len(witnesses),
+	}
+	genesis := core.Genesis{
+		Config:    &config,
+		Timestamp: simulatedGenesisTime,
+		Alloc:     make(core.GenesisAlloc),
+	}
+	for addr, account := range alloc {
+		genesis.Alloc[addr] = account
+	}
+	for _, key := range witnesses {
+		genesis.Witnesses = append(genesis.Witnesses, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	// Register every key the way registerWitness does, the election contract
+	// expects a non-empty url of each candidate
+	var (
+		registered []common.Address
+		urls       [][]byte
+	)
+	for i, key := range append(append([]*ecdsa.PrivateKey{}, witnesses...), candidates...) {
+		registered = append(registered, crypto.PubkeyToAddress(key.PublicKey))
+		urls = append(urls, []byte(fmt.Sprintf("/ip4/127.0.0.1/tcp/%d", 3000+i)))
+	}
+	storage, err := election.GenesisStorage(registered, urls)
+	if err != nil {
+		panic(err)
+	}
+	account := genesis.Alloc[election.ContractAddr()]
+	if account.Balance == nil {
+		account.Balance = new(big.Int)
+	}
+	if account.Storage == nil {
+		account.Storage = make(map[common.Hash]common.Hash)
+	}
+	for key, value := range storage {
+		account.Storage[key] = value
+	}
+	account.Nonce = 1 // The bounty is already in the storage, don't reset it on first call
+	genesis.Alloc[election.ContractAddr()] = account
+
 	database := vntdb.NewMemDatabase()
-	genesis := core.Genesis{Config: params.TestChainConfig, Alloc: alloc}
 	genesis.MustCommit(database)
-	blockchain, _ := core.NewBlockChain(database, nil, genesis.Config, dpos.NewFaker(), vm.Config{})
+	engine := dpos.New(config.Dpos, database)
+	blockchain, _ := core.NewBlockChain(database, nil, genesis.Config, engine, vm.Config{})
 
 	backend := &SimulatedBackend{
 		database:   database,
 		blockchain: blockchain,
+		engine:     engine,
+		witnesses:  make(map[common.Address]*ecdsa.PrivateKey),
 		config:     genesis.Config,
 		events:     filters.NewEventSystem(new(event.TypeMux), &filterBackend{database, blockchain}, false),
 	}
+	for _, key := range witnesses {
+		backend.witnesses[crypto.PubkeyToAddress(key.PublicKey)] = key
+	}
+	for _, key := range candidates {
+		backend.witnesses[crypto.PubkeyToAddress(key.PublicKey)] = key
+	}
 	backend.rollback()
 	return backend
 }
 
+// Blockchain returns the underlying blockchain, e.g. to inspect blocks and
+// receipts of the simulated chain.
+func (b *SimulatedBackend) Blockchain() *core.BlockChain {
+	return b.blockchain
+}
+
+// Witnesses returns the witness set of the current head, which changes as the
+// election contract rotates the witnesses.
+func (b *SimulatedBackend) Witnesses() []common.Address {
+	return b.blockchain.CurrentHeader().Witnesses
+}
+
+// AddWitnessKey makes key known to the simulated backend, so that blocks can be
+// produced after its owner has been elected as a witness.
+func (b *SimulatedBackend) AddWitnessKey(key *ecdsa.PrivateKey) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.witnesses[crypto.PubkeyToAddress(key.PublicKey)] = key
+}
+
 // Commit imports all the pending transactions as a single block and starts a
 // fresh new state.
 func (b *SimulatedBackend) Commit() {
@@ -102,11 +222,89 @@ func (b *SimulatedBackend) Rollback() {
 }
 
 func (b *SimulatedBackend) rollback() {
-	blocks, _ := core.GenerateChain(b.config, b.blockchain.CurrentBlock(), dpos.NewFaker(), b.database, 1, func(int, *core.BlockGen) {})
-	statedb, _ := b.blockchain.State()
+	blockTime := new(big.Int).Add(b.blockchain.CurrentBlock().Time(), big.NewInt(simulatedPeriod))
+	if err := b.setPending(nil, blockTime); err != nil {
+		panic(err)
+	}
+}
+
+// setPending replaces the pending block with a block containing txs, which is
+// produced at blockTime on top of the current head.
+func (b *SimulatedBackend) setPending(txs []*types.Transaction, blockTime *big.Int) error {
+	block, statedb, err := b.newBlock(txs, blockTime)
+	if err != nil {
+		return err
+	}
+	b.pendingBlock = block
+	b.pendingState = statedb
+	return nil
+}
+
+// newBlock assembles a block on top of the current head, which is sealed by
+// the witness in turn at blockTime and committed by all the known witnesses.
+func (b *SimulatedBackend) newBlock(txs []*types.Transaction, blockTime *big.Int) (*types.Block, *state.StateDB, error) {
+	parent := b.blockchain.CurrentBlock()
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		GasLimit:   core.CalcGasLimit(parent),
+	}
+	producer, err := b.engine.PrepareAt(b.blockchain, header, blockTime)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, ok := b.witnesses[producer]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown key of witness %s", producer.Hex())
+	}
+
+	statedb, err := b.blockchain.StateAt(parent.Root())
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		gasPool  = new(core.GasPool).AddGas(header.GasLimit)
+		receipts []*types.Receipt
+	)
+	for i, tx := range txs {
+		statedb.Prepare(tx.Hash(), common.Hash{}, i)
+		receipt, _, err := core.ApplyTransaction(b.config, b.blockchain, &header.Coinbase, gasPool, statedb, header, tx, &header.GasUsed, vm.Config{})
+		if err != nil {
+			return nil, nil, err
+		}
+		receipts = append(receipts, receipt)
+	}
+	block, err := b.engine.Finalize(b.blockchain, header, statedb, txs, receipts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Seal the block by the producer and commit it by every known witness
+	header = block.Header()
+	if header.Signature, err = crypto.Sign(dpos.SigHash(header).Bytes(), key); err != nil {
+		return nil, nil, err
+	}
+	block = block.WithSeal(header)
+
+	var cmtMsges []*types.CommitMsg
+	for _, witness := range header.Witnesses {
+		key, ok := b.witnesses[witness]
+		if !ok {
+			continue
+		}
+		msg := &types.CommitMsg{
+			Commiter:    witness,
+			BlockNumber: block.Number(),
+			BlockHash:   block.Hash(),
+		}
+		if msg.CommitSig, err = crypto.Sign(msg.Hash().Bytes(), key); err != nil {
+			return nil, nil, err
+		}
+		cmtMsges = append(cmtMsges, msg)
+	}
+	block.FillBftMsg(cmtMsges)
 
-	b.pendingBlock = blocks[0]
-	b.pendingState, _ = state.New(b.pendingBlock.Root(), statedb.Database())
+	return block, statedb, nil
 }
 
 // CodeAt returns the code associated with a certain account in the blockchain.
@@ -293,30 +491,25 @@ func (b *SimulatedBackend) callContract(ctx context.Context, call hubble.CallMsg
 }
 
 // SendTransaction updates the pending block to include the given transaction.
-// It panics if the transaction is invalid.
+// It returns an error if the transaction is invalid.
 func (b *SimulatedBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	sender, err := types.Sender(types.HomesteadSigner{}, tx)
 	if err != nil {
-		panic(fmt.Errorf("invalid transaction: %v", err))
+		return fmt.Errorf("invalid transaction: %v", err)
 	}
 	nonce := b.pendingState.GetNonce(sender)
 	if tx.Nonce() != nonce {
-		panic(fmt.Errorf("invalid transaction nonce: got %d, want %d", tx.Nonce(), nonce))
+		return fmt.Errorf("invalid transaction nonce: got %d, want %d", tx.Nonce(), nonce)
 	}
 
-	blocks, _ := core.GenerateChain(b.config, b.blockchain.CurrentBlock(), dpos.NewFaker(), b.database, 1, func(number int, block *core.BlockGen) {
-		for _, tx := range b.pendingBlock.Transactions() {
-			block.AddTxWithChain(b.blockchain, tx)
-		}
-		block.AddTxWithChain(b.blockchain, tx)
-	})
-	statedb, _ := b.blockchain.State()
-
-	b.pendingBlock = blocks[0]
-	b.pendingState, _ = state.New(b.pendingBlock.Root(), statedb.Database())
+	txs := append(types.Transactions{}, b.pendingBlock.Transactions()...)
+	txs = append(txs, tx)
+	if err := b.setPending(txs, b.pendingBlock.Time()); err != nil {
+		return fmt.Errorf("invalid transaction: %v", err)
+	}
 	return nil
 }
 
@@ -382,22 +575,20 @@ func (b *SimulatedBackend) SubscribeFilterLogs(ctx context.Context, query hubble
 	}), nil
 }
 
-// AdjustTime adds a time shift to the simulated clock.
+// AdjustTime adds a time shift to the simulated clock. The shift is rounded up
+// to whole block periods, as DPoS only produces blocks in its time slots.
 func (b *SimulatedBackend) AdjustTime(adjustment time.Duration) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	blocks, _ := core.GenerateChain(b.config, b.blockchain.CurrentBlock(), dpos.NewFaker(), b.database, 1, func(number int, block *core.BlockGen) {
-		for _, tx := range b.pendingBlock.Transactions() {
-			block.AddTx(tx)
-		}
-		block.OffsetTime(int64(adjustment.Seconds()))
-	})
-	statedb, _ := b.blockchain.State()
 
-	b.pendingBlock = blocks[0]
-	b.pendingState, _ = state.New(b.pendingBlock.Root(), statedb.Database())
+	seconds := int64(adjustment / time.Second)
+	if seconds <= 0 {
+		return errors.New("simulated clock can only move forward")
+	}
+	seconds = (seconds + simulatedPeriod - 1) / simulatedPeriod * simulatedPeriod
 
-	return nil
+	blockTime := new(big.Int).Add(b.pendingBlock.Time(), big.NewInt(seconds))
+	return b.setPending(b.pendingBlock.Transactions(), blockTime)
 }
 
 // callmsg implements core.Message to allow passing it as a transaction simulator.
@@ -0,0 +1,226 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package backends
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vntchain/go-vnt/accounts/abi"
+	"github.com/vntchain/go-vnt/accounts/abi/bind"
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core"
+	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/core/vm/election"
+	"github.com/vntchain/go-vnt/crypto"
+)
+
+const electionABIJSON = `[
+{"inputs":[{"name":"candidate","type":"address[]"}],"name":"voteWitnesses","outputs":[],"type":"function"},
+{"inputs":[{"name":"stakeCount","type":"uint256"}],"name":"stake","outputs":[],"type":"function"}
+]`
+
+var testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+
+func newTestBackend() *SimulatedBackend {
+	return NewSimulatedBackend(core.GenesisAlloc{
+		crypto.PubkeyToAddress(testKey.PublicKey): {Balance: new(big.Int).Mul(big.NewInt(1e18), big.NewInt(1e6))},
+	})
+}
+
+func TestSimulatedBackendProduceBlocks(t *testing.T) {
+	sim := newTestBackend()
+	witnesses := sim.Blockchain().Genesis().Witnesses()
+
+	for i := 0; i < 2*len(witnesses); i++ {
+		sim.Commit()
+	}
+	head := sim.Blockchain().CurrentBlock()
+	if head.NumberU64() != uint64(2*len(witnesses)) {
+		t.Fatalf("head number mismatch: have %d, want %d", head.NumberU64(), 2*len(witnesses))
+	}
+	// Witnesses take turns in producing blocks
+	for i := uint64(1); i <= head.NumberU64(); i++ {
+		block := sim.Blockchain().GetBlockByNumber(i)
+		if want := witnesses[(i-1)%uint64(len(witnesses))]; block.Coinbase() != want {
+			t.Errorf("block %d producer mismatch: have %x, want %x", i, block.Coinbase(), want)
+		}
+		if len(block.CmtMsges()) != len(witnesses) {
+			t.Errorf("block %d commit msg count mismatch: have %d, want %d", i, len(block.CmtMsges()), len(witnesses))
+		}
+	}
+}
+
+func TestSimulatedBackendAdjustTime(t *testing.T) {
+	sim := newTestBackend()
+	prevTime := sim.Blockchain().CurrentBlock().Time().Uint64()
+
+	if err := sim.AdjustTime(24 * time.Hour); err != nil {
+		t.Fatalf("failed to adjust time: %v", err)
+	}
+	sim.Commit()
+
+	head := sim.Blockchain().CurrentBlock()
+	if have, want := head.Time().Uint64()-prevTime, uint64(24*3600+simulatedPeriod); have != want {
+		t.Errorf("time shift mismatch: have %d, want %d", have, want)
+	}
+	if err := sim.AdjustTime(-time.Second); err == nil {
+		t.Errorf("moving the clock backward should fail")
+	}
+}
+
+func TestSimulatedBackendDeployWASM(t *testing.T) {
+	code, err := ioutil.ReadFile("testdata/event.wasm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	abiJSON, err := ioutil.ReadFile("testdata/event.abi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim := newTestBackend()
+	ctx := context.Background()
+
+	auth := bind.NewKeyedTransactor(testKey)
+	auth.GasLimit = 4000000
+	addr, tx, contract, err := bind.DeployWASMContract(auth, string(abiJSON), code, sim)
+	if err != nil {
+		t.Fatalf("failed to deploy contract: %v", err)
+	}
+	sim.Commit()
+
+	receipt, _ := sim.TransactionReceipt(ctx, tx.Hash())
+	if receipt == nil || receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("deployment failed, receipt: %v", receipt)
+	}
+	if receipt.ContractAddress != addr {
+		t.Errorf("contract address mismatch: have %x, want %x", receipt.ContractAddress, addr)
+	}
+	if code, _ := sim.CodeAt(ctx, addr, nil); len(code) == 0 {
+		t.Fatalf("no code after deployment")
+	}
+
+	// Emit an event and check the logs of the receipt
+	tx, err = contract.Transact(auth, "testEvent", "hello", common.HexToAddress("0x01"), uint64(1), uint32(2), int64(3), int32(4), big.NewInt(5), true)
+	if err != nil {
+		t.Fatalf("failed to call contract: %v", err)
+	}
+	sim.Commit()
+
+	receipt, _ = sim.TransactionReceipt(ctx, tx.Hash())
+	if receipt == nil || receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("call failed, receipt: %v", receipt)
+	}
+	if len(receipt.Logs) != 1 || receipt.Logs[0].Address != addr {
+		t.Fatalf("unexpected logs: %v", receipt.Logs)
+	}
+}
+
+func TestSimulatedBackendSendInvalidTransaction(t *testing.T) {
+	sim := newTestBackend()
+	ctx := context.Background()
+
+	tx, _ := types.SignTx(types.NewTransaction(1, common.HexToAddress("0x1234"), big.NewInt(1), 21000, big.NewInt(1), nil), types.HomesteadSigner{}, testKey)
+	if err := sim.SendTransaction(ctx, tx); err == nil {
+		t.Fatalf("transaction with a future nonce accepted")
+	}
+	tx, _ = types.SignTx(types.NewTransaction(0, common.HexToAddress("0x1234"), big.NewInt(1), 1, big.NewInt(1), nil), types.HomesteadSigner{}, testKey)
+	if err := sim.SendTransaction(ctx, tx); err == nil {
+		t.Fatalf("transaction with too little gas accepted")
+	}
+	// The backend stays usable after rejecting the transactions
+	tx, _ = types.SignTx(types.NewTransaction(0, common.HexToAddress("0x1234"), big.NewInt(1), 21000, big.NewInt(1), nil), types.HomesteadSigner{}, testKey)
+	if err := sim.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("failed to send valid transaction: %v", err)
+	}
+	sim.Commit()
+	if receipt, _ := sim.TransactionReceipt(ctx, tx.Hash()); receipt == nil || receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("transfer failed, receipt: %v", receipt)
+	}
+}
+
+func TestSimulatedBackendGenesisIsDeterministic(t *testing.T) {
+	if a, b := newTestBackend().Blockchain().Genesis().Hash(), newTestBackend().Blockchain().Genesis().Hash(); a != b {
+		t.Fatalf("genesis hash mismatch: %x != %x", a, b)
+	}
+}
+
+func TestSimulatedBackendWitnessRotation(t *testing.T) {
+	keys := NewWitnessKeys(simulatedWitnessesNum + 1)
+	witnesses, candidate := keys[:simulatedWitnessesNum], keys[simulatedWitnessesNum]
+	candidateAddr := crypto.PubkeyToAddress(candidate.PublicKey)
+
+	sim := NewSimulatedBackendWithCandidates(core.GenesisAlloc{
+		crypto.PubkeyToAddress(testKey.PublicKey): {Balance: new(big.Int).Mul(big.NewInt(1e18), big.NewInt(1e6))},
+	}, witnesses, []*ecdsa.PrivateKey{candidate})
+	ctx := context.Background()
+
+	for _, witness := range sim.Witnesses() {
+		if witness == candidateAddr {
+			t.Fatalf("candidate is a genesis witness")
+		}
+	}
+	if candidates := election.GetAllCandidates(sim.pendingState); len(candidates) != len(keys) {
+		t.Fatalf("candidate count mismatch: have %d, want %d", len(candidates), len(keys))
+	}
+
+	// Stake and vote for the candidate through the election contract
+	electionABI, err := abi.JSON(strings.NewReader(electionABIJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stake, _ := electionABI.Pack("stake", big.NewInt(1000))
+	vote, _ := electionABI.Pack("voteWitnesses", []common.Address{candidateAddr})
+	for nonce, input := range [][]byte{stake, vote} {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(nonce), election.ContractAddr(), common.Big0, 100000, big.NewInt(1), input), types.HomesteadSigner{}, testKey)
+		if err := sim.SendTransaction(ctx, tx); err != nil {
+			t.Fatalf("failed to send election transaction: %v", err)
+		}
+		sim.Commit()
+		if receipt, _ := sim.TransactionReceipt(ctx, tx.Hash()); receipt == nil || receipt.Status != types.ReceiptStatusSuccessful {
+			t.Fatalf("election transaction failed, receipt: %v", receipt)
+		}
+	}
+
+	// Pass the witness update interval, the next block elects the candidate
+	if err := sim.AdjustTime(time.Duration(3*simulatedWitnessesNum*simulatedPeriod) * time.Second); err != nil {
+		t.Fatalf("failed to adjust time: %v", err)
+	}
+	sim.Commit()
+
+	elected := false
+	for _, witness := range sim.Witnesses() {
+		elected = elected || witness == candidateAddr
+	}
+	if len(sim.Witnesses()) != simulatedWitnessesNum || !elected {
+		t.Fatalf("candidate not elected, witnesses: %x", sim.Witnesses())
+	}
+	// The new witness takes its turn in producing blocks
+	produced := false
+	for i := 0; i < simulatedWitnessesNum; i++ {
+		sim.Commit()
+		produced = produced || sim.Blockchain().CurrentBlock().Coinbase() == candidateAddr
+	}
+	if !produced {
+		t.Errorf("elected candidate produced no block")
+	}
+}
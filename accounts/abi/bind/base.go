@@ -21,11 +21,13 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 
 	vnt "github.com/vntchain/go-vnt"
 	"github.com/vntchain/go-vnt/accounts/abi"
 	"github.com/vntchain/go-vnt/common"
 	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/core/wavm"
 	"github.com/vntchain/go-vnt/crypto"
 	"github.com/vntchain/go-vnt/event"
 )
@@ -113,6 +115,32 @@ func DeployContract(opts *TransactOpts, abi abi.ABI, bytecode []byte, backend Co
 	return c.address, tx, c, nil
 }
 
+// DeployWASMContract deploys a WASM contract onto the VNT blockchain and binds
+// the deployment address with a Go wrapper. code is the raw wasm module, and
+// abiJSON the ABI definition it was generated with.
+func DeployWASMContract(opts *TransactOpts, abiJSON string, code []byte, backend ContractBackend, params ...interface{}) (common.Address, *types.Transaction, *BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	c := NewBoundContract(common.Address{}, parsed, backend, backend, backend)
+
+	input, err := c.abi.Pack("", params...)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	payload, err := wavm.PackDeployPayload(code, []byte(abiJSON), input)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	tx, err := c.transact(opts, nil, payload)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	c.address = crypto.CreateAddress(opts.From, tx.Nonce())
+	return c.address, tx, c, nil
+}
+
 // Call invokes the (constant) contract method with params as input values and
 // sets the output to result. The result type might be a single field for simple
 // returns, a slice of interfaces for anonymous returns and a struct for named
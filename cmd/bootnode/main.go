@@ -19,10 +19,15 @@ package main
 
 import (
 	"crypto/ecdsa"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"context"
 
@@ -41,6 +46,10 @@ func main() {
 		// writeAddr   = flag.Bool("writeaddress", false, "write out the node's pubkey hash and quit")
 		nodeKeyFile = flag.String("nodekey", "", "private key filename")
 		nodeKeyHex  = flag.String("nodekeyhex", "", "private key as hex (for testing)")
+		nodeKeyPass = flag.String("nodekeypassword", "", "file containing the passphrase of the encrypted private key")
+		signList    = flag.String("signlist", "", "sign the node urls of the file (one per line) as a bootstrap list and quit")
+		listSeq     = flag.Uint64("seq", 0, "sequence number of the signed bootstrap list")
+		dnsDomain   = flag.String("dnsdomain", "", "print the signed bootstrap list as DNS TXT records of the domain")
 		natdesc     = flag.String("nat", "none", "port mapping mechanism (any|none)")
 		netrestrict = flag.String("netrestrict", "", "restrict network communication to the given IP networks (CIDR masks)")
 		// runv5       = flag.Bool("v5", false, "run a v5 topic discovery bootnode")
@@ -60,22 +69,33 @@ func main() {
 	if err != nil {
 		utils.Fatalf("-nat: %v", err)
 	}
+	var passphrase string
+	if *nodeKeyPass != "" {
+		text, err := ioutil.ReadFile(*nodeKeyPass)
+		if err != nil {
+			utils.Fatalf("-nodekeypassword: %v", err)
+		}
+		passphrase = strings.TrimRight(strings.SplitN(string(text), "\n", 2)[0], "\r")
+	}
 	switch {
 	case *genKey != "":
 		nodeKey, err = crypto.GenerateKey()
 		if err != nil {
 			utils.Fatalf("could not generate key: %v", err)
 		}
-		if err = crypto.SaveECDSA(*genKey, nodeKey); err != nil {
+		if err = p2p.SaveNodeKey(*genKey, nodeKey, passphrase); err != nil {
 			utils.Fatalf("%v", err)
 		}
 		return
 	case *nodeKeyFile == "" && *nodeKeyHex == "":
-		// utils.Fatalf("Use -nodekey or -nodekeyhex to specify a private key")
+		// Keep the key in the data directory
+		if nodeKey, err = loadDataDirKey(*dataDir, passphrase); err != nil {
+			utils.Fatalf("%v", err)
+		}
 	case *nodeKeyFile != "" && *nodeKeyHex != "":
 		utils.Fatalf("Options -nodekey and -nodekeyhex are mutually exclusive")
 	case *nodeKeyFile != "":
-		if nodeKey, err = crypto.LoadECDSA(*nodeKeyFile); err != nil {
+		if nodeKey, err = p2p.LoadNodeKey(*nodeKeyFile, passphrase); err != nil {
 			utils.Fatalf("-nodekey: %v", err)
 		}
 	case *nodeKeyHex != "":
@@ -84,6 +104,13 @@ func main() {
 		}
 	}
 
+	if *signList != "" {
+		if err := signBootstrapList(nodeKey, *signList, *listSeq, *dnsDomain); err != nil {
+			utils.Fatalf("-signlist: %v", err)
+		}
+		return
+	}
+
 	// if *writeAddr {
 	// 	fmt.Printf("%v\n", discover.PubkeyID(&nodeKey.PublicKey))
 	// 	os.Exit(0)
@@ -137,7 +164,7 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	_, host, err := p2p.ConstructDHT(ctx, p2p.MakePort(*listenAddr), nodeKey, *dataDir, restrictList, natm)
+	_, host, err := p2p.ConstructDHT(ctx, p2p.MakePort(*listenAddr), nodeKey, *dataDir, "", restrictList, natm)
 	if err != nil {
 		log.Error(fmt.Sprintf("constructDHT a: %s", err))
 		return
@@ -154,3 +181,63 @@ func main() {
 
 	select {}
 }
+
+// loadDataDirKey loads the node key kept in the data directory. A key left in
+// the datastore by older versions is moved there, otherwise a new key is
+// generated.
+func loadDataDirKey(dataDir, passphrase string) (*ecdsa.PrivateKey, error) {
+	keyfile := filepath.Join(dataDir, "nodekey")
+	key, err := p2p.MigrateNodeKey(dataDir, "", keyfile, passphrase)
+	if err != nil || key != nil {
+		return key, err
+	}
+	if _, err := os.Stat(keyfile); err == nil {
+		return p2p.LoadNodeKey(keyfile, passphrase)
+	}
+	if key, err = crypto.GenerateKey(); err != nil {
+		return nil, err
+	}
+	return key, p2p.SaveNodeKey(keyfile, key, passphrase)
+}
+
+// signBootstrapList signs the node urls listed in the file and prints the list,
+// as json or as the TXT records of a DNS tree.
+func signBootstrapList(key *ecdsa.PrivateKey, path string, seq uint64, domain string) error {
+	text, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	list := &p2p.NodeList{Seq: seq, Nodes: []string{}}
+	for _, line := range strings.Split(string(text), "\n") {
+		if line = strings.TrimSpace(line); line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, err := p2p.ParseNode(line); err != nil {
+			return fmt.Errorf("invalid url %q: %v", line, err)
+		}
+		list.Nodes = append(list.Nodes, line)
+	}
+	if err := list.Sign(key); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Signer public key: %x\n", crypto.CompressPubkey(&key.PublicKey))
+
+	if domain == "" {
+		out, err := json.MarshalIndent(list, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	records := p2p.MakeDNSTree(domain, list)
+	names := make([]string, 0, len(records))
+	for name := range records {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("%s\tTXT\t%q\n", name, records[name])
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of go-vnt.
+//
+// go-vnt is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-vnt is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-vnt. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/vntchain/go-vnt/cmd/utils"
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core"
+	"github.com/vntchain/go-vnt/crypto"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	checkpointKeyFlag = cli.StringFlag{
+		Name:  "key",
+		Usage: "File holding the hex encoded private key to sign with",
+	}
+
+	checkpointCommand = cli.Command{
+		Name:     "checkpoint",
+		Usage:    "Manage trusted checkpoints",
+		Category: "BLOCKCHAIN COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Name:      "sign",
+				Usage:     "Sign a checkpoint with a trusted key",
+				ArgsUsage: "<checkpointFile>",
+				Action:    utils.MigrateFlags(signCheckpoint),
+				Flags: []cli.Flag{
+					checkpointKeyFlag,
+				},
+				Description: `
+    gvnt checkpoint sign --key trusted.key checkpoint.json
+
+Adds the signature of a trusted key to a checkpoint file, as produced by the
+admin.checkpoint() RPC of a running node. Nodes started with --checkpoint accept
+the file once it is signed by --checkpoint.threshold of the keys listed in
+--checkpoint.signers. Unsigned files are only accepted with --checkpoint.trustcert,
+if certified by the witnesses of the genesis or of the embedded checkpoint.`,
+			},
+		},
+	}
+)
+
+func signCheckpoint(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	if !ctx.IsSet(checkpointKeyFlag.Name) {
+		utils.Fatalf("The signing key is required (--%s).", checkpointKeyFlag.Name)
+	}
+	key, err := crypto.LoadECDSA(ctx.String(checkpointKeyFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to load the signing key: %v", err)
+	}
+	file := ctx.Args().First()
+	cp := new(core.SignedCheckpoint)
+	if err := common.LoadJSON(file, cp); err != nil {
+		utils.Fatalf("Failed to read the checkpoint: %v", err)
+	}
+	if err := cp.Sign(key); err != nil {
+		utils.Fatalf("Failed to sign the checkpoint: %v", err)
+	}
+	blob, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(file, blob, 0644); err != nil {
+		utils.Fatalf("Failed to write the checkpoint: %v", err)
+	}
+	fmt.Printf("Signed checkpoint #%d [%x…] as %s\n", cp.Number, cp.Hash.Bytes()[:4], crypto.PubkeyToAddress(key.PublicKey).Hex())
+	return nil
+}
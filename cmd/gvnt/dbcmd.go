@@ -0,0 +1,522 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of go-vnt.
+//
+// go-vnt is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-vnt is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-vnt. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/vntchain/go-vnt/cmd/utils"
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/consensus/dpos"
+	"github.com/vntchain/go-vnt/core"
+	"github.com/vntchain/go-vnt/core/rawdb"
+	"github.com/vntchain/go-vnt/core/state"
+	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/log"
+	"github.com/vntchain/go-vnt/vntdb"
+	"github.com/vntchain/go-vnt/vntp2p"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	freezerMarginFlag = cli.Uint64Flag{
+		Name:  "margin",
+		Usage: "Number of blocks below the finalized one kept in the key-value store",
+		Value: core.FreezerMargin,
+	}
+
+	dbCommand = cli.Command{
+		Name:     "db",
+		Usage:    "Low level database operations",
+		Category: "BLOCKCHAIN COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Name:      "inspect",
+				Usage:     "Show the size of the data held in the database",
+				ArgsUsage: " ",
+				Action:    utils.MigrateFlags(inspectDatabase),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.LightModeFlag,
+				},
+				Description: `
+    gvnt db inspect
+
+Iterates over the whole database, and prints the number and size of its entries
+by category of data, along with the size of the tables of the ancient store.`,
+			},
+			{
+				Name:      "stats",
+				Usage:     "Show the heads and the engine statistics of the database",
+				ArgsUsage: " ",
+				Action:    utils.MigrateFlags(showDatabaseStats),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.LightModeFlag,
+				},
+				Description: `
+    gvnt db stats
+
+Prints the engine keeping the database, the heads of the chain, the number of
+blocks in the ancient store and the internal statistics of LevelDB.`,
+			},
+			{
+				Name:      "verify",
+				Usage:     "Check the consistency of the canonical chain",
+				ArgsUsage: "[<first> [<last>]]",
+				Action:    utils.MigrateFlags(verifyChain),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+				},
+				Description: `
+    gvnt db verify [<first> [<last>]]
+
+Checks the canonical blocks from first, 0 by default, to last, the head header
+by default. Every block needs its canonical hash, header, hash to number mapping,
+total difficulty, body and receipts matching its header, and transaction
+lookups. Every header has to link to its parent, to follow its witness list and
+to carry the commit certificate of its witnesses. A witness list update whose
+proving state was pruned is reported as unverified, and the blocks of its
+witnesses are counted. The state of the head block has to be available. Every fault is
+printed, and the command fails if any is found.`,
+			},
+			{
+				Name:      "repair-head",
+				Usage:     "Rewind the chain to the last complete block",
+				ArgsUsage: " ",
+				Action:    utils.MigrateFlags(repairHead),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+				},
+				Description: `
+    gvnt db repair-head
+
+Rewinds the heads of the chain to the last canonical block whose header, total
+difficulty, body, receipts and state are all available, e.g. after a crash in
+the middle of a write. The canonical blocks past it are dropped, and synced
+again once the node is started. The node has to be stopped.`,
+			},
+			{
+				Name:      "get",
+				Usage:     "Show the value of a key of the database",
+				ArgsUsage: "<hex key>",
+				Action:    utils.MigrateFlags(getDatabaseKey),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.LightModeFlag,
+				},
+				Description: `
+    gvnt db get 0x4c617374426c6f636b
+
+Prints the value stored under the key in the key-value store, hex encoded.`,
+			},
+			{
+				Name:      "put",
+				Usage:     "Store a value under a key of the database",
+				ArgsUsage: "<hex key> <hex value>",
+				Action:    utils.MigrateFlags(putDatabaseKey),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.LightModeFlag,
+				},
+				Description: `
+    gvnt db put <hex key> <hex value>
+
+Stores the value under the key in the key-value store, replacing the previous
+one. Use with care, the node has to be stopped.`,
+			},
+			{
+				Name:      "delete",
+				Usage:     "Delete a key of the database",
+				ArgsUsage: "<hex key>",
+				Action:    utils.MigrateFlags(deleteDatabaseKey),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.LightModeFlag,
+				},
+				Description: `
+    gvnt db delete <hex key>
+
+Deletes the key from the key-value store. Use with care, the node has to be
+stopped.`,
+			},
+			{
+				Name:      "freezer",
+				Usage:     "Move the finalized blocks into the ancient store",
+				ArgsUsage: " ",
+				Action:    utils.MigrateFlags(freezeBlocks),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.CacheFlag,
+					freezerMarginFlag,
+				},
+				Description: `
+    gvnt db freezer --margin 2048
+
+Moves the canonical blocks older than the latest finalized one by more than
+--margin from the key-value store into the append-only ancient store, deleting
+the side chains at the same heights, then compacts the key-value store. A
+running node does so in the background, the command migrates an existing
+database at once. The node has to be stopped, and an interrupted migration
+resumes where it stopped.`,
+			},
+			{
+				Name:      "convert",
+				Usage:     "Move the databases over to another engine",
+				ArgsUsage: " ",
+				Action:    utils.MigrateFlags(convertDatabases),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.DBEngineFlag,
+					utils.CacheFlag,
+				},
+				Description: `
+    gvnt db convert --db.engine badger
+
+Copies the chain databases and the p2p datastore into new databases kept by the
+engine given with --db.engine, replacing the old ones once complete. The ancient
+store is kept as it is. The node has to be stopped, and an interrupted
+conversion leaves the databases untouched.`,
+			},
+		},
+	}
+)
+
+func inspectDatabase(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	db := utils.MakeChainDatabase(ctx, stack)
+	defer db.Close()
+
+	stats, err := rawdb.InspectDatabase(db)
+	if err != nil {
+		utils.Fatalf("Failed to inspect database: %v", err)
+	}
+	var (
+		table = tablewriter.NewWriter(os.Stdout)
+		total common.StorageSize
+	)
+	table.SetHeader([]string{"Store", "Category", "Items", "Size"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	for _, stat := range stats {
+		table.Append([]string{stat.Store, stat.Category, fmt.Sprintf("%d", stat.Count), stat.Size.String()})
+		total += stat.Size
+	}
+	table.SetFooter([]string{"", "Total", "", total.String()})
+	table.Render()
+	return nil
+}
+
+func freezeBlocks(ctx *cli.Context) error {
+	stack := makeFullNode(ctx)
+	chain, chainDb := utils.MakeChain(ctx, stack)
+	defer chainDb.Close()
+	defer chain.Stop()
+
+	start := time.Now()
+	moved, err := chain.Freeze(ctx.Uint64(freezerMarginFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to move blocks into ancient store: %v", err)
+	}
+	ancients, ok := chainDb.(rawdb.AncientReader)
+	if !ok {
+		utils.Fatalf("Database has no ancient store")
+	}
+	frozen, _ := ancients.Ancients()
+	log.Info("Moved blocks into ancient store", "moved", moved, "ancients", frozen, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	if db, ok := rawdb.DiskDB(chainDb).(vntdb.Store); ok && moved > 0 {
+		start = time.Now()
+		log.Info("Compacting database")
+		if err := db.Compact(nil, nil); err != nil {
+			utils.Fatalf("Failed to compact database: %v", err)
+		}
+		log.Info("Compacted database", "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return nil
+}
+
+func convertDatabases(ctx *cli.Context) error {
+	engine := ctx.GlobalString(utils.DBEngineFlag.Name)
+	if engine == "" {
+		utils.Fatalf("Target engine missing, set it with --%s", utils.DBEngineFlag.Name)
+	}
+	stack, cfg := makeConfigNode(ctx)
+
+	datastore := cfg.Node.P2P.NodeDatabase
+	if datastore == "" {
+		datastore = stack.DataDir()
+	}
+	for _, file := range []string{stack.ResolvePath("chaindata"), stack.ResolvePath("lightchaindata"), vntp2p.DatastorePath(datastore)} {
+		recorded, err := vntdb.DatabaseEngine(file)
+		if err != nil {
+			utils.Fatalf("Failed to read database engine: %v", err)
+		}
+		if recorded == "" || recorded == engine {
+			continue
+		}
+		start := time.Now()
+		log.Info("Converting database", "database", file, "from", recorded, "to", engine)
+		copied, err := vntdb.Convert(file, engine, ctx.GlobalInt(utils.CacheFlag.Name), 256)
+		if err != nil {
+			utils.Fatalf("Failed to convert database: %v", err)
+		}
+		log.Info("Converted database", "database", file, "entries", copied, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return nil
+}
+
+func showDatabaseStats(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	name := "chaindata"
+	if ctx.GlobalBool(utils.LightModeFlag.Name) {
+		name = "lightchaindata"
+	}
+	engine, err := vntdb.DatabaseEngine(stack.ResolvePath(name))
+	if err != nil {
+		utils.Fatalf("Failed to read database engine: %v", err)
+	}
+	db := utils.MakeChainDatabase(ctx, stack)
+	defer db.Close()
+
+	fmt.Println("Engine:", engine)
+	for _, head := range []struct {
+		name string
+		hash common.Hash
+	}{
+		{"Head header", rawdb.ReadHeadHeaderHash(db)},
+		{"Head fast block", rawdb.ReadHeadFastBlockHash(db)},
+		{"Head block", rawdb.ReadHeadBlockHash(db)},
+	} {
+		if number := rawdb.ReadHeaderNumber(db, head.hash); number != nil {
+			fmt.Printf("%s: #%d [%x]\n", head.name, *number, head.hash)
+		} else {
+			fmt.Printf("%s: missing [%x]\n", head.name, head.hash)
+		}
+	}
+	if ancients, ok := db.(rawdb.AncientReader); ok {
+		frozen, _ := ancients.Ancients()
+		fmt.Println("Ancient blocks:", frozen)
+	}
+	if kv, ok := rawdb.DiskDB(db).(vntdb.Store); ok {
+		showLeveldbStats(kv)
+	}
+	return nil
+}
+
+// errNoParentState is returned by the parent state retriever of verifyChain if
+// the state was pruned.
+var errNoParentState = errors.New("parent state unavailable")
+
+func verifyChain(ctx *cli.Context) error {
+	if len(ctx.Args()) > 2 {
+		utils.Fatalf("This command takes at most two arguments.")
+	}
+	stack, _ := makeConfigNode(ctx)
+	db := utils.MakeChainDatabase(ctx, stack)
+	defer db.Close()
+
+	var first, last uint64
+	if head := rawdb.ReadHeaderNumber(db, rawdb.ReadHeadHeaderHash(db)); head != nil {
+		last = *head
+	}
+	for i, number := range []*uint64{&first, &last} {
+		if arg := ctx.Args().Get(i); arg != "" {
+			n, err := strconv.ParseUint(arg, 10, 64)
+			if err != nil {
+				utils.Fatalf("Invalid block number %q: %v", arg, err)
+			}
+			*number = n
+		}
+	}
+	config := rawdb.ReadChainConfig(db, rawdb.ReadCanonicalHash(db, 0))
+	if config == nil || config.Dpos == nil {
+		utils.Fatalf("No DPoS chain config in database")
+	}
+	var (
+		engine  = dpos.New(config.Dpos, db)
+		statedb = state.NewDatabase(db)
+		faults  int
+		start   = time.Now()
+
+		unverified       []common.Address // Witness list of the last unverified update
+		unverifiedBlocks int
+	)
+	verify := func(header, parent *types.Header) error {
+		parentState := func() (*state.StateDB, error) {
+			if db, err := state.New(parent.Root, statedb); err == nil {
+				return db, nil
+			}
+			return nil, errNoParentState
+		}
+		// A witness list update can't be checked if the state proving it is
+		// pruned, so it's reported instead of checked against the witnesses it
+		// names. The blocks of those witnesses are counted as unverified.
+		switch err := engine.VerifyWitnessTransition(header, parent, parentState); {
+		case err == errNoParentState:
+			fmt.Printf("Block #%d [%x…]: witness set unverified, parent state pruned\n", header.Number, header.Hash().Bytes()[:4])
+			unverified = header.Witnesses
+			unverifiedBlocks++
+			return nil
+		case err != nil:
+			return err
+		}
+		if unverified != nil && sameAddresses(header.Witnesses, unverified) {
+			unverifiedBlocks++
+		} else {
+			unverified = nil
+		}
+		return engine.VerifyCertificate(header, header.Witnesses)
+	}
+	err := rawdb.VerifyChain(db, first, last, verify, func(fault rawdb.ChainFault) bool {
+		fmt.Println(fault)
+		faults++
+		return true
+	})
+	if err != nil {
+		utils.Fatalf("Failed to verify chain: %v", err)
+	}
+	if hash := rawdb.ReadHeadBlockHash(db); hash != (common.Hash{}) {
+		if number := rawdb.ReadHeaderNumber(db, hash); number != nil {
+			if header := rawdb.ReadHeader(db, hash, *number); header != nil {
+				if _, err := state.New(header.Root, statedb); err != nil {
+					fmt.Println(rawdb.ChainFault{Number: *number, Hash: hash, Err: err})
+					faults++
+				}
+			}
+		}
+	}
+	if faults > 0 {
+		utils.Fatalf("Found %d faults in blocks %d to %d", faults, first, last)
+	}
+	if unverifiedBlocks > 0 {
+		log.Warn("Blocks with unverified witness sets", "count", unverifiedBlocks)
+	}
+	log.Info("Verified chain", "first", first, "last", last, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func sameAddresses(a, b []common.Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func repairHead(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	db := utils.MakeChainDatabase(ctx, stack)
+	defer db.Close()
+
+	statedb := state.NewDatabase(db)
+	head, err := rawdb.RepairHead(db, func(root common.Hash) bool {
+		_, err := state.New(root, statedb)
+		return err == nil
+	})
+	if err != nil {
+		utils.Fatalf("Failed to repair chain head: %v", err)
+	}
+	log.Info("Repaired chain head", "number", head.Number, "hash", head.Hash())
+	return nil
+}
+
+// parseHex decodes a hex encoded command line argument, with or without its 0x
+// prefix.
+func parseHex(arg string) []byte {
+	blob, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(arg, "0x"), "0X"))
+	if err != nil {
+		utils.Fatalf("Invalid hex argument %q: %v", arg, err)
+	}
+	return blob
+}
+
+func getDatabaseKey(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("This command requires a key argument.")
+	}
+	stack, _ := makeConfigNode(ctx)
+	db := utils.MakeChainDatabase(ctx, stack)
+	defer db.Close()
+
+	key := parseHex(ctx.Args().First())
+	if has, err := db.Has(key); err != nil || !has {
+		utils.Fatalf("Key %x not found", key)
+	}
+	value, err := db.Get(key)
+	if err != nil {
+		utils.Fatalf("Failed to read key %x: %v", key, err)
+	}
+	fmt.Printf("%#x\n", value)
+	return nil
+}
+
+func putDatabaseKey(ctx *cli.Context) error {
+	if len(ctx.Args()) != 2 {
+		utils.Fatalf("This command requires key and value arguments.")
+	}
+	stack, _ := makeConfigNode(ctx)
+	db := utils.MakeChainDatabase(ctx, stack)
+	defer db.Close()
+
+	key, value := parseHex(ctx.Args().Get(0)), parseHex(ctx.Args().Get(1))
+	if prev, err := db.Get(key); err == nil {
+		log.Info("Replacing previous value", "key", fmt.Sprintf("%#x", key), "value", fmt.Sprintf("%#x", prev))
+	}
+	if err := db.Put(key, value); err != nil {
+		utils.Fatalf("Failed to write key %x: %v", key, err)
+	}
+	return nil
+}
+
+func deleteDatabaseKey(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("This command requires a key argument.")
+	}
+	stack, _ := makeConfigNode(ctx)
+	db := utils.MakeChainDatabase(ctx, stack)
+	defer db.Close()
+
+	key := parseHex(ctx.Args().First())
+	if prev, err := db.Get(key); err == nil {
+		log.Info("Deleting value", "key", fmt.Sprintf("%#x", key), "value", fmt.Sprintf("%#x", prev))
+	} else {
+		utils.Fatalf("Key %x not found", key)
+	}
+	if err := db.Delete(key); err != nil {
+		utils.Fatalf("Failed to delete key %x: %v", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of go-vnt.
+//
+// go-vnt is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-vnt is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-vnt. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/vntchain/go-vnt/cmd/utils"
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/era"
+	"github.com/vntchain/go-vnt/log"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	historyStepFlag = cli.Uint64Flag{
+		Name:  "step",
+		Usage: "Number of blocks of each history file",
+		Value: era.MaxBlocks,
+	}
+	historyChecksumsFlag = cli.StringFlag{
+		Name:  "checksums",
+		Usage: "File listing the trusted accumulator roots, to import without executing the blocks",
+	}
+
+	historyCommand = cli.Command{
+		Name:     "history",
+		Usage:    "Export and import the chain history in archive files",
+		Category: "BLOCKCHAIN COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Name:      "export",
+				Usage:     "Export the chain into history files",
+				ArgsUsage: "<dir> [<first> <last>]",
+				Action:    utils.MigrateFlags(exportHistory),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.CacheFlag,
+					historyStepFlag,
+				},
+				Description: `
+    gvnt history export <dir> [<first> <last>]
+
+Writes the canonical blocks from first to last, the whole chain by default, into
+files of --step blocks in dir, along with their receipts, total difficulties and
+commit certificates. Every file holds the accumulator root of its blocks, which
+are listed in the checksums.txt file of dir.`,
+			},
+			{
+				Name:      "import",
+				Usage:     "Import the chain from history files",
+				ArgsUsage: "<dir>",
+				Action:    utils.MigrateFlags(importHistory),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.CacheFlag,
+					utils.GCModeFlag,
+					historyChecksumsFlag,
+				},
+				Description: `
+    gvnt history import [--checksums <file>] <dir>
+
+Verifies the history files in dir concurrently and inserts their blocks in order.
+The blocks are executed, unless the accumulator roots of all the files are listed
+in the trusted --checksums file: the blocks are then inserted along with their
+receipts like fast synced ones, and the state of the head is synced once the node
+is started.`,
+			},
+		},
+	}
+)
+
+func exportHistory(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 && len(ctx.Args()) != 3 {
+		utils.Fatalf("This command requires a directory and optionally a block range.")
+	}
+	stack := makeFullNode(ctx)
+	chain, chainDb := utils.MakeChain(ctx, stack)
+	defer chainDb.Close()
+	defer chain.Stop()
+
+	first, last := uint64(0), chain.CurrentBlock().NumberU64()
+	if len(ctx.Args()) == 3 {
+		var ferr, lerr error
+		first, ferr = strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+		last, lerr = strconv.ParseUint(ctx.Args().Get(2), 10, 64)
+		if ferr != nil || lerr != nil {
+			utils.Fatalf("Invalid block range: %v %v", ctx.Args().Get(1), ctx.Args().Get(2))
+		}
+	}
+	start := time.Now()
+	if err := era.Export(chain, ctx.Args().First(), first, last, ctx.Uint64(historyStepFlag.Name)); err != nil {
+		utils.Fatalf("Export error: %v", err)
+	}
+	log.Info("Exported history", "first", first, "last", last, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func importHistory(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("This command requires a directory argument.")
+	}
+	var trusted map[common.Hash]bool
+	if path := ctx.String(historyChecksumsFlag.Name); path != "" {
+		var err error
+		if trusted, err = era.ReadChecksums(path); err != nil {
+			utils.Fatalf("Failed to read trusted checksums: %v", err)
+		}
+	}
+	stack := makeFullNode(ctx)
+	chain, chainDb := utils.MakeChain(ctx, stack)
+	defer chainDb.Close()
+	defer chain.Stop()
+
+	start := time.Now()
+	if err := era.Import(chain, ctx.Args().First(), trusted); err != nil {
+		utils.Fatalf("Import error: %v", err)
+	}
+	log.Info("Imported history", "head", chain.CurrentBlock().Number(), "fast", chain.CurrentFastBlock().Number(), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
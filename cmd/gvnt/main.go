@@ -61,7 +61,10 @@ var (
 		utils.BootnodesFlag,
 		utils.BootnodesV4Flag,
 		utils.BootnodesV5Flag,
+		utils.BootnodeListsFlag,
 		utils.DataDirFlag,
+		utils.AncientFlag,
+		utils.DBEngineFlag,
 		utils.KeyStoreDirFlag,
 		utils.NoUSBFlag,
 		// utils.EthashCacheDirFlag,
@@ -80,10 +83,17 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolValidateWasmFlag,
 		utils.FastSyncFlag,
 		utils.LightModeFlag,
 		utils.SyncModeFlag,
 		utils.GCModeFlag,
+		utils.PruneIntervalFlag,
+		utils.SnapshotFlag,
+		utils.CheckpointFlag,
+		utils.CheckpointSignersFlag,
+		utils.CheckpointThresholdFlag,
+		utils.CheckpointTrustCertFlag,
 		utils.LightServFlag,
 		utils.LightPeersFlag,
 		utils.LightKDFFlag,
@@ -102,9 +112,13 @@ var (
 		utils.NATFlag,
 		utils.NoDiscoverFlag,
 		utils.DiscoveryV5Flag,
+		utils.MsgEventsFlag,
 		utils.NetrestrictFlag,
+		utils.PermissionedFlag,
+		utils.PermissionRegistryFlag,
 		utils.NodeKeyFileFlag,
 		utils.NodeKeyHexFlag,
+		utils.NodeKeyPasswordFlag,
 		utils.DeveloperFlag,
 		utils.DeveloperPeriodFlag,
 		utils.TestnetFlag,
@@ -118,6 +132,13 @@ var (
 		utils.NoCompactionFlag,
 		utils.GpoBlocksFlag,
 		utils.GpoPercentileFlag,
+		utils.VerifierEnabledFlag,
+		utils.VerifierPersistFlag,
+		utils.VerifierWasmgenFlag,
+		utils.VerifierClangFlag,
+		utils.VerifierWasmLdFlag,
+		utils.VerifierIncludeFlag,
+		utils.VerifierTimeoutFlag,
 		utils.ExtraDataFlag,
 		configFileFlag,
 	}
@@ -158,6 +179,14 @@ func init() {
 		copydbCommand,
 		removedbCommand,
 		dumpCommand,
+		// See dbcmd.go:
+		dbCommand,
+		// See checkpointcmd.go:
+		checkpointCommand,
+		// See historycmd.go:
+		historyCommand,
+		// See snapshotcmd.go:
+		snapshotCommand,
 		// See monitorcmd.go:
 		monitorCommand,
 		// See accountcmd.go:
@@ -173,6 +202,8 @@ func init() {
 		licenseCommand,
 		// See config.go
 		dumpConfigCommand,
+		// See wasmcmd.go
+		wasmCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
@@ -0,0 +1,153 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of go-vnt.
+//
+// go-vnt is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-vnt is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-vnt. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"time"
+
+	"github.com/vntchain/go-vnt/cmd/utils"
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/rawdb"
+	"github.com/vntchain/go-vnt/core/state/pruner"
+	"github.com/vntchain/go-vnt/core/state/snapshot"
+	"github.com/vntchain/go-vnt/log"
+	"github.com/vntchain/go-vnt/trie"
+	"github.com/vntchain/go-vnt/vntdb"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	pruneRetainFlag = cli.Uint64Flag{
+		Name:  "retain",
+		Usage: "Number of recent blocks whose state is kept",
+		Value: 128,
+	}
+
+	snapshotCommand = cli.Command{
+		Name:     "snapshot",
+		Usage:    "Manage the state of the local chain",
+		Category: "BLOCKCHAIN COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Name:      "prune-state",
+				Usage:     "Delete the stale state from the database",
+				ArgsUsage: " ",
+				Action:    utils.MigrateFlags(pruneState),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.CacheFlag,
+					pruneRetainFlag,
+				},
+				Description: `
+    gvnt snapshot prune-state --retain 128
+
+Deletes the state of all blocks but the last --retain ones and the latest
+finalized one from the database, then compacts it. The node has to be stopped,
+and the state of its head block available on disk, which is the case after a
+clean shutdown. Interrupting the pruning leaves the retained state intact.`,
+			},
+			{
+				Name:      "verify-state",
+				Usage:     "Check the state snapshot against the head state root",
+				ArgsUsage: " ",
+				Action:    utils.MigrateFlags(verifyState),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+				},
+				Description: `
+    gvnt snapshot verify-state
+
+Rebuilds the tries of the head state from the flat snapshot written by a node
+run with --snapshot, and compares their roots with the ones of the head block.
+The node has to be stopped, the snapshot is flattened on disk at shutdown.`,
+			},
+		},
+	}
+)
+
+func pruneState(ctx *cli.Context) error {
+	stack := makeFullNode(ctx)
+	chain, chainDb := utils.MakeChain(ctx, stack)
+	defer chainDb.Close()
+
+	// Keep the chain from touching the state while pruning
+	final := chain.FinalizedHeader()
+	head := chain.CurrentBlock()
+	chain.Stop()
+
+	triedb := trie.NewDatabase(chainDb)
+	p, err := pruner.New(chainDb, triedb)
+	if err != nil {
+		utils.Fatalf("Failed to create pruner: %v", err)
+	}
+	start := time.Now()
+	if err := p.Keep(head.Root(), nil); err != nil {
+		utils.Fatalf("State of head block #%d unavailable: %v", head.NumberU64(), err)
+	}
+	kept, retain := 1, ctx.Uint64(pruneRetainFlag.Name)
+	for i := uint64(1); i < retain && i <= head.NumberU64(); i++ {
+		header := chain.GetHeaderByNumber(head.NumberU64() - i)
+		if err := p.Keep(header.Root, nil); err != nil {
+			log.Debug("State unavailable, skipping", "number", header.Number, "err", err)
+			continue
+		}
+		kept++
+	}
+	if final != nil && head.NumberU64()-final.Number.Uint64() >= retain {
+		if err := p.Keep(final.Root, nil); err != nil {
+			log.Warn("State of finalized block unavailable", "number", final.Number, "err", err)
+		} else {
+			kept++
+		}
+	}
+	log.Info("Marked state to keep", "states", kept, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	if _, _, err := p.Sweep(nil); err != nil {
+		utils.Fatalf("Failed to prune state: %v", err)
+	}
+	if db, ok := rawdb.DiskDB(chainDb).(vntdb.Store); ok {
+		start = time.Now()
+		log.Info("Compacting database")
+		if err := db.Compact(nil, nil); err != nil {
+			utils.Fatalf("Failed to compact database: %v", err)
+		}
+		log.Info("Compacted database", "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return nil
+}
+
+func verifyState(ctx *cli.Context) error {
+	stack := makeFullNode(ctx)
+	chainDb := utils.MakeChainDatabase(ctx, stack)
+	defer chainDb.Close()
+
+	head := rawdb.ReadHeadBlockHash(chainDb)
+	number := rawdb.ReadHeaderNumber(chainDb, head)
+	if number == nil {
+		utils.Fatalf("Head block unavailable")
+	}
+	header := rawdb.ReadHeader(chainDb, head, *number)
+	if header == nil {
+		utils.Fatalf("Head block #%d unavailable", *number)
+	}
+	start := time.Now()
+	if err := snapshot.VerifyState(chainDb, header.Root); err != nil {
+		utils.Fatalf("State snapshot of head block #%d invalid: %v", *number, err)
+	}
+	log.Info("Verified state snapshot", "number", *number, "root", header.Root, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
@@ -74,6 +74,8 @@ var AppHelpFlagGroups = []flagGroup{
 		Flags: []cli.Flag{
 			configFileFlag,
 			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.DBEngineFlag,
 			utils.KeyStoreDirFlag,
 			utils.NoUSBFlag,
 			utils.NetworkIdFlag,
@@ -81,6 +83,12 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.RinkebyFlag,
 			utils.SyncModeFlag,
 			utils.GCModeFlag,
+			utils.PruneIntervalFlag,
+			utils.SnapshotFlag,
+			utils.CheckpointFlag,
+			utils.CheckpointSignersFlag,
+			utils.CheckpointThresholdFlag,
+			utils.CheckpointTrustCertFlag,
 			utils.EthStatsURLFlag,
 			utils.IdentityFlag,
 			utils.LightServFlag,
@@ -107,6 +115,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.TxPoolAccountQueueFlag,
 			utils.TxPoolGlobalQueueFlag,
 			utils.TxPoolLifetimeFlag,
+			utils.TxPoolValidateWasmFlag,
 		},
 	},
 	{
@@ -152,15 +161,20 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.BootnodesFlag,
 			utils.BootnodesV4Flag,
 			utils.BootnodesV5Flag,
+			utils.BootnodeListsFlag,
 			utils.ListenPortFlag,
 			utils.MaxPeersFlag,
 			utils.MaxPendingPeersFlag,
 			utils.NATFlag,
 			utils.NoDiscoverFlag,
 			utils.DiscoveryV5Flag,
+			utils.MsgEventsFlag,
 			utils.NetrestrictFlag,
+			utils.PermissionedFlag,
+			utils.PermissionRegistryFlag,
 			utils.NodeKeyFileFlag,
 			utils.NodeKeyHexFlag,
+			utils.NodeKeyPasswordFlag,
 		},
 	},
 	{
@@ -181,6 +195,18 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.GpoPercentileFlag,
 		},
 	},
+	{
+		Name: "CONTRACT VERIFIER",
+		Flags: []cli.Flag{
+			utils.VerifierEnabledFlag,
+			utils.VerifierPersistFlag,
+			utils.VerifierWasmgenFlag,
+			utils.VerifierClangFlag,
+			utils.VerifierWasmLdFlag,
+			utils.VerifierIncludeFlag,
+			utils.VerifierTimeoutFlag,
+		},
+	},
 	{
 		Name: "VIRTUAL MACHINE",
 		Flags: []cli.Flag{
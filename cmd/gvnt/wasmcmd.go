@@ -0,0 +1,127 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of go-vnt.
+//
+// go-vnt is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-vnt is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-vnt. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/vntchain/go-vnt/cmd/utils"
+	"github.com/vntchain/go-vnt/common/hexutil"
+	"github.com/vntchain/go-vnt/core/wavm/validate"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	wasmAbiFlag = cli.StringFlag{
+		Name:  "abi",
+		Usage: "Abi json file of the contract, required for .wasm files",
+	}
+	wasmNoFloatFlag = cli.BoolFlag{
+		Name:  "nofloat",
+		Usage: "Reject floating point instructions",
+	}
+	wasmMaxMemoryFlag = cli.Uint64Flag{
+		Name:  "maxmemory",
+		Usage: "Maximum number of initial memory pages",
+		Value: uint64(validate.DefaultConfig.MaxMemoryPages),
+	}
+	wasmMaxTableFlag = cli.Uint64Flag{
+		Name:  "maxtable",
+		Usage: "Maximum number of initial table elements",
+		Value: uint64(validate.DefaultConfig.MaxTableSize),
+	}
+
+	wasmCommand = cli.Command{
+		Name:     "wasm",
+		Usage:    "Inspect wasm contracts",
+		Category: "MISCELLANEOUS COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Name:      "validate",
+				Usage:     "Statically validate a wasm contract",
+				ArgsUsage: "<wasmFile|payloadFile>",
+				Action:    utils.MigrateFlags(validateWasm),
+				Flags: []cli.Flag{
+					wasmAbiFlag,
+					wasmNoFloatFlag,
+					wasmMaxMemoryFlag,
+					wasmMaxTableFlag,
+				},
+				Description: `
+    gvnt wasm validate --abi abi.json contract.wasm
+    gvnt wasm validate contract.hex
+
+Checks a contract before it is deployed: the imports against the host functions,
+floating point instructions, memory and table limits, the exported functions
+against the abi methods and whether gas metering can be injected.
+
+The argument is either a compiled .wasm file together with its abi, or the
+deployment payload of a contract creation transaction, hex or raw.`,
+			},
+		},
+	}
+)
+
+func validateWasm(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	file := ctx.Args().First()
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		utils.Fatalf("Failed to read contract: %v", err)
+	}
+	config := validate.Config{
+		DisableFloatingPoint: ctx.Bool(wasmNoFloatFlag.Name),
+		MaxMemoryPages:       uint32(ctx.Uint64(wasmMaxMemoryFlag.Name)),
+		MaxTableSize:         uint32(ctx.Uint64(wasmMaxTableFlag.Name)),
+	}
+	if filepath.Ext(file) == ".wasm" {
+		if !ctx.IsSet(wasmAbiFlag.Name) {
+			utils.Fatalf("The --%s flag is required for .wasm files", wasmAbiFlag.Name)
+		}
+		abiJSON, err := ioutil.ReadFile(ctx.String(wasmAbiFlag.Name))
+		if err != nil {
+			utils.Fatalf("Failed to read abi: %v", err)
+		}
+		err = validate.Validate(data, abiJSON, &config)
+	} else {
+		data = bytes.TrimSpace(data)
+		if bytes.HasPrefix(data, []byte("0x")) {
+			if data, err = hexutil.Decode(string(data)); err != nil {
+				utils.Fatalf("Failed to decode payload: %v", err)
+			}
+		}
+		err = validate.ValidatePayload(data, &config)
+	}
+	if err == nil {
+		fmt.Printf("%s is valid\n", file)
+		return nil
+	}
+	if errs, ok := err.(validate.Errors); ok {
+		for _, err := range errs {
+			fmt.Println(err)
+		}
+	} else {
+		fmt.Println(err)
+	}
+	utils.Fatalf("%s is invalid", file)
+	return nil
+}
@@ -1,432 +1,512 @@
-// // Copyright 2017 The go-ethereum Authors
-// // This file is part of go-ethereum.
-// //
-// // go-ethereum is free software: you can redistribute it and/or modify
-// // it under the terms of the GNU General Public License as published by
-// // the Free Software Foundation, either version 3 of the License, or
-// // (at your option) any later version.
-// //
-// // go-ethereum is distributed in the hope that it will be useful,
-// // but WITHOUT ANY WARRANTY; without even the implied warranty of
-// // MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
-// // GNU General Public License for more details.
-// //
-// // You should have received a copy of the GNU General Public License
-// // along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
-
-// // p2psim provides a command-line client for a simulation HTTP API.
-// //
-// // Here is an example of creating a 2 node network with the first node
-// // connected to the second:
-// //
-// //     $ p2psim node create
-// //     Created node01
-// //
-// //     $ p2psim node start node01
-// //     Started node01
-// //
-// //     $ p2psim node create
-// //     Created node02
-// //
-// //     $ p2psim node start node02
-// //     Started node02
-// //
-// //     $ p2psim node connect node01 node02
-// //     Connected node01 to node02
-// //
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
 
+// p2psim runs vntp2p network simulations and provides a command-line client
+// for the simulation HTTP API.
+//
+// Here is an example of creating a 2 node network with the first node
+// connected to the second:
+//
+//	$ p2psim serve &
+//
+//	$ p2psim node create --protocols ping
+//	Created node01
+//
+//	$ p2psim node start node01
+//	Started node01
+//
+//	$ p2psim node create --protocols ping
+//	Created node02
+//
+//	$ p2psim node start node02
+//	Started node02
+//
+//	$ p2psim node connect node01 node02
+//	Connected node01 to node02
+//
+// Scripted scenarios run without a server:
+//
+//	$ p2psim run scenario.json
 package main
 
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/vntchain/go-vnt/log"
+	"github.com/vntchain/go-vnt/vntp2p/simulations"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var client *simulations.Client
+
+var (
+	latencyFlag = cli.DurationFlag{
+		Name:  "latency",
+		Usage: "one-way latency of the links",
+	}
+	lossFlag = cli.Float64Flag{
+		Name:  "loss",
+		Usage: "probability of a write being lost and retransmitted (0-1)",
+	}
+	verbosityFlag = cli.IntFlag{
+		Name:  "verbosity",
+		Usage: "log verbosity (0-9)",
+		Value: int(log.LvlWarn),
+	}
+)
+
 func main() {
+	app := cli.NewApp()
+	app.Usage = "vntp2p network simulator"
+	app.Flags = []cli.Flag{
+		cli.StringFlag{
+			Name:   "api",
+			Value:  "http://localhost:8888",
+			Usage:  "simulation API URL",
+			EnvVar: "P2PSIM_API_URL",
+		},
+	}
+	app.Before = func(ctx *cli.Context) error {
+		client = simulations.NewClient(ctx.GlobalString("api"))
+		return nil
+	}
+	app.Commands = []cli.Command{
+		{
+			Name:   "serve",
+			Usage:  "run a simulation and serve its HTTP API",
+			Action: serve,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "addr",
+					Value: "localhost:8888",
+					Usage: "HTTP listen address",
+				},
+				latencyFlag,
+				lossFlag,
+				verbosityFlag,
+			},
+		},
+		{
+			Name:      "run",
+			ArgsUsage: "<scenario.json>",
+			Usage:     "run a scenario in a new simulation",
+			Action:    runScenario,
+			Flags: []cli.Flag{
+				cli.DurationFlag{
+					Name:  "timeout",
+					Value: 10 * time.Minute,
+					Usage: "maximum run time of the scenario",
+				},
+				verbosityFlag,
+			},
+		},
+		{
+			Name:   "show",
+			Usage:  "show network information",
+			Action: showNetwork,
+		},
+		{
+			Name:   "node",
+			Usage:  "manage simulation nodes",
+			Action: listNodes,
+			Subcommands: []cli.Command{
+				{
+					Name:   "list",
+					Usage:  "list nodes",
+					Action: listNodes,
+				},
+				{
+					Name:   "create",
+					Usage:  "create a node",
+					Action: createNode,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "name",
+							Value: "",
+							Usage: "node name",
+						},
+						cli.StringFlag{
+							Name:  "protocols",
+							Value: "",
+							Usage: "node protocols (comma separated)",
+						},
+						cli.StringFlag{
+							Name:  "key",
+							Value: "",
+							Usage: "node private key (hex encoded)",
+						},
+						cli.IntFlag{
+							Name:  "maxpeers",
+							Usage: "maximum number of peers",
+						},
+						cli.BoolFlag{
+							Name:  "witness",
+							Usage: "vote in the bft protocol",
+						},
+						cli.Uint64Flag{
+							Name:  "blocks",
+							Usage: "number of blocks to start with in the sync protocol",
+						},
+					},
+				},
+				{
+					Name:      "show",
+					ArgsUsage: "<node>",
+					Usage:     "show node information",
+					Action:    showNode,
+				},
+				{
+					Name:      "peers",
+					ArgsUsage: "<node>",
+					Usage:     "show the peers of a node",
+					Action:    showPeers,
+				},
+				{
+					Name:      "start",
+					ArgsUsage: "<node>",
+					Usage:     "start a node",
+					Action:    startNode,
+				},
+				{
+					Name:      "stop",
+					ArgsUsage: "<node>",
+					Usage:     "stop a node",
+					Action:    stopNode,
+				},
+				{
+					Name:      "connect",
+					ArgsUsage: "<node> <peer>",
+					Usage:     "connect a node to a peer node",
+					Action:    connectNode,
+				},
+				{
+					Name:      "disconnect",
+					ArgsUsage: "<node> <peer>",
+					Usage:     "disconnect a node from a peer node",
+					Action:    disconnectNode,
+				},
+			},
+		},
+		{
+			Name:  "network",
+			Usage: "change the simulated network",
+			Subcommands: []cli.Command{
+				{
+					Name:      "link",
+					ArgsUsage: "[<node> <node>]",
+					Usage:     "set the quality of the link between two nodes, or of all links",
+					Action:    setLink,
+					Flags:     []cli.Flag{latencyFlag, lossFlag},
+				},
+				{
+					Name:      "partition",
+					ArgsUsage: "<node,node,...> [<node,node,...> ...]",
+					Usage:     "split the network into groups of nodes",
+					Action:    partition,
+				},
+				{
+					Name:   "heal",
+					Usage:  "remove the partitions",
+					Action: heal,
+				},
+			},
+		},
+	}
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
 
+func setupLogging(ctx *cli.Context) {
+	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	glogger.Verbosity(log.Lvl(ctx.Int(verbosityFlag.Name)))
+	log.Root().SetHandler(glogger)
 }
 
-// import (
-// 	"context"
-// 	"encoding/json"
-// 	"fmt"
-// 	"io"
-// 	"os"
-// 	"strings"
-// 	"text/tabwriter"
+func serve(ctx *cli.Context) error {
+	if len(ctx.Args()) != 0 {
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+	setupLogging(ctx)
 
-// 	"github.com/vntchain/go-vnt/crypto"
-// 	"github.com/vntchain/go-vnt/rpc"
-// 	"github.com/vntchain/go-vnt/vntp2p"
-// 	"gopkg.in/urfave/cli.v1"
-// )
+	sim := simulations.NewSimulation(simulations.LinkConfig{
+		Latency: ctx.Duration(latencyFlag.Name),
+		Loss:    ctx.Float64(lossFlag.Name),
+	})
+	defer sim.Shutdown()
 
-// var client *simulations.Client
+	server := &http.Server{Addr: ctx.String("addr"), Handler: simulations.NewServer(sim)}
+	go func() {
+		sigc := make(chan os.Signal, 1)
+		signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+		<-sigc
+		server.Close()
+	}()
+	fmt.Fprintln(ctx.App.Writer, "Serving simulation API on", server.Addr)
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
 
-// func main() {
-// 	app := cli.NewApp()
-// 	app.Usage = "devp2p simulation command-line client"
-// 	app.Flags = []cli.Flag{
-// 		cli.StringFlag{
-// 			Name:   "api",
-// 			Value:  "http://localhost:8888",
-// 			Usage:  "simulation API URL",
-// 			EnvVar: "P2PSIM_API_URL",
-// 		},
-// 	}
-// 	app.Before = func(ctx *cli.Context) error {
-// 		client = simulations.NewClient(ctx.GlobalString("api"))
-// 		return nil
-// 	}
-// 	app.Commands = []cli.Command{
-// 		{
-// 			Name:   "show",
-// 			Usage:  "show network information",
-// 			Action: showNetwork,
-// 		},
-// 		{
-// 			Name:   "events",
-// 			Usage:  "stream network events",
-// 			Action: streamNetwork,
-// 			Flags: []cli.Flag{
-// 				cli.BoolFlag{
-// 					Name:  "current",
-// 					Usage: "get existing nodes and conns first",
-// 				},
-// 				cli.StringFlag{
-// 					Name:  "filter",
-// 					Value: "",
-// 					Usage: "message filter",
-// 				},
-// 			},
-// 		},
-// 		{
-// 			Name:   "snapshot",
-// 			Usage:  "create a network snapshot to stdout",
-// 			Action: createSnapshot,
-// 		},
-// 		{
-// 			Name:   "load",
-// 			Usage:  "load a network snapshot from stdin",
-// 			Action: loadSnapshot,
-// 		},
-// 		{
-// 			Name:   "node",
-// 			Usage:  "manage simulation nodes",
-// 			Action: listNodes,
-// 			Subcommands: []cli.Command{
-// 				{
-// 					Name:   "list",
-// 					Usage:  "list nodes",
-// 					Action: listNodes,
-// 				},
-// 				{
-// 					Name:   "create",
-// 					Usage:  "create a node",
-// 					Action: createNode,
-// 					Flags: []cli.Flag{
-// 						cli.StringFlag{
-// 							Name:  "name",
-// 							Value: "",
-// 							Usage: "node name",
-// 						},
-// 						cli.StringFlag{
-// 							Name:  "services",
-// 							Value: "",
-// 							Usage: "node services (comma separated)",
-// 						},
-// 						cli.StringFlag{
-// 							Name:  "key",
-// 							Value: "",
-// 							Usage: "node private key (hex encoded)",
-// 						},
-// 					},
-// 				},
-// 				{
-// 					Name:      "show",
-// 					ArgsUsage: "<node>",
-// 					Usage:     "show node information",
-// 					Action:    showNode,
-// 				},
-// 				{
-// 					Name:      "start",
-// 					ArgsUsage: "<node>",
-// 					Usage:     "start a node",
-// 					Action:    startNode,
-// 				},
-// 				{
-// 					Name:      "stop",
-// 					ArgsUsage: "<node>",
-// 					Usage:     "stop a node",
-// 					Action:    stopNode,
-// 				},
-// 				{
-// 					Name:      "connect",
-// 					ArgsUsage: "<node> <peer>",
-// 					Usage:     "connect a node to a peer node",
-// 					Action:    connectNode,
-// 				},
-// 				{
-// 					Name:      "disconnect",
-// 					ArgsUsage: "<node> <peer>",
-// 					Usage:     "disconnect a node from a peer node",
-// 					Action:    disconnectNode,
-// 				},
-// 				{
-// 					Name:      "rpc",
-// 					ArgsUsage: "<node> <method> [<args>]",
-// 					Usage:     "call a node RPC method",
-// 					Action:    rpcNode,
-// 					Flags: []cli.Flag{
-// 						cli.BoolFlag{
-// 							Name:  "subscribe",
-// 							Usage: "method is a subscription",
-// 						},
-// 					},
-// 				},
-// 			},
-// 		},
-// 	}
-// 	app.Run(os.Args)
-// }
+func runScenario(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+	setupLogging(ctx)
 
-// func showNetwork(ctx *cli.Context) error {
-// 	if len(ctx.Args()) != 0 {
-// 		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
-// 	}
-// 	network, err := client.GetNetwork()
-// 	if err != nil {
-// 		return err
-// 	}
-// 	w := tabwriter.NewWriter(ctx.App.Writer, 1, 2, 2, ' ', 0)
-// 	defer w.Flush()
-// 	fmt.Fprintf(w, "NODES\t%d\n", len(network.Nodes))
-// 	fmt.Fprintf(w, "CONNS\t%d\n", len(network.Conns))
-// 	return nil
-// }
+	scenario, err := simulations.LoadScenario(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	sim := simulations.NewSimulation(simulations.LinkConfig{})
+	defer sim.Shutdown()
 
-// func streamNetwork(ctx *cli.Context) error {
-// 	if len(ctx.Args()) != 0 {
-// 		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
-// 	}
-// 	events := make(chan *simulations.Event)
-// 	sub, err := client.SubscribeNetwork(events, simulations.SubscribeOpts{
-// 		Current: ctx.Bool("current"),
-// 		Filter:  ctx.String("filter"),
-// 	})
-// 	if err != nil {
-// 		return err
-// 	}
-// 	defer sub.Unsubscribe()
-// 	enc := json.NewEncoder(ctx.App.Writer)
-// 	for {
-// 		select {
-// 		case event := <-events:
-// 			if err := enc.Encode(event); err != nil {
-// 				return err
-// 			}
-// 		case err := <-sub.Err():
-// 			return err
-// 		}
-// 	}
-// }
+	runCtx, cancel := context.WithTimeout(context.Background(), ctx.Duration("timeout"))
+	defer cancel()
+	if err := sim.Run(runCtx, scenario); err != nil {
+		return fmt.Errorf("scenario failed: %v", err)
+	}
+	w := tabwriter.NewWriter(ctx.App.Writer, 1, 2, 2, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintf(w, "PASSED\t%d steps\n", len(scenario.Steps))
+	for _, conn := range sim.Conns() {
+		fmt.Fprintf(w, "CONN\t%s\t%s\n", conn[0], conn[1])
+	}
+	return nil
+}
 
-// func createSnapshot(ctx *cli.Context) error {
-// 	if len(ctx.Args()) != 0 {
-// 		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
-// 	}
-// 	snap, err := client.CreateSnapshot()
-// 	if err != nil {
-// 		return err
-// 	}
-// 	return json.NewEncoder(os.Stdout).Encode(snap)
-// }
+func showNetwork(ctx *cli.Context) error {
+	if len(ctx.Args()) != 0 {
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+	network, err := client.GetNetwork()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(ctx.App.Writer, 1, 2, 2, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintf(w, "NODES\t%d\n", len(network.Nodes))
+	fmt.Fprintf(w, "CONNS\t%d\n", len(network.Conns))
+	for _, conn := range network.Conns {
+		fmt.Fprintf(w, "\t%s\t%s\n", conn[0], conn[1])
+	}
+	return nil
+}
+
+func listNodes(ctx *cli.Context) error {
+	if len(ctx.Args()) != 0 {
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+	nodes, err := client.GetNodes()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(ctx.App.Writer, 1, 2, 2, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintf(w, "NAME\tRUNNING\tPEERS\tPROTOCOLS\tID\n")
+	for _, node := range nodes {
+		fmt.Fprintf(w, "%s\t%t\t%d\t%s\t%s\n", node.Name, node.Running, node.Peers, strings.Join(protocolList(node), ","), node.ID)
+	}
+	return nil
+}
 
-// func loadSnapshot(ctx *cli.Context) error {
-// 	if len(ctx.Args()) != 0 {
-// 		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
-// 	}
-// 	snap := &simulations.Snapshot{}
-// 	if err := json.NewDecoder(os.Stdin).Decode(snap); err != nil {
-// 		return err
-// 	}
-// 	return client.LoadSnapshot(snap)
-// }
+func protocolList(node *simulations.NodeInfo) []string {
+	protos := make([]string, 0, len(node.Protocols))
+	for name := range node.Protocols {
+		protos = append(protos, name)
+	}
+	return protos
+}
 
-// func listNodes(ctx *cli.Context) error {
-// 	if len(ctx.Args()) != 0 {
-// 		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
-// 	}
-// 	nodes, err := client.GetNodes()
-// 	if err != nil {
-// 		return err
-// 	}
-// 	w := tabwriter.NewWriter(ctx.App.Writer, 1, 2, 2, ' ', 0)
-// 	defer w.Flush()
-// 	fmt.Fprintf(w, "NAME\tPROTOCOLS\tID\n")
-// 	for _, node := range nodes {
-// 		fmt.Fprintf(w, "%s\t%s\t%s\n", node.Name, strings.Join(protocolList(node), ","), node.ID)
-// 	}
-// 	return nil
-// }
+func createNode(ctx *cli.Context) error {
+	if len(ctx.Args()) != 0 {
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+	req := &simulations.CreateNodeRequest{
+		NodeConfig: simulations.NodeConfig{
+			Name:     ctx.String("name"),
+			MaxPeers: ctx.Int("maxpeers"),
+			Witness:  ctx.Bool("witness"),
+			Blocks:   ctx.Uint64("blocks"),
+		},
+		Key: ctx.String("key"),
+	}
+	if protocols := ctx.String("protocols"); protocols != "" {
+		req.Protocols = strings.Split(protocols, ",")
+	}
+	node, err := client.CreateNode(req)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(ctx.App.Writer, "Created", node.Name)
+	return nil
+}
 
-// func protocolList(node *vntp2p.NodeInfo) []string {
-// 	protos := make([]string, 0, len(node.Protocols))
-// 	for name := range node.Protocols {
-// 		protos = append(protos, name)
-// 	}
-// 	return protos
-// }
+func showNode(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) != 1 {
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+	nodeName := args[0]
+	node, err := client.GetNode(nodeName)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(ctx.App.Writer, 1, 2, 2, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintf(w, "NAME\t%s\n", node.Name)
+	fmt.Fprintf(w, "PROTOCOLS\t%s\n", strings.Join(protocolList(node), ","))
+	fmt.Fprintf(w, "ID\t%s\n", node.ID)
+	fmt.Fprintf(w, "URL\t%s\n", node.URL)
+	fmt.Fprintf(w, "RUNNING\t%t\n", node.Running)
+	fmt.Fprintf(w, "PEERS\t%d\n", node.Peers)
+	for name, proto := range node.Protocols {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "--- PROTOCOL INFO: %s\n", name)
+		fmt.Fprintf(w, "%v\n", proto)
+		fmt.Fprintf(w, "---\n")
+	}
+	return nil
+}
 
-// func createNode(ctx *cli.Context) error {
-// 	if len(ctx.Args()) != 0 {
-// 		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
-// 	}
-// 	config := &adapters.NodeConfig{
-// 		Name: ctx.String("name"),
-// 	}
-// 	if key := ctx.String("key"); key != "" {
-// 		privKey, err := crypto.HexToECDSA(key)
-// 		if err != nil {
-// 			return err
-// 		}
-// 		config.ID = vntp2p.PubkeyID(&privKey.PublicKey)
-// 		config.PrivateKey = privKey
-// 	}
-// 	if services := ctx.String("services"); services != "" {
-// 		config.Services = strings.Split(services, ",")
-// 	}
-// 	node, err := client.CreateNode(config)
-// 	if err != nil {
-// 		return err
-// 	}
-// 	fmt.Fprintln(ctx.App.Writer, "Created", node.Name)
-// 	return nil
-// }
+func showPeers(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) != 1 {
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+	peers, err := client.GetPeers(args[0])
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(ctx.App.Writer)
+	enc.SetIndent("", "  ")
+	return enc.Encode(peers)
+}
 
-// func showNode(ctx *cli.Context) error {
-// 	args := ctx.Args()
-// 	if len(args) != 1 {
-// 		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
-// 	}
-// 	nodeName := args[0]
-// 	node, err := client.GetNode(nodeName)
-// 	if err != nil {
-// 		return err
-// 	}
-// 	w := tabwriter.NewWriter(ctx.App.Writer, 1, 2, 2, ' ', 0)
-// 	defer w.Flush()
-// 	fmt.Fprintf(w, "NAME\t%s\n", node.Name)
-// 	fmt.Fprintf(w, "PROTOCOLS\t%s\n", strings.Join(protocolList(node), ","))
-// 	fmt.Fprintf(w, "ID\t%s\n", node.ID)
-// 	fmt.Fprintf(w, "VNODE\t%s\n", node.Vnode)
-// 	for name, proto := range node.Protocols {
-// 		fmt.Fprintln(w)
-// 		fmt.Fprintf(w, "--- PROTOCOL INFO: %s\n", name)
-// 		fmt.Fprintf(w, "%v\n", proto)
-// 		fmt.Fprintf(w, "---\n")
-// 	}
-// 	return nil
-// }
+func startNode(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) != 1 {
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+	nodeName := args[0]
+	if err := client.StartNode(nodeName); err != nil {
+		return err
+	}
+	fmt.Fprintln(ctx.App.Writer, "Started", nodeName)
+	return nil
+}
 
-// func startNode(ctx *cli.Context) error {
-// 	args := ctx.Args()
-// 	if len(args) != 1 {
-// 		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
-// 	}
-// 	nodeName := args[0]
-// 	if err := client.StartNode(nodeName); err != nil {
-// 		return err
-// 	}
-// 	fmt.Fprintln(ctx.App.Writer, "Started", nodeName)
-// 	return nil
-// }
+func stopNode(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) != 1 {
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+	nodeName := args[0]
+	if err := client.StopNode(nodeName); err != nil {
+		return err
+	}
+	fmt.Fprintln(ctx.App.Writer, "Stopped", nodeName)
+	return nil
+}
 
-// func stopNode(ctx *cli.Context) error {
-// 	args := ctx.Args()
-// 	if len(args) != 1 {
-// 		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
-// 	}
-// 	nodeName := args[0]
-// 	if err := client.StopNode(nodeName); err != nil {
-// 		return err
-// 	}
-// 	fmt.Fprintln(ctx.App.Writer, "Stopped", nodeName)
-// 	return nil
-// }
+func connectNode(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) != 2 {
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+	nodeName := args[0]
+	peerName := args[1]
+	if err := client.ConnectNode(nodeName, peerName); err != nil {
+		return err
+	}
+	fmt.Fprintln(ctx.App.Writer, "Connected", nodeName, "to", peerName)
+	return nil
+}
 
-// func connectNode(ctx *cli.Context) error {
-// 	args := ctx.Args()
-// 	if len(args) != 2 {
-// 		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
-// 	}
-// 	nodeName := args[0]
-// 	peerName := args[1]
-// 	if err := client.ConnectNode(nodeName, peerName); err != nil {
-// 		return err
-// 	}
-// 	fmt.Fprintln(ctx.App.Writer, "Connected", nodeName, "to", peerName)
-// 	return nil
-// }
+func disconnectNode(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) != 2 {
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+	nodeName := args[0]
+	peerName := args[1]
+	if err := client.DisconnectNode(nodeName, peerName); err != nil {
+		return err
+	}
+	fmt.Fprintln(ctx.App.Writer, "Disconnected", nodeName, "from", peerName)
+	return nil
+}
 
-// func disconnectNode(ctx *cli.Context) error {
-// 	args := ctx.Args()
-// 	if len(args) != 2 {
-// 		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
-// 	}
-// 	nodeName := args[0]
-// 	peerName := args[1]
-// 	if err := client.DisconnectNode(nodeName, peerName); err != nil {
-// 		return err
-// 	}
-// 	fmt.Fprintln(ctx.App.Writer, "Disconnected", nodeName, "from", peerName)
-// 	return nil
-// }
+func setLink(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) != 0 && len(args) != 2 {
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+	link := simulations.LinkConfig{
+		Latency: ctx.Duration(latencyFlag.Name),
+		Loss:    ctx.Float64(lossFlag.Name),
+	}
+	if len(args) == 0 {
+		if err := client.SetLink("", "", link); err != nil {
+			return err
+		}
+		fmt.Fprintln(ctx.App.Writer, "Changed all links")
+		return nil
+	}
+	if err := client.SetLink(args[0], args[1], link); err != nil {
+		return err
+	}
+	fmt.Fprintln(ctx.App.Writer, "Changed link between", args[0], "and", args[1])
+	return nil
+}
 
-// func rpcNode(ctx *cli.Context) error {
-// 	args := ctx.Args()
-// 	if len(args) < 2 {
-// 		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
-// 	}
-// 	nodeName := args[0]
-// 	method := args[1]
-// 	rpcClient, err := client.RPCClient(context.Background(), nodeName)
-// 	if err != nil {
-// 		return err
-// 	}
-// 	if ctx.Bool("subscribe") {
-// 		return rpcSubscribe(rpcClient, ctx.App.Writer, method, args[3:]...)
-// 	}
-// 	var result interface{}
-// 	params := make([]interface{}, len(args[3:]))
-// 	for i, v := range args[3:] {
-// 		params[i] = v
-// 	}
-// 	if err := rpcClient.Call(&result, method, params...); err != nil {
-// 		return err
-// 	}
-// 	return json.NewEncoder(ctx.App.Writer).Encode(result)
-// }
+func partition(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) == 0 {
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+	groups := make([][]string, len(args))
+	for i, arg := range args {
+		groups[i] = strings.Split(arg, ",")
+	}
+	if err := client.Partition(groups); err != nil {
+		return err
+	}
+	fmt.Fprintln(ctx.App.Writer, "Partitioned network into", len(groups)+1, "groups")
+	return nil
+}
 
-// func rpcSubscribe(client *rpc.Client, out io.Writer, method string, args ...string) error {
-// 	parts := strings.SplitN(method, "_", 2)
-// 	namespace := parts[0]
-// 	method = parts[1]
-// 	ch := make(chan interface{})
-// 	subArgs := make([]interface{}, len(args)+1)
-// 	subArgs[0] = method
-// 	for i, v := range args {
-// 		subArgs[i+1] = v
-// 	}
-// 	sub, err := client.Subscribe(context.Background(), namespace, ch, subArgs...)
-// 	if err != nil {
-// 		return err
-// 	}
-// 	defer sub.Unsubscribe()
-// 	enc := json.NewEncoder(out)
-// 	for {
-// 		select {
-// 		case v := <-ch:
-// 			if err := enc.Encode(v); err != nil {
-// 				return err
-// 			}
-// 		case err := <-sub.Err():
-// 			return err
-// 		}
-// 	}
-// }
+func heal(ctx *cli.Context) error {
+	if len(ctx.Args()) != 0 {
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+	if err := client.Heal(); err != nil {
+		return err
+	}
+	fmt.Fprintln(ctx.App.Writer, "Healed network")
+	return nil
+}
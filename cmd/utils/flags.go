@@ -21,6 +21,7 @@ import (
 	"crypto/ecdsa"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -45,6 +46,7 @@ import (
 	"github.com/vntchain/go-vnt/vnt"
 	"github.com/vntchain/go-vnt/vnt/downloader"
 	"github.com/vntchain/go-vnt/vnt/gasprice"
+	"github.com/vntchain/go-vnt/vnt/verifier"
 	"github.com/vntchain/go-vnt/vntdb"
 	"github.com/vntchain/go-vnt/vntp2p"
 	"github.com/vntchain/go-vnt/vntstats"
@@ -123,6 +125,14 @@ var (
 		Usage: "Data directory for the databases and keystore",
 		Value: DirectoryString{node.DefaultDataDir()},
 	}
+	AncientFlag = DirectoryFlag{
+		Name:  "datadir.ancient",
+		Usage: "Data directory for the ancient store of finalized blocks (default = inside chaindata)",
+	}
+	DBEngineFlag = cli.StringFlag{
+		Name:  "db.engine",
+		Usage: `Engine of new databases ("leveldb" or "badger", default = the one of existing databases or leveldb)`,
+	}
 	KeyStoreDirFlag = DirectoryFlag{
 		Name:  "keystore",
 		Usage: "Directory for the keystore (default = inside the datadir)",
@@ -146,7 +156,7 @@ var (
 	}
 	DeveloperFlag = cli.BoolFlag{
 		Name:  "dev",
-		Usage: "Ephemeral proof-of-authority network with a pre-funded developer account, mining enabled",
+		Usage: "Ephemeral single witness DPoS network with a pre-funded developer account, mining enabled",
 	}
 	DeveloperPeriodFlag = cli.IntFlag{
 		Name:  "dev.period",
@@ -180,6 +190,30 @@ var (
 		Usage: `Blockchain garbage collection mode ("full", "archive")`,
 		Value: "full",
 	}
+	PruneIntervalFlag = cli.Uint64Flag{
+		Name:  "prune.interval",
+		Usage: "Blocks between prunings of the stale state, keeping the finality window only (0 = never, full gcmode only)",
+	}
+	SnapshotFlag = cli.BoolFlag{
+		Name:  "snapshot",
+		Usage: "Serve state reads from a flat snapshot, generated in the background if missing",
+	}
+	CheckpointFlag = cli.StringFlag{
+		Name:  "checkpoint",
+		Usage: "JSON file of the trusted checkpoint to sync from",
+	}
+	CheckpointSignersFlag = cli.StringFlag{
+		Name:  "checkpoint.signers",
+		Usage: "Comma separated addresses of the keys trusted to sign checkpoints",
+	}
+	CheckpointThresholdFlag = cli.IntFlag{
+		Name:  "checkpoint.threshold",
+		Usage: "Number of trusted keys which have to sign a checkpoint (default = all signers)",
+	}
+	CheckpointTrustCertFlag = cli.BoolFlag{
+		Name:  "checkpoint.trustcert",
+		Usage: "Accept an unsigned checkpoint certified by the genesis or embedded checkpoint witnesses",
+	}
 	LightServFlag = cli.IntFlag{
 		Name:  "lightserv",
 		Usage: "Maximum percentage of time allowed for serving LES requests (0-90)",
@@ -244,6 +278,10 @@ var (
 		Usage: "Maximum amount of time non-executable transaction are queued",
 		Value: vnt.DefaultConfig.TxPool.Lifetime,
 	}
+	TxPoolValidateWasmFlag = cli.BoolFlag{
+		Name:  "txpool.validatewasm",
+		Usage: "Statically validate wasm contracts before admitting contract creations",
+	}
 	// Performance tuning settings
 	CacheFlag = cli.IntFlag{
 		Name:  "cache",
@@ -425,6 +463,11 @@ var (
 		Usage: "Comma separated vnode URLs for P2P v5 discovery bootstrap (light server, light nodes)",
 		Value: "",
 	}
+	BootnodeListsFlag = cli.StringFlag{
+		Name:  "bootnodelists",
+		Usage: "Comma separated signed bootstrap lists (dns://<pubkey>@<domain> or file://<pubkey>@<path>)",
+		Value: "",
+	}
 	NodeKeyFileFlag = cli.StringFlag{
 		Name:  "nodekey",
 		Usage: "P2P node key file",
@@ -433,6 +476,10 @@ var (
 		Name:  "nodekeyhex",
 		Usage: "P2P node key as hex (for testing)",
 	}
+	NodeKeyPasswordFlag = cli.StringFlag{
+		Name:  "nodekeypassword",
+		Usage: "File containing the passphrase of the encrypted P2P node key",
+	}
 	NATFlag = cli.StringFlag{
 		Name:  "nat",
 		Usage: "NAT port mapping mechanism (any|none)",
@@ -446,10 +493,22 @@ var (
 		Name:  "v5disc",
 		Usage: "Enables the experimental RLPx V5 (Topic Discovery) mechanism",
 	}
+	MsgEventsFlag = cli.BoolFlag{
+		Name:  "msgevents",
+		Usage: "Emits an admin_peerEvents event for every P2P message sent or received",
+	}
 	NetrestrictFlag = cli.StringFlag{
 		Name:  "netrestrict",
 		Usage: "Restricts network communication to the given IP networks (CIDR masks)",
 	}
+	PermissionedFlag = cli.BoolFlag{
+		Name:  "permissioned",
+		Usage: "Only allow the peers of the permissioned nodes file and registry to connect",
+	}
+	PermissionRegistryFlag = cli.StringFlag{
+		Name:  "permissionregistry",
+		Usage: "Address of the on-chain registry contract extending the allow-list of a permissioned network",
+	}
 
 	// ATM the url is left to the user and deployment to
 	JSpathFlag = cli.StringFlag{
@@ -469,6 +528,39 @@ var (
 		Usage: "Suggested gas price is the given percentile of a set of recent transaction gas prices",
 		Value: vnt.DefaultConfig.GPO.Percentile,
 	}
+	// Contract verifier settings
+	VerifierEnabledFlag = cli.BoolFlag{
+		Name:  "verifier",
+		Usage: "Enable verification of submitted contract sources",
+	}
+	VerifierPersistFlag = cli.BoolFlag{
+		Name:  "verifier.persist",
+		Usage: "Keep verified contract sources across restarts",
+	}
+	VerifierWasmgenFlag = cli.StringFlag{
+		Name:  "verifier.wasmgen",
+		Usage: "Path of the wasmgen tool used to package contract sources",
+		Value: vnt.DefaultConfig.Verifier.Wasmgen,
+	}
+	VerifierClangFlag = cli.StringFlag{
+		Name:  "verifier.clang",
+		Usage: "Path of the clang compiler used to compile contract sources",
+		Value: vnt.DefaultConfig.Verifier.Clang,
+	}
+	VerifierWasmLdFlag = cli.StringFlag{
+		Name:  "verifier.wasmld",
+		Usage: "Path of the wasm linker used to link contract sources",
+		Value: vnt.DefaultConfig.Verifier.WasmLd,
+	}
+	VerifierIncludeFlag = DirectoryFlag{
+		Name:  "verifier.include",
+		Usage: "Directory containing vntlib.h",
+	}
+	VerifierTimeoutFlag = cli.DurationFlag{
+		Name:  "verifier.timeout",
+		Usage: "Maximum time packaging a contract source may take",
+		Value: vnt.DefaultConfig.Verifier.Timeout,
+	}
 	WhisperEnabledFlag = cli.BoolFlag{
 		Name:  "shh",
 		Usage: "Enable Whisper",
@@ -516,7 +608,7 @@ func setNodeKey(ctx *cli.Context, cfg *vntp2p.Config) {
 	case file != "" && hex != "":
 		Fatalf("Options %q and %q are mutually exclusive", NodeKeyFileFlag.Name, NodeKeyHexFlag.Name)
 	case file != "":
-		if key, err = crypto.LoadECDSA(file); err != nil {
+		if key, err = vntp2p.LoadNodeKey(file, nodeKeyPassphrase(ctx)); err != nil {
 			Fatalf("Option %q: %v", NodeKeyFileFlag.Name, err)
 		}
 		cfg.PrivateKey = key
@@ -528,6 +620,20 @@ func setNodeKey(ctx *cli.Context, cfg *vntp2p.Config) {
 	}
 }
 
+// nodeKeyPassphrase returns the passphrase of the encrypted node key, or an
+// empty string if the key is not encrypted.
+func nodeKeyPassphrase(ctx *cli.Context) string {
+	path := ctx.GlobalString(NodeKeyPasswordFlag.Name)
+	if path == "" {
+		return ""
+	}
+	text, err := ioutil.ReadFile(path)
+	if err != nil {
+		Fatalf("Failed to read node key password file: %v", err)
+	}
+	return strings.TrimRight(strings.SplitN(string(text), "\n", 2)[0], "\r")
+}
+
 // setNodeUserIdent creates the user identifier from CLI flags.
 func setNodeUserIdent(ctx *cli.Context, cfg *node.Config) {
 	if identity := ctx.GlobalString(IdentityFlag.Name); len(identity) > 0 {
@@ -712,6 +818,17 @@ func setIPC(ctx *cli.Context, cfg *node.Config) {
 
 // makeDatabaseHandles raises out the number of allowed file handles per process
 // for Gvnt and returns half of the allowance to assign to the database.
+// isDatabaseEngine returns whether engine is one of the supported database
+// engines.
+func isDatabaseEngine(engine string) bool {
+	for _, known := range vntdb.Engines {
+		if engine == known {
+			return true
+		}
+	}
+	return false
+}
+
 func makeDatabaseHandles() int {
 	limit, err := fdlimit.Current()
 	if err != nil {
@@ -791,6 +908,9 @@ func SetP2PConfig(ctx *cli.Context, cfg *vntp2p.Config) {
 	setVNTBootnode(ctx, cfg) // yhx
 	setBootstrapNodes(ctx, cfg)
 	// setBootstrapNodesV5(ctx, cfg)
+	if ctx.GlobalIsSet(BootnodeListsFlag.Name) {
+		cfg.BootstrapLists = strings.Split(ctx.GlobalString(BootnodeListsFlag.Name), ",")
+	}
 
 	lightClient := ctx.GlobalBool(LightModeFlag.Name) || ctx.GlobalString(SyncModeFlag.Name) == "light"
 	lightServer := ctx.GlobalInt(LightServFlag.Name) != 0
@@ -836,6 +956,10 @@ func SetP2PConfig(ctx *cli.Context, cfg *vntp2p.Config) {
 	// }
 	// fmt.Println(forceV5Discovery)
 
+	if ctx.GlobalIsSet(MsgEventsFlag.Name) {
+		cfg.EnableMsgEvents = true
+	}
+
 	if netrestrict := ctx.GlobalString(NetrestrictFlag.Name); netrestrict != "" {
 		list, err := vntp2p.ParseNetlist(netrestrict)
 		if err != nil {
@@ -844,6 +968,10 @@ func SetP2PConfig(ctx *cli.Context, cfg *vntp2p.Config) {
 		cfg.NetRestrict = list
 	}
 
+	if ctx.GlobalIsSet(PermissionedFlag.Name) || ctx.GlobalIsSet(PermissionRegistryFlag.Name) {
+		cfg.Permissioned = true
+	}
+
 	if ctx.GlobalBool(DeveloperFlag.Name) {
 		// --dev mode can't use p2p networking.
 		cfg.MaxPeers = 0
@@ -860,6 +988,7 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setHTTP(ctx, cfg)
 	setWS(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
+	cfg.NodeKeyPassphrase = nodeKeyPassphrase(ctx)
 
 	switch {
 	case ctx.GlobalIsSet(DataDirFlag.Name):
@@ -872,6 +1001,12 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 		cfg.DataDir = filepath.Join(node.DefaultDataDir(), "rinkeby")
 	}
 
+	if ctx.GlobalIsSet(DBEngineFlag.Name) {
+		cfg.DBEngine = ctx.GlobalString(DBEngineFlag.Name)
+		if !isDatabaseEngine(cfg.DBEngine) {
+			Fatalf("Unknown database engine %q, want one of %v", cfg.DBEngine, vntdb.Engines)
+		}
+	}
 	if ctx.GlobalIsSet(KeyStoreDirFlag.Name) {
 		cfg.KeyStoreDir = ctx.GlobalString(KeyStoreDirFlag.Name)
 	}
@@ -892,6 +1027,30 @@ func setGPO(ctx *cli.Context, cfg *gasprice.Config) {
 	}
 }
 
+func setVerifier(ctx *cli.Context, cfg *verifier.Config) {
+	if ctx.GlobalIsSet(VerifierEnabledFlag.Name) {
+		cfg.Enabled = ctx.GlobalBool(VerifierEnabledFlag.Name)
+	}
+	if ctx.GlobalIsSet(VerifierPersistFlag.Name) {
+		cfg.Persist = ctx.GlobalBool(VerifierPersistFlag.Name)
+	}
+	if ctx.GlobalIsSet(VerifierWasmgenFlag.Name) {
+		cfg.Wasmgen = ctx.GlobalString(VerifierWasmgenFlag.Name)
+	}
+	if ctx.GlobalIsSet(VerifierClangFlag.Name) {
+		cfg.Clang = ctx.GlobalString(VerifierClangFlag.Name)
+	}
+	if ctx.GlobalIsSet(VerifierWasmLdFlag.Name) {
+		cfg.WasmLd = ctx.GlobalString(VerifierWasmLdFlag.Name)
+	}
+	if ctx.GlobalIsSet(VerifierIncludeFlag.Name) {
+		cfg.Include = ctx.GlobalString(VerifierIncludeFlag.Name)
+	}
+	if ctx.GlobalIsSet(VerifierTimeoutFlag.Name) {
+		cfg.Timeout = ctx.GlobalDuration(VerifierTimeoutFlag.Name)
+	}
+}
+
 func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolNoLocalsFlag.Name) {
 		cfg.NoLocals = ctx.GlobalBool(TxPoolNoLocalsFlag.Name)
@@ -923,6 +1082,9 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolValidateWasmFlag.Name) {
+		cfg.ValidateWasm = ctx.GlobalBool(TxPoolValidateWasmFlag.Name)
+	}
 }
 
 // checkExclusive verifies that only a single isntance of the provided flags was
@@ -984,8 +1146,38 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *vnt.Config) {
 	ks := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
 	setEtherbase(ctx, ks, cfg)
 	setGPO(ctx, &cfg.GPO)
+	setVerifier(ctx, &cfg.Verifier)
 	setTxPool(ctx, &cfg.TxPool)
 
+	if ctx.GlobalIsSet(PermissionRegistryFlag.Name) {
+		registry := ctx.GlobalString(PermissionRegistryFlag.Name)
+		if !common.IsHexAddress(registry) {
+			Fatalf("Option %q: invalid address %q", PermissionRegistryFlag.Name, registry)
+		}
+		address := common.HexToAddress(registry)
+		cfg.PermissionRegistry = &address
+	}
+
+	if ctx.GlobalIsSet(CheckpointFlag.Name) {
+		cfg.CheckpointFile = ctx.GlobalString(CheckpointFlag.Name)
+	}
+	if ctx.GlobalIsSet(CheckpointSignersFlag.Name) {
+		cfg.CheckpointSigners = nil
+		for _, signer := range splitAndTrim(ctx.GlobalString(CheckpointSignersFlag.Name)) {
+			if !common.IsHexAddress(signer) {
+				Fatalf("Option %q: invalid address %q", CheckpointSignersFlag.Name, signer)
+			}
+			cfg.CheckpointSigners = append(cfg.CheckpointSigners, common.HexToAddress(signer))
+		}
+		cfg.CheckpointThreshold = len(cfg.CheckpointSigners)
+	}
+	if ctx.GlobalIsSet(CheckpointThresholdFlag.Name) {
+		cfg.CheckpointThreshold = ctx.GlobalInt(CheckpointThresholdFlag.Name)
+	}
+	if ctx.GlobalIsSet(CheckpointTrustCertFlag.Name) {
+		cfg.CheckpointTrustCert = ctx.GlobalBool(CheckpointTrustCertFlag.Name)
+	}
+
 	switch {
 	case ctx.GlobalIsSet(SyncModeFlag.Name):
 		cfg.SyncMode = *GlobalTextMarshaler(ctx, SyncModeFlag.Name).(*downloader.SyncMode)
@@ -1007,12 +1199,24 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *vnt.Config) {
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheDatabaseFlag.Name) {
 		cfg.DatabaseCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheDatabaseFlag.Name) / 100
 	}
+	if ctx.GlobalIsSet(AncientFlag.Name) {
+		cfg.DatabaseFreezer = ctx.GlobalString(AncientFlag.Name)
+	}
 	cfg.DatabaseHandles = makeDatabaseHandles()
 
 	if gcmode := ctx.GlobalString(GCModeFlag.Name); gcmode != "full" && gcmode != "archive" {
 		Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
 	}
 	cfg.NoPruning = ctx.GlobalString(GCModeFlag.Name) == "archive"
+	if ctx.GlobalIsSet(PruneIntervalFlag.Name) {
+		if cfg.NoPruning {
+			Fatalf("--%s is only supported in full gcmode", PruneIntervalFlag.Name)
+		}
+		cfg.PruneInterval = ctx.GlobalUint64(PruneIntervalFlag.Name)
+	}
+	if ctx.GlobalIsSet(SnapshotFlag.Name) {
+		cfg.Snapshot = ctx.GlobalBool(SnapshotFlag.Name)
+	}
 
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheGCFlag.Name) {
 		cfg.TrieCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheGCFlag.Name) / 100
@@ -1046,6 +1250,37 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *vnt.Config) {
 			cfg.NetworkId = 4
 		}
 		cfg.Genesis = core.DefaultRinkebyGenesisBlock()
+	case ctx.GlobalBool(DeveloperFlag.Name):
+		// Create new developer account or reuse existing one
+		var (
+			developer accounts.Account
+			err       error
+		)
+		if accs := ks.Accounts(); len(accs) > 0 {
+			developer = ks.Accounts()[0]
+		} else {
+			developer, err = ks.NewAccount("")
+			if err != nil {
+				Fatalf("Failed to create developer account: %v", err)
+			}
+		}
+		if err := ks.Unlock(developer, ""); err != nil {
+			Fatalf("Failed to unlock developer account: %v", err)
+		}
+		log.Info("Using developer account", "address", developer.Address)
+
+		// A zero period means sealing a block as soon as a transaction is pending,
+		// DPoS still needs time slots, so use the shortest one.
+		period := uint64(ctx.GlobalInt(DeveloperPeriodFlag.Name))
+		if period == 0 {
+			period = 1
+			cfg.MinerOnDemand = true
+		}
+		cfg.Etherbase = developer.Address
+		cfg.Genesis = core.DeveloperGenesisBlock(period, developer.Address)
+		if !ctx.GlobalIsSet(GasPriceFlag.Name) {
+			cfg.GasPrice = big.NewInt(1)
+		}
 	}
 	// TODO(fjl): move trie cache generations into config
 	if gen := ctx.GlobalInt(TrieCacheGenFlag.Name); gen > 0 {
@@ -1113,11 +1348,15 @@ func MakeChainDatabase(ctx *cli.Context, stack *node.Node) vntdb.Database {
 		cache   = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheDatabaseFlag.Name) / 100
 		handles = makeDatabaseHandles()
 	)
-	name := "chaindata"
+	var (
+		chainDb vntdb.Database
+		err     error
+	)
 	if ctx.GlobalBool(LightModeFlag.Name) {
-		name = "lightchaindata"
+		chainDb, err = stack.OpenDatabase("lightchaindata", cache, handles)
+	} else {
+		chainDb, err = stack.OpenDatabaseWithFreezer("chaindata", cache, handles, ctx.GlobalString(AncientFlag.Name))
 	}
-	chainDb, err := stack.OpenDatabase(name, cache, handles)
 	if err != nil {
 		Fatalf("Could not open database: %v", err)
 	}
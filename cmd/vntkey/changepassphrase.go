@@ -3,7 +3,6 @@ package main
 import (
 	"fmt"
 	"io/ioutil"
-	"strings"
 
 	"github.com/vntchain/go-vnt/accounts/keystore"
 	"github.com/vntchain/go-vnt/cmd/utils"
@@ -43,16 +42,7 @@ Change the passphrase of a keyfile.`,
 
 		// Get a new passphrase.
 		fmt.Println("Please provide a new passphrase")
-		var newPhrase string
-		if passFile := ctx.String(newPassphraseFlag.Name); passFile != "" {
-			content, err := ioutil.ReadFile(passFile)
-			if err != nil {
-				utils.Fatalf("Failed to read new passphrase file '%s': %v", passFile, err)
-			}
-			newPhrase = strings.TrimRight(string(content), "\r\n")
-		} else {
-			newPhrase = promptPassphrase(true)
-		}
+		newPhrase := getNewPassphrase(ctx)
 
 		// Encrypt the key with the new passphrase.
 		newJson, err := keystore.EncryptKey(key, newPhrase, keystore.StandardScryptN, keystore.StandardScryptP)
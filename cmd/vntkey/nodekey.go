@@ -0,0 +1,149 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of go-vnt.
+//
+// go-vnt is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-vnt is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-vnt. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+
+	peer "github.com/libp2p/go-libp2p-peer"
+	"github.com/vntchain/go-vnt/cmd/utils"
+	"github.com/vntchain/go-vnt/crypto"
+	"github.com/vntchain/go-vnt/vntp2p"
+	"gopkg.in/urfave/cli.v1"
+)
+
+type outputRotateNodeKey struct {
+	OldID string `json:",omitempty"`
+	ID    string
+	URL   string
+}
+
+var commandRotateNodeKey = cli.Command{
+	Name:      "rotatenodekey",
+	Usage:     "replace a node key with a new one",
+	ArgsUsage: "<nodekey>",
+	Description: `
+Replace the p2p node key, e.g. <datadir>/gvnt/nodekey, with a new random key
+and print the node URL of the new identity. The previous key is kept in
+<nodekey>.old.
+
+An encrypted old key is decrypted with the passphrase read from --passwordfile
+or prompted for. With --encrypt the new key is encrypted with the passphrase
+read from --newpasswordfile or prompted for, gvnt reads it from the file given
+by --nodekeypassword.
+`,
+	Flags: []cli.Flag{
+		passphraseFlag,
+		newPassphraseFlag,
+		jsonFlag,
+		cli.BoolFlag{
+			Name:  "encrypt",
+			Usage: "encrypt the new node key with a passphrase",
+		},
+		cli.StringFlag{
+			Name:  "ip",
+			Usage: "public IP address of the node in the printed URL",
+			Value: "127.0.0.1",
+		},
+		cli.IntFlag{
+			Name:  "port",
+			Usage: "listening port of the node in the printed URL",
+			Value: 30303,
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		keyfile := ctx.Args().First()
+		if keyfile == "" {
+			utils.Fatalf("No node key file given")
+		}
+		ip := net.ParseIP(ctx.String("ip"))
+		if ip == nil {
+			utils.Fatalf("Invalid IP address %q", ctx.String("ip"))
+		}
+		var out outputRotateNodeKey
+		blob, err := ioutil.ReadFile(keyfile)
+		if err == nil {
+			// Report the identity being replaced, encrypted keys are
+			// decrypted with the passphrase of the old key
+			var oldPassphrase string
+			if blob = bytes.TrimSpace(blob); len(blob) > 0 && blob[0] == '{' {
+				if !ctx.IsSet(passphraseFlag.Name) {
+					fmt.Println("Please provide the passphrase of the old node key")
+				}
+				oldPassphrase = getPassphrase(ctx)
+			}
+			old, err := vntp2p.LoadNodeKey(keyfile, oldPassphrase)
+			if err != nil {
+				utils.Fatalf("Failed to read the old node key: %v", err)
+			}
+			if id, err := peer.IDFromPrivateKey(old); err == nil {
+				out.OldID = id.ToString()
+			}
+		} else if !os.IsNotExist(err) {
+			utils.Fatalf("Error reading the old node key: %v", err)
+		}
+		var passphrase string
+		if ctx.Bool("encrypt") {
+			if !ctx.IsSet(newPassphraseFlag.Name) {
+				fmt.Println("Please provide a passphrase for the new node key")
+			}
+			passphrase = getNewPassphrase(ctx)
+		}
+		if blob != nil {
+			if err := os.Rename(keyfile, keyfile+".old"); err != nil {
+				utils.Fatalf("Failed to keep the old node key: %v", err)
+			}
+		}
+
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			utils.Fatalf("Failed to generate node key: %v", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(keyfile), 0700); err != nil {
+			utils.Fatalf("Could not create directory %s", filepath.Dir(keyfile))
+		}
+		if err := vntp2p.SaveNodeKey(keyfile, key, passphrase); err != nil {
+			utils.Fatalf("Failed to write node key to %s: %v", keyfile, err)
+		}
+		id, err := peer.IDFromPrivateKey(key)
+		if err != nil {
+			utils.Fatalf("Failed to derive node id: %v", err)
+		}
+		out.ID = id.ToString()
+		if ip4 := ip.To4(); ip4 != nil {
+			out.URL = fmt.Sprintf("/ip4/%s/tcp/%d/ipfs/%s", ip4, ctx.Int("port"), out.ID)
+		} else {
+			out.URL = fmt.Sprintf("/ip6/%s/tcp/%d/ipfs/%s", ip, ctx.Int("port"), out.ID)
+		}
+
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(out)
+		} else {
+			if out.OldID != "" {
+				fmt.Println("Old ID:", out.OldID)
+			}
+			fmt.Println("ID:    ", out.ID)
+			fmt.Println("URL:   ", out.URL)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of go-vnt.
+//
+// go-vnt is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-vnt is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-vnt. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vntchain/go-vnt/vntp2p"
+)
+
+// Tests that rotating an encrypted node key asks for the passphrases of the
+// old and the new key separately.
+func TestRotateNodeKeyPassphrases(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "vntkey-test")
+	if err != nil {
+		t.Fatal("Can't create temporary directory:", err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	keyfile := filepath.Join(tmpdir, "nodekey")
+
+	create := runEthkey(t, "rotatenodekey", "--encrypt", keyfile)
+	create.Expect(`
+Please provide a passphrase for the new node key
+!! Unsupported terminal, password will be echoed.
+Passphrase: {{.InputLine "foo"}}
+Repeat passphrase: {{.InputLine "foo"}}
+`)
+	create.ExpectRegexp(`ID: +\w+\nURL: +\S+\n`)
+	create.ExpectExit()
+
+	rotate := runEthkey(t, "rotatenodekey", "--encrypt", keyfile)
+	rotate.Expect(`
+Please provide the passphrase of the old node key
+!! Unsupported terminal, password will be echoed.
+Passphrase: {{.InputLine "foo"}}
+Please provide a passphrase for the new node key
+Passphrase: {{.InputLine "bar"}}
+Repeat passphrase: {{.InputLine "bar"}}
+`)
+	rotate.ExpectRegexp(`Old ID: \w+\nID: +\w+\nURL: +\S+\n`)
+	rotate.ExpectExit()
+
+	if _, err := vntp2p.LoadNodeKey(keyfile+".old", "foo"); err != nil {
+		t.Errorf("old node key not kept: %v", err)
+	}
+	if _, err := vntp2p.LoadNodeKey(keyfile, "bar"); err != nil {
+		t.Errorf("new node key not encrypted with the new passphrase: %v", err)
+	}
+}
@@ -68,6 +68,20 @@ func getPassphrase(ctx *cli.Context) string {
 	return promptPassphrase(false)
 }
 
+// getNewPassphrase obtains a new passphrase given by the user, from the
+// --newpasswordfile command line flag or prompted for with confirmation.
+func getNewPassphrase(ctx *cli.Context) string {
+	if passphraseFile := ctx.String(newPassphraseFlag.Name); passphraseFile != "" {
+		content, err := ioutil.ReadFile(passphraseFile)
+		if err != nil {
+			utils.Fatalf("Failed to read new passphrase file '%s': %v",
+				passphraseFile, err)
+		}
+		return strings.TrimRight(string(content), "\r\n")
+	}
+	return promptPassphrase(true)
+}
+
 // signHash is a helper function that calculates a hash for the given message
 // that can be safely used to calculate a signature from.
 //
@@ -30,6 +30,7 @@ import (
 
 	"github.com/vntchain/go-vnt/accounts/abi"
 	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/crypto"
 )
 
 //clang -Xclang -ast-dump -fsyntax-only /Users/weisaizhang/Documents/go/src/github.com/vntchain/go-vnt/core/wasm/testdata/precompile/contract/main3.cpp
@@ -147,22 +148,40 @@ func main() {
 	// C.printf(str)
 	// C.free(unsafe.Pointer(str))
 
+	// Dispatch the packaging subcommands, the bare flags keep generating the
+	// abi and precompiled code only.
+	if len(os.Args) > 1 {
+		if run, ok := commands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				fmt.Printf("Error:%v\n", err)
+				os.Exit(-1)
+			}
+			return
+		}
+	}
+
 	flag.Parse()
 	if *codeFlag == "" {
 		fmt.Printf("Error:No Contract Code\n")
 		os.Exit(-1)
 	}
-	fmt.Printf("file path :%s\n", *codeFlag)
-	mustCFile(*codeFlag)
 	if *outputFlag == "" {
 		*outputFlag = path.Join(path.Dir(*codeFlag), "output")
 	}
-	code, err := ioutil.ReadFile(*codeFlag)
+	generate(*codeFlag, *outputFlag)
+}
+
+// generate parses the contract code, then writes the abi and the precompiled
+// code with the storage registry inserted to the output directory.
+func generate(codePath string, output string) {
+	fmt.Printf("file path :%s\n", codePath)
+	mustCFile(codePath)
+	code, err := ioutil.ReadFile(codePath)
 	if err != nil {
 		panic(err)
 	}
-	fileContent = readfile(*codeFlag)
-	cmd([]string{"-fname", *codeFlag})
+	fileContent = readfile(codePath)
+	cmd([]string{"-fname", codePath})
 	abigen := newAbiGen(code)
 	abigen.removeCommand()
 	abigen.parseMethod()
@@ -176,14 +195,15 @@ func main() {
 		pack = append(pack, abigen.abi.Constructor)
 	}
 
-	for _, v := range abigen.abi.Methods {
-		pack = append(pack, v)
+	// Keep the output stable between runs, so that builds are reproducible
+	for _, name := range sortedNames(abigen.abi.Methods) {
+		pack = append(pack, abigen.abi.Methods[name])
 	}
-	for _, v := range abigen.abi.Events {
-		pack = append(pack, v)
+	for _, name := range sortedNames(abigen.abi.Events) {
+		pack = append(pack, abigen.abi.Events[name])
 	}
-	for _, v := range abigen.abi.Calls {
-		pack = append(pack, v)
+	for _, name := range sortedNames(abigen.abi.Calls) {
+		pack = append(pack, abigen.abi.Calls[name])
 	}
 	// for _, v := range abigen.abi.Keys {
 	// 	pack = append(pack, v)
@@ -192,7 +212,7 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
-	err = writeFile(path.Join(*outputFlag, "abi.json"), res)
+	err = writeFile(path.Join(output, "abi.json"), res)
 	if err != nil {
 		panic(err)
 	}
@@ -203,11 +223,10 @@ func main() {
 
 	pre := abigen.insertRegistryCode()
 	// pre = abigen.insertMutableCode(pre)
-	err = writeFile(path.Join(*outputFlag, "precompile.c"), pre)
+	err = writeFile(path.Join(output, "precompile.c"), pre)
 	if err != nil {
 		panic(err)
 	}
-
 }
 
 func newAbiGen(code []byte) *abiGen {
@@ -470,14 +489,22 @@ func (gen *abiGen) insertRegistryCode() []byte {
 	// fmt.Printf("res %s\n", jsonres)
 
 	sym := parseKey()
+	keys := make([]string, 0, len(sym))
+	for k := range sym {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
 	insert := "\n"
-	for k, v1 := range sym {
+	for _, k := range keys {
+		v1 := sym[k]
 		for _, v2 := range v1.ValueSymbol {
 			//fmt.Printf("key2222 %s val2 %s StorageType %s \n", k, v1.ValueType, v2.Key, v2.KeyType)
 			insert = insert + fmt.Sprintf(regFmt, k, abi.KeyType(v1.ValueType), v2.Key, abi.KeyType(v2.KeyType), v2.IsArrayIndex)
 		}
 	}
-	funcName := fmt.Sprintf("%s%s", "key", GetRandomString(8))
+	// Derive the registry function name from the code instead of a random one,
+	// the same contract code always compiles to the same module.
+	funcName := fmt.Sprintf("%s%x", "key", crypto.Keccak256(gen.Code)[:4])
 	insertFuncBody := fmt.Sprintf(funcFmt, funcName, insert)
 
 	reg := regexp.MustCompile(methodReg)
@@ -529,6 +556,23 @@ func (gen *abiGen) insertRegistryCode() []byte {
 // 	return nil
 // }
 
+// sortedNames returns the keys of the given abi entries in alphabetical order.
+func sortedNames(entries interface{}) []string {
+	var names []string
+	switch entries := entries.(type) {
+	case map[string]Method:
+		for name := range entries {
+			names = append(names, name)
+		}
+	case map[string]Event:
+		for name := range entries {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 type Index [][]int
 
 func (idx Index) Len() int           { return len(idx) }
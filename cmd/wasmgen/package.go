@@ -0,0 +1,270 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of go-vnt.
+//
+// go-vnt is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-vnt is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-vnt. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"os/exec"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/vntchain/go-vnt/accounts/abi"
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/common/hexutil"
+	"github.com/vntchain/go-vnt/core/wavm"
+	"github.com/vntchain/go-vnt/core/wavm/validate"
+	"github.com/vntchain/go-vnt/params"
+)
+
+// commands are the subcommands packaging a contract into a deployment payload.
+//
+//	wasmgen compile -code main.c [args...]   generate, compile, validate and pack
+//	wasmgen validate -wasm main.wasm -abi abi.json
+//	wasmgen pack -wasm main.wasm -abi abi.json [args...]
+//	wasmgen version
+//
+// The trailing args are the constructor arguments of the contract.
+var commands = map[string]func(args []string) error{
+	"compile":  compileCommand,
+	"validate": validateCommand,
+	"pack":     packCommand,
+	"version":  versionCommand,
+}
+
+func compileCommand(args []string) error {
+	fs := flag.NewFlagSet("compile", flag.ExitOnError)
+	var (
+		codeFlag    = fs.String("code", "", "Code Path")
+		outputFlag  = fs.String("output", "", "Output Directory")
+		includeFlag = fs.String("include", "", "Directory of vntlib.h, default to the directory of code")
+		clangFlag   = fs.String("clang", "clang", "Path of the clang compiler")
+		linkerFlag  = fs.String("wasm-ld", "wasm-ld", "Path of the wasm linker")
+		noPackFlag  = fs.Bool("nopack", false, "Skip packing the deployment payload")
+		noFloatFlag = fs.Bool("nofloat", false, "Reject floating point instructions, like nodes disabling them do")
+	)
+	fs.Parse(args)
+	if *codeFlag == "" {
+		return errors.New("no contract code")
+	}
+	if *outputFlag == "" {
+		*outputFlag = path.Join(path.Dir(*codeFlag), "output")
+	}
+	if *includeFlag == "" {
+		*includeFlag = path.Dir(*codeFlag)
+	}
+	// Make sure the toolchain exists before doing any work
+	clang, err := exec.LookPath(*clangFlag)
+	if err != nil {
+		return fmt.Errorf("clang not found: %v", err)
+	}
+	linker, err := exec.LookPath(*linkerFlag)
+	if err != nil {
+		return fmt.Errorf("wasm-ld not found: %v", err)
+	}
+	generate(*codeFlag, *outputFlag)
+
+	var (
+		name     = strings.TrimSuffix(path.Base(*codeFlag), path.Ext(*codeFlag))
+		object   = path.Join(*outputFlag, name+".o")
+		wasmPath = path.Join(*outputFlag, name+".wasm")
+	)
+	if err := run(clang, "--target=wasm32", "-O3", "-nostdlib", "-fvisibility=hidden",
+		"-I", *includeFlag, "-c", path.Join(*outputFlag, "precompile.c"), "-o", object); err != nil {
+		return err
+	}
+	defer os.Remove(object)
+	if err := run(linker, "--no-entry", "--allow-undefined", "--export-dynamic", "--strip-all",
+		object, "-o", wasmPath); err != nil {
+		return err
+	}
+	code, abiJSON, err := readContract(wasmPath, path.Join(*outputFlag, "abi.json"))
+	if err != nil {
+		return err
+	}
+	if err := validateContract(code, abiJSON, *noFloatFlag); err != nil {
+		return err
+	}
+	if *noPackFlag {
+		return nil
+	}
+	return writePayload(code, abiJSON, fs.Args(), path.Join(*outputFlag, name+".hex"))
+}
+
+func validateCommand(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	var (
+		wasmFlag    = fs.String("wasm", "", "Wasm Path")
+		abiFlag     = fs.String("abi", "", "Abi Json Path")
+		noFloatFlag = fs.Bool("nofloat", false, "Reject floating point instructions, like nodes disabling them do")
+	)
+	fs.Parse(args)
+	code, abiJSON, err := readContract(*wasmFlag, *abiFlag)
+	if err != nil {
+		return err
+	}
+	if err := validateContract(code, abiJSON, *noFloatFlag); err != nil {
+		return err
+	}
+	fmt.Printf("%s is valid\n", *wasmFlag)
+	return nil
+}
+
+func packCommand(args []string) error {
+	fs := flag.NewFlagSet("pack", flag.ExitOnError)
+	var (
+		wasmFlag    = fs.String("wasm", "", "Wasm Path")
+		abiFlag     = fs.String("abi", "", "Abi Json Path")
+		outputFlag  = fs.String("output", "", "Output Hex Path, default to the wasm path with .hex extension")
+		noFloatFlag = fs.Bool("nofloat", false, "Reject floating point instructions, like nodes disabling them do")
+	)
+	fs.Parse(args)
+	code, abiJSON, err := readContract(*wasmFlag, *abiFlag)
+	if err != nil {
+		return err
+	}
+	if err := validateContract(code, abiJSON, *noFloatFlag); err != nil {
+		return err
+	}
+	if *outputFlag == "" {
+		*outputFlag = strings.TrimSuffix(*wasmFlag, path.Ext(*wasmFlag)) + ".hex"
+	}
+	return writePayload(code, abiJSON, fs.Args(), *outputFlag)
+}
+
+// versionCommand prints the version, which is recorded with verified sources
+// since the generated code depends on it.
+func versionCommand(args []string) error {
+	fmt.Println(params.Version)
+	return nil
+}
+
+// run executes a toolchain command, forwarding its output.
+func run(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %v", path.Base(name), err)
+	}
+	return nil
+}
+
+func readContract(wasmPath string, abiPath string) ([]byte, []byte, error) {
+	if wasmPath == "" || abiPath == "" {
+		return nil, nil, errors.New("both wasm and abi are required")
+	}
+	code, err := ioutil.ReadFile(wasmPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	abiJSON, err := ioutil.ReadFile(abiPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return code, abiJSON, nil
+}
+
+// writePayload packs the contract with the constructor arguments and writes the
+// hex encoded deployment payload to file.
+func writePayload(code []byte, abiJSON []byte, args []string, file string) error {
+	payload, err := packContract(code, abiJSON, args)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(file, []byte(hexutil.Encode(payload))); err != nil {
+		return err
+	}
+	fmt.Printf("deployment payload :%s\n", file)
+	return nil
+}
+
+// packContract assembles the data of the contract creation transaction, the
+// constructor arguments are converted to the types declared in the abi.
+func packContract(code []byte, abiJSON []byte, args []string) ([]byte, error) {
+	contractAbi, err := abi.JSON(bytes.NewReader(abiJSON))
+	if err != nil {
+		return nil, err
+	}
+	inputs := contractAbi.Constructor.Inputs
+	if len(args) != len(inputs) {
+		return nil, fmt.Errorf("constructor argument count mismatch: have %d, want %d", len(args), len(inputs))
+	}
+	params := make([]interface{}, len(args))
+	for i, input := range inputs {
+		if params[i], err = parseArgument(input.Type, args[i]); err != nil {
+			return nil, fmt.Errorf("argument %s: %v", input.Name, err)
+		}
+	}
+	input, err := contractAbi.Pack("", params...)
+	if err != nil {
+		return nil, err
+	}
+	return wavm.PackDeployPayload(code, abiJSON, input)
+}
+
+// parseArgument converts a command line argument to the go value of the abi type.
+func parseArgument(typ abi.Type, arg string) (interface{}, error) {
+	switch typ.T {
+	case abi.StringTy:
+		return arg, nil
+	case abi.BoolTy:
+		return strconv.ParseBool(arg)
+	case abi.AddressTy:
+		if !common.IsHexAddress(arg) {
+			return nil, fmt.Errorf("invalid address %q", arg)
+		}
+		return common.HexToAddress(arg), nil
+	case abi.IntTy, abi.UintTy:
+		if typ.Kind == reflect.Ptr {
+			v, ok := new(big.Int).SetString(arg, 0)
+			if !ok || (typ.T == abi.UintTy && v.Sign() < 0) {
+				return nil, fmt.Errorf("invalid %s %q", typ, arg)
+			}
+			return v, nil
+		}
+		if typ.T == abi.UintTy {
+			v, err := strconv.ParseUint(arg, 0, typ.Size)
+			if err != nil {
+				return nil, err
+			}
+			return reflect.ValueOf(v).Convert(typ.Type).Interface(), nil
+		}
+		v, err := strconv.ParseInt(arg, 0, typ.Size)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(v).Convert(typ.Type).Interface(), nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", typ)
+}
+
+// validateContract checks the compiled module with the rules a node applies
+// before deployment. NoFloat matches nodes running with floating point
+// instructions disabled.
+func validateContract(code []byte, abiJSON []byte, noFloat bool) error {
+	config := validate.DefaultConfig
+	config.DisableFloatingPoint = noFloat
+	return validate.Validate(code, abiJSON, &config)
+}
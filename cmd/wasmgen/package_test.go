@@ -0,0 +1,106 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of go-vnt.
+//
+// go-vnt is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-vnt is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-vnt. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	"github.com/vntchain/go-vnt/accounts/abi"
+	"github.com/vntchain/go-vnt/common"
+)
+
+func TestParseArgument(t *testing.T) {
+	tests := []struct {
+		typ  string
+		arg  string
+		want interface{}
+		fail bool
+	}{
+		{typ: "int32", arg: "-12", want: int32(-12)},
+		{typ: "uint32", arg: "0x10", want: uint32(16)},
+		{typ: "int64", arg: "-5", want: int64(-5)},
+		{typ: "uint64", arg: "18446744073709551615", want: uint64(18446744073709551615)},
+		{typ: "uint256", arg: "1000000000000000000000", want: new(big.Int).Mul(big.NewInt(1e18), big.NewInt(1000))},
+		{typ: "bool", arg: "true", want: true},
+		{typ: "string", arg: "vnt", want: "vnt"},
+		{typ: "address", arg: "0x122b5b6b6f8c5d43fd4a46b4a8f5e2e19ee8b0e0", want: common.HexToAddress("0x122b5b6b6f8c5d43fd4a46b4a8f5e2e19ee8b0e0")},
+		{typ: "uint32", arg: "-1", fail: true},
+		{typ: "uint256", arg: "-1", fail: true},
+		{typ: "address", arg: "0x12", fail: true},
+	}
+	for i, tt := range tests {
+		typ, err := abi.NewType(tt.typ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		have, err := parseArgument(typ, tt.arg)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("test %d: expected error for %s %q", i, tt.typ, tt.arg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: failed to parse %s %q: %v", i, tt.typ, tt.arg, err)
+			continue
+		}
+		if want, ok := tt.want.(*big.Int); ok {
+			if have.(*big.Int).Cmp(want) != 0 {
+				t.Errorf("test %d: value mismatch: have %v, want %v", i, have, want)
+			}
+		} else if have != tt.want {
+			t.Errorf("test %d: value mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
+
+func TestPackContract(t *testing.T) {
+	code, err := ioutil.ReadFile("../../core/wavm/testdata/erc20/erc20.wasm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	abiJSON, err := ioutil.ReadFile("../../core/wavm/testdata/erc20/abi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateContract(code, abiJSON, false); err != nil {
+		t.Fatalf("failed to validate contract: %v", err)
+	}
+	payload, err := packContract(code, abiJSON, []string{"1000000", "bitcoin", "BTC"})
+	if err != nil {
+		t.Fatalf("failed to pack contract: %v", err)
+	}
+	if !bytes.HasPrefix(payload, []byte(`{"Code":"`)) {
+		t.Errorf("payload is not a wasm deployment")
+	}
+	if _, err := packContract(code, abiJSON, []string{"1000000"}); err == nil {
+		t.Errorf("expected error for missing constructor arguments")
+	}
+
+	// Dropping a method from the exports must be detected
+	contractAbi, _ := abi.JSON(bytes.NewReader(abiJSON))
+	for name := range contractAbi.Methods {
+		renamed := bytes.Replace(code, []byte(name), bytes.Repeat([]byte("x"), len(name)), -1)
+		if err := validateContract(renamed, abiJSON, false); err == nil {
+			t.Errorf("expected error for unexported method %s", name)
+		}
+		break
+	}
+}
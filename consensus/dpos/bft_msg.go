@@ -133,6 +133,11 @@ func (bft *BftManager) VerifyCmtMsgOf(block *types.Block) error {
 }
 
 func (bft *BftManager) verifySig(sender common.Address, data []byte, sig []byte) bool {
+	return verifySig(sender, data, sig)
+}
+
+// verifySig checks that the signature of the data was made by the sender.
+func verifySig(sender common.Address, data []byte, sig []byte) bool {
 	pubkey, err := crypto.Ecrecover(data, sig)
 	if err != nil {
 		return false
@@ -92,17 +92,17 @@ type Dpos struct {
 	lock           sync.RWMutex   // Protects the signer fields
 	updateInterval *big.Int       // Duration of update witnesses list
 
-	sendBftPeerUpdateFn func(urls []string)
+	sendBftPeerUpdateFn func(witnesses []common.Address, urls []string)
 }
 
-// sigHash returns the hash which is used as input for the proof-of-authority
+// SigHash returns the hash which is used as input for the proof-of-authority
 // signing. It is the hash of the entire header apart from the 65 byte signature
 // contained at the end of the extra data.
 //
 // Note, the method requires the extra data to be at least 65 bytes, otherwise it
 // panics. This is done to avoid accidentally using both forms (signature present
 // or not), which could be abused to produce different hashes for the same header.
-func sigHash(header *types.Header) (hash common.Hash) {
+func SigHash(header *types.Header) (hash common.Hash) {
 	hasher := sha3.NewKeccak256()
 
 	rlp.Encode(hasher, []interface{}{
@@ -135,7 +135,7 @@ func ecrecover(header *types.Header, sigcache *lru.ARCCache) (common.Address, er
 	signature := header.Signature
 
 	// Recover the public key and the VNT address
-	pubkey, err := crypto.Ecrecover(sigHash(header).Bytes(), signature)
+	pubkey, err := crypto.Ecrecover(SigHash(header).Bytes(), signature)
 	if err != nil {
 		return common.Address{}, err
 	}
@@ -176,7 +176,7 @@ func NewFaker() *Dpos {
 	return dp
 }
 
-func (d *Dpos) InitBft(sendBftMsg func(types.ConsensusMsg), SendPeerUpdate func(urls []string), verifyBlock func(*types.Block) (types.Receipts, []*types.Log, uint64, error), writeBlock func(*types.Block) error) {
+func (d *Dpos) InitBft(sendBftMsg func(types.ConsensusMsg), SendPeerUpdate func(witnesses []common.Address, urls []string), verifyBlock func(*types.Block) (types.Receipts, []*types.Log, uint64, error), writeBlock func(*types.Block) error) {
 	d.sendBftPeerUpdateFn = SendPeerUpdate
 
 	// Init bft function
@@ -446,6 +446,55 @@ func needSetUpdateTime(update bool, number uint64) bool {
 	return update || number == 1
 }
 
+// PrepareAt is like Prepare, but produces the header at the given time instead
+// of the next slot of the local clock, and chooses the witness in turn as the
+// block producer instead of the local signer. It doesn't start a BFT round,
+// so it can be used to assemble blocks deterministically, e.g. by the
+// simulated backend.
+func (d *Dpos) PrepareAt(chain consensus.ChainReader, header *types.Header, produceTime *big.Int) (common.Address, error) {
+	var (
+		updated bool
+		err     error
+	)
+
+	number := header.Number.Uint64()
+	if number == 0 {
+		return common.Address{}, errUnknownBlock
+	}
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return common.Address{}, consensus.ErrUnknownAncestor
+	}
+
+	dur := new(big.Int).Sub(produceTime, parent.Time)
+	if dur.Sign() <= 0 || dur.Uint64()%d.config.Period != 0 {
+		return common.Address{}, errInvalidTimestamp
+	}
+	header.Time = new(big.Int).Set(produceTime)
+	header.Difficulty = big.NewInt(1)
+
+	// Update witness list if needed，and set Extra with update value
+	updated, header.Witnesses, err = d.getWitnessesForProduce(header, chain, parent)
+	if err != nil {
+		return common.Address{}, err
+	}
+	header.Extra = make([]byte, updateTimeLen)
+	if needSetUpdateTime(updated, number) {
+		copy(header.Extra, encodeUpdateTime(header.Time))
+	} else {
+		copy(header.Extra, parent.Extra)
+	}
+
+	// Find out who's in turn at this time
+	for _, witness := range header.Witnesses {
+		if d.inTurn(header, witness, chain, nil) {
+			header.Coinbase = witness
+			return witness, nil
+		}
+	}
+	return common.Address{}, errOutTurn
+}
+
 // Finalize implements consensus.Engine,  grants reward and returns the final block.
 func (d *Dpos) Finalize(chain consensus.ChainReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, receipts []*types.Receipt) (*types.Block, error) {
 	// Granting bounty, if any left
@@ -523,7 +572,7 @@ func (d *Dpos) Seal(chain consensus.ChainReader, block *types.Block, stop <-chan
 	d.lock.RUnlock()
 
 	// Sign all the things without Signature
-	sighash, err := signFn(accounts.Account{Address: witness}, sigHash(header).Bytes())
+	sighash, err := signFn(accounts.Account{Address: witness}, SigHash(header).Bytes())
 	if err != nil {
 		return nil, err
 	}
@@ -717,7 +766,7 @@ func (d *Dpos) getWitnesses(header *types.Header, db *state.StateDB, parent *typ
 		updated = false
 	}
 	if updated && d.sendBftPeerUpdateFn != nil {
-		d.sendBftPeerUpdateFn(urls)
+		d.sendBftPeerUpdateFn(witnesses, urls)
 	}
 	return updated, witnesses
 }
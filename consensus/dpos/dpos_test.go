@@ -5,9 +5,14 @@ import (
 	"testing"
 
 	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core"
+	"github.com/vntchain/go-vnt/core/rawdb"
+	"github.com/vntchain/go-vnt/core/state"
 	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/core/vm"
 	"github.com/vntchain/go-vnt/core/vm/election"
 	"github.com/vntchain/go-vnt/params"
+	"github.com/vntchain/go-vnt/vntdb"
 )
 
 func TestUpdateTime(t *testing.T) {
@@ -185,3 +190,48 @@ func TestCalcVoteBounty(t *testing.T) {
 		}
 	}
 }
+
+func TestDeveloperGenesis(t *testing.T) {
+	var (
+		db      = vntdb.NewMemDatabase()
+		witness = common.HexToAddress("0x122b5b6b6f8c5d43fd4a46b4a8f5e2e19ee8b0e0")
+		genesis = core.DeveloperGenesisBlock(3, witness).MustCommit(db)
+	)
+	if genesis.Time().Uint64()%3 != 0 {
+		t.Errorf("genesis time %v is not aligned to period", genesis.Time())
+	}
+	statedb, err := state.New(genesis.Root(), state.NewDatabase(db))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The witness should be elected without any registration
+	witnesses, _ := election.GetFirstNCandidates(statedb, 1)
+	if len(witnesses) != 1 || witnesses[0] != witness {
+		t.Fatalf("witnesses mismatch: have %x, want [%x]", witnesses, witness)
+	}
+	if bounty := election.QueryRestVNTBounty(statedb); bounty.Sign() <= 0 {
+		t.Errorf("rest bounty not initialized: %v", bounty)
+	}
+
+	// The witness should be in turn for every slot, including the ones
+	// which re-elect witnesses from the election contract
+	config := rawdb.ReadChainConfig(db, genesis.Hash())
+	dp := New(config.Dpos, db)
+	chain, err := core.NewBlockChain(db, nil, config, dp, vm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+
+	for i := uint64(1); i <= 5; i++ {
+		header := &types.Header{ParentHash: genesis.Hash(), Number: big.NewInt(1)}
+		produceTime := new(big.Int).SetUint64(genesis.Time().Uint64() + i*config.Dpos.Period)
+		producer, err := dp.PrepareAt(chain, header, produceTime)
+		if err != nil {
+			t.Fatalf("slot %d: failed to prepare header: %v", i, err)
+		}
+		if producer != witness {
+			t.Errorf("slot %d: producer mismatch: have %x, want %x", i, producer, witness)
+		}
+	}
+}
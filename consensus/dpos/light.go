@@ -0,0 +1,291 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package dpos
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/state"
+	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/log"
+)
+
+// Light clients can't execute blocks, so they can't recompute the witness list
+// of a block from the state of its parent. They check the transitions of the
+// witness set instead: a block keeps the witnesses of its parent, except for
+// the blocks updating the witness list, whose new set is either endorsed by
+// the commit messages of 2f+1 previous witnesses, or proven by the election
+// storage of the parent state. A block is final once 2f+1 of its witnesses
+// committed to it.
+
+var (
+	// errInsufficientCommits is returned if less than 2f+1 witnesses committed
+	// to a block.
+	errInsufficientCommits = errors.New("not enough commit messages from witnesses")
+
+	// errInvalidCommit is returned if a commit message of a block is not for the
+	// block or not signed by its committer.
+	errInvalidCommit = errors.New("invalid commit message")
+
+	// errUnprovenWitnesses is returned if a new witness list is neither endorsed
+	// by the previous witnesses nor matching the election state.
+	errUnprovenWitnesses = errors.New("witness list update not endorsed nor proven")
+
+	// errInvalidUpdateTime is returned if the update time in header.Extra is not
+	// consistent with the parent.
+	errInvalidUpdateTime = errors.New("invalid witness list update time")
+)
+
+// Quorum returns the number of witnesses, 2f+1, which have to commit to a block.
+func (d *Dpos) Quorum() int {
+	return d.bft.quorum
+}
+
+// countCommits returns the number of distinct witnesses of the set which
+// committed to the header. Every commit message has to be valid, even the ones
+// of committers outside of the set.
+func countCommits(header *types.Header, witnesses []common.Address) (int, error) {
+	set := make(map[common.Address]struct{}, len(witnesses))
+	for _, w := range witnesses {
+		set[w] = struct{}{}
+	}
+	var (
+		hash      = header.Hash()
+		committed = make(map[common.Address]struct{})
+	)
+	for _, m := range header.CmtMsges {
+		if m.BlockHash != hash || m.BlockNumber == nil || m.BlockNumber.Cmp(header.Number) != 0 {
+			return 0, errInvalidCommit
+		}
+		if !verifySig(m.Commiter, m.Hash().Bytes(), m.CommitSig) {
+			return 0, errInvalidCommit
+		}
+		if _, ok := set[m.Commiter]; ok {
+			committed[m.Commiter] = struct{}{}
+		}
+	}
+	return len(committed), nil
+}
+
+// VerifyCertificate checks that 2f+1 distinct witnesses of the set committed to
+// the header.
+func (d *Dpos) VerifyCertificate(header *types.Header, witnesses []common.Address) error {
+	n, err := countCommits(header, witnesses)
+	if err != nil {
+		return err
+	}
+	if n < d.Quorum() {
+		return fmt.Errorf("%v: have %d, want %d", errInsufficientCommits, n, d.Quorum())
+	}
+	return nil
+}
+
+// lastUpdateTime returns the time the witness list of the header was updated.
+func lastUpdateTime(header *types.Header) *big.Int {
+	if header.Number.Sign() == 0 {
+		return header.Time
+	}
+	var upTime updateTime
+	copy(upTime[:], header.Extra)
+	return upTime.bigInt()
+}
+
+// VerifyWitnessTransition checks the witness list of the header against the one
+// of its parent. An updated list which is not endorsed by 2f+1 witnesses of
+// the parent is checked against the election state of the parent, which is
+// retrieved through parentState only if needed.
+func (d *Dpos) VerifyWitnessTransition(header, parent *types.Header, parentState func() (*state.StateDB, error)) error {
+	if len(header.Extra) != updateTimeLen {
+		return errInvalidExtraLen
+	}
+	number := header.Number.Uint64()
+	switch {
+	case !d.updatedWitnessCheckByTime(header):
+		// The witness list and its update time are kept
+		if number == 1 || !bytes.Equal(header.Extra, parent.Extra) {
+			return errInvalidUpdateTime
+		}
+		if !sameWitnesses(header.Witnesses, parent.Witnesses) {
+			return errWitnesses
+		}
+		return nil
+
+	case !d.needUpdateWitnesses(header.Time, lastUpdateTime(parent)):
+		// Block 1 records its time without updating the list
+		if number != 1 {
+			return errInvalidUpdateTime
+		}
+		if !sameWitnesses(header.Witnesses, parent.Witnesses) {
+			return errWitnesses
+		}
+		return nil
+	}
+	// The witness list was read from the election state of the parent
+	if n, err := countCommits(header, parent.Witnesses); err != nil {
+		return err
+	} else if n >= d.Quorum() {
+		return nil
+	}
+	db, err := parentState()
+	if err != nil {
+		return err
+	}
+	witnesses, _ := d.GetWitnessesFromStateDB(db)
+	if err := db.Error(); err != nil {
+		return err
+	}
+	if !sameWitnesses(header.Witnesses, witnesses) {
+		log.Debug("Unproven witness list update", "number", number, "hash", header.Hash(), "header", header.Witnesses, "state", witnesses)
+		return errUnprovenWitnesses
+	}
+	return nil
+}
+
+// VerifyLightHeader checks the witness list of a header and its commit
+// certificate, on top of the checks of VerifyHeader. Light clients use it
+// instead of executing the block.
+func (d *Dpos) VerifyLightHeader(header, parent *types.Header, parentState func() (*state.StateDB, error)) error {
+	if header.Number.Sign() == 0 {
+		return nil
+	}
+	if err := d.VerifyWitnessTransition(header, parent, parentState); err != nil {
+		return err
+	}
+	return d.VerifyCertificate(header, header.Witnesses)
+}
+
+func sameWitnesses(a, b []common.Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// WitnessTracker follows the witness list of the chain from update block to
+// update block. Any header of the current witness list can be verified by its
+// commit certificate, without the headers in between.
+type WitnessTracker struct {
+	engine *Dpos
+	update *types.Header // Block which introduced the current witness list
+	lock   sync.RWMutex
+}
+
+// NewWitnessTracker creates a tracker starting at a trusted header, e.g. the
+// genesis block or a checkpoint.
+func NewWitnessTracker(engine *Dpos, trusted *types.Header) *WitnessTracker {
+	return &WitnessTracker{engine: engine, update: trusted}
+}
+
+// Witnesses returns the current witness list.
+func (t *WitnessTracker) Witnesses() []common.Address {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return append([]common.Address(nil), t.update.Witnesses...)
+}
+
+// Update returns the block which introduced the current witness list.
+func (t *WitnessTracker) Update() *types.Header {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.update
+}
+
+// Reset moves to the witness list of a trusted header, e.g. a header up to a
+// trusted checkpoint.
+func (t *WitnessTracker) Reset(trusted *types.Header) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.update = trusted
+}
+
+// Tracks reports whether the header follows the block introducing the current
+// witness list and carries that list, i.e. whether VerifyHeader can check it.
+func (t *WitnessTracker) Tracks(header *types.Header) bool {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return header.Number.Cmp(t.update.Number) > 0 && carriesWitnesses(header, t.update)
+}
+
+// carriesWitnesses reports whether the header carries the witness list
+// introduced by the update block. The genesis has no update time, block 1
+// records it without changing the list.
+func carriesWitnesses(header, update *types.Header) bool {
+	if update.Number.Sign() != 0 && !bytes.Equal(header.Extra, update.Extra) {
+		return false
+	}
+	return sameWitnesses(header.Witnesses, update.Witnesses)
+}
+
+// VerifyHeader checks that the header belongs to the current witness list and
+// was committed to by 2f+1 of its witnesses.
+func (t *WitnessTracker) VerifyHeader(header *types.Header) error {
+	t.lock.RLock()
+	update := t.update
+	t.lock.RUnlock()
+
+	return t.verifyHeader(update, header)
+}
+
+func (t *WitnessTracker) verifyHeader(update, header *types.Header) error {
+	if header.Hash() == update.Hash() {
+		return nil
+	}
+	if header.Number.Cmp(update.Number) <= 0 {
+		return fmt.Errorf("header #%d precedes the witness list update #%d", header.Number, update.Number)
+	}
+	if !carriesWitnesses(header, update) {
+		return errWitnesses
+	}
+	return t.engine.VerifyCertificate(header, update.Witnesses)
+}
+
+// Advance moves to the witness list introduced by the update block. Its parent
+// is the last block of the current witness list, its state is only retrieved
+// if the new list has to be checked against the election state.
+func (t *WitnessTracker) Advance(parent, update *types.Header, parentState func() (*state.StateDB, error)) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if err := t.verifyHeader(t.update, parent); err != nil {
+		return fmt.Errorf("parent of update: %v", err)
+	}
+	if update.ParentHash != parent.Hash() || update.Number.Uint64() != parent.Number.Uint64()+1 {
+		return fmt.Errorf("update #%d is not a child of #%d", update.Number, parent.Number)
+	}
+	if !t.engine.updatedWitnessCheckByTime(update) {
+		return fmt.Errorf("block #%d doesn't update the witness list", update.Number)
+	}
+	if err := t.engine.VerifyLightHeader(update, parent, parentState); err != nil {
+		return err
+	}
+	t.update = update
+	return nil
+}
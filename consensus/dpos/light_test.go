@@ -0,0 +1,267 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package dpos
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core"
+	"github.com/vntchain/go-vnt/core/state"
+	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/core/vm/election"
+	"github.com/vntchain/go-vnt/crypto"
+	"github.com/vntchain/go-vnt/params"
+	"github.com/vntchain/go-vnt/vntdb"
+)
+
+type lightTester struct {
+	dp   *Dpos
+	keys map[common.Address]*ecdsa.PrivateKey
+}
+
+func newLightTester(n int) (*lightTester, []common.Address) {
+	lt := &lightTester{
+		dp:   New(&params.DposConfig{WitnessesNum: 4, Period: 2}, nil),
+		keys: make(map[common.Address]*ecdsa.PrivateKey),
+	}
+	return lt, lt.newWitnesses(n)
+}
+
+func (lt *lightTester) newWitnesses(n int) []common.Address {
+	var addrs []common.Address
+	for i := 0; i < n; i++ {
+		key, _ := crypto.GenerateKey()
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		lt.keys[addr] = key
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// header creates a child of the parent at the given time.
+func (lt *lightTester) header(parent *types.Header, time int64, update bool, witnesses []common.Address) *types.Header {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Time:       big.NewInt(time),
+		Witnesses:  witnesses,
+		Difficulty: big.NewInt(1),
+	}
+	if update || parent.Number.Sign() == 0 {
+		header.Extra = encodeUpdateTime(header.Time)
+	} else {
+		header.Extra = common.CopyBytes(parent.Extra)
+	}
+	return header
+}
+
+// commit adds the commit messages of the committers to the header.
+func (lt *lightTester) commit(header *types.Header, committers ...common.Address) {
+	for _, c := range committers {
+		msg := &types.CommitMsg{Commiter: c, BlockNumber: header.Number, BlockHash: header.Hash()}
+		msg.CommitSig, _ = crypto.Sign(msg.Hash().Bytes(), lt.keys[c])
+		header.CmtMsges = append(header.CmtMsges, msg)
+	}
+}
+
+// electionState returns a state whose election contract elects the candidates.
+func electionState(t *testing.T, candidates []common.Address) (*state.StateDB, []common.Address) {
+	storage, err := election.GenesisStorage(candidates, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(vntdb.NewMemDatabase()))
+	for key, value := range storage {
+		statedb.SetState(common.BytesToAddress([]byte{9}), key, value)
+	}
+	elected, _ := election.GetFirstNCandidates(statedb, len(candidates))
+	return statedb, elected
+}
+
+func noState() (*state.StateDB, error) {
+	return nil, errors.New("state should not be needed")
+}
+
+func TestVerifyCertificate(t *testing.T) {
+	lt, witnesses := newLightTester(4)
+	header := &types.Header{Number: big.NewInt(5), Time: big.NewInt(10), Witnesses: witnesses}
+
+	lt.commit(header, witnesses[0], witnesses[1])
+	if err := lt.dp.VerifyCertificate(header, witnesses); err == nil {
+		t.Error("certificate of 2 witnesses accepted")
+	}
+	// Duplicate commits count once
+	lt.commit(header, witnesses[1])
+	if err := lt.dp.VerifyCertificate(header, witnesses); err == nil {
+		t.Error("duplicate commit counted twice")
+	}
+	lt.commit(header, witnesses[2])
+	if err := lt.dp.VerifyCertificate(header, witnesses); err != nil {
+		t.Errorf("certificate of 3 witnesses rejected: %v", err)
+	}
+	// Commits of others don't count against another set
+	if err := lt.dp.VerifyCertificate(header, lt.newWitnesses(4)); err == nil {
+		t.Error("certificate accepted for another witness set")
+	}
+	// Forged commits invalidate the certificate
+	forged := types.CopyHeader(header)
+	forged.CmtMsges[3].Commiter = witnesses[3]
+	if err := lt.dp.VerifyCertificate(forged, witnesses); err != errInvalidCommit {
+		t.Errorf("forged commit: have %v, want %v", err, errInvalidCommit)
+	}
+}
+
+func TestVerifyWitnessTransition(t *testing.T) {
+	lt, witnesses := newLightTester(4)
+	genesis := &types.Header{Number: common.Big0, Time: big.NewInt(1000), Witnesses: witnesses}
+
+	// Block 1 records its time but keeps the list
+	block1 := lt.header(genesis, 1002, false, witnesses)
+	if err := lt.dp.VerifyWitnessTransition(block1, genesis, noState); err != nil {
+		t.Fatalf("block 1 rejected: %v", err)
+	}
+	if err := lt.dp.VerifyWitnessTransition(lt.header(genesis, 1002, false, lt.newWitnesses(4)), genesis, noState); err != errWitnesses {
+		t.Errorf("block 1 with other witnesses: have %v, want %v", err, errWitnesses)
+	}
+	// Blocks within the update interval keep list and update time
+	block2 := lt.header(block1, 1004, false, witnesses)
+	if err := lt.dp.VerifyWitnessTransition(block2, block1, noState); err != nil {
+		t.Fatalf("block 2 rejected: %v", err)
+	}
+	if err := lt.dp.VerifyWitnessTransition(lt.header(block1, 1004, true, witnesses), block1, noState); err != errInvalidUpdateTime {
+		t.Errorf("early update: have %v, want %v", err, errInvalidUpdateTime)
+	}
+
+	// An update endorsed by the previous witnesses needs no state
+	next := append(witnesses[:2:2], lt.newWitnesses(2)...)
+	update := lt.header(block2, 1002+24, true, next)
+	lt.commit(update, witnesses[0], witnesses[1], witnesses[2])
+	if err := lt.dp.VerifyWitnessTransition(update, block2, noState); err != nil {
+		t.Errorf("endorsed update rejected: %v", err)
+	}
+
+	// Otherwise the list has to match the election state of the parent
+	statedb, elected := electionState(t, next)
+	update = lt.header(block2, 1002+24, true, elected)
+	lt.commit(update, elected...)
+	parentState := func() (*state.StateDB, error) { return statedb, nil }
+	if err := lt.dp.VerifyWitnessTransition(update, block2, parentState); err != nil {
+		t.Errorf("proven update rejected: %v", err)
+	}
+	forged := lt.header(block2, 1002+24, true, lt.newWitnesses(4))
+	lt.commit(forged, forged.Witnesses...)
+	if err := lt.dp.VerifyWitnessTransition(forged, block2, parentState); err != errUnprovenWitnesses {
+		t.Errorf("unproven update: have %v, want %v", err, errUnprovenWitnesses)
+	}
+}
+
+func TestWitnessTracker(t *testing.T) {
+	lt, witnesses := newLightTester(4)
+	genesis := &types.Header{Number: common.Big0, Time: big.NewInt(1000), Witnesses: witnesses}
+	tracker := NewWitnessTracker(lt.dp, genesis)
+
+	block1 := lt.header(genesis, 1002, false, witnesses)
+	lt.commit(block1, witnesses[:3]...)
+	if err := tracker.Advance(genesis, block1, noState); err != nil {
+		t.Fatalf("failed to advance to block 1: %v", err)
+	}
+
+	// Headers of the current list are verified without their ancestors
+	header := lt.header(block1, 1010, false, witnesses)
+	header.Number = big.NewInt(5)
+	lt.commit(header, witnesses[1:]...)
+	if err := tracker.VerifyHeader(header); err != nil {
+		t.Errorf("header of current list rejected: %v", err)
+	}
+	uncommitted := lt.header(block1, 1010, false, witnesses)
+	if err := tracker.VerifyHeader(uncommitted); err == nil {
+		t.Error("uncommitted header accepted")
+	}
+
+	// Move to the next list, proven by the election state
+	parent := lt.header(block1, 1024, false, witnesses)
+	parent.Number = big.NewInt(12)
+	lt.commit(parent, witnesses[:3]...)
+	statedb, elected := electionState(t, lt.newWitnesses(4))
+	update := lt.header(parent, 1026, true, elected)
+	lt.commit(update, elected...)
+	if err := tracker.Advance(parent, update, func() (*state.StateDB, error) { return statedb, nil }); err != nil {
+		t.Fatalf("failed to advance to the update: %v", err)
+	}
+	if w := tracker.Witnesses(); !sameWitnesses(w, elected) {
+		t.Errorf("witnesses mismatch: have %x, want %x", w, elected)
+	}
+	if err := tracker.VerifyHeader(header); err == nil {
+		t.Error("header of the previous list accepted")
+	}
+}
+
+func TestCheckpointProofs(t *testing.T) {
+	lt, witnesses := newLightTester(4)
+	header := &types.Header{Number: big.NewInt(5), Time: big.NewInt(10), Witnesses: witnesses}
+	lt.commit(header, witnesses[:3]...)
+
+	// Certified checkpoints survive the json roundtrip
+	cp, err := core.NewCertifiedCheckpoint(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, _ := json.Marshal(cp)
+	decoded := new(core.SignedCheckpoint)
+	if err := json.Unmarshal(blob, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.Verify(nil, 0, witnesses, false, lt.dp); err == nil {
+		t.Error("unsigned checkpoint accepted without trusting certificates")
+	}
+	if err := decoded.Verify(nil, 0, witnesses, true, lt.dp); err != nil {
+		t.Errorf("certified checkpoint rejected: %v", err)
+	}
+	// The certificate has to come from the anchor witnesses
+	if err := decoded.Verify(nil, 0, lt.newWitnesses(4), true, lt.dp); err == nil {
+		t.Error("checkpoint certified by unanchored witnesses accepted")
+	}
+	decoded.Witnesses = lt.newWitnesses(4)
+	if err := decoded.Verify(nil, 0, witnesses, true, lt.dp); err == nil {
+		t.Error("checkpoint with other witnesses accepted")
+	}
+
+	// Signed checkpoints need the threshold of trusted keys
+	signers := lt.newWitnesses(3)
+	for _, signer := range signers[:2] {
+		if err := cp.Sign(lt.keys[signer]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cp.Verify(signers, 2, nil, false, lt.dp); err != nil {
+		t.Errorf("signed checkpoint rejected: %v", err)
+	}
+	if err := cp.Verify(signers, 3, nil, false, lt.dp); err == nil {
+		t.Error("checkpoint accepted below threshold")
+	}
+	if err := cp.Verify(signers[2:], 1, nil, false, lt.dp); err == nil {
+		t.Error("checkpoint accepted without trusted signatures")
+	}
+	if err := cp.Verify(nil, 0, witnesses, true, lt.dp); err == nil {
+		t.Error("signed checkpoint accepted without signers")
+	}
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/vntchain/go-vnt/consensus"
 	"github.com/vntchain/go-vnt/core/rawdb"
 	"github.com/vntchain/go-vnt/core/state"
+	"github.com/vntchain/go-vnt/core/state/snapshot"
 	"github.com/vntchain/go-vnt/core/types"
 	"github.com/vntchain/go-vnt/core/vm"
 	"github.com/vntchain/go-vnt/crypto"
@@ -59,6 +60,8 @@ const (
 	maxTimeFutureBlocks = 30
 	badBlockLimit       = 10
 	triesInMemory       = 128
+	maxFinalityLookback = 128 // Blocks below the head searched for a finalized block
+	snapshotLayers      = 64  // Diff layers of the state snapshot kept in memory, fewer than triesInMemory
 
 	// BlockChainVersion ensures that an incompatible database forces a resync from scratch.
 	BlockChainVersion = 3
@@ -70,6 +73,8 @@ type CacheConfig struct {
 	Disabled      bool          // Whether to disable trie write caching (archive node)
 	TrieNodeLimit int           // Memory limit (MB) at which to flush the current in-memory trie to disk
 	TrieTimeLimit time.Duration // Time limit after which to flush the current in-memory trie to disk
+	PruneInterval uint64        // Blocks between prunings of the stale state from disk (0 = never)
+	Snapshot      bool          // Whether to serve state reads from a flat snapshot
 }
 
 // BlockChain represents the canonical chain given a database with a genesis
@@ -93,6 +98,8 @@ type BlockChain struct {
 	db     vntdb.Database // Low level persistent database to store final content in
 	triegc *prque.Prque   // Priority queue mapping block numbers to tries to gc
 	gcproc time.Duration  // Accumulates canonical block processing for trie dumping
+	// pruning must be atomically called
+	pruning int32 // Whether stale state is being pruned from disk
 
 	hc            *HeaderChain
 	rmLogsFeed    event.Feed
@@ -112,6 +119,7 @@ type BlockChain struct {
 	currentFastBlock atomic.Value // Current head of the fast-sync chain (may be above the block chain!)
 
 	stateCache   state.Database // State database to reuse between imports (contains state cache)
+	snaps        *snapshot.Tree // Flat snapshot of the recent states, nil if disabled
 	bodyCache    *lru.Cache     // Cache for the most recent block bodies
 	bodyRLPCache *lru.Cache     // Cache for the most recent block bodies in RLP encoded format
 	blockCache   *lru.Cache     // Cache for the most recent entire blocks
@@ -177,6 +185,15 @@ func NewBlockChain(db vntdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	if err := bc.loadLastState(); err != nil {
 		return nil, err
 	}
+	if cacheConfig.Snapshot {
+		snaps, err := snapshot.New(db, bc.stateCache.TrieDB(), bc.CurrentBlock().Root())
+		if err != nil {
+			log.Warn("State snapshot unavailable", "err", err)
+		} else {
+			bc.snaps = snaps
+			bc.stateCache = state.WithSnapshots(bc.stateCache, snaps)
+		}
+	}
 	// Check the current state of the block hashes and make sure that we do not have any of the bad blocks in our chain
 	for hash := range BadHashes {
 		if header := bc.GetHeaderByHash(hash); header != nil {
@@ -192,6 +209,10 @@ func NewBlockChain(db vntdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	}
 	// Take ownership of this particular state
 	go bc.update()
+	if _, ok := db.(rawdb.AncientStore); ok {
+		bc.wg.Add(1)
+		go bc.freeze()
+	}
 	return bc, nil
 }
 
@@ -275,6 +296,13 @@ func (bc *BlockChain) SetHead(head uint64) error {
 	bc.hc.SetHead(head, delFn)
 	currentHeader := bc.hc.CurrentHeader()
 
+	// Drop the rewound blocks from the ancient store too
+	if ancients, ok := bc.db.(rawdb.AncientStore); ok {
+		if err := ancients.TruncateAncients(currentHeader.Number.Uint64() + 1); err != nil {
+			log.Error("Failed to truncate ancient store", "err", err)
+		}
+	}
+
 	// Clear out any stale content from the caches
 	bc.bodyCache.Purge()
 	bc.bodyRLPCache.Purge()
@@ -308,7 +336,22 @@ func (bc *BlockChain) SetHead(head uint64) error {
 	rawdb.WriteHeadBlockHash(bc.db, currentBlock.Hash())
 	rawdb.WriteHeadFastBlockHash(bc.db, currentFastBlock.Hash())
 
-	return bc.loadLastState()
+	if err := bc.loadLastState(); err != nil {
+		return err
+	}
+	bc.rebuildSnapshot()
+	return nil
+}
+
+// rebuildSnapshot generates the state snapshot of the head block if it isn't
+// covered by the snapshot tree.
+func (bc *BlockChain) rebuildSnapshot() {
+	if bc.snaps == nil {
+		return
+	}
+	if root := bc.CurrentBlock().Root(); bc.snaps.Snapshot(root) == nil {
+		bc.snaps.Rebuild(root)
+	}
 }
 
 // FastSyncCommitHead sets the current head block to the one defined by the hash
@@ -326,6 +369,7 @@ func (bc *BlockChain) FastSyncCommitHead(hash common.Hash) error {
 	bc.mu.Lock()
 	bc.currentBlock.Store(block)
 	bc.mu.Unlock()
+	bc.rebuildSnapshot()
 
 	log.Info("Committed new head block", "number", block.Number(), "hash", hash)
 	return nil
@@ -369,6 +413,11 @@ func (bc *BlockChain) Validator() Validator {
 	return bc.validator
 }
 
+// GetVMConfig returns the block chain VM config.
+func (bc *BlockChain) GetVMConfig() *vm.Config {
+	return &bc.vmConfig
+}
+
 // Processor returns the current processor.
 func (bc *BlockChain) Processor() Processor {
 	bc.procmu.RLock()
@@ -413,6 +462,7 @@ func (bc *BlockChain) ResetWithGenesisBlock(genesis *types.Block) error {
 	bc.hc.SetGenesis(bc.genesisBlock.Header())
 	bc.hc.SetCurrentHeader(bc.genesisBlock.Header())
 	bc.currentFastBlock.Store(bc.genesisBlock)
+	bc.rebuildSnapshot()
 
 	return nil
 }
@@ -643,6 +693,13 @@ func (bc *BlockChain) Stop() {
 
 	bc.wg.Wait()
 
+	// Flatten the state snapshot into the disk, matching the head state below
+	if bc.snaps != nil {
+		if err := bc.snaps.Cap(bc.CurrentBlock().Root(), 0); err != nil {
+			log.Error("Failed to flatten state snapshot", "err", err)
+		}
+		bc.snaps.Close()
+	}
 	// Ensure the state of a recent block is also stored to disk before exiting.
 	// We're writing three different states to catch different restart scenarios:
 	//  - HEAD:     So we don't need to reprocess any blocks in the general case
@@ -939,6 +996,9 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 				}
 				triedb.Dereference(root.(common.Hash), common.Hash{})
 			}
+			if interval := bc.cacheConfig.PruneInterval; interval > 0 && current%interval == 0 && atomic.LoadInt32(&bc.pruning) == 0 {
+				bc.pruneState()
+			}
 		}
 	}
 	rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receipts)
@@ -974,6 +1034,14 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 	// Set new head.
 	if status == CanonStatTy {
 		bc.insert(block)
+
+		// Flatten the snapshot layers below the new head
+		if bc.snaps != nil {
+			if err := bc.snaps.Cap(block.Root(), snapshotLayers); err != nil {
+				log.Warn("Failed to cap state snapshot", "err", err)
+				bc.snaps.Rebuild(block.Root())
+			}
+		}
 	}
 	bc.futureBlocks.Remove(block.Hash())
 	// fmt.Printf("******STATEDB****** %s \n", state.Dump())
@@ -1055,6 +1123,10 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []interface{}, []*ty
 			bc.reportBlock(block, nil, ErrBlacklistedHash)
 			return i, events, coalescedLogs, ErrBlacklistedHash
 		}
+		if bc.hc.conflictsCheckpoint(block.Header()) {
+			bc.reportBlock(block, nil, ErrCheckpointMismatch)
+			return i, events, coalescedLogs, ErrCheckpointMismatch
+		}
 		// Wait for the block's verification to complete
 		bstart := time.Now()
 
@@ -1493,6 +1565,24 @@ func (bc *BlockChain) CurrentHeader() *types.Header {
 	return bc.hc.CurrentHeader()
 }
 
+// FinalizedHeader returns the latest header near the head of the chain which
+// carries the commit certificate of its witnesses, nil if none is found or the
+// consensus engine doesn't certify blocks.
+func (bc *BlockChain) FinalizedHeader() *types.Header {
+	engine, ok := bc.engine.(certificateVerifier)
+	if !ok {
+		return nil
+	}
+	header := bc.CurrentHeader()
+	for i := 0; i < maxFinalityLookback && header != nil && header.Number.Sign() > 0; i++ {
+		if engine.VerifyCertificate(header, header.Witnesses) == nil {
+			return header
+		}
+		header = bc.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	}
+	return nil
+}
+
 // GetTd retrieves a block's total difficulty in the canonical chain from the
 // database by hash and number, caching it if found.
 func (bc *BlockChain) GetTd(hash common.Hash, number uint64) *big.Int {
@@ -1553,6 +1643,18 @@ func (bc *BlockChain) Config() *params.ChainConfig { return bc.chainConfig }
 // Engine retrieves the blockchain's consensus engine.
 func (bc *BlockChain) Engine() consensus.Engine { return bc.engine }
 
+// SetCheckpoint sets the trusted checkpoint, blocks conflicting with it are
+// refused. It fails if the local chain conflicts with it.
+func (bc *BlockChain) SetCheckpoint(cp *params.Checkpoint) error {
+	bc.chainmu.Lock()
+	defer bc.chainmu.Unlock()
+
+	return bc.hc.SetCheckpoint(cp)
+}
+
+// Checkpoint retrieves the trusted checkpoint, nil if none.
+func (bc *BlockChain) Checkpoint() *params.Checkpoint { return bc.hc.Checkpoint() }
+
 // SubscribeRemovedLogsEvent registers a subscription of RemovedLogsEvent.
 func (bc *BlockChain) SubscribeRemovedLogsEvent(ch chan<- RemovedLogsEvent) event.Subscription {
 	return bc.scope.Track(bc.rmLogsFeed.Subscribe(ch))
@@ -0,0 +1,198 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/common/hexutil"
+	"github.com/vntchain/go-vnt/consensus"
+	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/crypto"
+	"github.com/vntchain/go-vnt/log"
+	"github.com/vntchain/go-vnt/params"
+	"github.com/vntchain/go-vnt/rlp"
+)
+
+var (
+	// ErrCheckpointMismatch is returned if a block conflicts with the trusted
+	// checkpoint.
+	ErrCheckpointMismatch = errors.New("block conflicts with trusted checkpoint")
+
+	errNoCheckpointProof    = errors.New("checkpoint neither signed nor certified")
+	errNoCheckpointSigners  = errors.New("no trusted checkpoint signers configured")
+	errUntrustedCertificate = errors.New("unsigned checkpoint, certificates have to be trusted explicitly")
+)
+
+// SignedCheckpoint is a checkpoint along with its proof: either the signatures
+// of a quorum of trusted keys, or the header of the checkpoint block carrying
+// a commit certificate. The witnesses named by the checkpoint are not trusted,
+// the certificate has to come from 2f+1 witnesses of an anchor: the genesis or
+// an earlier verified checkpoint. Certified checkpoints are only accepted when
+// explicitly trusted, as the witnesses of the anchor may have left long ago.
+type SignedCheckpoint struct {
+	params.Checkpoint
+	Signatures []hexutil.Bytes `json:"signatures,omitempty"`
+	Header     hexutil.Bytes   `json:"header,omitempty"` // RLP encoded header with its commit messages
+}
+
+// certificateVerifier is implemented by consensus engines checking the commit
+// certificates of blocks.
+type certificateVerifier interface {
+	VerifyCertificate(header *types.Header, witnesses []common.Address) error
+}
+
+// NewCertifiedCheckpoint creates a checkpoint of a block, proven by the commit
+// certificate in its header.
+func NewCertifiedCheckpoint(header *types.Header) (*SignedCheckpoint, error) {
+	blob, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedCheckpoint{
+		Checkpoint: params.Checkpoint{
+			Number:    header.Number.Uint64(),
+			Hash:      header.Hash(),
+			Witnesses: header.Witnesses,
+		},
+		Header: blob,
+	}, nil
+}
+
+// SigHash returns the hash signed by the trusted keys.
+func (c *SignedCheckpoint) SigHash() common.Hash {
+	blob, _ := rlp.EncodeToBytes([]interface{}{c.Number, c.Hash, c.Witnesses})
+	return crypto.Keccak256Hash(blob)
+}
+
+// Sign adds the signature of a trusted key to the checkpoint.
+func (c *SignedCheckpoint) Sign(key *ecdsa.PrivateKey) error {
+	sig, err := crypto.Sign(c.SigHash().Bytes(), key)
+	if err != nil {
+		return err
+	}
+	c.Signatures = append(c.Signatures, sig)
+	return nil
+}
+
+// Verify checks that the checkpoint is signed by threshold distinct trusted
+// keys. If it carries no signatures and trustCertificate is set, it has to be
+// certified by the anchor witnesses instead.
+func (c *SignedCheckpoint) Verify(signers []common.Address, threshold int, anchor []common.Address, trustCertificate bool, engine consensus.Engine) error {
+	switch {
+	case len(c.Signatures) > 0:
+		return c.verifySignatures(signers, threshold)
+	case len(c.Header) > 0 && !trustCertificate:
+		return errUntrustedCertificate
+	case len(c.Header) > 0:
+		return c.verifyCertificate(anchor, engine)
+	}
+	return errNoCheckpointProof
+}
+
+func (c *SignedCheckpoint) verifySignatures(signers []common.Address, threshold int) error {
+	if len(signers) == 0 {
+		return errNoCheckpointSigners
+	}
+	if threshold <= 0 || threshold > len(signers) {
+		return fmt.Errorf("invalid checkpoint threshold %d of %d signers", threshold, len(signers))
+	}
+	trusted := make(map[common.Address]bool, len(signers))
+	for _, signer := range signers {
+		trusted[signer] = true
+	}
+	var (
+		hash   = c.SigHash()
+		signed = make(map[common.Address]bool)
+	)
+	for _, sig := range c.Signatures {
+		pubkey, err := crypto.SigToPub(hash.Bytes(), sig)
+		if err != nil {
+			return err
+		}
+		if addr := crypto.PubkeyToAddress(*pubkey); trusted[addr] {
+			signed[addr] = true
+		}
+	}
+	if len(signed) < threshold {
+		return fmt.Errorf("checkpoint signed by %d trusted keys, want %d", len(signed), threshold)
+	}
+	return nil
+}
+
+func (c *SignedCheckpoint) verifyCertificate(anchor []common.Address, engine consensus.Engine) error {
+	header := new(types.Header)
+	if err := rlp.DecodeBytes(c.Header, header); err != nil {
+		return err
+	}
+	if header.Hash() != c.Hash || header.Number.Uint64() != c.Number {
+		return fmt.Errorf("checkpoint header #%d [%x…] mismatch", header.Number, header.Hash().Bytes()[:4])
+	}
+	if !sameAddresses(header.Witnesses, c.Witnesses) {
+		return errors.New("checkpoint witnesses mismatch")
+	}
+	v, ok := engine.(certificateVerifier)
+	if !ok {
+		return errors.New("consensus engine can't verify commit certificates")
+	}
+	if len(anchor) == 0 {
+		return errors.New("no anchor witnesses to verify the certificate")
+	}
+	if err := v.VerifyCertificate(header, anchor); err != nil {
+		return fmt.Errorf("checkpoint not certified by the anchor witnesses: %v", err)
+	}
+	return nil
+}
+
+// LoadCheckpoint returns the trusted checkpoint of the chain: the one read from
+// the file, which has to be proven, or the one embedded for the network. The
+// certificate of an unsigned checkpoint is checked against the witnesses of
+// the embedded checkpoint if it precedes, or else of the genesis.
+func LoadCheckpoint(file string, signers []common.Address, threshold int, trustCertificate bool, genesis *types.Header, engine consensus.Engine) (*params.Checkpoint, error) {
+	embedded := params.TrustedCheckpoints[genesis.Hash()]
+	if file == "" {
+		return embedded, nil
+	}
+	cp := new(SignedCheckpoint)
+	if err := common.LoadJSON(file, cp); err != nil {
+		return nil, err
+	}
+	anchor := genesis.Witnesses
+	if embedded != nil && embedded.Number < cp.Number && len(embedded.Witnesses) > 0 {
+		anchor = embedded.Witnesses
+	}
+	if err := cp.Verify(signers, threshold, anchor, trustCertificate, engine); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint: %v", err)
+	}
+	log.Info("Loaded trusted checkpoint", "number", cp.Number, "hash", cp.Hash, "witnesses", len(cp.Witnesses))
+	return &cp.Checkpoint, nil
+}
+
+func sameAddresses(a, b []common.Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,405 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package era implements an archive format for the history of the chain. Each
+// file holds the canonical blocks of a fixed range along with their receipts
+// and total difficulties, and an accumulator root committing to all of them,
+// so that files can be verified on their own and imported in any order.
+//
+// A file is a sequence of records, each made of an 8 byte header, holding the
+// little endian uint16 type and uint32 length of the record followed by two
+// zero bytes, and of its data:
+//
+//	Version | Block(first) ... Block(last) | Accumulator | BlockIndex
+//	Block = Header | Body | Receipts | TotalDifficulty
+//
+// Headers, bodies and receipts are snappy compressed RLP, the headers keeping
+// the commit messages certifying them. The accumulator is the root of the trie
+// of the hashes and total difficulties of the blocks, as derived for
+// transactions. The block index holds the number of the first block, the
+// offsets of the blocks in the file and their count, little endian.
+package era
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+
+	"github.com/golang/snappy"
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/rlp"
+)
+
+// Record types of a file.
+const (
+	typeVersion         uint16 = 0x3265
+	typeHeader          uint16 = 0x03
+	typeBody            uint16 = 0x04
+	typeReceipts        uint16 = 0x05
+	typeTotalDifficulty uint16 = 0x06
+	typeAccumulator     uint16 = 0x07
+	typeBlockIndex      uint16 = 0x3266
+)
+
+// recordHeaderSize is the size of the header of a record.
+const recordHeaderSize = 8
+
+// MaxBlocks is the largest number of blocks held by a file.
+const MaxBlocks = 8192
+
+var (
+	errEmptyFile     = errors.New("no blocks in file")
+	errTooManyBlocks = errors.New("too many blocks in file")
+	errCorruptFile   = errors.New("corrupt file")
+	errOutOfRange    = errors.New("block out of range of file")
+	errBrokenChain   = errors.New("block not linked to its parent")
+	errTxRoot        = errors.New("transaction root mismatch")
+	errReceiptRoot   = errors.New("receipt root mismatch")
+	errTdMismatch    = errors.New("total difficulty mismatch")
+	errAccumulator   = errors.New("accumulator root mismatch")
+)
+
+// CertificateVerifier is implemented by consensus engines able to check the
+// commit certificates of headers.
+type CertificateVerifier interface {
+	// VerifyCertificate checks that 2f+1 witnesses of the set committed to the
+	// header.
+	VerifyCertificate(header *types.Header, witnesses []common.Address) error
+}
+
+// headerRecord is the entry of a block in the accumulator.
+type headerRecord struct {
+	Hash            common.Hash
+	TotalDifficulty *big.Int
+}
+
+// headerRecords derives the accumulator root of the blocks of a file.
+type headerRecords []headerRecord
+
+func (r headerRecords) Len() int { return len(r) }
+
+func (r headerRecords) GetRlp(i int) []byte {
+	enc, _ := rlp.EncodeToBytes(r[i])
+	return enc
+}
+
+// Builder writes the blocks of a file.
+type Builder struct {
+	w       *bufio.Writer
+	written int64
+
+	start   uint64
+	offsets []int64
+	records headerRecords
+}
+
+// NewBuilder creates a builder writing a file into w.
+func NewBuilder(w io.Writer) *Builder {
+	return &Builder{w: bufio.NewWriter(w)}
+}
+
+// Add appends a block with its receipts and total difficulty to the file. The
+// blocks have to be added in order.
+func (b *Builder) Add(block *types.Block, receipts types.Receipts, td *big.Int) error {
+	if len(b.offsets) == 0 {
+		if err := b.writeRecord(typeVersion, nil); err != nil {
+			return err
+		}
+		b.start = block.NumberU64()
+	}
+	if len(b.offsets) == MaxBlocks {
+		return errTooManyBlocks
+	}
+	if number := b.start + uint64(len(b.offsets)); block.NumberU64() != number {
+		return fmt.Errorf("block #%d added out of order, want #%d", block.NumberU64(), number)
+	}
+	b.offsets = append(b.offsets, b.written)
+	b.records = append(b.records, headerRecord{block.Hash(), new(big.Int).Set(td)})
+
+	for _, entry := range []struct {
+		typ uint16
+		val interface{}
+	}{
+		{typeHeader, block.Header()},
+		{typeBody, block.Body()},
+		{typeReceipts, receipts},
+	} {
+		enc, err := rlp.EncodeToBytes(entry.val)
+		if err != nil {
+			return err
+		}
+		if err := b.writeRecord(entry.typ, snappy.Encode(nil, enc)); err != nil {
+			return err
+		}
+	}
+	return b.writeRecord(typeTotalDifficulty, td.Bytes())
+}
+
+// Finish writes the accumulator and the block index of the file, returning the
+// accumulator root.
+func (b *Builder) Finish() (common.Hash, error) {
+	if len(b.offsets) == 0 {
+		return common.Hash{}, errEmptyFile
+	}
+	root := types.DeriveSha(b.records)
+	if err := b.writeRecord(typeAccumulator, root[:]); err != nil {
+		return common.Hash{}, err
+	}
+	index := make([]byte, 8+8*len(b.offsets)+8)
+	binary.LittleEndian.PutUint64(index, b.start)
+	for i, offset := range b.offsets {
+		binary.LittleEndian.PutUint64(index[8+8*i:], uint64(offset))
+	}
+	binary.LittleEndian.PutUint64(index[len(index)-8:], uint64(len(b.offsets)))
+	if err := b.writeRecord(typeBlockIndex, index); err != nil {
+		return common.Hash{}, err
+	}
+	return root, b.w.Flush()
+}
+
+// writeRecord writes a record of the given type.
+func (b *Builder) writeRecord(typ uint16, data []byte) error {
+	var header [recordHeaderSize]byte
+	binary.LittleEndian.PutUint16(header[:], typ)
+	binary.LittleEndian.PutUint32(header[2:], uint32(len(data)))
+	if _, err := b.w.Write(header[:]); err != nil {
+		return err
+	}
+	if _, err := b.w.Write(data); err != nil {
+		return err
+	}
+	b.written += int64(recordHeaderSize + len(data))
+	return nil
+}
+
+// File is a file of blocks opened for reading.
+type File struct {
+	f    *os.File
+	size int64
+
+	start   uint64
+	offsets []int64
+	root    common.Hash
+}
+
+// Open opens the file at path, reading its block index and accumulator root.
+func Open(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := newFile(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return file, nil
+}
+
+func newFile(f *os.File) (*File, error) {
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	file := &File{f: f, size: stat.Size()}
+
+	// The block index is at the end of the file, ending with the block count
+	var count [8]byte
+	if file.size < 2*recordHeaderSize+8 {
+		return nil, errCorruptFile
+	}
+	if _, err := f.ReadAt(count[:], file.size-8); err != nil {
+		return nil, err
+	}
+	n := binary.LittleEndian.Uint64(count[:])
+	if n == 0 || n > MaxBlocks {
+		return nil, errCorruptFile
+	}
+	indexOffset := file.size - int64(recordHeaderSize+8+8*n+8)
+	accOffset := indexOffset - (recordHeaderSize + common.HashLength)
+	if accOffset < recordHeaderSize {
+		return nil, errCorruptFile
+	}
+	if _, _, err := file.readRecord(0, typeVersion); err != nil {
+		return nil, err
+	}
+	index, _, err := file.readRecord(indexOffset, typeBlockIndex)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(index)) != 8+8*n+8 {
+		return nil, errCorruptFile
+	}
+	file.start = binary.LittleEndian.Uint64(index)
+	file.offsets = make([]int64, n)
+	for i := range file.offsets {
+		offset := int64(binary.LittleEndian.Uint64(index[8+8*i:]))
+		if offset < recordHeaderSize || offset >= accOffset {
+			return nil, errCorruptFile
+		}
+		file.offsets[i] = offset
+	}
+	root, _, err := file.readRecord(accOffset, typeAccumulator)
+	if err != nil {
+		return nil, err
+	}
+	if len(root) != common.HashLength {
+		return nil, errCorruptFile
+	}
+	file.root = common.BytesToHash(root)
+	return file, nil
+}
+
+// Start returns the number of the first block of the file.
+func (f *File) Start() uint64 { return f.start }
+
+// Count returns the number of blocks in the file.
+func (f *File) Count() uint64 { return uint64(len(f.offsets)) }
+
+// Root returns the accumulator root recorded in the file.
+func (f *File) Root() common.Hash { return f.root }
+
+// Close closes the file.
+func (f *File) Close() error { return f.f.Close() }
+
+// Block reads the block with the given number, along with its receipts and its
+// total difficulty.
+func (f *File) Block(number uint64) (*types.Block, types.Receipts, *big.Int, error) {
+	if number < f.start || number-f.start >= f.Count() {
+		return nil, nil, nil, errOutOfRange
+	}
+	var (
+		header   types.Header
+		body     types.Body
+		receipts types.Receipts
+	)
+	offset := f.offsets[number-f.start]
+	for _, entry := range []struct {
+		typ uint16
+		val interface{}
+	}{
+		{typeHeader, &header},
+		{typeBody, &body},
+		{typeReceipts, &receipts},
+	} {
+		data, next, err := f.readRecord(offset, entry.typ)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("block #%d: %v", number, err)
+		}
+		if data, err = snappy.Decode(nil, data); err != nil {
+			return nil, nil, nil, fmt.Errorf("block #%d: %v", number, err)
+		}
+		if err := rlp.DecodeBytes(data, entry.val); err != nil {
+			return nil, nil, nil, fmt.Errorf("block #%d: %v", number, err)
+		}
+		offset = next
+	}
+	td, _, err := f.readRecord(offset, typeTotalDifficulty)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("block #%d: %v", number, err)
+	}
+	block := types.NewBlockWithHeader(&header).WithBody(body.Transactions)
+	return block, receipts, new(big.Int).SetBytes(td), nil
+}
+
+// readRecord reads the data of the record of the given type at offset,
+// returning the offset of the next record.
+func (f *File) readRecord(offset int64, typ uint16) ([]byte, int64, error) {
+	var header [recordHeaderSize]byte
+	if _, err := f.f.ReadAt(header[:], offset); err != nil {
+		return nil, 0, err
+	}
+	if binary.LittleEndian.Uint16(header[:]) != typ {
+		return nil, 0, fmt.Errorf("%v: record type %#x at offset %d, want %#x", errCorruptFile, binary.LittleEndian.Uint16(header[:]), offset, typ)
+	}
+	length := int64(binary.LittleEndian.Uint32(header[2:]))
+	if offset+recordHeaderSize+length > f.size {
+		return nil, 0, fmt.Errorf("%v: record at offset %d past end of file", errCorruptFile, offset)
+	}
+	data := make([]byte, length)
+	if _, err := f.f.ReadAt(data, offset+recordHeaderSize); err != nil {
+		return nil, 0, err
+	}
+	return data, offset + recordHeaderSize + length, nil
+}
+
+// Verify checks that the blocks of the file are linked to each other and match
+// the roots of their headers, that their total difficulties add up, and that
+// they are the ones committed to by the accumulator root of the file. The
+// commit certificates of the headers are checked too if verifier isn't nil.
+//
+// The certificates are checked against the witness lists carried by the
+// headers themselves, whose transitions can't be proven without the state.
+// They only show that the file is consistent: a forged file can carry forged
+// witnesses certifying its blocks. The blocks are only proven by a trusted
+// accumulator root, or by executing them.
+func Verify(f *File, verifier CertificateVerifier) error {
+	_, _, _, err := f.load(verifier)
+	return err
+}
+
+// load reads and verifies all the blocks of the file. As with Verify, the
+// commit certificates are checked against the witnesses named by the headers,
+// which is only safe if the accumulator root is trusted or the blocks are
+// executed afterwards.
+func (f *File) load(verifier CertificateVerifier) ([]*types.Block, []types.Receipts, []*big.Int, error) {
+	var (
+		blocks   = make([]*types.Block, 0, f.Count())
+		receipts = make([]types.Receipts, 0, f.Count())
+		tds      = make([]*big.Int, 0, f.Count())
+		records  = make(headerRecords, 0, f.Count())
+	)
+	for number := f.start; number < f.start+f.Count(); number++ {
+		block, rs, td, err := f.Block(number)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if block.NumberU64() != number {
+			return nil, nil, nil, fmt.Errorf("%v: block #%d at #%d", errCorruptFile, block.NumberU64(), number)
+		}
+		if len(blocks) > 0 {
+			parent := blocks[len(blocks)-1]
+			if block.ParentHash() != parent.Hash() {
+				return nil, nil, nil, fmt.Errorf("block #%d: %v", number, errBrokenChain)
+			}
+			if new(big.Int).Add(tds[len(tds)-1], block.Difficulty()).Cmp(td) != 0 {
+				return nil, nil, nil, fmt.Errorf("block #%d: %v", number, errTdMismatch)
+			}
+		}
+		if types.DeriveSha(block.Transactions()) != block.TxHash() {
+			return nil, nil, nil, fmt.Errorf("block #%d: %v", number, errTxRoot)
+		}
+		if types.DeriveSha(rs) != block.ReceiptHash() {
+			return nil, nil, nil, fmt.Errorf("block #%d: %v", number, errReceiptRoot)
+		}
+		if verifier != nil && number > 0 {
+			if err := verifier.VerifyCertificate(block.Header(), block.Header().Witnesses); err != nil {
+				return nil, nil, nil, fmt.Errorf("block #%d: %v", number, err)
+			}
+		}
+		blocks, receipts, tds = append(blocks, block), append(receipts, rs), append(tds, td)
+		records = append(records, headerRecord{block.Hash(), td})
+	}
+	if root := types.DeriveSha(records); root != f.root {
+		return nil, nil, nil, fmt.Errorf("%v: have %x, want %x", errAccumulator, root, f.root)
+	}
+	return blocks, receipts, tds, nil
+}
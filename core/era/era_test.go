@@ -0,0 +1,248 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vntchain/go-vnt/accounts/abi/bind/backends"
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/consensus/dpos"
+	"github.com/vntchain/go-vnt/core"
+	"github.com/vntchain/go-vnt/core/rawdb"
+	"github.com/vntchain/go-vnt/core/state"
+	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/core/vm"
+	"github.com/vntchain/go-vnt/crypto"
+	"github.com/vntchain/go-vnt/vntdb"
+)
+
+var testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+
+// newTestChain produces a simulated chain of n blocks with a transfer each.
+func newTestChain(t *testing.T, n int) *core.BlockChain {
+	sim := backends.NewSimulatedBackend(core.GenesisAlloc{
+		crypto.PubkeyToAddress(testKey.PublicKey): {Balance: big.NewInt(1e18)},
+	})
+	chain := sim.Blockchain()
+	for i := 0; i < n; i++ {
+		tx, err := types.SignTx(types.NewTransaction(uint64(i), common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil), types.HomesteadSigner{}, testKey)
+		if err != nil {
+			t.Fatalf("failed to sign transaction: %v", err)
+		}
+		if err := sim.SendTransaction(context.Background(), tx); err != nil {
+			t.Fatalf("failed to send transaction: %v", err)
+		}
+		sim.Commit()
+	}
+	return chain
+}
+
+// newEmptyChain creates a chain holding only the genesis of src.
+func newEmptyChain(t *testing.T, src *core.BlockChain) *core.BlockChain {
+	var (
+		db      = vntdb.NewMemDatabase()
+		genesis = src.Genesis()
+	)
+	statedb, err := src.StateAt(genesis.Root())
+	if err != nil {
+		t.Fatalf("failed to open genesis state: %v", err)
+	}
+	for it := state.NewNodeIterator(statedb); it.Next(); {
+		if it.Hash == (common.Hash{}) {
+			continue
+		}
+		if blob, err := statedb.Database().TrieDB().Node(it.Hash); err == nil {
+			db.Put(it.Hash[:], blob)
+		}
+	}
+	rawdb.WriteTd(db, genesis.Hash(), 0, genesis.Difficulty())
+	rawdb.WriteBlock(db, genesis)
+	rawdb.WriteReceipts(db, genesis.Hash(), 0, nil)
+	rawdb.WriteCanonicalHash(db, genesis.Hash(), 0)
+	rawdb.WriteHeadBlockHash(db, genesis.Hash())
+	rawdb.WriteHeadHeaderHash(db, genesis.Hash())
+	rawdb.WriteChainConfig(db, genesis.Hash(), src.Config())
+
+	chain, err := core.NewBlockChain(db, nil, src.Config(), dpos.New(src.Config().Dpos, db), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	return chain
+}
+
+// Tests that a chain exported into files is imported back, either executed or,
+// if the accumulators are trusted, along with its receipts.
+func TestExportImport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vnt-era-test")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := newTestChain(t, 12)
+	if err := Export(src, dir, 0, 12, 5); err != nil {
+		t.Fatalf("failed to export chain: %v", err)
+	}
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.era"))
+	if len(paths) != 3 {
+		t.Fatalf("file count mismatch: have %d, want 3", len(paths))
+	}
+	for _, path := range paths {
+		file, err := Open(path)
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", path, err)
+		}
+		if err := Verify(file, src.Engine().(CertificateVerifier)); err != nil {
+			t.Errorf("failed to verify %s: %v", path, err)
+		}
+		if want := Filename(file.Start()/5, file.Root()); filepath.Base(path) != want {
+			t.Errorf("file name mismatch: have %s, want %s", filepath.Base(path), want)
+		}
+		file.Close()
+	}
+	head := src.CurrentBlock()
+
+	// Import by executing the blocks
+	executed := newEmptyChain(t, src)
+	if err := Import(executed, dir, nil); err != nil {
+		t.Fatalf("failed to import executed chain: %v", err)
+	}
+	if have := executed.CurrentBlock(); have.Hash() != head.Hash() {
+		t.Errorf("executed head mismatch: have #%d, want #%d", have.NumberU64(), head.NumberU64())
+	}
+	// Import along with the receipts of trusted accumulators
+	trusted, err := ReadChecksums(filepath.Join(dir, ChecksumsFile))
+	if err != nil {
+		t.Fatalf("failed to read checksums: %v", err)
+	}
+	if len(trusted) != 3 {
+		t.Fatalf("trusted root count mismatch: have %d, want 3", len(trusted))
+	}
+	fast := newEmptyChain(t, src)
+	if err := Import(fast, dir, trusted); err != nil {
+		t.Fatalf("failed to import trusted chain: %v", err)
+	}
+	if have := fast.CurrentFastBlock(); have.Hash() != head.Hash() {
+		t.Errorf("fast head mismatch: have #%d, want #%d", have.NumberU64(), head.NumberU64())
+	}
+	if have := fast.CurrentBlock(); have.NumberU64() != 0 {
+		t.Errorf("full head mismatch: have #%d, want genesis", have.NumberU64())
+	}
+	for number := uint64(1); number <= head.NumberU64(); number++ {
+		block := src.GetBlockByNumber(number)
+		if receipts := fast.GetReceiptsByHash(block.Hash()); types.DeriveSha(receipts) != block.ReceiptHash() {
+			t.Errorf("block #%d: receipts mismatch", number)
+		}
+		if header := fast.GetHeaderByNumber(number); header == nil || len(header.CmtMsges) != len(block.CmtMsges()) {
+			t.Errorf("block #%d: commit messages missing", number)
+		}
+	}
+	// Files of untrusted accumulators are refused
+	file, err := Open(paths[1])
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", paths[1], err)
+	}
+	delete(trusted, file.Root())
+	file.Close()
+	if err := Import(newEmptyChain(t, src), dir, trusted); err == nil {
+		t.Errorf("imported file of untrusted accumulator")
+	}
+}
+
+// Tests that files inconsistent with their accumulator root are detected.
+func TestVerifyCorruptFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vnt-era-test")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	chain := newTestChain(t, 4)
+	write := func(name string, td func(number uint64) *big.Int) *File {
+		path := filepath.Join(dir, name)
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+		builder := NewBuilder(f)
+		for number := uint64(1); number <= 4; number++ {
+			block := chain.GetBlockByNumber(number)
+			if err := builder.Add(block, chain.GetReceiptsByHash(block.Hash()), td(number)); err != nil {
+				t.Fatalf("failed to add block #%d: %v", number, err)
+			}
+		}
+		if _, err := builder.Finish(); err != nil {
+			t.Fatalf("failed to finish file: %v", err)
+		}
+		f.Close()
+
+		file, err := Open(path)
+		if err != nil {
+			t.Fatalf("failed to open file: %v", err)
+		}
+		return file
+	}
+	tdOf := func(number uint64) *big.Int {
+		return chain.GetTdByHash(chain.GetBlockByNumber(number).Hash())
+	}
+	valid := write("valid.era", tdOf)
+	defer valid.Close()
+	if err := Verify(valid, nil); err != nil {
+		t.Fatalf("failed to verify valid file: %v", err)
+	}
+	if valid.Start() != 1 || valid.Count() != 4 {
+		t.Errorf("range mismatch: have #%d+%d, want #1+4", valid.Start(), valid.Count())
+	}
+	// Total difficulties not adding up
+	broken := write("broken.era", func(number uint64) *big.Int {
+		if number == 3 {
+			return new(big.Int).Add(tdOf(number), common.Big1)
+		}
+		return tdOf(number)
+	})
+	defer broken.Close()
+	if err := Verify(broken, nil); err == nil {
+		t.Errorf("verified file with broken total difficulties")
+	}
+	// Accumulator root replaced
+	blob, err := ioutil.ReadFile(filepath.Join(dir, "valid.era"))
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	accOffset := len(blob) - (recordHeaderSize + 8 + 8*4 + 8) - common.HashLength
+	blob[accOffset] ^= 0xff
+	if err := ioutil.WriteFile(filepath.Join(dir, "tampered.era"), blob, 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	tampered, err := Open(filepath.Join(dir, "tampered.era"))
+	if err != nil {
+		t.Fatalf("failed to open tampered file: %v", err)
+	}
+	defer tampered.Close()
+	if err := Verify(tampered, nil); err == nil {
+		t.Errorf("verified file with tampered accumulator root")
+	}
+	if _, _, _, err := valid.Block(5); err != errOutOfRange {
+		t.Errorf("out of range error mismatch: have %v, want %v", err, errOutOfRange)
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core"
+	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/log"
+)
+
+// ChecksumsFile is the file listing the accumulator roots of the files of a
+// directory, along with their names.
+const ChecksumsFile = "checksums.txt"
+
+// Filename returns the name of the file holding the blocks of the given epoch,
+// named after its accumulator root.
+func Filename(epoch uint64, root common.Hash) string {
+	return fmt.Sprintf("vnt-%05d-%x.era", epoch, root[:4])
+}
+
+// Export writes the canonical blocks from first to last into dir, in files of
+// the step blocks of an epoch, and lists the accumulator roots of all the files
+// of dir into its checksums file.
+func Export(chain *core.BlockChain, dir string, first, last, step uint64) error {
+	if first > last {
+		return fmt.Errorf("first block %d past last %d", first, last)
+	}
+	if step == 0 || step > MaxBlocks {
+		return fmt.Errorf("invalid step %d, want 1 to %d", step, MaxBlocks)
+	}
+	if head := chain.CurrentBlock().NumberU64(); last > head {
+		return fmt.Errorf("last block %d past head %d", last, head)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	start := time.Now()
+	for epoch := first / step; epoch <= last/step; epoch++ {
+		from, to := epoch*step, (epoch+1)*step-1
+		if from < first {
+			from = first
+		}
+		if to > last {
+			to = last
+		}
+		root, err := exportFile(chain, dir, epoch, from, to)
+		if err != nil {
+			return err
+		}
+		log.Info("Exported history file", "epoch", epoch, "first", from, "last", to, "root", root, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return writeChecksums(dir)
+}
+
+// exportFile writes the blocks from first to last into the file of the epoch.
+func exportFile(chain *core.BlockChain, dir string, epoch, first, last uint64) (common.Hash, error) {
+	tmp := filepath.Join(dir, fmt.Sprintf("vnt-%05d.era.tmp", epoch))
+	f, err := os.Create(tmp)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer os.Remove(tmp)
+
+	builder := NewBuilder(f)
+	for number := first; number <= last; number++ {
+		block := chain.GetBlockByNumber(number)
+		if block == nil {
+			f.Close()
+			return common.Hash{}, fmt.Errorf("block #%d not found", number)
+		}
+		td := chain.GetTd(block.Hash(), number)
+		if td == nil {
+			f.Close()
+			return common.Hash{}, fmt.Errorf("total difficulty of block #%d not found", number)
+		}
+		if err := builder.Add(block, chain.GetReceiptsByHash(block.Hash()), td); err != nil {
+			f.Close()
+			return common.Hash{}, err
+		}
+	}
+	root, err := builder.Finish()
+	if err != nil {
+		f.Close()
+		return common.Hash{}, err
+	}
+	if err := f.Close(); err != nil {
+		return common.Hash{}, err
+	}
+	return root, os.Rename(tmp, filepath.Join(dir, Filename(epoch, root)))
+}
+
+// writeChecksums lists the accumulator roots of the files of dir into its
+// checksums file.
+func writeChecksums(dir string) error {
+	files, err := openDir(dir)
+	if err != nil {
+		return err
+	}
+	var lines []string
+	for _, file := range files {
+		lines = append(lines, fmt.Sprintf("%x %s", file.root, filepath.Base(file.f.Name())))
+		file.Close()
+	}
+	return ioutil.WriteFile(filepath.Join(dir, ChecksumsFile), []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// ReadChecksums reads the accumulator roots listed in a checksums file.
+func ReadChecksums(path string) (map[common.Hash]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	roots := make(map[common.Hash]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		root := common.FromHex(fields[0])
+		if len(root) != common.HashLength {
+			return nil, fmt.Errorf("invalid accumulator root %q", fields[0])
+		}
+		roots[common.BytesToHash(root)] = true
+	}
+	return roots, scanner.Err()
+}
+
+// openDir opens the files of dir, sorted by their first block.
+func openDir(dir string) ([]*File, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.era"))
+	if err != nil {
+		return nil, err
+	}
+	files := make([]*File, 0, len(paths))
+	for _, path := range paths {
+		file, err := Open(path)
+		if err != nil {
+			for _, file := range files {
+				file.Close()
+			}
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].start < files[j].start })
+	return files, nil
+}
+
+// loadedFile is a file read and verified ahead of its import.
+type loadedFile struct {
+	blocks   []*types.Block
+	receipts []types.Receipts
+	err      error
+}
+
+// Import inserts the blocks of the files of dir into the chain, in order. The
+// files are read and verified concurrently, and have to follow each other
+// without gaps. If trusted is nil, the blocks are executed, which checks their
+// witness lists against the state. Otherwise, every file has to be committed
+// to by a trusted accumulator root, and its blocks are inserted along with their
+// receipts without being executed, like fast synced ones, so the state of the
+// head has to be synced afterwards. The commit certificates checked while
+// loading the files name their own witnesses, so they prove nothing in either
+// mode: the trusted root or the execution does.
+func Import(chain *core.BlockChain, dir string, trusted map[common.Hash]bool) error {
+	files, err := openDir(dir)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}()
+	for i, file := range files {
+		if i > 0 && file.start != files[i-1].start+files[i-1].Count() {
+			return fmt.Errorf("%s: first block #%d doesn't follow #%d", file.f.Name(), file.start, files[i-1].start+files[i-1].Count()-1)
+		}
+		if trusted != nil && !trusted[file.root] {
+			return fmt.Errorf("%s: untrusted accumulator root %x", file.f.Name(), file.root)
+		}
+	}
+	verifier, _ := chain.Engine().(CertificateVerifier)
+
+	// Load the files concurrently, keeping only a few loaded ahead of the import
+	var (
+		loaded  = make([]chan *loadedFile, len(files))
+		workers = make(chan struct{}, runtime.NumCPU())
+		quit    = make(chan struct{})
+	)
+	defer close(quit)
+	for i := range loaded {
+		loaded[i] = make(chan *loadedFile, 1)
+	}
+	go func() {
+		for i, file := range files {
+			select {
+			case workers <- struct{}{}:
+			case <-quit:
+				return
+			}
+			go func(i int, file *File) {
+				blocks, receipts, _, err := file.load(verifier)
+				loaded[i] <- &loadedFile{blocks, receipts, err}
+			}(i, file)
+		}
+	}()
+	start := time.Now()
+	for i, file := range files {
+		res := <-loaded[i]
+		<-workers
+		if res.err != nil {
+			return fmt.Errorf("%s: %v", file.f.Name(), res.err)
+		}
+		blocks, receipts := res.blocks, res.receipts
+		if blocks[0].NumberU64() == 0 {
+			if blocks[0].Hash() != chain.Genesis().Hash() {
+				return fmt.Errorf("%s: genesis mismatch: have %x, want %x", file.f.Name(), blocks[0].Hash(), chain.Genesis().Hash())
+			}
+			blocks, receipts = blocks[1:], receipts[1:]
+		}
+		if len(blocks) > 0 {
+			if trusted != nil {
+				err = insertReceiptChain(chain, blocks, receipts)
+			} else {
+				err = insertChain(chain, blocks)
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %v", file.f.Name(), err)
+			}
+		}
+		log.Info("Imported history file", "first", file.start, "count", file.Count(), "root", file.root, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return nil
+}
+
+// insertChain executes and inserts the blocks the chain misses.
+func insertChain(chain *core.BlockChain, blocks []*types.Block) error {
+	for len(blocks) > 0 && chain.HasBlockAndState(blocks[0].Hash(), blocks[0].NumberU64()) {
+		blocks = blocks[1:]
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+	_, err := chain.InsertChain(blocks)
+	return err
+}
+
+// insertReceiptChain inserts the headers, bodies and receipts of the blocks the
+// chain misses, without executing them.
+func insertReceiptChain(chain *core.BlockChain, blocks []*types.Block, receipts []types.Receipts) error {
+	headers := make([]*types.Header, len(blocks))
+	for i, block := range blocks {
+		headers[i] = block.Header()
+	}
+	if _, err := chain.InsertHeaderChain(headers, 1); err != nil {
+		return err
+	}
+	for len(blocks) > 0 && chain.HasBlock(blocks[0].Hash(), blocks[0].NumberU64()) {
+		blocks, receipts = blocks[1:], receipts[1:]
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+	_, err := chain.InsertReceiptChain(blocks, receipts)
+	return err
+}
@@ -52,6 +52,11 @@ type ChainHeadEvent struct{ Block *types.Block }
 
 type SendBftMsgEvent struct{ BftMsg types.BftMsg }
 
-type BftPeerChangeEvent struct{ Urls []string }
+// BftPeerChangeEvent is posted when the witness list changes. Witnesses holds
+// the addresses belonging to Urls, it is empty if only the urls are known.
+type BftPeerChangeEvent struct {
+	Witnesses []common.Address
+	Urls      []string
+}
 
 type RecBftMsgEvent struct{ BftMsg types.BftMsg }
@@ -0,0 +1,67 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"time"
+
+	"github.com/vntchain/go-vnt/core/rawdb"
+	"github.com/vntchain/go-vnt/log"
+)
+
+// FreezerMargin is the number of blocks below the finalized one which are kept
+// in the key-value store, the older ones are moved into the ancient store.
+const FreezerMargin = 2048
+
+// freezerRecheckInterval is the time between two moves of the old blocks into
+// the ancient store.
+const freezerRecheckInterval = time.Minute
+
+// freeze periodically moves the old blocks into the ancient store, until the
+// chain is stopped.
+func (bc *BlockChain) freeze() {
+	defer bc.wg.Done()
+
+	ticker := time.NewTicker(freezerRecheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := bc.Freeze(FreezerMargin); err != nil && err != rawdb.ErrInterrupted {
+			log.Error("Failed to move blocks into ancient store", "err", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-bc.quit:
+			return
+		}
+	}
+}
+
+// Freeze moves the canonical blocks older than the finalized one by more than
+// margin into the ancient store, returning their number. Only the blocks with
+// their receipts are moved, not the headers fast sync is ahead with.
+func (bc *BlockChain) Freeze(margin uint64) (int, error) {
+	final := bc.FinalizedHeader()
+	if final == nil || final.Number.Uint64() <= margin {
+		return 0, nil
+	}
+	limit := final.Number.Uint64() - margin
+	if head := bc.CurrentFastBlock().NumberU64(); head < limit {
+		limit = head
+	}
+	return rawdb.FreezeBlocks(bc.db, limit, bc.quit)
+}
@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"time"
 
 	"github.com/vntchain/go-vnt/common"
 	"github.com/vntchain/go-vnt/common/hexutil"
@@ -31,6 +32,7 @@ import (
 	"github.com/vntchain/go-vnt/core/rawdb"
 	"github.com/vntchain/go-vnt/core/state"
 	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/core/vm/election"
 	"github.com/vntchain/go-vnt/log"
 	"github.com/vntchain/go-vnt/params"
 	"github.com/vntchain/go-vnt/rlp"
@@ -328,6 +330,36 @@ func DefaultRinkebyGenesisBlock() *Genesis {
 	}
 }
 
+// DeveloperGenesisBlock returns the 'gvnt --dev' genesis block. The given
+// witness is the only block producer, it is pre-funded and registered as an
+// active candidate in the election contract. Period must be positive.
+func DeveloperGenesisBlock(period uint64, witness common.Address) *Genesis {
+	// Override the default period to the user requested one
+	config := *params.TestChainConfig
+	config.Dpos = &params.DposConfig{
+		Period:       period,
+		WitnessesNum: 1,
+	}
+	storage, err := election.GenesisStorage([]common.Address{witness}, nil)
+	if err != nil {
+		panic(err)
+	}
+	now := uint64(time.Now().Unix())
+
+	// Assemble and return the genesis with the election contract initialized
+	return &Genesis{
+		Config:     &config,
+		Timestamp:  now - now%period,
+		GasLimit:   6283185,
+		Difficulty: big.NewInt(1),
+		Witnesses:  []common.Address{witness},
+		Alloc: map[common.Address]GenesisAccount{
+			common.BytesToAddress([]byte{9}): {Balance: big.NewInt(0), Nonce: 1, Storage: storage}, // Election
+			witness:                          {Balance: new(big.Int).Mul(big.NewInt(1e18), big.NewInt(1e9))},
+		},
+	}
+}
+
 func decodePrealloc(data string) GenesisAlloc {
 	var p []struct{ Addr, Balance *big.Int }
 	if err := rlp.NewStream(strings.NewReader(data), 0).Decode(&p); err != nil {
@@ -62,8 +62,9 @@ type HeaderChain struct {
 
 	procInterrupt func() bool
 
-	rand   *mrand.Rand
-	engine consensus.Engine
+	rand       *mrand.Rand
+	engine     consensus.Engine
+	checkpoint *params.Checkpoint // Trusted checkpoint, chains conflicting with it are refused
 }
 
 // NewHeaderChain creates a new HeaderChain structure.
@@ -239,6 +240,9 @@ func (hc *HeaderChain) ValidateHeaderChain(chain []*types.Header, checkFreq int)
 		if BadHashes[header.Hash()] {
 			return i, ErrBlacklistedHash
 		}
+		if hc.conflictsCheckpoint(header) {
+			return i, ErrCheckpointMismatch
+		}
 		// Otherwise wait for headers checks and ensure they pass
 		if err := <-results; err != nil {
 			return i, err
@@ -488,6 +492,31 @@ func (hc *HeaderChain) Config() *params.ChainConfig { return hc.config }
 // Engine retrieves the header chain's consensus engine.
 func (hc *HeaderChain) Engine() consensus.Engine { return hc.engine }
 
+// SetCheckpoint sets the trusted checkpoint of the chain. It fails if the local
+// canonical chain conflicts with it.
+func (hc *HeaderChain) SetCheckpoint(cp *params.Checkpoint) error {
+	if cp != nil {
+		if header := hc.GetHeaderByNumber(cp.Number); header != nil && header.Hash() != cp.Hash {
+			return fmt.Errorf("%v: local #%d [%x…], checkpoint [%x…]", ErrCheckpointMismatch, cp.Number, header.Hash().Bytes()[:4], cp.Hash.Bytes()[:4])
+		}
+	}
+	hc.checkpoint = cp
+	return nil
+}
+
+// Checkpoint retrieves the trusted checkpoint of the chain, nil if none.
+func (hc *HeaderChain) Checkpoint() *params.Checkpoint { return hc.checkpoint }
+
+// conflictsCheckpoint reports whether the header is at the height of the
+// trusted checkpoint but isn't the checkpoint block.
+func (hc *HeaderChain) conflictsCheckpoint(header *types.Header) bool {
+	cp := hc.checkpoint
+	if cp == nil || header.Number.Uint64() != cp.Number {
+		return false
+	}
+	return header.Hash() != cp.Hash || !sameAddresses(header.Witnesses, cp.Witnesses)
+}
+
 // GetBlock implements consensus.ChainReader, and returns nil for every input as
 // a header chain does not have blocks available for retrieval.
 func (hc *HeaderChain) GetBlock(hash common.Hash, number uint64) *types.Block {
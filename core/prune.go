@@ -0,0 +1,83 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"sync/atomic"
+
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/state/pruner"
+	"github.com/vntchain/go-vnt/log"
+)
+
+// pruneState deletes from disk the state of all blocks but the finalized one
+// and the ones still held in memory, in the background. The state of the
+// finalized block is flushed first, so a crashed node resumes from it. The trie
+// database keeps being flushed and garbage collected while pruning: the nodes
+// it writes meanwhile are reported to the pruner, which doesn't sweep them. If
+// a root is dereferenced before it's marked, pruning is aborted.
+//
+// The method expects the chain mutex to be held.
+func (bc *BlockChain) pruneState() {
+	final := bc.FinalizedHeader()
+	if final == nil {
+		log.Warn("Skipping state pruning, no finalized block")
+		return
+	}
+	triedb := bc.stateCache.TrieDB()
+	if err := triedb.Commit(final.Root, false); err != nil {
+		log.Error("Failed to commit finalized state", "number", final.Number, "err", err)
+		return
+	}
+	// Gather the roots of the tries in memory, along with the finalized one
+	roots := []common.Hash{final.Root}
+	var items []interface{}
+	var prios []float32
+	for !bc.triegc.Empty() {
+		root, number := bc.triegc.Pop()
+		roots = append(roots, root.(common.Hash))
+		items, prios = append(items, root), append(prios, number)
+	}
+	for i := range items {
+		bc.triegc.Push(items[i], prios[i])
+	}
+	p, err := pruner.New(bc.db, triedb)
+	if err != nil {
+		log.Warn("Skipping state pruning", "err", err)
+		return
+	}
+	atomic.StoreInt32(&bc.pruning, 1)
+	triedb.SetFlushCallback(p.Flushed)
+	log.Info("Pruning stale state", "finalized", final.Number, "roots", len(roots))
+
+	bc.wg.Add(1)
+	go func() {
+		defer bc.wg.Done()
+		defer atomic.StoreInt32(&bc.pruning, 0)
+		defer triedb.SetFlushCallback(nil)
+
+		for _, root := range roots {
+			if err := p.Keep(root, bc.quit); err != nil {
+				log.Error("Failed to mark state to keep", "root", root, "err", err)
+				return
+			}
+		}
+		if _, _, err := p.Sweep(bc.quit); err != nil {
+			log.Error("Failed to prune stale state", "err", err)
+		}
+	}()
+}
@@ -0,0 +1,347 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/log"
+	"github.com/vntchain/go-vnt/vntdb"
+)
+
+var (
+	// ErrInterrupted is returned if freezing blocks was aborted.
+	ErrInterrupted = errors.New("freezing interrupted")
+
+	errNoAncientStore = errors.New("database has no ancient store")
+	errNotIterable    = errors.New("database can't be iterated")
+)
+
+// freezerBatchLimit is the number of blocks moved into the ancient store before
+// it's flushed and the blocks are deleted from the key-value store.
+const freezerBatchLimit = 2048
+
+// AncientReader wraps the reads of the ancient store, which holds the parts of
+// the finalized canonical blocks by number.
+type AncientReader interface {
+	// HasAncient returns whether a part of the block with the given number is
+	// in the ancient store.
+	HasAncient(kind string, number uint64) (bool, error)
+
+	// Ancient returns a part of the block with the given number.
+	Ancient(kind string, number uint64) ([]byte, error)
+
+	// Ancients returns the number of blocks in the ancient store.
+	Ancients() (uint64, error)
+
+	// AncientSize returns the size of an ancient table on disk.
+	AncientSize(kind string) (uint64, error)
+}
+
+// AncientWriter wraps the writes of the ancient store.
+type AncientWriter interface {
+	// AppendAncient stores the parts of the block following the last one in the
+	// ancient store.
+	AppendAncient(number uint64, hash, header, body, receipts, td []byte) error
+
+	// TruncateAncients drops the blocks past the given number of them.
+	TruncateAncients(items uint64) error
+
+	// Sync flushes the ancient store to disk.
+	Sync() error
+}
+
+// AncientStore is the append-only store of the finalized canonical blocks.
+type AncientStore interface {
+	AncientReader
+	AncientWriter
+}
+
+// KeyValueStore is a database which can be iterated over.
+type KeyValueStore interface {
+	vntdb.Database
+	NewIterator() iterator.Iterator
+	NewIteratorWithPrefix(prefix []byte) iterator.Iterator
+}
+
+// freezerdb is a key-value store with the finalized blocks moved out into an
+// ancient store.
+type freezerdb struct {
+	KeyValueStore
+	*freezer
+
+	freezeLock sync.Mutex // Lock serializing the moves of blocks into the ancient store
+}
+
+// Close closes both the key-value store and the ancient store.
+func (db *freezerdb) Close() {
+	if err := db.freezer.Close(); err != nil {
+		log.Error("Failed to close ancient store", "err", err)
+	}
+	db.KeyValueStore.Close()
+}
+
+// NewDatabaseWithFreezer adds the ancient store in dir to a key-value store.
+// The blocks are read from whichever holds them through the accessors of this
+// package, FreezeBlocks moves them over.
+func NewDatabaseWithFreezer(db vntdb.Database, dir string) (vntdb.Database, error) {
+	kv, ok := db.(KeyValueStore)
+	if !ok {
+		return nil, errNotIterable
+	}
+	frdb, err := newFreezer(dir)
+	if err != nil {
+		return nil, err
+	}
+	// Both stores have to be of the same chain, the genesis is kept in both
+	if frozen, _ := frdb.Ancients(); frozen > 0 {
+		genesis, _ := frdb.Ancient(freezerHashTable, 0)
+		if kvgenesis, _ := kv.Get(headerHashKey(0)); len(kvgenesis) > 0 && !bytes.Equal(genesis, kvgenesis) {
+			frdb.Close()
+			return nil, fmt.Errorf("genesis mismatch: ancient store %x, key-value store %x", genesis, kvgenesis)
+		}
+	}
+	return &freezerdb{KeyValueStore: kv, freezer: frdb}, nil
+}
+
+// DiskDB returns the key-value store of a database, without its ancient store.
+func DiskDB(db vntdb.Database) vntdb.Database {
+	if fdb, ok := db.(*freezerdb); ok {
+		return fdb.KeyValueStore
+	}
+	return db
+}
+
+// FreezeBlocks moves the canonical blocks below limit from the key-value store
+// into the ancient store, returning their number. The blocks of the side chains
+// at the same heights are deleted, and so are the ones of the canonical chain,
+// but for the genesis and the hash to number mappings.
+func FreezeBlocks(db vntdb.Database, limit uint64, interrupt <-chan struct{}) (int, error) {
+	fdb, ok := db.(*freezerdb)
+	if !ok {
+		return 0, errNoAncientStore
+	}
+	fdb.freezeLock.Lock()
+	defer fdb.freezeLock.Unlock()
+
+	var (
+		start  = time.Now()
+		logged = time.Now()
+		moved  int
+	)
+	for {
+		frozen, _ := fdb.Ancients()
+		if frozen >= limit {
+			break
+		}
+		first, last := frozen, frozen+freezerBatchLimit
+		if last > limit {
+			last = limit
+		}
+		// Append the batch of blocks, then flush it before deleting them
+		var fail error
+		number := first
+		for ; number < last; number++ {
+			if fail = freezeNext(fdb, number, interrupt); fail != nil {
+				break
+			}
+		}
+		if err := fdb.Sync(); err != nil {
+			return moved, err
+		}
+		if err := deleteFrozen(fdb.KeyValueStore, first, number); err != nil {
+			return moved, err
+		}
+		moved += int(number - first)
+		if fail != nil {
+			return moved, fail
+		}
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Moving blocks into ancient store", "number", last-1, "moved", moved, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if moved > 0 {
+		log.Info("Moved blocks into ancient store", "moved", moved, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return moved, nil
+}
+
+// freezeNext appends the block with the given number to the ancient store,
+// unless interrupted.
+func freezeNext(fdb *freezerdb, number uint64, interrupt <-chan struct{}) error {
+	select {
+	case <-interrupt:
+		return ErrInterrupted
+	default:
+	}
+	hash, header, body, receipts, td, err := freezeBlock(fdb.KeyValueStore, number)
+	if err != nil {
+		return err
+	}
+	return fdb.AppendAncient(number, hash[:], header, body, receipts, td)
+}
+
+// deleteFrozen deletes the blocks with numbers in [first, last) from the
+// key-value store, once they're in the ancient store.
+func deleteFrozen(kv KeyValueStore, first, last uint64) error {
+	batch := kv.NewBatch()
+	for number := first; number < last; number++ {
+		if number == 0 {
+			continue
+		}
+		canonical := ReadCanonicalHash(kv, number)
+		it := kv.NewIteratorWithPrefix(append(common.CopyBytes(headerPrefix), encodeBlockNumber(number)...))
+		for it.Next() {
+			key := it.Key()
+			if len(key) != len(headerPrefix)+8+common.HashLength {
+				continue
+			}
+			hash := common.BytesToHash(key[len(headerPrefix)+8:])
+			DeleteReceipts(batch, hash, number)
+			DeleteBody(batch, hash, number)
+			DeleteTd(batch, hash, number)
+			if hash == canonical {
+				// Keep the hash to number mapping, the block is looked up by hash
+				batch.Delete(headerKey(number, hash))
+			} else {
+				DeleteHeader(batch, hash, number)
+			}
+		}
+		it.Release()
+		if err := it.Error(); err != nil {
+			return err
+		}
+		DeleteCanonicalHash(batch, number)
+
+		if batch.ValueSize() > vntdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	return batch.Write()
+}
+
+// DatabaseStat is the number and size of the entries of a category of data.
+type DatabaseStat struct {
+	Store    string // Store holding the entries, key-value or ancient
+	Category string
+	Count    uint64
+	Size     common.StorageSize
+}
+
+// InspectDatabase iterates over the whole database, sizing its entries by the
+// category of data they hold.
+func InspectDatabase(db vntdb.Database) ([]DatabaseStat, error) {
+	kv, ok := DiskDB(db).(KeyValueStore)
+	if !ok {
+		return nil, errNotIterable
+	}
+	categories := []struct {
+		name  string
+		match func(key []byte) bool
+	}{
+		{"Headers", keyMatcher(headerPrefix, 8+common.HashLength)},
+		{"Total difficulties", func(key []byte) bool {
+			return keyMatcher(headerPrefix, 8+common.HashLength+len(headerTDSuffix))(key) && bytes.HasSuffix(key, headerTDSuffix)
+		}},
+		{"Canonical hashes", func(key []byte) bool {
+			return keyMatcher(headerPrefix, 8+len(headerHashSuffix))(key) && bytes.HasSuffix(key, headerHashSuffix)
+		}},
+		{"Hash to number mappings", keyMatcher(headerNumberPrefix, common.HashLength)},
+		{"Bodies", keyMatcher(blockBodyPrefix, 8+common.HashLength)},
+		{"Receipts", keyMatcher(blockReceiptsPrefix, 8+common.HashLength)},
+		{"Transaction lookups", keyMatcher(txLookupPrefix, common.HashLength)},
+		{"Bloom bits", keyMatcher(bloomBitsPrefix, 2+8+common.HashLength)},
+		{"Trie nodes and code", func(key []byte) bool { return len(key) == common.HashLength }},
+		{"Snapshot accounts", keyMatcher(SnapshotAccountPrefix, common.HashLength)},
+		{"Snapshot storage", keyMatcher(SnapshotStoragePrefix, 2*common.HashLength)},
+		{"Preimages", keyMatcher(preimagePrefix, common.HashLength)},
+		{"Chain configs", keyMatcher(configPrefix, common.HashLength)},
+		{"Chain indexes", func(key []byte) bool { return bytes.HasPrefix(key, BloomBitsIndexPrefix) }},
+		{"Metadata", func(key []byte) bool {
+			for _, meta := range [][]byte{databaseVerisionKey, headHeaderKey, headBlockKey, headFastBlockKey, fastTrieProgressKey, snapshotRootKey} {
+				if bytes.Equal(key, meta) {
+					return true
+				}
+			}
+			return false
+		}},
+	}
+	stats := make([]DatabaseStat, len(categories)+1)
+	for i, category := range categories {
+		stats[i] = DatabaseStat{Store: "Key-Value store", Category: category.name}
+	}
+	stats[len(categories)] = DatabaseStat{Store: "Key-Value store", Category: "Unaccounted"}
+
+	var (
+		start  = time.Now()
+		logged = time.Now()
+		count  uint64
+	)
+	it := kv.NewIterator()
+	defer it.Release()
+
+	for it.Next() {
+		key, size := it.Key(), common.StorageSize(len(it.Key())+len(it.Value()))
+		stat := &stats[len(categories)]
+		for i, category := range categories {
+			if category.match(key) {
+				stat = &stats[i]
+				break
+			}
+		}
+		stat.Count++
+		stat.Size += size
+
+		if count++; time.Since(logged) > 8*time.Second {
+			log.Info("Inspecting database", "count", count, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	if ancients, ok := db.(AncientReader); ok {
+		frozen, _ := ancients.Ancients()
+		for _, table := range []string{freezerHeaderTable, freezerBodiesTable, freezerReceiptTable, freezerDifficultyTable, freezerHashTable} {
+			size, err := ancients.AncientSize(table)
+			if err != nil {
+				return nil, err
+			}
+			stats = append(stats, DatabaseStat{Store: "Ancient store", Category: table, Count: frozen, Size: common.StorageSize(size)})
+		}
+	}
+	return stats, nil
+}
+
+// keyMatcher returns whether keys are made of the prefix and a suffix of the
+// given length.
+func keyMatcher(prefix []byte, suffix int) func(key []byte) bool {
+	return func(key []byte) bool {
+		return len(key) == len(prefix)+suffix && bytes.HasPrefix(key, prefix)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/vntdb"
+)
+
+// writeTestChain writes a canonical chain of blocks, along with a side block
+// at each height.
+func writeTestChain(db vntdb.Database, n uint64) (canon []*types.Block, side []*types.Block) {
+	parent := common.Hash{}
+	for i := uint64(0); i < n; i++ {
+		block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(i), ParentHash: parent})
+		fork := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(i), ParentHash: parent, Extra: []byte("side")})
+		for _, b := range []*types.Block{block, fork} {
+			WriteBlock(db, b)
+			WriteReceipts(db, b.Hash(), i, nil)
+			WriteTd(db, b.Hash(), i, new(big.Int).SetUint64(i+1))
+		}
+		WriteCanonicalHash(db, block.Hash(), i)
+		canon, side = append(canon, block), append(side, fork)
+		parent = block.Hash()
+	}
+	return canon, side
+}
+
+// Tests that the blocks moved into the ancient store are read through the same
+// accessors, and that the side chains at their heights are deleted.
+func TestFreezeBlocks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vnt-freezer-test")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	kvdb := vntdb.NewMemDatabase()
+	db, err := NewDatabaseWithFreezer(kvdb, dir)
+	if err != nil {
+		t.Fatalf("failed to open ancient store: %v", err)
+	}
+	canon, side := writeTestChain(db, 16)
+
+	if moved, err := FreezeBlocks(db, 10, nil); err != nil || moved != 10 {
+		t.Fatalf("blocks moved mismatch: have %d (%v), want 10", moved, err)
+	}
+	for i, block := range canon {
+		number := uint64(i)
+		if hash := ReadCanonicalHash(db, number); hash != block.Hash() {
+			t.Errorf("block %d: canonical hash mismatch: have %x, want %x", i, hash, block.Hash())
+		}
+		if header := ReadHeader(db, block.Hash(), number); header == nil || header.Hash() != block.Hash() {
+			t.Errorf("block %d: header mismatch", i)
+		}
+		if !HasBody(db, block.Hash(), number) || ReadBody(db, block.Hash(), number) == nil {
+			t.Errorf("block %d: body missing", i)
+		}
+		if receipts := ReadReceipts(db, block.Hash(), number); receipts == nil {
+			t.Errorf("block %d: receipts missing", i)
+		}
+		if td := ReadTd(db, block.Hash(), number); td == nil || td.Uint64() != number+1 {
+			t.Errorf("block %d: total difficulty mismatch: have %v, want %d", i, td, number+1)
+		}
+		if n := ReadHeaderNumber(db, block.Hash()); n == nil || *n != number {
+			t.Errorf("block %d: hash to number mapping missing", i)
+		}
+		// The frozen blocks are gone from the key-value store, but the genesis
+		frozen := i > 0 && i < 10
+		if has, _ := kvdb.Has(headerKey(number, block.Hash())); has == frozen {
+			t.Errorf("block %d: key-value store header presence mismatch: have %v, want %v", i, has, !frozen)
+		}
+		if HasHeader(db, side[i].Hash(), number) == frozen {
+			t.Errorf("block %d: side block presence mismatch", i)
+		}
+	}
+	// Freezing is resumed from the ancient store after reopening it
+	db.Close()
+	if db, err = NewDatabaseWithFreezer(kvdb, dir); err != nil {
+		t.Fatalf("failed to reopen ancient store: %v", err)
+	}
+	defer db.Close()
+
+	if moved, err := FreezeBlocks(db, 16, nil); err != nil || moved != 6 {
+		t.Fatalf("blocks moved mismatch: have %d (%v), want 6", moved, err)
+	}
+	if frozen, _ := db.(AncientReader).Ancients(); frozen != 16 {
+		t.Fatalf("ancients mismatch: have %d, want 16", frozen)
+	}
+	if _, err := FreezeBlocks(db, 17, nil); err == nil {
+		t.Errorf("missing block frozen")
+	}
+	// Rewinding drops the blocks from the ancient store
+	db.(AncientStore).TruncateAncients(12)
+	if hash := ReadCanonicalHash(db, 12); hash != (common.Hash{}) {
+		t.Errorf("truncated canonical hash returned: %x", hash)
+	}
+	if header := ReadHeader(db, canon[11].Hash(), 11); header == nil {
+		t.Errorf("kept header missing")
+	}
+}
+
+// Tests that an ancient store of another chain is refused.
+func TestFreezerGenesisMismatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vnt-freezer-test")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, _ := NewDatabaseWithFreezer(vntdb.NewMemDatabase(), dir)
+	writeTestChain(db, 2)
+	FreezeBlocks(db, 2, nil)
+	db.Close()
+
+	other := vntdb.NewMemDatabase()
+	WriteCanonicalHash(other, common.Hash{0x01}, 0)
+	if _, err := NewDatabaseWithFreezer(other, dir); err == nil {
+		t.Fatalf("ancient store of another chain opened")
+	}
+}
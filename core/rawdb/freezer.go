@@ -0,0 +1,199 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/log"
+)
+
+// errUnknownTable is returned if an ancient table which doesn't exist is used.
+var errUnknownTable = errors.New("unknown table")
+
+// freezer is the append-only store of the finalized canonical blocks, kept as
+// a set of flat tables numbered by block. Each table holds one part of the
+// blocks, all tables hold the same blocks.
+type freezer struct {
+	frozen uint64 // Number of blocks stored in all tables, accessed atomically
+
+	tables map[string]*freezerTable
+	lock   sync.Mutex // Lock serializing the writes
+}
+
+// newFreezer opens the ancient store in dir, cutting all the tables to the
+// blocks they all hold.
+func newFreezer(dir string) (*freezer, error) {
+	f := &freezer{tables: make(map[string]*freezerTable)}
+	for _, name := range []string{freezerHashTable, freezerHeaderTable, freezerBodiesTable, freezerReceiptTable, freezerDifficultyTable} {
+		table, err := newFreezerTable(dir, name, freezerNoCompression[name])
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		f.tables[name] = table
+	}
+	frozen := f.tables[freezerHashTable].Items()
+	for _, table := range f.tables {
+		if items := table.Items(); items < frozen {
+			frozen = items
+		}
+	}
+	if err := f.truncate(frozen); err != nil {
+		f.Close()
+		return nil, err
+	}
+	log.Info("Opened ancient store", "dir", dir, "blocks", frozen)
+	return f, nil
+}
+
+// HasAncient returns whether a part of the block with the given number is in
+// the ancient store.
+func (f *freezer) HasAncient(kind string, number uint64) (bool, error) {
+	if _, ok := f.tables[kind]; !ok {
+		return false, errUnknownTable
+	}
+	return number < atomic.LoadUint64(&f.frozen), nil
+}
+
+// Ancient returns a part of the block with the given number.
+func (f *freezer) Ancient(kind string, number uint64) ([]byte, error) {
+	table, ok := f.tables[kind]
+	if !ok {
+		return nil, errUnknownTable
+	}
+	if number >= atomic.LoadUint64(&f.frozen) {
+		return nil, errOutOfBounds
+	}
+	return table.Retrieve(number)
+}
+
+// Ancients returns the number of blocks in the ancient store.
+func (f *freezer) Ancients() (uint64, error) {
+	return atomic.LoadUint64(&f.frozen), nil
+}
+
+// AncientSize returns the size of an ancient table on disk.
+func (f *freezer) AncientSize(kind string) (uint64, error) {
+	table, ok := f.tables[kind]
+	if !ok {
+		return 0, errUnknownTable
+	}
+	return table.Size(), nil
+}
+
+// AppendAncient stores the parts of the block following the last one in the
+// ancient store. If any part fails, none of them is kept.
+func (f *freezer) AppendAncient(number uint64, hash, header, body, receipts, td []byte) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if frozen := atomic.LoadUint64(&f.frozen); number != frozen {
+		return fmt.Errorf("%v: block #%d, expected #%d", errOutOrderInsertion, number, frozen)
+	}
+	parts := []struct {
+		kind string
+		blob []byte
+	}{
+		{freezerHashTable, hash},
+		{freezerHeaderTable, header},
+		{freezerBodiesTable, body},
+		{freezerReceiptTable, receipts},
+		{freezerDifficultyTable, td},
+	}
+	for _, part := range parts {
+		if err := f.tables[part.kind].Append(number, part.blob); err != nil {
+			if rerr := f.truncate(number); rerr != nil {
+				log.Error("Failed to roll back ancient store", "number", number, "err", rerr)
+			}
+			return fmt.Errorf("table %s: %v", part.kind, err)
+		}
+	}
+	atomic.AddUint64(&f.frozen, 1)
+	return nil
+}
+
+// TruncateAncients drops the blocks past the given number of them.
+func (f *freezer) TruncateAncients(items uint64) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if atomic.LoadUint64(&f.frozen) <= items {
+		return nil
+	}
+	return f.truncate(items)
+}
+
+// truncate cuts all the tables to the given number of blocks.
+func (f *freezer) truncate(items uint64) error {
+	for _, table := range f.tables {
+		if err := table.truncate(items); err != nil {
+			return err
+		}
+	}
+	atomic.StoreUint64(&f.frozen, items)
+	return nil
+}
+
+// Sync flushes the ancient store to disk.
+func (f *freezer) Sync() error {
+	for _, table := range f.tables {
+		if err := table.Sync(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the tables of the ancient store.
+func (f *freezer) Close() error {
+	var errs []error
+	for _, table := range f.tables {
+		if err := table.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// freezeBlock reads the parts of a canonical block from the key-value store,
+// in their encoding in the ancient store.
+func freezeBlock(db DatabaseReader, number uint64) (hash common.Hash, header, body, receipts, td []byte, err error) {
+	if hash = ReadCanonicalHash(db, number); hash == (common.Hash{}) {
+		return hash, nil, nil, nil, nil, fmt.Errorf("canonical hash of block #%d missing", number)
+	}
+	if header, _ = db.Get(headerKey(number, hash)); len(header) == 0 {
+		return hash, nil, nil, nil, nil, fmt.Errorf("header of block #%d missing", number)
+	}
+	if body, _ = db.Get(blockBodyKey(number, hash)); len(body) == 0 {
+		return hash, nil, nil, nil, nil, fmt.Errorf("body of block #%d missing", number)
+	}
+	if receipts, _ = db.Get(blockReceiptsKey(number, hash)); len(receipts) == 0 {
+		return hash, nil, nil, nil, nil, fmt.Errorf("receipts of block #%d missing", number)
+	}
+	if td, _ = db.Get(headerTDKey(number, hash)); len(td) == 0 {
+		return hash, nil, nil, nil, nil, fmt.Errorf("total difficulty of block #%d missing", number)
+	}
+	return hash, header, body, receipts, td, nil
+}
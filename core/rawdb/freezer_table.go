@@ -0,0 +1,274 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/golang/snappy"
+)
+
+var (
+	// errOutOfBounds is returned if the item requested is not stored.
+	errOutOfBounds = errors.New("out of bounds")
+
+	// errOutOrderInsertion is returned if an item is appended out of sequence.
+	errOutOrderInsertion = errors.New("the append operation is out-order")
+
+	// errClosed is returned if the table is used after being closed.
+	errClosed = errors.New("closed")
+)
+
+// indexEntrySize is the size of an offset in the index file.
+const indexEntrySize = 8
+
+// freezerTable is an append-only table of items numbered from zero. The items
+// are stored back to back in a data file, and the index file holds the offset
+// in it of the end of each item, after a leading zero offset.
+//
+// The data of an item is written before its index entry, so a crash leaves at
+// most a dangling tail of data, cut when the table is reopened.
+type freezerTable struct {
+	items uint64 // Number of items stored, accessed atomically
+
+	name          string
+	noCompression bool     // Whether the items are stored without snappy compression
+	data          *os.File // Items back to back
+	index         *os.File // End offsets of the items in the data file
+	size          uint64   // Size of the data file
+	lock          sync.RWMutex
+}
+
+// newFreezerTable opens the table with the given name in dir, creating it if
+// missing and repairing it if it was left inconsistent.
+func newFreezerTable(dir, name string, noCompression bool) (*freezerTable, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	ext := ".cdat"
+	if noCompression {
+		ext = ".rdat"
+	}
+	data, err := os.OpenFile(filepath.Join(dir, name+ext), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	index, err := os.OpenFile(filepath.Join(dir, name+".ridx"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		data.Close()
+		return nil, err
+	}
+	t := &freezerTable{
+		name:          name,
+		noCompression: noCompression,
+		data:          data,
+		index:         index,
+	}
+	if err := t.repair(); err != nil {
+		t.Close()
+		return nil, fmt.Errorf("table %s: %v", name, err)
+	}
+	return t, nil
+}
+
+// repair cuts the partial index entries, the entries pointing past the end of
+// the data file and the data past the last entry.
+func (t *freezerTable) repair() error {
+	stat, err := t.index.Stat()
+	if err != nil {
+		return err
+	}
+	indexSize := stat.Size()
+	if indexSize < indexEntrySize {
+		// Fresh table, or one crashed before its leading offset got written
+		if _, err := t.index.WriteAt(make([]byte, indexEntrySize), 0); err != nil {
+			return err
+		}
+		indexSize = indexEntrySize
+	}
+	indexSize -= indexSize % indexEntrySize
+
+	if stat, err = t.data.Stat(); err != nil {
+		return err
+	}
+	dataSize := uint64(stat.Size())
+
+	last, err := t.offset(uint64(indexSize/indexEntrySize) - 1)
+	if err != nil {
+		return err
+	}
+	for last > dataSize && indexSize > indexEntrySize {
+		indexSize -= indexEntrySize
+		if last, err = t.offset(uint64(indexSize/indexEntrySize) - 1); err != nil {
+			return err
+		}
+	}
+	if last > dataSize {
+		return fmt.Errorf("first item past the end of the data: %d > %d", last, dataSize)
+	}
+	if err := t.index.Truncate(indexSize); err != nil {
+		return err
+	}
+	if err := t.data.Truncate(int64(last)); err != nil {
+		return err
+	}
+	t.items, t.size = uint64(indexSize/indexEntrySize)-1, last
+	return nil
+}
+
+// offset reads the index entry with the given position.
+func (t *freezerTable) offset(pos uint64) (uint64, error) {
+	entry := make([]byte, indexEntrySize)
+	if _, err := t.index.ReadAt(entry, int64(pos*indexEntrySize)); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(entry), nil
+}
+
+// Items returns the number of items stored.
+func (t *freezerTable) Items() uint64 {
+	return atomic.LoadUint64(&t.items)
+}
+
+// Append stores the item with the given number, which has to be the one after
+// the last item stored.
+func (t *freezerTable) Append(item uint64, blob []byte) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil {
+		return errClosed
+	}
+	if item != atomic.LoadUint64(&t.items) {
+		return errOutOrderInsertion
+	}
+	if !t.noCompression {
+		blob = snappy.Encode(nil, blob)
+	}
+	if _, err := t.data.WriteAt(blob, int64(t.size)); err != nil {
+		return err
+	}
+	entry := make([]byte, indexEntrySize)
+	binary.BigEndian.PutUint64(entry, t.size+uint64(len(blob)))
+	if _, err := t.index.WriteAt(entry, int64((item+1)*indexEntrySize)); err != nil {
+		return err
+	}
+	t.size += uint64(len(blob))
+	atomic.AddUint64(&t.items, 1)
+	return nil
+}
+
+// Retrieve returns the item with the given number.
+func (t *freezerTable) Retrieve(item uint64) ([]byte, error) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.index == nil {
+		return nil, errClosed
+	}
+	if item >= atomic.LoadUint64(&t.items) {
+		return nil, errOutOfBounds
+	}
+	bounds := make([]byte, 2*indexEntrySize)
+	if _, err := t.index.ReadAt(bounds, int64(item*indexEntrySize)); err != nil {
+		return nil, err
+	}
+	start, end := binary.BigEndian.Uint64(bounds), binary.BigEndian.Uint64(bounds[indexEntrySize:])
+	blob := make([]byte, end-start)
+	if _, err := t.data.ReadAt(blob, int64(start)); err != nil {
+		return nil, err
+	}
+	if t.noCompression {
+		return blob, nil
+	}
+	return snappy.Decode(nil, blob)
+}
+
+// truncate drops the items past the given number of them.
+func (t *freezerTable) truncate(items uint64) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil {
+		return errClosed
+	}
+	if atomic.LoadUint64(&t.items) <= items {
+		return nil
+	}
+	size, err := t.offset(items)
+	if err != nil {
+		return err
+	}
+	if err := t.index.Truncate(int64((items + 1) * indexEntrySize)); err != nil {
+		return err
+	}
+	if err := t.data.Truncate(int64(size)); err != nil {
+		return err
+	}
+	t.size = size
+	atomic.StoreUint64(&t.items, items)
+	return nil
+}
+
+// Size returns the size of the table on disk.
+func (t *freezerTable) Size() uint64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.size + (atomic.LoadUint64(&t.items)+1)*indexEntrySize
+}
+
+// Sync flushes the table to disk.
+func (t *freezerTable) Sync() error {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.index == nil {
+		return errClosed
+	}
+	if err := t.data.Sync(); err != nil {
+		return err
+	}
+	return t.index.Sync()
+}
+
+// Close closes the files of the table.
+func (t *freezerTable) Close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var errs []error
+	for _, f := range []*os.File{t.data, t.index} {
+		if f == nil {
+			continue
+		}
+		if err := f.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	t.data, t.index = nil, nil
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
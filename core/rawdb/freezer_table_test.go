@@ -0,0 +1,145 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testItem returns the blob of the test item with the given number.
+func testItem(i uint64) []byte {
+	return bytes.Repeat([]byte{byte(i)}, int(i%16)+1)
+}
+
+// Tests that items appended to a table are retrieved after reopening it, with
+// and without compression.
+func TestFreezerTableReopen(t *testing.T) {
+	for _, noCompression := range []bool{false, true} {
+		dir, err := ioutil.TempDir("", "vnt-freezer-test")
+		if err != nil {
+			t.Fatalf("failed to create temporary directory: %v", err)
+		}
+		defer os.RemoveAll(dir)
+
+		table, err := newFreezerTable(dir, "test", noCompression)
+		if err != nil {
+			t.Fatalf("failed to create table: %v", err)
+		}
+		for i := uint64(0); i < 100; i++ {
+			if err := table.Append(i, testItem(i)); err != nil {
+				t.Fatalf("failed to append item %d: %v", i, err)
+			}
+		}
+		if err := table.Append(101, testItem(101)); err != errOutOrderInsertion {
+			t.Errorf("out of order append error mismatch: have %v, want %v", err, errOutOrderInsertion)
+		}
+		table.Close()
+
+		if table, err = newFreezerTable(dir, "test", noCompression); err != nil {
+			t.Fatalf("failed to reopen table: %v", err)
+		}
+		if items := table.Items(); items != 100 {
+			t.Fatalf("items mismatch: have %d, want 100", items)
+		}
+		for i := uint64(0); i < 100; i++ {
+			if blob, err := table.Retrieve(i); err != nil || !bytes.Equal(blob, testItem(i)) {
+				t.Fatalf("item %d mismatch: have %x (%v), want %x", i, blob, err, testItem(i))
+			}
+		}
+		if _, err := table.Retrieve(100); err != errOutOfBounds {
+			t.Errorf("missing item error mismatch: have %v, want %v", err, errOutOfBounds)
+		}
+		table.Close()
+	}
+}
+
+// Tests that a table cut short by a crash is repaired to the items complete on
+// disk when reopened.
+func TestFreezerTableRepair(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vnt-freezer-test")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	table, _ := newFreezerTable(dir, "test", true)
+	for i := uint64(0); i < 10; i++ {
+		table.Append(i, testItem(i))
+	}
+	table.Close()
+
+	// Lose the end of the last item, and half of an index entry past it
+	data := filepath.Join(dir, "test.rdat")
+	stat, _ := os.Stat(data)
+	os.Truncate(data, stat.Size()-1)
+
+	index, _ := os.OpenFile(filepath.Join(dir, "test.ridx"), os.O_WRONLY|os.O_APPEND, 0644)
+	index.Write([]byte{0x00, 0x01, 0x02})
+	index.Close()
+
+	if table, err = newFreezerTable(dir, "test", true); err != nil {
+		t.Fatalf("failed to reopen table: %v", err)
+	}
+	defer table.Close()
+
+	if items := table.Items(); items != 9 {
+		t.Fatalf("items mismatch: have %d, want 9", items)
+	}
+	if err := table.Append(9, testItem(9)); err != nil {
+		t.Fatalf("failed to append over the repaired item: %v", err)
+	}
+	for i := uint64(0); i < 10; i++ {
+		if blob, err := table.Retrieve(i); err != nil || !bytes.Equal(blob, testItem(i)) {
+			t.Fatalf("item %d mismatch: have %x (%v), want %x", i, blob, err, testItem(i))
+		}
+	}
+}
+
+// Tests that truncating a table drops the items past the new end only.
+func TestFreezerTableTruncate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vnt-freezer-test")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	table, _ := newFreezerTable(dir, "test", false)
+	defer table.Close()
+
+	for i := uint64(0); i < 10; i++ {
+		table.Append(i, testItem(i))
+	}
+	if err := table.truncate(4); err != nil {
+		t.Fatalf("failed to truncate table: %v", err)
+	}
+	if _, err := table.Retrieve(4); err != errOutOfBounds {
+		t.Errorf("truncated item error mismatch: have %v, want %v", err, errOutOfBounds)
+	}
+	if err := table.Append(4, []byte{0xff}); err != nil {
+		t.Fatalf("failed to append after truncation: %v", err)
+	}
+	if blob, _ := table.Retrieve(3); !bytes.Equal(blob, testItem(3)) {
+		t.Errorf("kept item mismatch: have %x, want %x", blob, testItem(3))
+	}
+	if blob, _ := table.Retrieve(4); !bytes.Equal(blob, []byte{0xff}) {
+		t.Errorf("appended item mismatch: have %x, want ff", blob)
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/log"
+	"github.com/vntchain/go-vnt/vntdb"
+)
+
+var (
+	errMissingCanonicalHash = errors.New("canonical hash missing")
+	errMissingHeader        = errors.New("header missing")
+	errHeaderHashMismatch   = errors.New("header hash mismatch")
+	errHeaderNumberMismatch = errors.New("header number mismatch")
+	errMissingHeaderNumber  = errors.New("hash to number mapping missing")
+	errBrokenChain          = errors.New("parent hash mismatch")
+	errMissingTd            = errors.New("total difficulty missing")
+	errMissingBody          = errors.New("body missing")
+	errTxRootMismatch       = errors.New("transaction root mismatch")
+	errMissingReceipts      = errors.New("receipts missing")
+	errReceiptRootMismatch  = errors.New("receipt root mismatch")
+	errMissingTxLookup      = errors.New("transaction lookup missing")
+	errNoCompleteBlock      = errors.New("no complete block in the canonical chain")
+)
+
+// ChainFault is an inconsistency of a canonical block found by VerifyChain.
+type ChainFault struct {
+	Number uint64
+	Hash   common.Hash // Canonical hash of the block, empty if missing
+	Err    error
+}
+
+func (f ChainFault) String() string {
+	return fmt.Sprintf("block %d [%x…]: %v", f.Number, f.Hash[:4], f.Err)
+}
+
+// VerifyChain checks the canonical blocks from first to last: their canonical
+// hashes, headers, hash to number mappings and total difficulties have to be
+// stored, each header has to link to the previous one, their bodies and
+// receipts have to match the roots of their headers, and their transactions
+// have to be indexed. The headers linked to their parents are also checked by
+// verify, if not nil, e.g. for their commit certificates. Every fault found is
+// reported, and the verification stops once report returns false.
+func VerifyChain(db vntdb.Database, first, last uint64, verify func(header, parent *types.Header) error, report func(ChainFault) bool) error {
+	var (
+		start  = time.Now()
+		logged = time.Now()
+		parent *types.Header
+	)
+	if first > 0 {
+		if hash := ReadCanonicalHash(db, first-1); hash != (common.Hash{}) {
+			parent = ReadHeader(db, hash, first-1)
+		}
+	}
+	for number := first; number <= last; number++ {
+		header, faults := verifyBlock(db, number)
+		if header != nil && parent != nil {
+			if header.ParentHash != parent.Hash() {
+				faults = append(faults, errBrokenChain)
+			} else if verify != nil {
+				if err := verify(header, parent); err != nil {
+					faults = append(faults, err)
+				}
+			}
+		}
+		hash := ReadCanonicalHash(db, number)
+		for _, err := range faults {
+			if !report(ChainFault{Number: number, Hash: hash, Err: err}) {
+				return nil
+			}
+		}
+		parent = header
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Verifying chain", "number", number, "last", last, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+		if number == last {
+			break // Avoid overflowing if last is the largest number
+		}
+	}
+	return nil
+}
+
+// verifyBlock checks the data stored for the canonical block of the given
+// number, returning its header if found and the faults of the block.
+func verifyBlock(db vntdb.Database, number uint64) (*types.Header, []error) {
+	hash := ReadCanonicalHash(db, number)
+	if hash == (common.Hash{}) {
+		return nil, []error{errMissingCanonicalHash}
+	}
+	header := ReadHeader(db, hash, number)
+	if header == nil {
+		return nil, []error{errMissingHeader}
+	}
+	var faults []error
+	if header.Hash() != hash {
+		faults = append(faults, errHeaderHashMismatch)
+	}
+	if header.Number.Uint64() != number {
+		faults = append(faults, errHeaderNumberMismatch)
+	}
+	if n := ReadHeaderNumber(db, hash); n == nil || *n != number {
+		faults = append(faults, errMissingHeaderNumber)
+	}
+	if ReadTd(db, hash, number) == nil {
+		faults = append(faults, errMissingTd)
+	}
+	body := ReadBody(db, hash, number)
+	if body == nil {
+		return header, append(faults, errMissingBody)
+	}
+	txs := types.Transactions(body.Transactions)
+	if types.DeriveSha(txs) != header.TxHash {
+		faults = append(faults, errTxRootMismatch)
+	}
+	receipts := ReadReceipts(db, hash, number)
+	switch {
+	case receipts == nil && len(txs) > 0:
+		faults = append(faults, errMissingReceipts)
+	case receipts != nil && types.DeriveSha(receipts) != header.ReceiptHash:
+		faults = append(faults, errReceiptRootMismatch)
+	}
+	for i, tx := range txs {
+		if blockHash, _, index := ReadTxLookupEntry(db, tx.Hash()); blockHash != hash || index != uint64(i) {
+			faults = append(faults, errMissingTxLookup)
+			break
+		}
+	}
+	return header, faults
+}
+
+// RepairHead rewinds the heads of the chain to the last complete canonical
+// block, whose header, total difficulty, body and receipts are stored and whose
+// state is available according to hasState. The canonical hashes past it are
+// deleted, and so are the blocks past it in the ancient store, so that they are
+// synced again.
+func RepairHead(db vntdb.Database, hasState func(root common.Hash) bool) (*types.Header, error) {
+	// Find the last canonical block, even past the recorded heads
+	var top uint64
+	for _, head := range []common.Hash{ReadHeadHeaderHash(db), ReadHeadFastBlockHash(db), ReadHeadBlockHash(db)} {
+		if number := ReadHeaderNumber(db, head); number != nil && *number > top {
+			top = *number
+		}
+	}
+	ancients, _ := db.(AncientStore)
+	if ancients != nil {
+		if frozen, _ := ancients.Ancients(); frozen > top+1 {
+			top = frozen - 1
+		}
+	}
+	for ReadCanonicalHash(db, top+1) != (common.Hash{}) {
+		top++
+	}
+	// Walk back to the last complete one
+	var head *types.Header
+	for number := top; ; number-- {
+		if header, faults := verifyBlock(db, number); header != nil && len(faults) == 0 && hasState(header.Root) {
+			head = header
+			break
+		}
+		if number == 0 {
+			return nil, errNoCompleteBlock
+		}
+	}
+	number := head.Number.Uint64()
+	if ancients != nil {
+		if frozen, _ := ancients.Ancients(); frozen > number+1 {
+			if err := ancients.TruncateAncients(number + 1); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for n := number + 1; n <= top; n++ {
+		DeleteCanonicalHash(db, n)
+	}
+	WriteHeadHeaderHash(db, head.Hash())
+	WriteHeadFastBlockHash(db, head.Hash())
+	WriteHeadBlockHash(db, head.Hash())
+
+	return head, nil
+}
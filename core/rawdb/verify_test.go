@@ -0,0 +1,183 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/vntdb"
+)
+
+// writeFullChain writes a canonical chain of blocks with a transaction each but
+// the genesis, along with their receipts and transaction lookups.
+func writeFullChain(db vntdb.Database, n uint64) []*types.Block {
+	var (
+		blocks []*types.Block
+		parent common.Hash
+	)
+	for i := uint64(0); i < n; i++ {
+		header := &types.Header{Number: new(big.Int).SetUint64(i), ParentHash: parent, Root: common.Hash{byte(i + 1)}}
+		var (
+			txs      []*types.Transaction
+			receipts []*types.Receipt
+		)
+		if i > 0 {
+			txs = append(txs, types.NewTransaction(i, common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil))
+			receipts = append(receipts, types.NewReceipt(nil, false, 21000))
+		}
+		block := types.NewBlock(header, txs, receipts)
+		WriteBlock(db, block)
+		WriteReceipts(db, block.Hash(), i, receipts)
+		WriteTd(db, block.Hash(), i, new(big.Int).SetUint64(i+1))
+		WriteCanonicalHash(db, block.Hash(), i)
+		WriteTxLookupEntries(db, block)
+
+		blocks = append(blocks, block)
+		parent = block.Hash()
+	}
+	head := blocks[len(blocks)-1].Hash()
+	WriteHeadHeaderHash(db, head)
+	WriteHeadFastBlockHash(db, head)
+	WriteHeadBlockHash(db, head)
+	return blocks
+}
+
+// Tests that the faults of the canonical chain are all reported.
+func TestVerifyChain(t *testing.T) {
+	db := vntdb.NewMemDatabase()
+	blocks := writeFullChain(db, 10)
+
+	collect := func(verify func(header, parent *types.Header) error) map[uint64]error {
+		faults := make(map[uint64]error)
+		if err := VerifyChain(db, 0, 9, verify, func(fault ChainFault) bool {
+			faults[fault.Number] = fault.Err
+			return true
+		}); err != nil {
+			t.Fatalf("failed to verify chain: %v", err)
+		}
+		return faults
+	}
+	if faults := collect(nil); len(faults) != 0 {
+		t.Fatalf("faults found in consistent chain: %v", faults)
+	}
+	// Break the chain in various ways
+	DeleteBody(db, blocks[2].Hash(), 2)
+	DeleteReceipts(db, blocks[4].Hash(), 4)
+	DeleteTxLookupEntry(db, blocks[6].Transactions()[0].Hash())
+	DeleteCanonicalHash(db, 8)
+
+	errUncertified := errors.New("uncertified")
+	verify := func(header, parent *types.Header) error {
+		if header.Number.Uint64() == 5 {
+			return errUncertified
+		}
+		return nil
+	}
+	want := map[uint64]error{
+		2: errMissingBody,
+		4: errMissingReceipts,
+		5: errUncertified,
+		6: errMissingTxLookup,
+		8: errMissingCanonicalHash,
+	}
+	faults := collect(verify)
+	if len(faults) != len(want) {
+		t.Errorf("fault count mismatch: have %v, want %v", faults, want)
+	}
+	for number, err := range want {
+		if faults[number] != err {
+			t.Errorf("block %d: fault mismatch: have %v, want %v", number, faults[number], err)
+		}
+	}
+	// Block 9 doesn't link to a canonical parent anymore, but it's not checked
+	if _, ok := faults[9]; ok {
+		t.Errorf("block 9: unexpected fault %v", faults[9])
+	}
+	// Stop at the first fault
+	var reported int
+	VerifyChain(db, 0, 9, nil, func(ChainFault) bool {
+		reported++
+		return false
+	})
+	if reported != 1 {
+		t.Errorf("reported faults mismatch: have %d, want 1", reported)
+	}
+}
+
+// Tests that the heads are rewound to the last complete block with a state,
+// dropping the canonical blocks past it from both stores.
+func TestRepairHead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vnt-repair-test")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := NewDatabaseWithFreezer(vntdb.NewMemDatabase(), dir)
+	if err != nil {
+		t.Fatalf("failed to open ancient store: %v", err)
+	}
+	defer db.Close()
+
+	blocks := writeFullChain(db, 12)
+	if _, err := FreezeBlocks(db, 8, nil); err != nil {
+		t.Fatalf("failed to freeze blocks: %v", err)
+	}
+	// Block 11 misses its body, and the last states are lost
+	DeleteBody(db, blocks[11].Hash(), 11)
+	hasState := func(root common.Hash) bool {
+		return root[0] <= 5
+	}
+	head, err := RepairHead(db, hasState)
+	if err != nil {
+		t.Fatalf("failed to repair head: %v", err)
+	}
+	if head.Hash() != blocks[4].Hash() {
+		t.Fatalf("head mismatch: have %d, want 4", head.Number)
+	}
+	for _, hash := range []common.Hash{ReadHeadHeaderHash(db), ReadHeadFastBlockHash(db), ReadHeadBlockHash(db)} {
+		if hash != blocks[4].Hash() {
+			t.Errorf("head hash mismatch: have %x, want %x", hash, blocks[4].Hash())
+		}
+	}
+	if frozen, _ := db.(AncientReader).Ancients(); frozen != 5 {
+		t.Errorf("ancients mismatch: have %d, want 5", frozen)
+	}
+	for i := range blocks {
+		if hash := ReadCanonicalHash(db, uint64(i)); (hash == blocks[i].Hash()) != (i <= 4) {
+			t.Errorf("block %d: canonical hash mismatch: have %x", i, hash)
+		}
+	}
+	var faults []ChainFault
+	VerifyChain(db, 0, 4, nil, func(fault ChainFault) bool {
+		faults = append(faults, fault)
+		return true
+	})
+	if len(faults) != 0 {
+		t.Errorf("faults found in repaired chain: %v", faults)
+	}
+	// Nothing to rewind to without any state
+	if _, err := RepairHead(db, func(common.Hash) bool { return false }); err != errNoCompleteBlock {
+		t.Errorf("error mismatch: have %v, want %v", err, errNoCompleteBlock)
+	}
+}
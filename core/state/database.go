@@ -21,6 +21,7 @@ import (
 	"sync"
 
 	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/state/snapshot"
 	"github.com/vntchain/go-vnt/vntdb"
 	"github.com/vntchain/go-vnt/trie"
 	lru "github.com/hashicorp/golang-lru"
@@ -83,6 +84,26 @@ func NewDatabase(db vntdb.Database) Database {
 	}
 }
 
+// snapshotDatabase is implemented by state databases serving reads from a
+// snapshot tree.
+type snapshotDatabase interface {
+	Snapshots() *snapshot.Tree
+}
+
+// WithSnapshots wraps a state database, so that the states covered by the
+// snapshot tree are read from it instead of the tries.
+func WithSnapshots(db Database, snaps *snapshot.Tree) Database {
+	return &snapshotDB{Database: db, snaps: snaps}
+}
+
+type snapshotDB struct {
+	Database
+	snaps *snapshot.Tree
+}
+
+// Snapshots returns the snapshot tree of the database.
+func (db *snapshotDB) Snapshots() *snapshot.Tree { return db.snaps }
+
 type cachingDB struct {
 	db            *trie.Database
 	mu            sync.Mutex
@@ -0,0 +1,217 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package pruner deletes the stale state of a full node from its database.
+//
+// Pruning is a mark and sweep: every trie node and contract code reachable from
+// the state roots to keep is marked, then all the unmarked state entries are
+// deleted. The storage tries of the kept states are marked whole, so iterating
+// over the storage of a contract (as the election precompile does) keeps
+// working on them.
+//
+// The trie database may keep flushing nodes while pruning, the nodes reported
+// through Flushed are never swept.
+package pruner
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/state"
+	"github.com/vntchain/go-vnt/crypto"
+	"github.com/vntchain/go-vnt/log"
+	"github.com/vntchain/go-vnt/rlp"
+	"github.com/vntchain/go-vnt/trie"
+	"github.com/vntchain/go-vnt/vntdb"
+)
+
+var (
+	// ErrInterrupted is returned if pruning was aborted.
+	ErrInterrupted = errors.New("pruning interrupted")
+
+	errNotIterable = errors.New("database can't be iterated")
+
+	emptyCode = crypto.Keccak256Hash(nil)
+)
+
+// Database is a key-value store which can be iterated over.
+type Database interface {
+	vntdb.Database
+	NewIterator() iterator.Iterator
+}
+
+// Pruner marks the state to keep and sweeps the rest from the database.
+type Pruner struct {
+	db     Database
+	triedb *trie.Database // Database resolving the kept states, may hold unflushed nodes
+
+	marked map[common.Hash]struct{} // Trie nodes and code reachable from the kept roots
+
+	flushed map[common.Hash]struct{} // Trie nodes written to disk since the pruner was created
+	lock    sync.Mutex               // Orders the flushed nodes against their deletion
+}
+
+// New creates a pruner of the state entries in db, resolving the kept states
+// through triedb.
+func New(db vntdb.Database, triedb *trie.Database) (*Pruner, error) {
+	iterable, ok := db.(Database)
+	if !ok {
+		return nil, errNotIterable
+	}
+	return &Pruner{
+		db:      iterable,
+		triedb:  triedb,
+		marked:  make(map[common.Hash]struct{}),
+		flushed: make(map[common.Hash]struct{}),
+	}, nil
+}
+
+// Keep marks the whole state with the given root, including the storage tries
+// and the code of its contracts. If the state can't be resolved, none of it is
+// marked and the error is returned.
+func (p *Pruner) Keep(root common.Hash, interrupt <-chan struct{}) error {
+	var fresh []common.Hash
+	err := p.markTrie(root, &fresh, interrupt, func(leaf []byte) error {
+		var account state.Account
+		if err := rlp.DecodeBytes(leaf, &account); err != nil {
+			return err
+		}
+		if !bytes.Equal(account.CodeHash, emptyCode[:]) {
+			p.mark(common.BytesToHash(account.CodeHash), &fresh)
+		}
+		return p.markTrie(account.Root, &fresh, interrupt, nil)
+	})
+	if err != nil {
+		// Subtries below the marked nodes may be unmarked, roll back
+		for _, hash := range fresh {
+			delete(p.marked, hash)
+		}
+		return err
+	}
+	return nil
+}
+
+// markTrie marks all the nodes of a trie, calling onLeaf with the value of each
+// leaf reached. Subtries which are already marked are skipped.
+func (p *Pruner) markTrie(root common.Hash, fresh *[]common.Hash, interrupt <-chan struct{}, onLeaf func([]byte) error) error {
+	tr, err := trie.New(root, p.triedb)
+	if err != nil {
+		return err
+	}
+	it := tr.NodeIterator(nil)
+	for descend := true; it.Next(descend); {
+		select {
+		case <-interrupt:
+			return ErrInterrupted
+		default:
+		}
+		descend = true
+		if hash := it.Hash(); hash != (common.Hash{}) {
+			if _, ok := p.marked[hash]; ok {
+				descend = false
+				continue
+			}
+			p.mark(hash, fresh)
+		}
+		if it.Leaf() && onLeaf != nil {
+			if err := onLeaf(it.LeafBlob()); err != nil {
+				return err
+			}
+		}
+	}
+	return it.Error()
+}
+
+func (p *Pruner) mark(hash common.Hash, fresh *[]common.Hash) {
+	if _, ok := p.marked[hash]; !ok {
+		p.marked[hash] = struct{}{}
+		*fresh = append(*fresh, hash)
+	}
+}
+
+// Flushed records a trie node about to be written to disk, so it isn't swept
+// even if it isn't reachable from the kept roots. It is meant to be set as the
+// flush callback of the trie database and is safe for concurrent use.
+func (p *Pruner) Flushed(hash common.Hash) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.flushed[hash] = struct{}{}
+}
+
+// sweep deletes the state entry unless it was flushed meanwhile, returning
+// whether it was deleted.
+func (p *Pruner) sweep(hash common.Hash) (bool, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.flushed[hash]; ok {
+		return false, nil
+	}
+	return true, p.db.Delete(hash[:])
+}
+
+// Sweep deletes all the state entries of the database which weren't marked,
+// returning their number and size.
+func (p *Pruner) Sweep(interrupt <-chan struct{}) (int, common.StorageSize, error) {
+	var (
+		start   = time.Now()
+		logged  = time.Now()
+		deleted int
+		size    common.StorageSize
+	)
+	it := p.db.NewIterator()
+	defer it.Release()
+
+	for it.Next() {
+		// Trie nodes and contract code are the only entries keyed by bare hashes
+		key := it.Key()
+		if len(key) != common.HashLength {
+			continue
+		}
+		if _, ok := p.marked[common.BytesToHash(key)]; ok {
+			continue
+		}
+		select {
+		case <-interrupt:
+			return deleted, size, ErrInterrupted
+		default:
+		}
+		swept, err := p.sweep(common.BytesToHash(key))
+		if err != nil {
+			return deleted, size, err
+		}
+		if !swept {
+			continue
+		}
+		size += common.StorageSize(len(key) + len(it.Value()))
+		deleted++
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Pruning stale state", "deleted", deleted, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return deleted, size, err
+	}
+	log.Info("Pruned stale state", "kept", len(p.marked), "deleted", deleted, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return deleted, size, nil
+}
@@ -0,0 +1,221 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/state"
+	"github.com/vntchain/go-vnt/trie"
+	"github.com/vntchain/go-vnt/vntdb"
+)
+
+// newTestDatabase creates an iterable database in a temporary directory.
+func newTestDatabase(t *testing.T) (*vntdb.LDBDatabase, func()) {
+	dir, err := ioutil.TempDir("", "vnt-pruner-test")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	db, err := vntdb.NewLDBDatabase(dir, 0, 0)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// commitState writes a state with a contract and a few accounts to disk,
+// applying modify first.
+func commitState(t *testing.T, sdb state.Database, parent common.Hash, modify func(*state.StateDB)) common.Hash {
+	statedb, err := state.New(parent, sdb)
+	if err != nil {
+		t.Fatalf("failed to open state %x: %v", parent, err)
+	}
+	modify(statedb)
+	root, err := statedb.Commit(false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	if err := sdb.TrieDB().Commit(root, false); err != nil {
+		t.Fatalf("failed to flush state: %v", err)
+	}
+	return root
+}
+
+// Tests that pruning keeps the whole kept state, storage included, and deletes
+// the stale one.
+func TestPruneState(t *testing.T) {
+	db, release := newTestDatabase(t)
+	defer release()
+
+	var (
+		sdb      = state.NewDatabase(db)
+		contract = common.Address{0xc0}
+	)
+	stale := commitState(t, sdb, common.Hash{}, func(statedb *state.StateDB) {
+		statedb.SetCode(contract, []byte{0x01, 0x02, 0x03})
+		for i := byte(0); i < 64; i++ {
+			statedb.AddBalance(common.Address{i}, big.NewInt(int64(i)+1))
+			statedb.SetState(contract, common.Hash{i}, common.Hash{i + 1})
+		}
+	})
+	kept := commitState(t, sdb, stale, func(statedb *state.StateDB) {
+		for i := byte(0); i < 64; i += 2 {
+			statedb.AddBalance(common.Address{i}, big.NewInt(1))
+			statedb.SetState(contract, common.Hash{i}, common.Hash{i + 2})
+		}
+	})
+	p, err := New(db, trie.NewDatabase(db))
+	if err != nil {
+		t.Fatalf("failed to create pruner: %v", err)
+	}
+	if err := p.Keep(kept, nil); err != nil {
+		t.Fatalf("failed to mark state: %v", err)
+	}
+	deleted, _, err := p.Sweep(nil)
+	if err != nil {
+		t.Fatalf("failed to sweep state: %v", err)
+	}
+	if deleted == 0 {
+		t.Fatalf("no stale state deleted")
+	}
+	// The kept state is complete
+	statedb, err := state.New(kept, state.NewDatabase(db))
+	if err != nil {
+		t.Fatalf("failed to open kept state: %v", err)
+	}
+	for it := state.NewNodeIterator(statedb); it.Next(); {
+		if it.Error != nil {
+			t.Fatalf("kept state incomplete: %v", it.Error)
+		}
+	}
+	slots := 0
+	statedb.ForEachStorage(contract, func(key, value common.Hash) bool {
+		slots++
+		return true
+	})
+	if slots != 64 {
+		t.Errorf("storage slots mismatch: have %d, want 64", slots)
+	}
+	if code := statedb.GetCode(contract); len(code) != 3 {
+		t.Errorf("contract code mismatch: have %x", code)
+	}
+	// The stale state is gone
+	if _, err := state.New(stale, state.NewDatabase(db)); err == nil {
+		t.Errorf("stale state not pruned")
+	}
+}
+
+// Tests that a state which can't be resolved leaves no marks behind, so the
+// subtries it shares with the kept states aren't skipped.
+func TestKeepMissingState(t *testing.T) {
+	db, release := newTestDatabase(t)
+	defer release()
+
+	sdb := state.NewDatabase(db)
+	root := commitState(t, sdb, common.Hash{}, func(statedb *state.StateDB) {
+		for i := byte(0); i < 64; i++ {
+			statedb.SetState(common.Address{0xc0}, common.Hash{i}, common.Hash{i + 1})
+		}
+	})
+	// Drop the last storage node reached from the disk
+	statedb, _ := state.New(root, sdb)
+	var last common.Hash
+	for it := state.NewNodeIterator(statedb); it.Next(); {
+		if it.Hash != (common.Hash{}) && it.Hash != root {
+			last = it.Hash
+		}
+	}
+	db.Delete(last[:])
+
+	p, err := New(db, trie.NewDatabase(db))
+	if err != nil {
+		t.Fatalf("failed to create pruner: %v", err)
+	}
+	if err := p.Keep(root, nil); err == nil {
+		t.Fatalf("incomplete state marked")
+	}
+	if len(p.marked) != 0 {
+		t.Fatalf("marks left behind: have %d, want 0", len(p.marked))
+	}
+}
+
+// Tests that the nodes flushed by the trie database while pruning are kept, even
+// if they aren't reachable from the kept roots.
+func TestPruneFlushedState(t *testing.T) {
+	db, release := newTestDatabase(t)
+	defer release()
+
+	sdb := state.NewDatabase(db)
+	stale := commitState(t, sdb, common.Hash{}, func(statedb *state.StateDB) {
+		for i := byte(0); i < 64; i++ {
+			statedb.AddBalance(common.Address{i}, big.NewInt(int64(i)+1))
+		}
+	})
+	p, err := New(db, sdb.TrieDB())
+	if err != nil {
+		t.Fatalf("failed to create pruner: %v", err)
+	}
+	sdb.TrieDB().SetFlushCallback(p.Flushed)
+
+	// States written after the marking, flushed by size and committed
+	fresh := func(salt byte) common.Hash {
+		statedb, _ := state.New(common.Hash{}, sdb)
+		for i := byte(0); i < 64; i++ {
+			statedb.AddBalance(common.Address{salt, i}, big.NewInt(int64(i)+1))
+			statedb.SetState(common.Address{salt}, common.Hash{i}, common.Hash{i + 1})
+		}
+		root, err := statedb.Commit(false)
+		if err != nil {
+			t.Fatalf("failed to commit state: %v", err)
+		}
+		return root
+	}
+	capped := fresh(0xa0)
+	if err := sdb.TrieDB().Cap(0); err != nil {
+		t.Fatalf("failed to flush state: %v", err)
+	}
+	committed := fresh(0xb0)
+	if err := sdb.TrieDB().Commit(committed, false); err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	sdb.TrieDB().SetFlushCallback(nil)
+
+	if _, _, err := p.Sweep(nil); err != nil {
+		t.Fatalf("failed to sweep state: %v", err)
+	}
+	for _, root := range []common.Hash{capped, committed} {
+		statedb, err := state.New(root, state.NewDatabase(db))
+		if err != nil {
+			t.Fatalf("failed to open flushed state %x: %v", root, err)
+		}
+		for it := state.NewNodeIterator(statedb); it.Next(); {
+			if it.Error != nil {
+				t.Fatalf("flushed state %x incomplete: %v", root, it.Error)
+			}
+		}
+	}
+	if _, err := state.New(stale, state.NewDatabase(db)); err == nil {
+		t.Errorf("stale state not pruned")
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"sync"
+
+	"github.com/vntchain/go-vnt/common"
+)
+
+// diffLayer is the snapshot of a state held in memory, as the changes made to
+// the state of its parent. Its content is never modified once created.
+type diffLayer struct {
+	root common.Hash
+
+	destructs map[common.Hash]struct{}               // Accounts whose storage was wiped
+	accounts  map[common.Hash][]byte                 // Accounts changed, nil if deleted
+	storage   map[common.Hash]map[common.Hash][]byte // Storage slots changed, nil if deleted
+
+	parent snapshot // Layer below, swapped for the disk layer it got flattened into
+	stale  bool     // Whether the layer was flattened or dropped
+	lock   sync.RWMutex
+}
+
+// newDiffLayer creates a diff layer on top of its parent.
+func newDiffLayer(parent snapshot, root common.Hash, destructs map[common.Hash]struct{}, accounts map[common.Hash][]byte, storage map[common.Hash]map[common.Hash][]byte) *diffLayer {
+	return &diffLayer{
+		root:      root,
+		destructs: destructs,
+		accounts:  accounts,
+		storage:   storage,
+		parent:    parent,
+	}
+}
+
+// Root returns the root of the state.
+func (dl *diffLayer) Root() common.Hash { return dl.root }
+
+// Parent returns the layer below.
+func (dl *diffLayer) Parent() snapshot {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	return dl.parent
+}
+
+// Stale returns whether the layer was flattened or dropped.
+func (dl *diffLayer) Stale() bool {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	return dl.stale
+}
+
+func (dl *diffLayer) setParent(parent snapshot) {
+	dl.lock.Lock()
+	defer dl.lock.Unlock()
+
+	dl.parent = parent
+}
+
+func (dl *diffLayer) markStale() {
+	dl.lock.Lock()
+	defer dl.lock.Unlock()
+
+	dl.stale = true
+}
+
+// live returns the parent of the layer, or an error if the layer is stale.
+func (dl *diffLayer) live() (snapshot, error) {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	if dl.stale {
+		return nil, ErrSnapshotStale
+	}
+	return dl.parent, nil
+}
+
+// Account returns the account with the given hash as encoded in the account
+// trie, nil if there's none.
+func (dl *diffLayer) Account(hash common.Hash) ([]byte, error) {
+	parent, err := dl.live()
+	if err != nil {
+		return nil, err
+	}
+	if data, ok := dl.accounts[hash]; ok {
+		return data, nil
+	}
+	if _, ok := dl.destructs[hash]; ok {
+		return nil, nil
+	}
+	return parent.Account(hash)
+}
+
+// Storage returns the storage slot with the given hash of an account as encoded
+// in its storage trie, nil if there's none.
+func (dl *diffLayer) Storage(accountHash, storageHash common.Hash) ([]byte, error) {
+	parent, err := dl.live()
+	if err != nil {
+		return nil, err
+	}
+	if data, ok := dl.storage[accountHash][storageHash]; ok {
+		return data, nil
+	}
+	if _, ok := dl.destructs[accountHash]; ok {
+		return nil, nil
+	}
+	return parent.Storage(accountHash, storageHash)
+}
+
+// Slots returns all the storage slots of an account, in the order of its
+// storage trie.
+func (dl *diffLayer) Slots(accountHash common.Hash) ([]Slot, error) {
+	slots, err := dl.slots(accountHash)
+	if err != nil {
+		return nil, err
+	}
+	return sortSlots(slots), nil
+}
+
+func (dl *diffLayer) slots(accountHash common.Hash) (map[common.Hash][]byte, error) {
+	parent, err := dl.live()
+	if err != nil {
+		return nil, err
+	}
+	var slots map[common.Hash][]byte
+	if _, ok := dl.destructs[accountHash]; ok {
+		slots = make(map[common.Hash][]byte)
+	} else if slots, err = parent.slots(accountHash); err != nil {
+		return nil, err
+	}
+	for hash, data := range dl.storage[accountHash] {
+		if data == nil {
+			delete(slots, hash)
+		} else {
+			slots[hash] = data
+		}
+	}
+	return slots, nil
+}
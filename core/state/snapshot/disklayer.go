@@ -0,0 +1,239 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/rawdb"
+	"github.com/vntchain/go-vnt/log"
+	"github.com/vntchain/go-vnt/trie"
+	"github.com/vntchain/go-vnt/vntdb"
+)
+
+// diskLayer is the snapshot of a state kept on disk. While being generated,
+// only the accounts up to the generation marker are served.
+type diskLayer struct {
+	diskdb vntdb.Database // Database holding the snapshot entries
+	triedb *trie.Database // Database holding the tries to generate from
+	root   common.Hash
+
+	genMarker []byte             // Hash of the last account generated, nil if done
+	genAbort  chan chan struct{} // Channel to stop the generator, nil if not running
+	stale     bool               // Whether the layer was flattened into another one
+	lock      sync.RWMutex
+}
+
+// Root returns the root of the state.
+func (dl *diskLayer) Root() common.Hash { return dl.root }
+
+// Parent returns nil, there's no layer below the disk.
+func (dl *diskLayer) Parent() snapshot { return nil }
+
+// Stale returns whether the layer was flattened into another one.
+func (dl *diskLayer) Stale() bool {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	return dl.stale
+}
+
+func (dl *diskLayer) markStale() {
+	dl.lock.Lock()
+	defer dl.lock.Unlock()
+
+	dl.stale = true
+}
+
+// covered returns whether the snapshot entries of the account are generated,
+// or an error if the layer is stale. It expects the read lock to be held.
+func (dl *diskLayer) covered(accountHash common.Hash) error {
+	if dl.stale {
+		return ErrSnapshotStale
+	}
+	if dl.genMarker != nil && bytes.Compare(accountHash[:], dl.genMarker) > 0 {
+		return ErrNotCoveredYet
+	}
+	return nil
+}
+
+// Account returns the account with the given hash as encoded in the account
+// trie, nil if there's none.
+func (dl *diskLayer) Account(hash common.Hash) ([]byte, error) {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	if err := dl.covered(hash); err != nil {
+		return nil, err
+	}
+	return rawdb.ReadAccountSnapshot(dl.diskdb, hash), nil
+}
+
+// Storage returns the storage slot with the given hash of an account as encoded
+// in its storage trie, nil if there's none.
+func (dl *diskLayer) Storage(accountHash, storageHash common.Hash) ([]byte, error) {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	if err := dl.covered(accountHash); err != nil {
+		return nil, err
+	}
+	return rawdb.ReadStorageSnapshot(dl.diskdb, accountHash, storageHash), nil
+}
+
+// Slots returns all the storage slots of an account, in the order of its
+// storage trie.
+func (dl *diskLayer) Slots(accountHash common.Hash) ([]Slot, error) {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	if err := dl.covered(accountHash); err != nil {
+		return nil, err
+	}
+	var slots []Slot
+	it := dl.diskdb.(iteratee).NewIteratorWithPrefix(storagePrefix(accountHash))
+	defer it.Release()
+
+	for it.Next() {
+		slots = append(slots, Slot{
+			Hash:  common.BytesToHash(it.Key()[len(rawdb.SnapshotStoragePrefix)+common.HashLength:]),
+			Value: common.CopyBytes(it.Value()),
+		})
+	}
+	return slots, it.Error()
+}
+
+func (dl *diskLayer) slots(accountHash common.Hash) (map[common.Hash][]byte, error) {
+	sorted, err := dl.Slots(accountHash)
+	if err != nil {
+		return nil, err
+	}
+	slots := make(map[common.Hash][]byte, len(sorted))
+	for _, slot := range sorted {
+		slots[slot.Hash] = slot.Value
+	}
+	return slots, nil
+}
+
+// storagePrefix returns the key prefix of the storage slots of an account.
+func storagePrefix(accountHash common.Hash) []byte {
+	return append(common.CopyBytes(rawdb.SnapshotStoragePrefix), accountHash.Bytes()...)
+}
+
+// stopGeneration stops the generator of the layer, if running.
+func (dl *diskLayer) stopGeneration() {
+	if dl.genAbort == nil {
+		return
+	}
+	stop := make(chan struct{})
+	dl.genAbort <- stop
+	<-stop
+	dl.genAbort = nil
+}
+
+// diffToDisk flattens a diff layer into the disk layer below it, returning the
+// new disk layer. The changes of the accounts not generated yet are skipped,
+// the generation resumes from the new state.
+func diffToDisk(bottom *diffLayer) *diskLayer {
+	base := bottom.Parent().(*diskLayer)
+	base.stopGeneration()
+
+	// Readers of the old layer must not see the new state
+	base.lock.Lock()
+	defer base.lock.Unlock()
+
+	base.stale = true
+	marker := base.genMarker
+	covered := func(hash common.Hash) bool {
+		return marker == nil || bytes.Compare(hash[:], marker) <= 0
+	}
+	// The changes may be written in several batches, the snapshot on disk is
+	// invalid until the last one is written along with the new root
+	batch := base.diskdb.NewBatch()
+	rawdb.DeleteSnapshotRoot(batch)
+	flush := func() {
+		if batch.ValueSize() > vntdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to write snapshot", "err", err)
+			}
+			batch.Reset()
+		}
+	}
+	for hash := range bottom.destructs {
+		if !covered(hash) {
+			continue
+		}
+		rawdb.DeleteAccountSnapshot(batch, hash)
+		wipeStorage(base.diskdb, batch, hash)
+		flush()
+	}
+	for hash, data := range bottom.accounts {
+		if !covered(hash) {
+			continue
+		}
+		if data == nil {
+			rawdb.DeleteAccountSnapshot(batch, hash)
+		} else {
+			rawdb.WriteAccountSnapshot(batch, hash, data)
+		}
+		flush()
+	}
+	for accountHash, slots := range bottom.storage {
+		if !covered(accountHash) {
+			continue
+		}
+		for storageHash, data := range slots {
+			if data == nil {
+				rawdb.DeleteStorageSnapshot(batch, accountHash, storageHash)
+			} else {
+				rawdb.WriteStorageSnapshot(batch, accountHash, storageHash, data)
+			}
+		}
+		flush()
+	}
+	if marker == nil {
+		rawdb.WriteSnapshotRoot(batch, bottom.root)
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write snapshot", "err", err)
+	}
+	bottom.markStale()
+
+	res := &diskLayer{
+		diskdb:    base.diskdb,
+		triedb:    base.triedb,
+		root:      bottom.root,
+		genMarker: marker,
+	}
+	if marker != nil {
+		res.genAbort = make(chan chan struct{})
+		go res.generate(marker)
+	}
+	return res
+}
+
+// wipeStorage deletes all the storage slots of an account on disk.
+func wipeStorage(diskdb vntdb.Database, batch vntdb.Batch, accountHash common.Hash) {
+	it := diskdb.(iteratee).NewIteratorWithPrefix(storagePrefix(accountHash))
+	defer it.Release()
+
+	for it.Next() {
+		batch.Delete(common.CopyBytes(it.Key()))
+	}
+}
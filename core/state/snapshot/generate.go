@@ -0,0 +1,184 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"math/big"
+	"time"
+
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/rawdb"
+	"github.com/vntchain/go-vnt/crypto"
+	"github.com/vntchain/go-vnt/log"
+	"github.com/vntchain/go-vnt/rlp"
+	"github.com/vntchain/go-vnt/trie"
+	"github.com/vntchain/go-vnt/vntdb"
+)
+
+// emptyRoot is the known root hash of an empty trie.
+var emptyRoot = crypto.Keccak256Hash([]byte{0x80})
+
+// account is the consensus representation of accounts, as encoded in the
+// account trie.
+type account struct {
+	Nonce    uint64
+	Balance  *big.Int
+	Root     common.Hash
+	CodeHash []byte
+}
+
+// generateSnapshot wipes the snapshot on disk and starts generating the one of
+// the state with the given root in the background.
+func generateSnapshot(diskdb vntdb.Database, triedb *trie.Database, root common.Hash) *diskLayer {
+	start := time.Now()
+	batch := diskdb.NewBatch()
+	rawdb.DeleteSnapshotRoot(batch)
+	// Trie nodes share the key space, only the keys of snapshot length are ours
+	wipes := []struct {
+		prefix []byte
+		keylen int
+	}{
+		{rawdb.SnapshotAccountPrefix, len(rawdb.SnapshotAccountPrefix) + common.HashLength},
+		{rawdb.SnapshotStoragePrefix, len(rawdb.SnapshotStoragePrefix) + 2*common.HashLength},
+	}
+	for _, wipe := range wipes {
+		it := diskdb.(iteratee).NewIteratorWithPrefix(wipe.prefix)
+		for it.Next() {
+			if len(it.Key()) != wipe.keylen {
+				continue
+			}
+			batch.Delete(common.CopyBytes(it.Key()))
+			if batch.ValueSize() > vntdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					log.Crit("Failed to wipe snapshot", "err", err)
+				}
+				batch.Reset()
+			}
+		}
+		it.Release()
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to wipe snapshot", "err", err)
+	}
+	log.Info("Generating state snapshot", "root", root, "wiped", common.PrettyDuration(time.Since(start)))
+
+	dl := &diskLayer{
+		diskdb:    diskdb,
+		triedb:    triedb,
+		root:      root,
+		genMarker: []byte{},
+		genAbort:  make(chan chan struct{}),
+	}
+	go dl.generate(dl.genMarker)
+	return dl
+}
+
+// generate writes the snapshot entries of the accounts after the marker, and
+// of their storage, from the tries.
+func (dl *diskLayer) generate(marker []byte) {
+	abort := dl.genAbort
+	stop, err := dl.generateFrom(marker, abort)
+	if stop != nil {
+		// Aborted, the generation resumes from the next disk layer
+		close(stop)
+		return
+	}
+	if err != nil {
+		// The state went missing, the generation resumes from the next disk layer
+		log.Warn("State snapshot generation failed", "root", dl.root, "err", err)
+	}
+	close(<-abort)
+}
+
+// generateFrom generates the snapshot until done or aborted, returning the
+// abort request if any. The marker is only moved once all the entries of an
+// account are on disk, so the changes flattened into the layer are never
+// overwritten by stale ones.
+func (dl *diskLayer) generateFrom(marker []byte, abort chan chan struct{}) (chan struct{}, error) {
+	var (
+		batch    = dl.diskdb.NewBatch()
+		start    = time.Now()
+		logged   = time.Now()
+		accounts int
+		slots    int
+	)
+	// flush writes out the batch and moves the marker to the given account
+	flush := func(hash []byte) {
+		if err := batch.Write(); err != nil {
+			log.Crit("Failed to write snapshot", "err", err)
+		}
+		batch.Reset()
+
+		dl.lock.Lock()
+		dl.genMarker = common.CopyBytes(hash)
+		dl.lock.Unlock()
+	}
+	accTrie, err := trie.New(dl.root, dl.triedb)
+	if err != nil {
+		return nil, err
+	}
+	it := trie.NewIterator(accTrie.NodeIterator(marker))
+	for it.Next() {
+		if bytes.Equal(it.Key, marker) {
+			continue
+		}
+		var acc account
+		if err := rlp.DecodeBytes(it.Value, &acc); err != nil {
+			return nil, err
+		}
+		accountHash := common.BytesToHash(it.Key)
+		rawdb.WriteAccountSnapshot(batch, accountHash, it.Value)
+		accounts++
+
+		if acc.Root != emptyRoot {
+			storageTrie, err := trie.New(acc.Root, dl.triedb)
+			if err != nil {
+				return nil, err
+			}
+			sit := trie.NewIterator(storageTrie.NodeIterator(nil))
+			for sit.Next() {
+				rawdb.WriteStorageSnapshot(batch, accountHash, common.BytesToHash(sit.Key), sit.Value)
+				slots++
+			}
+			if sit.Err != nil {
+				return nil, sit.Err
+			}
+		}
+		if batch.ValueSize() > vntdb.IdealBatchSize {
+			flush(it.Key)
+		}
+		select {
+		case stop := <-abort:
+			flush(it.Key)
+			return stop, nil
+		default:
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Generating state snapshot", "root", dl.root, "at", accountHash, "accounts", accounts, "slots", slots, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if it.Err != nil {
+		return nil, it.Err
+	}
+	rawdb.WriteSnapshotRoot(batch, dl.root)
+	flush(nil)
+
+	log.Info("Generated state snapshot", "root", dl.root, "accounts", accounts, "slots", slots, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil, nil
+}
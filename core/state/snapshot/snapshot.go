@@ -0,0 +1,250 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package snapshot implements a flat view of the state, serving account and
+// storage reads without walking the tries.
+//
+// The snapshot of the state of a recent block is kept on disk, keyed by the
+// hashes of the accounts and storage slots as in the tries. The states of the
+// blocks on top of it are kept in memory as diff layers, each holding the
+// changes made by its block. Once too many diff layers pile up, the bottom one
+// is flattened into the disk layer.
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/rawdb"
+	"github.com/vntchain/go-vnt/log"
+	"github.com/vntchain/go-vnt/trie"
+	"github.com/vntchain/go-vnt/vntdb"
+)
+
+var (
+	// ErrSnapshotStale is returned from data accessors if the layer was flattened
+	// or dropped since it was retrieved.
+	ErrSnapshotStale = errors.New("snapshot stale")
+
+	// ErrNotCoveredYet is returned from data accessors if the requested entry
+	// wasn't generated yet.
+	ErrNotCoveredYet = errors.New("not covered yet")
+
+	errNotIterable = errors.New("database can't be iterated")
+)
+
+// Snapshot is the flat view of a state.
+type Snapshot interface {
+	// Root returns the root of the state.
+	Root() common.Hash
+
+	// Account returns the account with the given hash as encoded in the account
+	// trie, nil if there's none.
+	Account(hash common.Hash) ([]byte, error)
+
+	// Storage returns the storage slot with the given hash of an account as
+	// encoded in its storage trie, nil if there's none.
+	Storage(accountHash, storageHash common.Hash) ([]byte, error)
+
+	// Slots returns all the storage slots of an account, in the order of its
+	// storage trie.
+	Slots(accountHash common.Hash) ([]Slot, error)
+}
+
+// Slot is a storage slot as encoded in the storage trie.
+type Slot struct {
+	Hash  common.Hash
+	Value []byte
+}
+
+// snapshot is a layer of the snapshot tree.
+type snapshot interface {
+	Snapshot
+
+	// Parent returns the layer below, nil for the disk layer.
+	Parent() snapshot
+
+	// Stale returns whether the layer was flattened or dropped.
+	Stale() bool
+
+	// slots returns the storage slots of an account, keyed by their hashes.
+	slots(accountHash common.Hash) (map[common.Hash][]byte, error)
+}
+
+// iteratee is a key-value store which can be iterated over.
+type iteratee interface {
+	NewIteratorWithPrefix(prefix []byte) iterator.Iterator
+}
+
+// Tree is the collection of the snapshot layers of the recent states, a single
+// disk layer with the diff layers on top of it.
+type Tree struct {
+	diskdb vntdb.Database
+	triedb *trie.Database
+	layers map[common.Hash]snapshot
+	lock   sync.RWMutex
+}
+
+// New loads the snapshot on disk if it is of the state with the given root, or
+// generates it in the background.
+func New(diskdb vntdb.Database, triedb *trie.Database, root common.Hash) (*Tree, error) {
+	if _, ok := diskdb.(iteratee); !ok {
+		return nil, errNotIterable
+	}
+	t := &Tree{
+		diskdb: diskdb,
+		triedb: triedb,
+		layers: make(map[common.Hash]snapshot),
+	}
+	if rawdb.ReadSnapshotRoot(diskdb) == root {
+		log.Info("Loaded state snapshot", "root", root)
+		t.layers[root] = &diskLayer{diskdb: diskdb, triedb: triedb, root: root}
+	} else {
+		t.layers[root] = generateSnapshot(diskdb, triedb, root)
+	}
+	return t, nil
+}
+
+// Snapshot returns the snapshot of the state with the given root, nil if none.
+func (t *Tree) Snapshot(root common.Hash) Snapshot {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if snap, ok := t.layers[root]; ok {
+		return snap
+	}
+	return nil
+}
+
+// Update adds the snapshot of a state as a diff layer on top of the one of its
+// parent. The destructed accounts have their storage wiped before the changes
+// are applied.
+func (t *Tree) Update(root, parentRoot common.Hash, destructs map[common.Hash]struct{}, accounts map[common.Hash][]byte, storage map[common.Hash]map[common.Hash][]byte) error {
+	if root == parentRoot {
+		return nil
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if _, ok := t.layers[root]; ok {
+		return nil
+	}
+	parent, ok := t.layers[parentRoot]
+	if !ok {
+		return fmt.Errorf("parent [%#x] snapshot missing", parentRoot)
+	}
+	t.layers[root] = newDiffLayer(parent, root, destructs, accounts, storage)
+	return nil
+}
+
+// Cap flattens the diff layers below the state with the given root into the
+// disk layer, until at most the given number of them remains. The layers which
+// don't descend from the new disk layer are dropped.
+func (t *Tree) Cap(root common.Hash, layers int) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	snap, ok := t.layers[root]
+	if !ok {
+		return fmt.Errorf("snapshot [%#x] missing", root)
+	}
+	var diffs []*diffLayer
+	for layer := snap; layer.Parent() != nil; layer = layer.Parent() {
+		diffs = append(diffs, layer.(*diffLayer))
+	}
+	if len(diffs) <= layers {
+		return nil
+	}
+	var base *diskLayer
+	for len(diffs) > layers {
+		base = diffToDisk(diffs[len(diffs)-1])
+		diffs = diffs[:len(diffs)-1]
+	}
+	if len(diffs) > 0 {
+		diffs[len(diffs)-1].setParent(base)
+	}
+	// Drop everything not built on top of the new disk layer
+	for hash, layer := range t.layers {
+		if !descends(layer, base) {
+			if diff, ok := layer.(*diffLayer); ok {
+				diff.markStale()
+			}
+			delete(t.layers, hash)
+		}
+	}
+	t.layers[base.root] = base
+	return nil
+}
+
+// descends returns whether the layer is built on top of the disk layer.
+func descends(layer snapshot, base *diskLayer) bool {
+	for ; layer != nil; layer = layer.Parent() {
+		if layer == snapshot(base) {
+			return true
+		}
+		if layer.Stale() {
+			return false
+		}
+	}
+	return false
+}
+
+// Rebuild drops all the layers and generates the snapshot of the state with
+// the given root in the background.
+func (t *Tree) Rebuild(root common.Hash) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, layer := range t.layers {
+		switch layer := layer.(type) {
+		case *diffLayer:
+			layer.markStale()
+		case *diskLayer:
+			layer.stopGeneration()
+			layer.markStale()
+		}
+	}
+	t.layers = map[common.Hash]snapshot{root: generateSnapshot(t.diskdb, t.triedb, root)}
+}
+
+// Close stops the generation of the snapshot, if running.
+func (t *Tree) Close() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, layer := range t.layers {
+		if disk, ok := layer.(*diskLayer); ok {
+			disk.stopGeneration()
+		}
+	}
+}
+
+// sortSlots orders storage slots as in the storage trie.
+func sortSlots(slots map[common.Hash][]byte) []Slot {
+	sorted := make([]Slot, 0, len(slots))
+	for hash, value := range slots {
+		sorted = append(sorted, Slot{Hash: hash, Value: value})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].Hash[:], sorted[j].Hash[:]) < 0
+	})
+	return sorted
+}
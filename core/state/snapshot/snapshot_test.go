@@ -0,0 +1,396 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/rawdb"
+	"github.com/vntchain/go-vnt/crypto"
+	"github.com/vntchain/go-vnt/rlp"
+	"github.com/vntchain/go-vnt/trie"
+	"github.com/vntchain/go-vnt/vntdb"
+)
+
+// testAccount is the content of an account in a test state.
+type testAccount struct {
+	balance int64
+	storage map[common.Hash][]byte
+}
+
+// encode returns the account as encoded in the account trie, with the given
+// storage root.
+func (acc *testAccount) encode(root common.Hash) []byte {
+	blob, _ := rlp.EncodeToBytes(&account{
+		Balance:  big.NewInt(acc.balance),
+		Root:     root,
+		CodeHash: crypto.Keccak256(nil),
+	})
+	return blob
+}
+
+// makeState writes the tries of a state to disk, returning its root.
+func makeState(t *testing.T, triedb *trie.Database, accounts map[common.Hash]*testAccount) common.Hash {
+	accTrie, _ := trie.New(common.Hash{}, triedb)
+	for hash, acc := range accounts {
+		storageTrie, _ := trie.New(common.Hash{}, triedb)
+		for slot, value := range acc.storage {
+			storageTrie.Update(slot[:], value)
+		}
+		storageRoot, err := storageTrie.Commit(nil)
+		if err != nil {
+			t.Fatalf("failed to commit storage trie: %v", err)
+		}
+		accTrie.Update(hash[:], acc.encode(storageRoot))
+	}
+	root, err := accTrie.Commit(func(leaf []byte, parent common.Hash) error {
+		var acc account
+		if err := rlp.DecodeBytes(leaf, &acc); err != nil {
+			return err
+		}
+		triedb.Reference(acc.Root, parent)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to commit account trie: %v", err)
+	}
+	if err := triedb.Commit(root, false); err != nil {
+		t.Fatalf("failed to flush state: %v", err)
+	}
+	return root
+}
+
+// waitGeneration blocks until the disk layer of the tree is generated.
+func waitGeneration(t *testing.T, tree *Tree) *diskLayer {
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		tree.lock.RLock()
+		for _, layer := range tree.layers {
+			if disk, ok := layer.(*diskLayer); ok {
+				disk.lock.RLock()
+				done := disk.genMarker == nil
+				disk.lock.RUnlock()
+				if done {
+					tree.lock.RUnlock()
+					return disk
+				}
+			}
+		}
+		tree.lock.RUnlock()
+	}
+	t.Fatalf("snapshot generation timed out")
+	return nil
+}
+
+var (
+	accountA = common.Hash{0x0a}
+	accountB = common.Hash{0x0b}
+	accountC = common.Hash{0x0c}
+)
+
+// testStates returns a state and the one of its child block, where accountA
+// has a slot changed and one deleted and accountB is destructed.
+func testStates() (map[common.Hash]*testAccount, map[common.Hash]*testAccount) {
+	parent := map[common.Hash]*testAccount{
+		accountA: {balance: 1, storage: make(map[common.Hash][]byte)},
+		accountB: {balance: 2, storage: map[common.Hash][]byte{{0x01}: {0x01}}},
+		accountC: {balance: 3},
+	}
+	child := map[common.Hash]*testAccount{
+		accountA: {balance: 4, storage: make(map[common.Hash][]byte)},
+		accountC: {balance: 3},
+	}
+	for i := byte(1); i <= 32; i++ {
+		parent[accountA].storage[common.Hash{i}] = []byte{i}
+		if i != 2 {
+			child[accountA].storage[common.Hash{i}] = []byte{i}
+		}
+	}
+	child[accountA].storage[common.Hash{1}] = []byte{0xff}
+	return parent, child
+}
+
+// update adds the child state of testStates to the tree.
+func update(t *testing.T, tree *Tree, triedb *trie.Database, root, parent common.Hash, child map[common.Hash]*testAccount) {
+	storageTrie, _ := trie.New(common.Hash{}, triedb)
+	for slot, value := range child[accountA].storage {
+		storageTrie.Update(slot[:], value)
+	}
+	destructs := map[common.Hash]struct{}{accountB: {}}
+	accounts := map[common.Hash][]byte{
+		accountA: child[accountA].encode(storageTrie.Hash()),
+		accountB: nil,
+	}
+	storage := map[common.Hash]map[common.Hash][]byte{
+		accountA: {{0x01}: {0xff}, {0x02}: nil},
+	}
+	if err := tree.Update(root, parent, destructs, accounts, storage); err != nil {
+		t.Fatalf("failed to update snapshot: %v", err)
+	}
+}
+
+// Tests that a generated snapshot matches its state, and that a corrupted one
+// fails the verification.
+func TestGenerateSnapshot(t *testing.T) {
+	var (
+		db     = vntdb.NewMemDatabase()
+		triedb = trie.NewDatabase(db)
+	)
+	state, _ := testStates()
+	root := makeState(t, triedb, state)
+
+	// A trie node sharing the prefix of the snapshot entries
+	node := append(common.CopyBytes(rawdb.SnapshotAccountPrefix), make([]byte, common.HashLength-1)...)
+	db.Put(node, []byte{0x01})
+
+	tree, err := New(db, triedb, root)
+	if err != nil {
+		t.Fatalf("failed to create snapshot: %v", err)
+	}
+	waitGeneration(t, tree)
+
+	if ok, _ := db.Has(node); !ok {
+		t.Fatalf("trie node wiped with the snapshot")
+	}
+
+	if err := VerifyState(db, root); err != nil {
+		t.Fatalf("generated snapshot invalid: %v", err)
+	}
+	slots, err := tree.Snapshot(root).Slots(accountA)
+	if err != nil {
+		t.Fatalf("failed to read slots: %v", err)
+	}
+	if len(slots) != 32 {
+		t.Fatalf("slots mismatch: have %d, want 32", len(slots))
+	}
+	if !sort.SliceIsSorted(slots, func(i, j int) bool { return bytes.Compare(slots[i].Hash[:], slots[j].Hash[:]) < 0 }) {
+		t.Errorf("slots not in trie order")
+	}
+	// A reloaded snapshot needs no generation
+	if tree, _ = New(db, triedb, root); tree.Snapshot(root).(*diskLayer).genMarker != nil {
+		t.Errorf("snapshot regenerated on reload")
+	}
+	rawdb.WriteStorageSnapshot(db, accountA, common.Hash{0x01}, []byte{0xee})
+	if err := VerifyState(db, root); err == nil {
+		t.Errorf("corrupted snapshot verified")
+	}
+}
+
+// Tests that diff layers serve the changes of their state on top of their
+// parent, and that flattening them writes the child state to disk.
+func TestDiffLayers(t *testing.T) {
+	var (
+		db     = vntdb.NewMemDatabase()
+		triedb = trie.NewDatabase(db)
+	)
+	parentState, childState := testStates()
+	parentRoot := makeState(t, triedb, parentState)
+	childRoot := makeState(t, triedb, childState)
+
+	tree, _ := New(db, triedb, parentRoot)
+	waitGeneration(t, tree)
+	update(t, tree, triedb, childRoot, parentRoot, childState)
+
+	parent, child := tree.Snapshot(parentRoot), tree.Snapshot(childRoot)
+	if data, _ := child.Account(accountB); data != nil {
+		t.Errorf("destructed account served: %x", data)
+	}
+	if data, _ := child.Storage(accountB, common.Hash{0x01}); data != nil {
+		t.Errorf("destructed storage served: %x", data)
+	}
+	if data, _ := child.Storage(accountA, common.Hash{0x01}); !bytes.Equal(data, []byte{0xff}) {
+		t.Errorf("changed slot mismatch: have %x, want ff", data)
+	}
+	if data, _ := parent.Storage(accountA, common.Hash{0x01}); !bytes.Equal(data, []byte{0x01}) {
+		t.Errorf("parent slot mismatch: have %x, want 01", data)
+	}
+	if slots, _ := child.Slots(accountA); len(slots) != 31 {
+		t.Errorf("child slots mismatch: have %d, want 31", len(slots))
+	}
+	if data, _ := child.Account(accountC); data == nil {
+		t.Errorf("unchanged account missing")
+	}
+	// Flatten the child into the disk
+	if err := tree.Cap(childRoot, 0); err != nil {
+		t.Fatalf("failed to cap snapshot: %v", err)
+	}
+	if _, err := parent.Account(accountC); err != ErrSnapshotStale {
+		t.Errorf("flattened parent error mismatch: have %v, want %v", err, ErrSnapshotStale)
+	}
+	if _, ok := tree.Snapshot(childRoot).(*diskLayer); !ok {
+		t.Errorf("child not flattened")
+	}
+	if err := VerifyState(db, childRoot); err != nil {
+		t.Errorf("flattened snapshot invalid: %v", err)
+	}
+}
+
+// Tests that capping drops the layers which don't descend from the new disk
+// layer, and keeps the ones that do.
+func TestCapDropsForks(t *testing.T) {
+	var (
+		db     = vntdb.NewMemDatabase()
+		triedb = trie.NewDatabase(db)
+	)
+	state, _ := testStates()
+	root := makeState(t, triedb, state)
+
+	tree, _ := New(db, triedb, root)
+	waitGeneration(t, tree)
+
+	change := func(balance int64) map[common.Hash][]byte {
+		return map[common.Hash][]byte{accountC: (&testAccount{balance: balance}).encode(emptyRoot)}
+	}
+	var (
+		canon = common.Hash{0x01}
+		fork  = common.Hash{0x02}
+		head  = common.Hash{0x03}
+	)
+	tree.Update(canon, root, nil, change(10), nil)
+	tree.Update(fork, root, nil, change(20), nil)
+	tree.Update(head, canon, nil, change(30), nil)
+
+	forked := tree.Snapshot(fork)
+	if err := tree.Cap(head, 1); err != nil {
+		t.Fatalf("failed to cap snapshot: %v", err)
+	}
+	if tree.Snapshot(fork) != nil {
+		t.Errorf("fork not dropped")
+	}
+	if _, err := forked.Account(accountC); err != ErrSnapshotStale {
+		t.Errorf("dropped fork error mismatch: have %v, want %v", err, ErrSnapshotStale)
+	}
+	if _, ok := tree.Snapshot(canon).(*diskLayer); !ok {
+		t.Errorf("canonical layer not flattened")
+	}
+	want := (&testAccount{balance: 30}).encode(emptyRoot)
+	if data, _ := tree.Snapshot(head).Account(accountC); !bytes.Equal(data, want) {
+		t.Errorf("head account mismatch: have %x, want %x", data, want)
+	}
+}
+
+// Tests that flattening a layer into a disk layer still being generated skips
+// the changes not covered yet, and that the generation completes the snapshot
+// of the new state.
+func TestFlattenDuringGeneration(t *testing.T) {
+	var (
+		db     = vntdb.NewMemDatabase()
+		triedb = trie.NewDatabase(db)
+	)
+	parentState, childState := testStates()
+	parentRoot := makeState(t, triedb, parentState)
+	childRoot := makeState(t, triedb, childState)
+
+	tree, _ := New(db, triedb, parentRoot)
+	disk := waitGeneration(t, tree)
+
+	// Rewind the generation to the first account
+	first := accountA
+	for hash := range parentState {
+		if bytes.Compare(hash[:], first[:]) < 0 {
+			first = hash
+		}
+	}
+	batch := db.NewBatch()
+	for hash := range parentState {
+		if hash != first {
+			rawdb.DeleteAccountSnapshot(batch, hash)
+			wipeStorage(db, batch, hash)
+		}
+	}
+	rawdb.DeleteSnapshotRoot(batch)
+	batch.Write()
+	disk.genMarker = first[:]
+
+	if _, err := disk.Account(accountC); err != ErrNotCoveredYet {
+		t.Errorf("uncovered account error mismatch: have %v, want %v", err, ErrNotCoveredYet)
+	}
+	update(t, tree, triedb, childRoot, parentRoot, childState)
+	if err := tree.Cap(childRoot, 0); err != nil {
+		t.Fatalf("failed to cap snapshot: %v", err)
+	}
+	waitGeneration(t, tree)
+	if err := VerifyState(db, childRoot); err != nil {
+		t.Errorf("snapshot invalid after generation: %v", err)
+	}
+}
+
+// rootRecordingDB records the snapshot root on disk after every batch write.
+type rootRecordingDB struct {
+	*vntdb.MemDatabase
+	roots []common.Hash
+}
+
+func (db *rootRecordingDB) NewBatch() vntdb.Batch {
+	return &rootRecordingBatch{Batch: db.MemDatabase.NewBatch(), db: db}
+}
+
+type rootRecordingBatch struct {
+	vntdb.Batch
+	db *rootRecordingDB
+}
+
+func (b *rootRecordingBatch) Write() error {
+	err := b.Batch.Write()
+	b.db.roots = append(b.db.roots, rawdb.ReadSnapshotRoot(b.db))
+	return err
+}
+
+// Tests that a layer flattened in several batches doesn't leave the old root on
+// disk along with part of the new state, and that the new root is only written
+// with the last batch.
+func TestFlattenRootMarker(t *testing.T) {
+	var (
+		db     = &rootRecordingDB{MemDatabase: vntdb.NewMemDatabase()}
+		triedb = trie.NewDatabase(db)
+	)
+	state, _ := testStates()
+	root := makeState(t, triedb, state)
+
+	tree, _ := New(db, triedb, root)
+	waitGeneration(t, tree)
+	if have := rawdb.ReadSnapshotRoot(db); have != root {
+		t.Fatalf("generated root mismatch: have %x, want %x", have, root)
+	}
+	accounts := make(map[common.Hash][]byte)
+	for i := 0; i < 4*vntdb.IdealBatchSize/256; i++ {
+		accounts[common.BytesToHash(crypto.Keccak256(big.NewInt(int64(i)).Bytes()))] = bytes.Repeat([]byte{0x01}, 256)
+	}
+	child := common.Hash{0x01}
+	if err := tree.Update(child, root, nil, accounts, nil); err != nil {
+		t.Fatalf("failed to update snapshot: %v", err)
+	}
+	db.roots = nil
+	if err := tree.Cap(child, 0); err != nil {
+		t.Fatalf("failed to cap snapshot: %v", err)
+	}
+	if len(db.roots) < 2 {
+		t.Fatalf("flattened in %d batches, want several", len(db.roots))
+	}
+	for i, have := range db.roots[:len(db.roots)-1] {
+		if have != (common.Hash{}) {
+			t.Errorf("batch %d: root on disk with partial state: %x", i, have)
+		}
+	}
+	if have := db.roots[len(db.roots)-1]; have != child {
+		t.Errorf("final root mismatch: have %x, want %x", have, child)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"fmt"
+
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/rawdb"
+	"github.com/vntchain/go-vnt/rlp"
+	"github.com/vntchain/go-vnt/trie"
+	"github.com/vntchain/go-vnt/vntdb"
+)
+
+// VerifyState checks the snapshot on disk against the state with the given
+// root, by rebuilding the account and storage tries from its entries. The
+// tries are built in memory.
+func VerifyState(diskdb vntdb.Database, root common.Hash) error {
+	db, ok := diskdb.(iteratee)
+	if !ok {
+		return errNotIterable
+	}
+	if have := rawdb.ReadSnapshotRoot(diskdb); have != root {
+		return fmt.Errorf("snapshot root mismatch: have %x, want %x", have, root)
+	}
+	accTrie, _ := trie.New(common.Hash{}, trie.NewDatabase(vntdb.NewMemDatabase()))
+
+	it := db.NewIteratorWithPrefix(rawdb.SnapshotAccountPrefix)
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(rawdb.SnapshotAccountPrefix)+common.HashLength {
+			continue
+		}
+		accountHash := common.BytesToHash(key[len(rawdb.SnapshotAccountPrefix):])
+		var acc account
+		if err := rlp.DecodeBytes(it.Value(), &acc); err != nil {
+			return fmt.Errorf("account %x: %v", accountHash, err)
+		}
+		storageRoot, err := verifyStorage(db, accountHash)
+		if err != nil {
+			return err
+		}
+		if storageRoot != acc.Root {
+			return fmt.Errorf("account %x storage root mismatch: have %x, want %x", accountHash, storageRoot, acc.Root)
+		}
+		accTrie.Update(accountHash[:], common.CopyBytes(it.Value()))
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if have := accTrie.Hash(); have != root {
+		return fmt.Errorf("state root mismatch: have %x, want %x", have, root)
+	}
+	return nil
+}
+
+// verifyStorage returns the root of the storage trie rebuilt from the slots of
+// an account.
+func verifyStorage(db iteratee, accountHash common.Hash) (common.Hash, error) {
+	storageTrie, _ := trie.New(common.Hash{}, trie.NewDatabase(vntdb.NewMemDatabase()))
+
+	it := db.NewIteratorWithPrefix(storagePrefix(accountHash))
+	defer it.Release()
+
+	for it.Next() {
+		storageHash := it.Key()[len(rawdb.SnapshotStoragePrefix)+common.HashLength:]
+		storageTrie.Update(common.CopyBytes(storageHash), common.CopyBytes(it.Value()))
+	}
+	return storageTrie.Hash(), it.Error()
+}
@@ -27,11 +27,17 @@ import (
 	"strings"
 	"testing"
 	"testing/quick"
+	"time"
 
 	check "gopkg.in/check.v1"
 
 	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/rawdb"
+	"github.com/vntchain/go-vnt/core/state/snapshot"
 	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/crypto"
+	"github.com/vntchain/go-vnt/rlp"
+	"github.com/vntchain/go-vnt/trie"
 	"github.com/vntchain/go-vnt/vntdb"
 )
 
@@ -435,3 +441,160 @@ func TestCopyOfCopy(t *testing.T) {
 		t.Fatalf("2nd copy fail, expected 42, got %v", got)
 	}
 }
+
+// Tests that the storage cache of an object is carried over to the copy, not
+// only the dirty slots, so that finalised slots can still be iterated.
+func TestCopyCachedStorage(t *testing.T) {
+	sdb, _ := New(common.Hash{}, NewDatabase(vntdb.NewMemDatabase()))
+	addr := common.HexToAddress("aaaa")
+	key, value := common.HexToHash("01"), common.HexToHash("02")
+	sdb.SetState(addr, key, value)
+	sdb.Finalise(false)
+
+	copy := sdb.Copy()
+	storage := make(map[common.Hash]common.Hash)
+	copy.ForEachStorage(addr, func(k, v common.Hash) bool {
+		storage[k] = v
+		return true
+	})
+	if have := storage[key]; have != value {
+		t.Errorf("iterated slot mismatch: have %x, want %x", have, value)
+	}
+	if have := copy.GetState(addr, key); have != value {
+		t.Errorf("copied slot mismatch: have %x, want %x", have, value)
+	}
+}
+
+// Tests that a state served from the flat snapshot reads and iterates the same
+// as the one served from the tries, across storage changes and suicides.
+func TestSnapshotReads(t *testing.T) {
+	var (
+		db       = vntdb.NewMemDatabase()
+		sdb      = NewDatabase(db)
+		contract = common.Address{0xc0}
+		doomed   = common.Address{0xd0}
+	)
+	state, _ := New(common.Hash{}, sdb)
+	for i := byte(1); i <= 64; i++ {
+		state.AddBalance(common.Address{i}, big.NewInt(int64(i)))
+		state.SetState(contract, common.Hash{i}, common.Hash{i})
+		state.SetState(doomed, common.Hash{i}, common.Hash{i})
+	}
+	root, _ := state.Commit(false)
+	sdb.TrieDB().Commit(root, false)
+
+	snaps, err := snapshot.New(db, sdb.TrieDB(), root)
+	if err != nil {
+		t.Fatalf("failed to create snapshot: %v", err)
+	}
+	for start := time.Now(); rawdb.ReadSnapshotRoot(db) != root; time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("snapshot generation timed out")
+		}
+	}
+	snapdb := WithSnapshots(sdb, snaps)
+
+	state, _ = New(root, snapdb)
+	for i := byte(1); i <= 64; i += 2 {
+		state.SetState(contract, common.Hash{i}, common.Hash{})
+		state.SetState(contract, common.Hash{i + 1}, common.Hash{0xff, i})
+	}
+	state.Suicide(doomed)
+	state.SetState(doomed, common.Hash{0x01}, common.Hash{0x01})
+	root, _ = state.Commit(false)
+	if snaps.Snapshot(root) == nil {
+		t.Fatalf("snapshot of committed state missing")
+	}
+	flat, _ := New(root, snapdb)
+	tries, _ := New(root, sdb)
+	for _, addr := range []common.Address{contract, doomed, {0x01}} {
+		if flat.Exist(addr) != tries.Exist(addr) || flat.GetBalance(addr).Cmp(tries.GetBalance(addr)) != 0 {
+			t.Errorf("account %x mismatch", addr)
+		}
+		var have, want []common.Hash
+		flat.ForEachStorage(addr, func(key, value common.Hash) bool {
+			have = append(have, key, value)
+			return true
+		})
+		tries.ForEachStorage(addr, func(key, value common.Hash) bool {
+			want = append(want, key, value)
+			return true
+		})
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("account %x storage iteration mismatch: have %d entries, want %d", addr, len(have), len(want))
+		}
+		for i := byte(1); i <= 64; i++ {
+			if have, want := flat.GetState(addr, common.Hash{i}), tries.GetState(addr, common.Hash{i}); have != want {
+				t.Errorf("account %x slot %d mismatch: have %x, want %x", addr, i, have, want)
+			}
+		}
+	}
+	if err := snaps.Cap(root, 0); err != nil {
+		t.Fatalf("failed to cap snapshot: %v", err)
+	}
+	if err := snapshot.VerifyState(db, root); err != nil {
+		t.Errorf("flattened snapshot invalid: %v", err)
+	}
+}
+
+// Tests that the proofs of accounts and storage slots are verified against the
+// state root, and prove missing ones absent.
+func TestGetProof(t *testing.T) {
+	state, _ := New(common.Hash{}, NewDatabase(vntdb.NewMemDatabase()))
+	for i := byte(1); i < 64; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		state.AddBalance(addr, big.NewInt(int64(i)))
+		state.SetState(addr, common.Hash{i}, common.Hash{0, i})
+	}
+	root, _ := state.Commit(false)
+	state, _ = New(root, state.Database())
+
+	verify := func(root common.Hash, key []byte, proof [][]byte) []byte {
+		proofDb := vntdb.NewMemDatabase()
+		for _, node := range proof {
+			proofDb.Put(crypto.Keccak256(node), node)
+		}
+		value, _, err := trie.VerifyProof(root, key, proofDb)
+		if err != nil {
+			t.Fatalf("failed to verify proof of %x: %v", key, err)
+		}
+		return value
+	}
+	addr := common.BytesToAddress([]byte{7})
+	proof, err := state.GetProof(addr)
+	if err != nil {
+		t.Fatalf("failed to prove account: %v", err)
+	}
+	var account Account
+	if err := rlp.DecodeBytes(verify(root, crypto.Keccak256(addr.Bytes()), proof), &account); err != nil {
+		t.Fatalf("failed to decode proven account: %v", err)
+	}
+	if account.Balance.Int64() != 7 {
+		t.Errorf("proven balance mismatch: have %v, want 7", account.Balance)
+	}
+	proof, err = state.GetStorageProof(addr, common.Hash{7})
+	if err != nil {
+		t.Fatalf("failed to prove storage: %v", err)
+	}
+	_, content, _, _ := rlp.Split(verify(account.Root, crypto.Keccak256(common.Hash{7}.Bytes()), proof))
+	if common.BytesToHash(content) != (common.Hash{0, 7}) {
+		t.Errorf("proven storage mismatch: have %x, want %x", content, common.Hash{0, 7})
+	}
+	// Missing slots and accounts are proven absent
+	if proof, err = state.GetStorageProof(addr, common.Hash{8}); err != nil {
+		t.Fatalf("failed to prove missing storage: %v", err)
+	}
+	if value := verify(account.Root, crypto.Keccak256(common.Hash{8}.Bytes()), proof); value != nil {
+		t.Errorf("missing storage proven as %x", value)
+	}
+	missing := common.BytesToAddress([]byte{0xff})
+	if proof, err = state.GetProof(missing); err != nil {
+		t.Fatalf("failed to prove missing account: %v", err)
+	}
+	if value := verify(root, crypto.Keccak256(missing.Bytes()), proof); value != nil {
+		t.Errorf("missing account proven as %x", value)
+	}
+	if _, err := state.GetStorageProof(missing, common.Hash{1}); err == nil {
+		t.Errorf("proved storage of missing account")
+	}
+}
@@ -28,6 +28,8 @@ import (
 	"github.com/vntchain/go-vnt/common"
 	"github.com/vntchain/go-vnt/core/state"
 	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/core/vm"
+	"github.com/vntchain/go-vnt/core/wavm/validate"
 	"github.com/vntchain/go-vnt/event"
 	"github.com/vntchain/go-vnt/log"
 	"github.com/vntchain/go-vnt/metrics"
@@ -76,6 +78,10 @@ var (
 	// than some meaningful limit a user might use. This is not a consensus error
 	// making the transaction invalid, rather a DOS protection.
 	ErrOversizedData = errors.New("oversized data")
+
+	// ErrInvalidContract is returned if the wasm contract of a contract creation
+	// fails static validation.
+	ErrInvalidContract = errors.New("invalid wasm contract")
 )
 
 var (
@@ -117,6 +123,7 @@ type blockChain interface {
 	CurrentBlock() *types.Block
 	GetBlock(hash common.Hash, number uint64) *types.Block
 	StateAt(root common.Hash) (*state.StateDB, error)
+	GetVMConfig() *vm.Config
 
 	SubscribeChainHeadEvent(ch chan<- ChainHeadEvent) event.Subscription
 }
@@ -136,6 +143,8 @@ type TxPoolConfig struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	ValidateWasm bool // Whether contract creations are statically validated before acceptance
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -592,6 +601,12 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if tx.Gas() < intrGas {
 		return ErrIntrinsicGas
 	}
+	// Reject contracts which are bound to fail in the virtual machine
+	if pool.config.ValidateWasm && tx.To() == nil {
+		if err := validate.ValidatePayload(tx.Data(), validate.NewConfig(pool.chain.GetVMConfig())); err != nil {
+			return fmt.Errorf("%v: %v", ErrInvalidContract, err)
+		}
+	}
 	return nil
 }
 
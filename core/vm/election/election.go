@@ -791,6 +791,59 @@ func GetStake(stateDB inter.StateDB, addr common.Address) *Stake {
 	return &s
 }
 
+// ContractAddr returns the address of the election contract, whose storage holds
+// the election records.
+func ContractAddr() common.Address {
+	return electionAddr
+}
+
+// CandidateStorageKeys returns the storage keys of the election contract holding
+// the candidate record of addr.
+func CandidateStorageKeys(stateDB inter.StateDB, addr common.Address) []common.Hash {
+	return CandidateKeys(addr, stateGetter(stateDB))
+}
+
+// VoterStorageKeys returns the storage keys of the election contract holding the
+// voter record of addr.
+func VoterStorageKeys(stateDB inter.StateDB, addr common.Address) []common.Hash {
+	return VoterKeys(addr, stateGetter(stateDB))
+}
+
+// StakeStorageKeys returns the storage keys of the election contract holding the
+// stake record of addr.
+func StakeStorageKeys(stateDB inter.StateDB, addr common.Address) []common.Hash {
+	return StakeKeys(addr, stateGetter(stateDB))
+}
+
+// CandidateKeys derives the storage keys holding the candidate record of addr
+// from the prefix encoding of the record. The lengths of its variable fields
+// are read through get, e.g. from the values of a proof.
+func CandidateKeys(addr common.Address, get func(key common.Hash) common.Hash) []common.Hash {
+	candidate := newCandidate()
+	return storageKeysOf(CANDIDATEPREFIX, addr, &candidate, get)
+}
+
+// VoterKeys derives the storage keys holding the voter record of addr, reading
+// the lengths of its variable fields through get.
+func VoterKeys(addr common.Address, get func(key common.Hash) common.Hash) []common.Hash {
+	var voter Voter
+	return storageKeysOf(VOTERPREFIX, addr, &voter, get)
+}
+
+// StakeKeys derives the storage keys holding the stake record of addr, reading
+// the lengths of its variable fields through get.
+func StakeKeys(addr common.Address, get func(key common.Hash) common.Hash) []common.Hash {
+	var stake Stake
+	return storageKeysOf(STAKEPREFIX, addr, &stake, get)
+}
+
+// stateGetter reads the storage of the election contract from stateDB.
+func stateGetter(stateDB inter.StateDB) func(key common.Hash) common.Hash {
+	return func(key common.Hash) common.Hash {
+		return stateDB.GetState(electionAddr, key)
+	}
+}
+
 func AddCandidatesBounty(stateDB inter.StateDB, bonus map[common.Address]*big.Int) error {
 	for addr, bu := range bonus {
 		if err := addCandidateBounty(stateDB, addr, bu); err != nil {
@@ -822,3 +875,32 @@ func QueryRestVNTBounty(stateDB inter.StateDB) *big.Int {
 	bounty := getRestBounty(stateDB)
 	return bounty.RestTotalBounty
 }
+
+// GenesisStorage returns the storage of the election contract in which the
+// given witnesses are registered as active candidates and the rest bounty is
+// initialized. It is used to pre-populate the genesis state of chains which
+// have no way to register witnesses, like the developer chain.
+func GenesisStorage(witnesses []common.Address, urls [][]byte) (map[common.Hash]common.Hash, error) {
+	if len(urls) != 0 && len(urls) != len(witnesses) {
+		return nil, fmt.Errorf("witnesses and urls count mismatch: %d != %d", len(witnesses), len(urls))
+	}
+	storage := make(map[common.Hash]common.Hash)
+	setFn := func(key common.Hash, value common.Hash) {
+		storage[key] = value
+	}
+	for i, addr := range witnesses {
+		candidate := newCandidate()
+		candidate.Owner = addr
+		candidate.Active = true
+		if len(urls) != 0 {
+			candidate.Url = urls[i]
+		}
+		if err := convertToKV(CANDIDATEPREFIX, candidate, setFn); err != nil {
+			return nil, err
+		}
+	}
+	if err := convertToKV(BOUNTYPREFIX, Bounty{restTotalBounty}, setFn); err != nil {
+		return nil, err
+	}
+	return storage, nil
+}
@@ -307,6 +307,19 @@ func convertToStruct(prefix byte, addr common.Address, v interface{}, getFn func
 	return nil
 }
 
+// storageKeysOf returns the keys read through get to decode the record of addr
+// into v, in order. The record may be absent, in which case the keys read up to
+// the failing field prove its absence.
+func storageKeysOf(prefix byte, addr common.Address, v interface{}, get func(key common.Hash) common.Hash) []common.Hash {
+	var keys []common.Hash
+	getFn := func(key common.Hash) common.Hash {
+		keys = append(keys, key)
+		return get(key)
+	}
+	convertToStruct(prefix, addr, v, getFn)
+	return keys
+}
+
 func getAllCandidate(db inter.StateDB) CandidateList {
 	var result CandidateList
 	addrs := make(map[common.Address]struct{})
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/rawdb"
 	"github.com/vntchain/go-vnt/core/state"
+	"github.com/vntchain/go-vnt/core/state/snapshot"
 	"github.com/vntchain/go-vnt/vntdb"
 )
 
@@ -284,6 +287,51 @@ func TestGetAllCandidate(t *testing.T) {
 	}
 }
 
+func TestGetAllCandidateFromSnapshot(t *testing.T) {
+	db := vntdb.NewMemDatabase()
+	sdb := state.NewDatabase(db)
+	stateDB, _ := state.New(common.Hash{}, sdb)
+
+	ctx := testContext{StateDB: stateDB}
+	c := newElectionContext(&ctx)
+
+	for i := 0; i < 64; i++ {
+		candidate1 := candidate
+		candidate1.Owner[0] = byte(i)
+		candidate1.VoteCount = big.NewInt(int64(i))
+		c.setCandidate(candidate1)
+	}
+	root, _ := stateDB.Commit(false)
+	sdb.TrieDB().Commit(root, false)
+
+	snaps, err := snapshot.New(db, sdb.TrieDB(), root)
+	if err != nil {
+		t.Fatalf("failed to create snapshot: %v", err)
+	}
+	for start := time.Now(); rawdb.ReadSnapshotRoot(db) != root; time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("snapshot generation timed out")
+		}
+	}
+	flatDB, _ := state.New(root, state.WithSnapshots(sdb, snaps))
+	trieDB, _ := state.New(root, sdb)
+
+	want := make(map[common.Address]Candidate)
+	for _, candidate := range getAllCandidate(trieDB) {
+		want[candidate.Owner] = candidate
+	}
+	have := getAllCandidate(flatDB)
+	if len(have) != len(want) {
+		t.Fatalf("the number of candidates is wrong: have %d, want %d", len(have), len(want))
+	}
+	for _, candidate := range have {
+		wanted := want[candidate.Owner]
+		if _, err := sameCandidate(&candidate, &wanted); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
 func TestGetFirstXCandidates_1(t *testing.T) {
 	db := vntdb.NewMemDatabase()
 	stateDB, _ := state.New(common.Hash{}, state.NewDatabase(db))
@@ -536,3 +584,41 @@ func TestAddCandidateBounty(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+// Tests that the storage keys of a record are the ones it's written to, and that
+// an absent record only needs its first key to be proven absent.
+func TestStorageKeys(t *testing.T) {
+	stateDB, _ := state.New(common.Hash{}, state.NewDatabase(vntdb.NewMemDatabase()))
+
+	for _, tt := range []struct {
+		name   string
+		prefix byte
+		record interface{}
+		keysOf func(addr common.Address) []common.Hash
+	}{
+		{"voter", VOTERPREFIX, voter, func(addr common.Address) []common.Hash { return VoterStorageKeys(stateDB, addr) }},
+		{"candidate", CANDIDATEPREFIX, candidate, func(addr common.Address) []common.Hash { return CandidateStorageKeys(stateDB, addr) }},
+		{"stake", STAKEPREFIX, stake, func(addr common.Address) []common.Hash { return StakeStorageKeys(stateDB, addr) }},
+	} {
+		written := make(map[common.Hash]bool)
+		err := convertToKV(tt.prefix, tt.record, func(key common.Hash, value common.Hash) {
+			written[key] = true
+			stateDB.SetState(electionAddr, key, value)
+		})
+		if err != nil {
+			t.Fatalf("%s: failed to write record: %v", tt.name, err)
+		}
+		keys := tt.keysOf(voter.Owner)
+		if len(keys) != len(written) {
+			t.Errorf("%s: key count mismatch: have %d, want %d", tt.name, len(keys), len(written))
+		}
+		for _, key := range keys {
+			if !written[key] {
+				t.Errorf("%s: unwritten key %x", tt.name, key)
+			}
+		}
+		if keys := tt.keysOf(common.BytesToAddress([]byte{0xff})); len(keys) != 1 {
+			t.Errorf("%s: absent record key count mismatch: have %d, want 1", tt.name, len(keys))
+		}
+	}
+}
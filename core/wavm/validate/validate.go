@@ -0,0 +1,358 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package validate statically checks wasm contracts before they are deployed,
+// so that modules which could only fail at run time are rejected early.
+package validate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vntchain/go-vnt/accounts/abi"
+	"github.com/vntchain/go-vnt/core/vm"
+	"github.com/vntchain/go-vnt/core/wavm"
+	"github.com/vntchain/go-vnt/core/wavm/gas"
+	"github.com/vntchain/vnt-wasm/disasm"
+	wasmvalidate "github.com/vntchain/vnt-wasm/validate"
+	"github.com/vntchain/vnt-wasm/wasm"
+)
+
+const envModuleName = "env"
+
+// Config are the rules a module is validated against.
+type Config struct {
+	DisableFloatingPoint bool   // Reject floating point instructions
+	MaxMemoryPages       uint32 // Maximum number of initial linear memory pages
+	MaxTableSize         uint32 // Maximum number of initial table elements
+}
+
+// DefaultConfig contains the default validation rules.
+var DefaultConfig = Config{
+	MaxMemoryPages: wavm.DefaultMaxMemoryPages,
+	MaxTableSize:   wavm.DefaultMaxTableSize,
+}
+
+// NewConfig returns the validation rules matching the virtual machine
+// configuration of a chain.
+func NewConfig(vmConfig *vm.Config) *Config {
+	config := DefaultConfig
+	config.DisableFloatingPoint = vmConfig.DisableFloatingPoint
+	if vmConfig.MaxMemoryPages > 0 {
+		config.MaxMemoryPages = uint32(vmConfig.MaxMemoryPages)
+	}
+	if vmConfig.MaxTableSize > 0 {
+		config.MaxTableSize = uint32(vmConfig.MaxTableSize)
+	}
+	return &config
+}
+
+// Errors is the list of problems found in a module.
+type Errors []error
+
+func (errs Errors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ValidatePayload validates the contract in the data of a contract creation
+// transaction.
+func ValidatePayload(data []byte, config *Config) error {
+	code, _, err := wavm.UnpackDeployPayload(data)
+	if err != nil {
+		return err
+	}
+	return Validate(code.Code, code.Abi, config)
+}
+
+// Validate checks the wasm module against its abi. The returned error is of
+// type Errors if the module could be parsed, listing every problem found.
+func Validate(code []byte, abiJSON []byte, config *Config) error {
+	contractAbi, err := wavm.GetAbi(abiJSON)
+	if err != nil {
+		return fmt.Errorf("invalid abi: %v", err)
+	}
+	env, err := envModule(contractAbi)
+	if err != nil {
+		return err
+	}
+	module, err := wasm.DecodeModule(bytes.NewReader(code))
+	if err != nil {
+		return fmt.Errorf("invalid module: %v", err)
+	}
+
+	var errs Errors
+	errs = append(errs, checkImports(module, env)...)
+	errs = append(errs, checkLimits(module, config)...)
+	errs = append(errs, checkExports(module, contractAbi)...)
+	if len(errs) > 0 {
+		return errs
+	}
+	// The module is well formed, instantiate it the same way as the virtual
+	// machine does and check the function bodies.
+	module, err = wasm.ReadModule(bytes.NewReader(code), func(name string) (*wasm.Module, error) {
+		return env, nil
+	})
+	if err != nil {
+		return Errors{err}
+	}
+	if err := wasmvalidate.VerifyModule(module); err != nil {
+		return Errors{err}
+	}
+	if errs := checkBodies(module, config); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// envModule returns the host module the contract imports from, including the
+// events and calls declared in the abi.
+func envModule(contractAbi abi.ABI) (module *wasm.Module, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid abi: %v", r)
+		}
+	}()
+	env := wavm.EnvModule{}
+	env.InitModule(&wavm.ChainContext{Abi: contractAbi})
+	return env.GetModule(), nil
+}
+
+// checkImports makes sure that only functions of the host module are imported,
+// with the signatures of the host functions, and that gas can be metered.
+func checkImports(module *wasm.Module, env *wasm.Module) Errors {
+	var (
+		errs   Errors
+		addGas bool
+	)
+	if module.Import != nil {
+		for _, entry := range module.Import.Entries {
+			name := entry.ModuleName + "." + entry.FieldName
+			if entry.ModuleName != envModuleName {
+				errs = append(errs, fmt.Errorf("import %s: unknown module %s", name, entry.ModuleName))
+				continue
+			}
+			imp, ok := entry.Type.(wasm.FuncImport)
+			if !ok {
+				errs = append(errs, fmt.Errorf("import %s: only functions can be imported, have %s", name, entry.Type.Kind()))
+				continue
+			}
+			export, ok := env.Export.Entries[entry.FieldName]
+			if !ok {
+				errs = append(errs, fmt.Errorf("import %s: not provided by the host", name))
+				continue
+			}
+			sig, err := typeAt(module, imp.Type)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("import %s: %v", name, err))
+				continue
+			}
+			if host := env.FunctionIndexSpace[export.Index].Sig; !sameSignature(sig, host) {
+				errs = append(errs, fmt.Errorf("import %s: signature mismatch: have %s, want %s", name, sig, host))
+				continue
+			}
+			if entry.FieldName == wavm.OpNameAddGas {
+				addGas = true
+			}
+		}
+	}
+	if !addGas {
+		errs = append(errs, fmt.Errorf("import %s.%s is missing, gas can not be metered", envModuleName, wavm.OpNameAddGas))
+	}
+	return errs
+}
+
+// checkLimits checks the initial size of the linear memory and the table,
+// which are allocated when the module is instantiated.
+func checkLimits(module *wasm.Module, config *Config) Errors {
+	var errs Errors
+	if module.Memory != nil {
+		if len(module.Memory.Entries) > 1 {
+			errs = append(errs, fmt.Errorf("at most one memory is allowed, have %d", len(module.Memory.Entries)))
+		}
+		for _, entry := range module.Memory.Entries {
+			if entry.Limits.Initial > config.MaxMemoryPages {
+				errs = append(errs, fmt.Errorf("initial memory exceeds limit: have %d pages, want at most %d", entry.Limits.Initial, config.MaxMemoryPages))
+			}
+		}
+	}
+	if module.Table != nil {
+		if len(module.Table.Entries) > 1 {
+			errs = append(errs, fmt.Errorf("at most one table is allowed, have %d", len(module.Table.Entries)))
+		}
+		for _, entry := range module.Table.Entries {
+			if entry.Limits.Initial > config.MaxTableSize {
+				errs = append(errs, fmt.Errorf("initial table exceeds limit: have %d elements, want at most %d", entry.Limits.Initial, config.MaxTableSize))
+			}
+		}
+	}
+	return errs
+}
+
+// checkExports makes sure each method of the abi and the constructor is
+// exported by a function with the signature the virtual machine calls it with.
+func checkExports(module *wasm.Module, contractAbi abi.ABI) Errors {
+	if module.Export == nil {
+		return Errors{errors.New("module has no export section")}
+	}
+	methods := make(map[string]abi.Method)
+	for name, method := range contractAbi.Methods {
+		methods[name] = method
+	}
+	if contractAbi.Constructor.Name != "" {
+		methods[contractAbi.Constructor.Name] = contractAbi.Constructor
+	}
+	var errs Errors
+	for name, method := range methods {
+		entry, ok := module.Export.Entries[name]
+		if !ok || entry.Kind != wasm.ExternalFunction {
+			errs = append(errs, fmt.Errorf("method %s is not exported", name))
+			continue
+		}
+		sig, err := functionSig(module, entry.Index)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("method %s: %v", name, err))
+			continue
+		}
+		if err := checkSignature(method, sig); err != nil {
+			errs = append(errs, fmt.Errorf("method %s: %v", name, err))
+		}
+	}
+	return errs
+}
+
+// checkBodies disassembles each function, looking for floating point
+// instructions and making sure the gas counter can be injected.
+func checkBodies(module *wasm.Module, config *Config) Errors {
+	var (
+		errs Errors
+		rule = gas.NewGas(true)
+	)
+	for i, fn := range module.FunctionIndexSpace {
+		if fn.IsHost() {
+			continue
+		}
+		disassembly, err := disasm.Disassemble(fn, module)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("function %d: %v", i, err))
+			continue
+		}
+		if config.DisableFloatingPoint {
+			for _, instr := range disassembly.Code {
+				if rule.Rules[rule.Ops[instr.Op.Code]].Metering == gas.MeteringForbidden {
+					errs = append(errs, fmt.Errorf("function %d: floating point instruction %s", i, instr.Op.Name))
+					break
+				}
+			}
+		}
+		if err := injectCounter(disassembly, module, config); err != nil {
+			errs = append(errs, fmt.Errorf("function %d: %v", i, err))
+		}
+	}
+	return errs
+}
+
+func injectCounter(disassembly *disasm.Disassembly, module *wasm.Module, config *Config) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to inject gas counter: %v", r)
+		}
+	}()
+	gas.InjectCounter(disassembly.Code, module, gas.NewGas(config.DisableFloatingPoint))
+	return nil
+}
+
+// functionSig returns the signature of the function at index in the function
+// index space, the imported functions come first.
+func functionSig(module *wasm.Module, index uint32) (*wasm.FunctionSig, error) {
+	if module.Import != nil {
+		for _, entry := range module.Import.Entries {
+			if imp, ok := entry.Type.(wasm.FuncImport); ok {
+				if index == 0 {
+					return typeAt(module, imp.Type)
+				}
+				index--
+			}
+		}
+	}
+	if module.Function == nil || int(index) >= len(module.Function.Types) {
+		return nil, fmt.Errorf("function index %d out of range", index)
+	}
+	return typeAt(module, module.Function.Types[index])
+}
+
+func typeAt(module *wasm.Module, index uint32) (*wasm.FunctionSig, error) {
+	if module.Types == nil || int(index) >= len(module.Types.Entries) {
+		return nil, fmt.Errorf("type index %d out of range", index)
+	}
+	return &module.Types.Entries[index], nil
+}
+
+// checkSignature compares the wasm signature with the one the virtual machine
+// derives from the abi: strings, addresses and uint256 are passed by pointer.
+func checkSignature(method abi.Method, sig *wasm.FunctionSig) error {
+	if len(sig.ParamTypes) != len(method.Inputs) {
+		return fmt.Errorf("param count mismatch: have %d, want %d", len(sig.ParamTypes), len(method.Inputs))
+	}
+	for i, input := range method.Inputs {
+		if want := valueType(input.Type); sig.ParamTypes[i] != want {
+			return fmt.Errorf("param %s type mismatch: have %s, want %s", input.Name, sig.ParamTypes[i], want)
+		}
+	}
+	if len(method.Outputs) == 0 {
+		if len(sig.ReturnTypes) != 0 {
+			return errors.New("returns a value but the abi has no output")
+		}
+		return nil
+	}
+	if len(sig.ReturnTypes) != 1 {
+		return errors.New("returns no value but the abi has an output")
+	}
+	if want := valueType(method.Outputs[0].Type); sig.ReturnTypes[0] != want {
+		return fmt.Errorf("return type mismatch: have %s, want %s", sig.ReturnTypes[0], want)
+	}
+	return nil
+}
+
+func valueType(typ abi.Type) wasm.ValueType {
+	switch typ.T {
+	case abi.IntTy, abi.UintTy:
+		if typ.Size == 64 {
+			return wasm.ValueTypeI64
+		}
+	}
+	return wasm.ValueTypeI32
+}
+
+// sameSignature compares an imported signature with the host one. Only the
+// number of results is compared, the interpreter keeps every value as 64 bits
+// on its stack and host functions returning pointers are declared as i64.
+func sameSignature(sig, host *wasm.FunctionSig) bool {
+	if len(sig.ParamTypes) != len(host.ParamTypes) || len(sig.ReturnTypes) != len(host.ReturnTypes) {
+		return false
+	}
+	for i := range sig.ParamTypes {
+		if sig.ParamTypes[i] != host.ParamTypes[i] {
+			return false
+		}
+	}
+	return true
+}
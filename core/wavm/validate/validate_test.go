@@ -0,0 +1,199 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package validate
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vntchain/go-vnt/core/vm"
+	"github.com/vntchain/go-vnt/core/wavm"
+	"github.com/vntchain/vnt-wasm/wasm"
+)
+
+var testContracts = []string{"erc20", "bounds", "convert", "env", "initializeVariables", "mapping"}
+
+func readContract(t *testing.T, name string) ([]byte, []byte) {
+	dir := filepath.Join("..", "testdata", name)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var code []byte
+	for _, file := range files {
+		if filepath.Ext(file.Name()) == ".wasm" {
+			if code, err = ioutil.ReadFile(filepath.Join(dir, file.Name())); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	abiJSON, err := ioutil.ReadFile(filepath.Join(dir, "abi.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code, abiJSON
+}
+
+func TestValidate(t *testing.T) {
+	for _, name := range testContracts {
+		code, abiJSON := readContract(t, name)
+		if err := Validate(code, abiJSON, &DefaultConfig); err != nil {
+			t.Errorf("%s: validation failed: %v", name, err)
+		}
+	}
+}
+
+func TestValidatePayload(t *testing.T) {
+	code, abiJSON := readContract(t, "erc20")
+	payload, err := wavm.PackDeployPayload(code, abiJSON, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidatePayload(payload, &DefaultConfig); err != nil {
+		t.Errorf("validation failed: %v", err)
+	}
+	if err := ValidatePayload([]byte("0x1234"), &DefaultConfig); err == nil {
+		t.Error("expected error for payload without code")
+	}
+	// Constructor input may contain the closing brace of the container
+	payload, err = wavm.PackDeployPayload(code, abiJSON, []byte("}}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidatePayload(payload, &DefaultConfig); err != nil {
+		t.Errorf("validation with constructor input failed: %v", err)
+	}
+	// A container the virtual machine would split elsewhere is rejected
+	braced := append([]byte(`{"Extra":"}",`), payload[1:]...)
+	if err := ValidatePayload(braced, &DefaultConfig); err == nil {
+		t.Error("expected error for brace inside the container")
+	}
+}
+
+func TestNewConfig(t *testing.T) {
+	if config := NewConfig(&vm.Config{}); *config != DefaultConfig {
+		t.Errorf("config mismatch: have %+v, want %+v", *config, DefaultConfig)
+	}
+	config := NewConfig(&vm.Config{DisableFloatingPoint: true, MaxMemoryPages: 16})
+	if !config.DisableFloatingPoint || config.MaxMemoryPages != 16 || config.MaxTableSize != DefaultConfig.MaxTableSize {
+		t.Errorf("config not derived from the vm config: %+v", *config)
+	}
+}
+
+func TestValidateMismatch(t *testing.T) {
+	code, abiJSON := readContract(t, "erc20")
+
+	// A method missing from the module
+	missing := bytes.Replace(abiJSON, []byte(`[`), []byte(`[{"name":"Missing","constant":false,"inputs":[],"outputs":[],"type":"function"},`), 1)
+	err := Validate(code, missing, &DefaultConfig)
+	if err == nil || !strings.Contains(err.Error(), "method Missing is not exported") {
+		t.Errorf("expected missing method error, got %v", err)
+	}
+	// Memory above the limit
+	config := DefaultConfig
+	config.MaxMemoryPages = 0
+	err = Validate(code, abiJSON, &config)
+	if err == nil || !strings.Contains(err.Error(), "initial memory exceeds limit") {
+		t.Errorf("expected memory limit error, got %v", err)
+	}
+	// Garbage module
+	if err := Validate([]byte{0x00, 0x61, 0x73}, abiJSON, &DefaultConfig); err == nil {
+		t.Error("expected error for truncated module")
+	}
+}
+
+// testAbi declares the single method exported by the test modules.
+var testAbi = []byte(`[{"name":"Test","constant":false,"inputs":[],"outputs":[],"type":"function"}]`)
+
+// addGasImport imports the gas counter with the host signature, type 0 of the
+// test modules.
+var addGasImport = wasm.ImportEntry{ModuleName: "env", FieldName: wavm.OpNameAddGas, Type: wasm.FuncImport{Type: 0}}
+
+// testModule encodes a module with the given imports, exporting a function
+// Test running code.
+func testModule(t *testing.T, imports []wasm.ImportEntry, code []byte) []byte {
+	module := &wasm.Module{
+		Types: &wasm.SectionTypes{Entries: []wasm.FunctionSig{
+			{Form: int8(wasm.TypeFunc), ParamTypes: []wasm.ValueType{wasm.ValueTypeI64}},
+			{Form: int8(wasm.TypeFunc)},
+		}},
+		Import:   &wasm.SectionImports{Entries: imports},
+		Function: &wasm.SectionFunctions{Types: []uint32{1}},
+		Export: &wasm.SectionExports{Entries: map[string]wasm.ExportEntry{
+			"Test": {FieldStr: "Test", Kind: wasm.ExternalFunction, Index: uint32(len(imports))},
+		}},
+		Code: &wasm.SectionCode{Bodies: []wasm.FunctionBody{{Code: code}}},
+	}
+	buf := new(bytes.Buffer)
+	if err := wasm.EncodeModule(buf, module); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestValidateRejected(t *testing.T) {
+	noFloat := DefaultConfig
+	noFloat.DisableFloatingPoint = true
+
+	tests := []struct {
+		name    string
+		imports []wasm.ImportEntry
+		code    []byte
+		config  *Config
+		err     string
+	}{
+		{
+			name:    "floating point",
+			imports: []wasm.ImportEntry{addGasImport},
+			code:    []byte{0x43, 0x00, 0x00, 0x80, 0x3f, 0x1a}, // f32.const 1; drop
+			config:  &noFloat,
+			err:     "function 1: floating point instruction f32.const",
+		},
+		{
+			name:    "unknown module",
+			imports: []wasm.ImportEntry{addGasImport, {ModuleName: "host", FieldName: "AddGas", Type: wasm.FuncImport{Type: 0}}},
+			config:  &DefaultConfig,
+			err:     "import host.AddGas: unknown module host",
+		},
+		{
+			name:    "unknown host function",
+			imports: []wasm.ImportEntry{addGasImport, {ModuleName: "env", FieldName: "Missing", Type: wasm.FuncImport{Type: 1}}},
+			config:  &DefaultConfig,
+			err:     "import env.Missing: not provided by the host",
+		},
+		{
+			name:   "missing gas import",
+			config: &DefaultConfig,
+			err:    "import env.AddGas is missing, gas can not be metered",
+		},
+	}
+	for _, tt := range tests {
+		code := testModule(t, tt.imports, tt.code)
+		err := Validate(code, testAbi, tt.config)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: error mismatch: have %v, want %s", tt.name, err, tt.err)
+		}
+	}
+	// The floating point module is fine unless floats are disabled
+	code := testModule(t, tests[0].imports, tests[0].code)
+	if err := Validate(code, testAbi, &DefaultConfig); err != nil {
+		t.Errorf("floating point module rejected: %v", err)
+	}
+}
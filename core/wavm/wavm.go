@@ -3,6 +3,9 @@ package wavm
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"math/big"
 	"sync/atomic"
 
@@ -25,12 +28,54 @@ import (
 
 var emptyCodeHash = crypto.Keccak256Hash(nil)
 
+const (
+	// DefaultMaxMemoryPages is the number of initial linear memory pages a
+	// contract may declare, if vm.Config.MaxMemoryPages is unset.
+	DefaultMaxMemoryPages = 1024
+
+	// DefaultMaxTableSize is the number of initial table elements a contract
+	// may declare, if vm.Config.MaxTableSize is unset.
+	DefaultMaxTableSize = 8192
+)
+
 type WasmCode struct {
 	Code     []byte
 	Abi      []byte
 	Compiled []byte
 }
 
+// PackDeployPayload assembles the data of a contract creation transaction: the
+// JSON encoded {Code, Abi} container, followed by the packed constructor input.
+func PackDeployPayload(code []byte, abi []byte, input []byte) ([]byte, error) {
+	payload, err := json.Marshal(WasmCode{Code: code, Abi: abi})
+	if err != nil {
+		return nil, err
+	}
+	return append(payload, input...), nil
+}
+
+// UnpackDeployPayload splits the data of a contract creation transaction into
+// the {Code, Abi} container and the packed constructor input.
+func UnpackDeployPayload(data []byte) (*WasmCode, []byte, error) {
+	reader := bytes.NewReader(data)
+	decoder := json.NewDecoder(reader)
+	code := new(WasmCode)
+	if err := decoder.Decode(code); err != nil {
+		return nil, nil, fmt.Errorf("invalid wasm code in payload: %v", err)
+	}
+	// The container ends where the decoder stopped reading, the decoder may
+	// have buffered some of the input after it
+	buffered, _ := ioutil.ReadAll(decoder.Buffered())
+	end := len(data) - reader.Len() - len(buffered)
+
+	// The virtual machine splits the payload at the first '}', reject containers
+	// it would split differently
+	if bytes.IndexByte(data, 0x7d) != end-1 {
+		return nil, nil, errors.New("invalid wasm code in payload: '}' inside the container")
+	}
+	return code, data[end:], nil
+}
+
 type WAVM struct {
 	// Context provides auxiliary blockchain related information
 	vm.Context
@@ -36,11 +36,13 @@ import (
 	"github.com/vntchain/go-vnt/common/math"
 	"github.com/vntchain/go-vnt/core"
 	"github.com/vntchain/go-vnt/core/rawdb"
+	"github.com/vntchain/go-vnt/core/state"
 	"github.com/vntchain/go-vnt/core/types"
 	"github.com/vntchain/go-vnt/core/vm"
 	"github.com/vntchain/go-vnt/core/vm/election"
 	"github.com/vntchain/go-vnt/core/wavm"
 	"github.com/vntchain/go-vnt/core/wavm/utils"
+	"github.com/vntchain/go-vnt/core/wavm/validate"
 	"github.com/vntchain/go-vnt/crypto"
 	"github.com/vntchain/go-vnt/log"
 	"github.com/vntchain/go-vnt/params"
@@ -577,6 +579,31 @@ func (s *PublicBlockChainAPI) GetAbi(ctx context.Context, address common.Address
 	return wasmcode.Abi, state.Error()
 }
 
+// WasmValidationResult is the outcome of the static validation of a wasm contract.
+type WasmValidationResult struct {
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors"`
+}
+
+// ValidateWasm statically validates the data of a contract creation transaction,
+// reporting every problem which would make the contract fail in the virtual machine.
+func (s *PublicBlockChainAPI) ValidateWasm(ctx context.Context, data hexutil.Bytes) (*WasmValidationResult, error) {
+	result := &WasmValidationResult{Valid: true, Errors: []string{}}
+	err := validate.ValidatePayload(data, validate.NewConfig(s.b.GetVMConfig()))
+	if err == nil {
+		return result, nil
+	}
+	result.Valid = false
+	if errs, ok := err.(validate.Errors); ok {
+		for _, err := range errs {
+			result.Errors = append(result.Errors, err.Error())
+		}
+	} else {
+		result.Errors = append(result.Errors, err.Error())
+	}
+	return result, nil
+}
+
 // GetStorageAt returns the storage from the state at the given address, key and
 // block number. The rpc.LatestBlockNumber and rpc.PendingBlockNumber meta block
 // numbers are also allowed.
@@ -589,6 +616,119 @@ func (s *PublicBlockChainAPI) GetStorageAt(ctx context.Context, address common.A
 	return res[:], state.Error()
 }
 
+// AccountResult is the Merkle proof of an account, along with the proofs of
+// some of its storage slots.
+type AccountResult struct {
+	Address      common.Address  `json:"address"`
+	AccountProof []string        `json:"accountProof"`
+	Balance      *hexutil.Big    `json:"balance"`
+	CodeHash     common.Hash     `json:"codeHash"`
+	Nonce        hexutil.Uint64  `json:"nonce"`
+	StorageHash  common.Hash     `json:"storageHash"`
+	StorageProof []StorageResult `json:"storageProof"`
+}
+
+// StorageResult is the Merkle proof of a storage slot.
+type StorageResult struct {
+	Key   string       `json:"key"`
+	Value *hexutil.Big `json:"value"`
+	Proof []string     `json:"proof"`
+}
+
+// GetProof returns the Merkle proof of the account of the given address and of
+// its given storage keys, in the state of the given block number. Like the rest
+// of the chain API, the proof methods are served in the core namespace, e.g. as
+// core_getProof, and not as vnt_getProof.
+func (s *PublicBlockChainAPI) GetProof(ctx context.Context, address common.Address, storageKeys []string, blockNr rpc.BlockNumber) (*AccountResult, error) {
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	keys := make([]common.Hash, len(storageKeys))
+	for i, key := range storageKeys {
+		keys[i] = common.HexToHash(key)
+	}
+	return proveAccount(state, address, keys)
+}
+
+// GetCandidateProof returns the Merkle proof of the candidate record of the given
+// address, held in the storage of the election contract. Clients derive the
+// storage keys of the record themselves to verify the proof.
+func (s *PublicBlockChainAPI) GetCandidateProof(ctx context.Context, address common.Address, blockNr rpc.BlockNumber) (*AccountResult, error) {
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	return proveAccount(state, election.ContractAddr(), election.CandidateStorageKeys(state, address))
+}
+
+// GetVoterProof returns the Merkle proof of the voter record of the given address,
+// held in the storage of the election contract.
+func (s *PublicBlockChainAPI) GetVoterProof(ctx context.Context, address common.Address, blockNr rpc.BlockNumber) (*AccountResult, error) {
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	return proveAccount(state, election.ContractAddr(), election.VoterStorageKeys(state, address))
+}
+
+// GetStakeProof returns the Merkle proof of the stake record of the given address,
+// held in the storage of the election contract.
+func (s *PublicBlockChainAPI) GetStakeProof(ctx context.Context, address common.Address, blockNr rpc.BlockNumber) (*AccountResult, error) {
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	return proveAccount(state, election.ContractAddr(), election.StakeStorageKeys(state, address))
+}
+
+// proveAccount builds the proofs of the account of address and of its storage
+// keys. The storage of a missing account is left unproven, since the account
+// proof already shows it empty.
+func proveAccount(statedb *state.StateDB, address common.Address, keys []common.Hash) (*AccountResult, error) {
+	proof, err := statedb.GetProof(address)
+	if err != nil {
+		return nil, err
+	}
+	result := &AccountResult{
+		Address:      address,
+		AccountProof: toHexSlice(proof),
+		Balance:      (*hexutil.Big)(statedb.GetBalance(address)),
+		CodeHash:     statedb.GetCodeHash(address),
+		Nonce:        hexutil.Uint64(statedb.GetNonce(address)),
+		StorageProof: make([]StorageResult, len(keys)),
+	}
+	storageTrie := statedb.StorageTrie(address)
+	if storageTrie != nil {
+		result.StorageHash = storageTrie.Hash()
+	}
+	for i, key := range keys {
+		result.StorageProof[i] = StorageResult{
+			Key:   hexutil.Encode(key[:]),
+			Value: (*hexutil.Big)(statedb.GetState(address, key).Big()),
+			Proof: []string{},
+		}
+		if storageTrie == nil {
+			continue
+		}
+		storageProof, err := statedb.GetStorageProof(address, key)
+		if err != nil {
+			return nil, err
+		}
+		result.StorageProof[i].Proof = toHexSlice(storageProof)
+	}
+	return result, statedb.Error()
+}
+
+// toHexSlice encodes the nodes of a proof in hex.
+func toHexSlice(b [][]byte) []string {
+	r := make([]string, len(b))
+	for i := range b {
+		r[i] = hexutil.Encode(b[i])
+	}
+	return r
+}
+
 // CallArgs represents the arguments for a call.
 type CallArgs struct {
 	From     common.Address  `json:"from"`
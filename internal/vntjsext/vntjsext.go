@@ -77,6 +77,36 @@ vnt._extend({
 			call: 'admin_removePeer',
 			params: 1
 		}),
+		new vnt._extend.Method({
+			name: 'addTrustedPeer',
+			call: 'admin_addTrustedPeer',
+			params: 1
+		}),
+		new vnt._extend.Method({
+			name: 'removeTrustedPeer',
+			call: 'admin_removeTrustedPeer',
+			params: 1
+		}),
+		new vnt._extend.Method({
+			name: 'ban',
+			call: 'admin_ban',
+			params: 2
+		}),
+		new vnt._extend.Method({
+			name: 'unban',
+			call: 'admin_unban',
+			params: 1
+		}),
+		new vnt._extend.Method({
+			name: 'allowPeer',
+			call: 'admin_allowPeer',
+			params: 1
+		}),
+		new vnt._extend.Method({
+			name: 'disallowPeer',
+			call: 'admin_disallowPeer',
+			params: 1
+		}),
 		new vnt._extend.Method({
 			name: 'exportChain',
 			call: 'admin_exportChain',
@@ -88,6 +118,11 @@ vnt._extend({
 			call: 'admin_importChain',
 			params: 1
 		}),
+		new vnt._extend.Method({
+			name: 'checkpoint',
+			call: 'admin_checkpoint',
+			params: 0
+		}),
 		new vnt._extend.Method({
 			name: 'sleepBlocks',
 			call: 'admin_sleepBlocks',
@@ -127,6 +162,14 @@ vnt._extend({
 			name: 'datadir',
 			getter: 'admin_datadir'
 		}),
+		new vnt._extend.Property({
+			name: 'bans',
+			getter: 'admin_bans'
+		}),
+		new vnt._extend.Property({
+			name: 'allowList',
+			getter: 'admin_allowList'
+		}),
 	]
 });
 `
@@ -373,6 +416,30 @@ vnt._extend({
 			params: 2,
 			inputFormatter: [vnt._extend.formatters.inputBlockNumberFormatter, vnt._extend.utils.toHex]
 		}),
+		new vnt._extend.Method({
+			name: 'getProof',
+			call: 'core_getProof',
+			params: 3,
+			inputFormatter: [vnt._extend.formatters.inputAddressFormatter, null, vnt._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new vnt._extend.Method({
+			name: 'getCandidateProof',
+			call: 'core_getCandidateProof',
+			params: 2,
+			inputFormatter: [vnt._extend.formatters.inputAddressFormatter, vnt._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new vnt._extend.Method({
+			name: 'getVoterProof',
+			call: 'core_getVoterProof',
+			params: 2,
+			inputFormatter: [vnt._extend.formatters.inputAddressFormatter, vnt._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new vnt._extend.Method({
+			name: 'getStakeProof',
+			call: 'core_getStakeProof',
+			params: 2,
+			inputFormatter: [vnt._extend.formatters.inputAddressFormatter, vnt._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
 	],
 	properties: [
 		new vnt._extend.Property({
@@ -102,6 +102,12 @@ func (b *LesApiBackend) GetTd(hash common.Hash) *big.Int {
 	return b.vnt.blockchain.GetTdByHash(hash)
 }
 
+// GetVMConfig returns the default configuration, as light clients don't
+// execute blocks with a configured virtual machine.
+func (b *LesApiBackend) GetVMConfig() *vm.Config {
+	return &vm.Config{}
+}
+
 func (b *LesApiBackend) GetVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header, vmCfg vm.Config) (vm.VM, func() error, error) {
 	state.SetBalance(msg.From(), math.MaxBig256)
 	context := core.NewVMContext(msg, header, b.vnt.blockchain, nil)
@@ -123,6 +123,13 @@ func New(ctx *node.ServiceContext, config *vnt.Config) (*LightVnt, error) {
 		leth.blockchain.SetHead(compat.RewindTo)
 		rawdb.WriteChainConfig(chainDb, genesisHash, chainConfig)
 	}
+	checkpoint, err := core.LoadCheckpoint(config.CheckpointFile, config.CheckpointSigners, config.CheckpointThreshold, config.CheckpointTrustCert, rawdb.ReadHeader(chainDb, genesisHash, 0), leth.engine)
+	if err != nil {
+		return nil, err
+	}
+	if err := leth.blockchain.SetCheckpoint(checkpoint); err != nil {
+		return nil, err
+	}
 
 	leth.txPool = light.NewTxPool(leth.chainConfig, leth.blockchain, leth.relay)
 	if leth.protocolManager, err = NewProtocolManager(leth.chainConfig, true, ClientProtocolVersions, config.NetworkId, leth.eventMux, leth.engine, leth.peers, leth.blockchain, nil, chainDb, leth.odr, leth.relay, leth.serverPool, quitSync, &leth.wg); err != nil {
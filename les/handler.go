@@ -161,9 +161,10 @@ func NewProtocolManager(chainConfig *params.ChainConfig, lightSync bool, protoco
 		version := version // Closure for the run
 		fmt.Println(version)
 		manager.SubProtocols = append(manager.SubProtocols, vntp2p.Protocol{
-			Name:    "les",
-			Version: version,
-			Length:  ProtocolLengths[version],
+			Name:       "les",
+			Version:    version,
+			Length:     ProtocolLengths[version],
+			MaxMsgSize: ProtocolMaxMsgSize,
 			Run: func(p *vntp2p.Peer, rw vntp2p.MsgReadWriter) error {
 				var entry *poolEntry
 				peer := manager.newPeer(int(version), networkId, p, rw)
@@ -203,9 +204,9 @@ func NewProtocolManager(chainConfig *params.ChainConfig, lightSync bool, protoco
 		return nil, errIncompatibleConfig
 	}
 
-	removePeer := manager.removePeer
+	removePeer := func(id libp2p.ID, reason error) { manager.removePeer(id) }
 	if disableClientRemovePeer {
-		removePeer = func(id libp2p.ID) {}
+		removePeer = func(id libp2p.ID, reason error) {}
 	}
 
 	if lightSync {
@@ -27,6 +27,7 @@ import (
 	"github.com/hashicorp/golang-lru"
 	"github.com/vntchain/go-vnt/common"
 	"github.com/vntchain/go-vnt/consensus"
+	"github.com/vntchain/go-vnt/consensus/dpos"
 	"github.com/vntchain/go-vnt/core"
 	"github.com/vntchain/go-vnt/core/rawdb"
 	"github.com/vntchain/go-vnt/core/state"
@@ -43,6 +44,21 @@ var (
 	blockCacheLimit = 256
 )
 
+// ErrUntrackedWitnesses is returned by VerifyWitnessedHeader if the header
+// doesn't carry the witness list followed by the chain.
+var ErrUntrackedWitnesses = errors.New("header not of the tracked witness list")
+
+// witnessProofTimeout is the time allowed to retrieve the election state
+// proving a witness list update.
+const witnessProofTimeout = time.Minute
+
+// witnessVerifier is implemented by engines whose blocks are endorsed by a set
+// of witnesses, like DPoS. Light clients check the witness list transitions
+// and the commit certificates of the headers instead of executing the blocks.
+type witnessVerifier interface {
+	VerifyLightHeader(header, parent *types.Header, parentState func() (*state.StateDB, error)) error
+}
+
 // LightChain represents a canonical chain that by default only handles block
 // headers, downloading block bodies and receipts on demand through an ODR
 // interface. It only does header validation during chain insertion.
@@ -55,6 +71,7 @@ type LightChain struct {
 	chainHeadFeed event.Feed
 	scope         event.SubscriptionScope
 	genesisBlock  *types.Block
+	witnesses     *dpos.WitnessTracker // Follows the witness list of the chain, nil if not DPoS
 
 	mu      sync.RWMutex
 	chainmu sync.RWMutex
@@ -104,6 +121,10 @@ func NewLightChain(odr OdrBackend, config *params.ChainConfig, engine consensus.
 	if err := bc.loadLastState(); err != nil {
 		return nil, err
 	}
+	// The witness list of the head was verified when it was inserted
+	if d, ok := engine.(*dpos.Dpos); ok {
+		bc.witnesses = dpos.NewWitnessTracker(d, bc.CurrentHeader())
+	}
 	// Check the current state of the block hashes and make sure that we do not have any of the bad blocks in our chain
 	for hash := range core.BadHashes {
 		if header := bc.GetHeaderByHash(hash); header != nil {
@@ -357,6 +378,11 @@ func (self *LightChain) InsertHeaderChain(chain []*types.Header, checkFreq int)
 	if i, err := self.hc.ValidateHeaderChain(chain, checkFreq); err != nil {
 		return i, err
 	}
+	if v, ok := self.engine.(witnessVerifier); ok {
+		if i, err := self.verifyWitnesses(v, chain); err != nil {
+			return i, err
+		}
+	}
 
 	// Make sure only one thread manipulates the chain at once
 	self.chainmu.Lock()
@@ -391,6 +417,109 @@ func (self *LightChain) InsertHeaderChain(chain []*types.Header, checkFreq int)
 	return i, err
 }
 
+// verifyWitnesses checks the witness lists and commit certificates of a
+// contiguous chain of headers. Headers of the witness list followed by the
+// tracker only need their commit certificate, blocks updating it advance the
+// tracker. Other headers, e.g. of side chains, are checked against their
+// parent. The election state proving a witness list update is retrieved
+// through ODR.
+func (self *LightChain) verifyWitnesses(v witnessVerifier, chain []*types.Header) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), witnessProofTimeout)
+	defer cancel()
+
+	cp := self.hc.Checkpoint()
+	for i, header := range chain {
+		if header.Number.Sign() == 0 {
+			continue
+		}
+		// Blocks up to the trusted checkpoint need no proof
+		if cp != nil && header.Number.Uint64() <= cp.Number {
+			if self.witnesses != nil && !self.witnesses.Tracks(header) {
+				self.witnesses.Reset(header)
+			}
+			continue
+		}
+		var parent *types.Header
+		if i > 0 {
+			parent = chain[i-1]
+		} else {
+			parent = self.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+		}
+		if parent == nil {
+			return i, consensus.ErrUnknownAncestor
+		}
+		parentState := func() (*state.StateDB, error) {
+			return NewState(ctx, parent, self.odr), nil
+		}
+		var err error
+		switch t := self.witnesses; {
+		case t != nil && t.Tracks(header):
+			err = t.VerifyHeader(header)
+		case t != nil && (parent.Hash() == t.Update().Hash() || t.Tracks(parent)):
+			err = t.Advance(parent, header, parentState)
+		default:
+			err = v.VerifyLightHeader(header, parent, parentState)
+		}
+		if err != nil {
+			log.Warn("Rejected light header", "number", header.Number, "hash", header.Hash(), "err", err)
+			return i, err
+		}
+	}
+	return 0, nil
+}
+
+// TracksWitnesses reports whether the header carries the witness list followed
+// by the chain, i.e. whether VerifyWitnessedHeader can check it.
+func (self *LightChain) TracksWitnesses(header *types.Header) bool {
+	return self.witnesses != nil && self.witnesses.Tracks(header)
+}
+
+// VerifyWitnessedHeader checks a header of the witness list followed by the
+// chain by its commit certificate alone, without its ancestors. It returns
+// ErrUntrackedWitnesses if the header carries another witness list.
+func (self *LightChain) VerifyWitnessedHeader(header *types.Header) error {
+	if !self.TracksWitnesses(header) {
+		return ErrUntrackedWitnesses
+	}
+	return self.witnesses.VerifyHeader(header)
+}
+
+// AdvanceWitnesses moves the witness list followed by the chain to the one
+// introduced by the update block, skipping the headers in between. The parent
+// of the update has to carry the current list, the election state of the
+// parent is retrieved through ODR if the new list has to be proven by it.
+func (self *LightChain) AdvanceWitnesses(ctx context.Context, parent, update *types.Header) error {
+	if self.witnesses == nil {
+		return ErrUntrackedWitnesses
+	}
+	return self.witnesses.Advance(parent, update, func() (*state.StateDB, error) {
+		return NewState(ctx, parent, self.odr), nil
+	})
+}
+
+// Witnesses returns the block which introduced the witness list followed by
+// the chain, nil if the engine has no witnesses.
+func (self *LightChain) Witnesses() *types.Header {
+	if self.witnesses == nil {
+		return nil
+	}
+	return self.witnesses.Update()
+}
+
+// SetCheckpoint sets the trusted checkpoint, headers conflicting with it are
+// refused and the witness lists of the headers up to it are not verified.
+func (self *LightChain) SetCheckpoint(cp *params.Checkpoint) error {
+	self.chainmu.Lock()
+	defer self.chainmu.Unlock()
+
+	return self.hc.SetCheckpoint(cp)
+}
+
+// Checkpoint retrieves the trusted checkpoint, nil if none.
+func (self *LightChain) Checkpoint() *params.Checkpoint {
+	return self.hc.Checkpoint()
+}
+
 // CurrentHeader retrieves the current head header of the canonical chain. The
 // header is retrieved from the HeaderChain's internal cache.
 func (self *LightChain) CurrentHeader() *types.Header {
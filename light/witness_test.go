@@ -0,0 +1,181 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package light
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vntchain/go-vnt/accounts/abi"
+	"github.com/vntchain/go-vnt/accounts/abi/bind/backends"
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/consensus/dpos"
+	"github.com/vntchain/go-vnt/core"
+	"github.com/vntchain/go-vnt/core/rawdb"
+	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/core/vm/election"
+	"github.com/vntchain/go-vnt/crypto"
+	"github.com/vntchain/go-vnt/vntdb"
+)
+
+const electionABIJSON = `[
+{"inputs":[{"name":"candidate","type":"address[]"}],"name":"voteWitnesses","outputs":[],"type":"function"},
+{"inputs":[{"name":"stakeCount","type":"uint256"}],"name":"stake","outputs":[],"type":"function"}
+]`
+
+// newRotatedChain produces a simulated chain electing a new witness, and
+// returns its headers and the block introducing the new witness list.
+func newRotatedChain(t *testing.T) (*backends.SimulatedBackend, []*types.Header, *types.Header) {
+	const witnessesNum = 4
+
+	keys := backends.NewWitnessKeys(witnessesNum + 1)
+	candidate := keys[witnessesNum]
+	candidateAddr := crypto.PubkeyToAddress(candidate.PublicKey)
+	key, _ := crypto.GenerateKey()
+
+	sim := backends.NewSimulatedBackendWithCandidates(core.GenesisAlloc{
+		crypto.PubkeyToAddress(key.PublicKey): {Balance: new(big.Int).Mul(big.NewInt(1e18), big.NewInt(1e6))},
+	}, keys[:witnessesNum], keys[witnessesNum:])
+
+	electionABI, err := abi.JSON(strings.NewReader(electionABIJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stake, _ := electionABI.Pack("stake", big.NewInt(1000))
+	vote, _ := electionABI.Pack("voteWitnesses", []common.Address{candidateAddr})
+	for nonce, input := range [][]byte{stake, vote} {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(nonce), election.ContractAddr(), common.Big0, 100000, big.NewInt(1), input), types.HomesteadSigner{}, key)
+		if err := sim.SendTransaction(context.Background(), tx); err != nil {
+			t.Fatalf("failed to send election transaction: %v", err)
+		}
+		sim.Commit()
+	}
+	if err := sim.AdjustTime(time.Duration(3*witnessesNum*2) * time.Second); err != nil {
+		t.Fatalf("failed to adjust time: %v", err)
+	}
+	for i := 0; i < 2*witnessesNum; i++ {
+		sim.Commit()
+	}
+	var (
+		headers []*types.Header
+		update  *types.Header
+	)
+	chain := sim.Blockchain()
+	for i := uint64(1); i <= chain.CurrentBlock().NumberU64(); i++ {
+		header := chain.GetHeaderByNumber(i)
+		if update == nil && !sameWitnessList(header.Witnesses, chain.Genesis().Witnesses()) {
+			update = header
+		}
+		headers = append(headers, header)
+	}
+	if update == nil {
+		t.Fatalf("witness list not updated")
+	}
+	return sim, headers, update
+}
+
+func sameWitnessList(a, b []common.Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// newWitnessLightChain creates a light chain with the genesis of the simulated
+// chain, verifying witnesses with the DPoS engine.
+func newWitnessLightChain(t *testing.T, sim *backends.SimulatedBackend) *LightChain {
+	var (
+		db      = vntdb.NewMemDatabase()
+		genesis = sim.Blockchain().Genesis()
+		config  = sim.Blockchain().Config()
+	)
+	rawdb.WriteTd(db, genesis.Hash(), 0, genesis.Difficulty())
+	rawdb.WriteBlock(db, genesis)
+	rawdb.WriteReceipts(db, genesis.Hash(), 0, nil)
+	rawdb.WriteCanonicalHash(db, genesis.Hash(), 0)
+	rawdb.WriteHeadBlockHash(db, genesis.Hash())
+	rawdb.WriteHeadHeaderHash(db, genesis.Hash())
+	rawdb.WriteChainConfig(db, genesis.Hash(), config)
+
+	lc, err := NewLightChain(&dummyOdr{db: db}, config, dpos.New(config.Dpos, db))
+	if err != nil {
+		t.Fatalf("failed to create light chain: %v", err)
+	}
+	return lc
+}
+
+// Tests that a light chain follows the witness list across a rotation while
+// skipping the headers in between.
+func TestSkipWitnessRotation(t *testing.T) {
+	sim, headers, update := newRotatedChain(t)
+	lc := newWitnessLightChain(t, sim)
+
+	head := headers[len(headers)-1]
+	parent := headers[update.Number.Uint64()-2]
+
+	// Headers of the genesis witnesses are checked by their certificates alone
+	if err := lc.VerifyWitnessedHeader(headers[0]); err != nil {
+		t.Fatalf("genesis witness header rejected: %v", err)
+	}
+	if err := lc.VerifyWitnessedHeader(head); err != ErrUntrackedWitnesses {
+		t.Fatalf("header of the new witnesses error mismatch: have %v, want %v", err, ErrUntrackedWitnesses)
+	}
+	// Jump to the new witness list from the last header of the old one
+	if err := lc.AdvanceWitnesses(context.Background(), parent, update); err != nil {
+		t.Fatalf("failed to advance witnesses: %v", err)
+	}
+	if lc.Witnesses().Hash() != update.Hash() {
+		t.Fatalf("witness update mismatch: have #%d, want #%d", lc.Witnesses().Number, update.Number)
+	}
+	if err := lc.VerifyWitnessedHeader(head); err != nil {
+		t.Fatalf("header of the new witnesses rejected: %v", err)
+	}
+	if err := lc.VerifyWitnessedHeader(headers[0]); err != ErrUntrackedWitnesses {
+		t.Fatalf("header of the old witnesses error mismatch: have %v, want %v", err, ErrUntrackedWitnesses)
+	}
+	// A header lacking the commit certificate is refused
+	forged := types.CopyHeader(head)
+	forged.CmtMsges = forged.CmtMsges[:1]
+	if err := lc.VerifyWitnessedHeader(forged); err == nil {
+		t.Fatalf("header without certificate accepted")
+	}
+}
+
+// Tests that inserting a header chain across a rotation advances the witness
+// list followed by the light chain.
+func TestInsertWitnessRotation(t *testing.T) {
+	sim, headers, update := newRotatedChain(t)
+	lc := newWitnessLightChain(t, sim)
+
+	if _, err := lc.InsertHeaderChain(headers, 1); err != nil {
+		t.Fatalf("failed to insert headers: %v", err)
+	}
+	if lc.CurrentHeader().Hash() != headers[len(headers)-1].Hash() {
+		t.Fatalf("head mismatch: have #%d, want #%d", lc.CurrentHeader().Number, len(headers))
+	}
+	if lc.Witnesses().Hash() != update.Hash() {
+		t.Fatalf("witness update mismatch: have #%d, want #%d", lc.Witnesses().Number, update.Number)
+	}
+}
@@ -147,6 +147,12 @@ func (self *Miner) SetExtra(extra []byte) error {
 	return nil
 }
 
+// SetOnDemand sets whether the miner only seals blocks containing transactions,
+// which are sealed as soon as they enter the transaction pool.
+func (self *Miner) SetOnDemand(onDemand bool) {
+	self.worker.setOnDemand(onDemand)
+}
+
 // Pending returns the currently pending block and associated state.
 func (self *Miner) Pending() (*types.Block, *state.StateDB) {
 	return self.worker.pending()
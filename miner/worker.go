@@ -110,6 +110,8 @@ type worker struct {
 
 	coinbase common.Address
 	extra    []byte
+	onDemand bool          // Skip sealing empty blocks, used by developer mode
+	sealing  *types.Header // Header of the last block pushed for sealing on demand
 
 	currentMu sync.Mutex
 	current   *Work
@@ -179,6 +181,25 @@ func (self *worker) setExtra(extra []byte) {
 	self.extra = extra
 }
 
+func (self *worker) setOnDemand(onDemand bool) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.onDemand = onDemand
+}
+
+// sealOnDemand reports whether new transactions should be sealed right away,
+// which is the case if mining on demand and no block is being sealed on top
+// of the current head.
+func (self *worker) sealOnDemand() bool {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if !self.onDemand || atomic.LoadInt32(&self.mining) == 0 {
+		return false
+	}
+	return self.sealing == nil || self.sealing.Number.Cmp(self.chain.CurrentBlock().Number()) <= 0
+}
+
 func (self *worker) pending() (*types.Block, *state.StateDB) {
 	if atomic.LoadInt32(&self.mining) == 0 {
 		// return a snapshot to avoid contention on currentMu mutex
@@ -216,16 +237,17 @@ func (self *worker) start() {
 		dp.InitBft(self.SendBftMsg, self.SendBftPeerChangeMsg, self.chain.VerifyBlock, self.writeBlock)
 		// 刚启动节点的bft节点设置
 		currentRoot := self.chain.CurrentHeader().Root
+		var witnesses []common.Address
 		witnessesUrl := self.chain.Config().Dpos.WitnessesUrl
 		if db, err := self.chain.StateAt(currentRoot); err != nil {
 			log.Error("get current db error", "err", err)
 		} else {
-			_, urls := dp.GetWitnessesFromStateDB(db)
+			addrs, urls := dp.GetWitnessesFromStateDB(db)
 			if len(urls) > 0 {
-				witnessesUrl = urls
+				witnesses, witnessesUrl = addrs, urls
 			}
 		}
-		self.SendBftPeerChangeMsg(witnessesUrl)
+		self.SendBftPeerChangeMsg(witnesses, witnessesUrl)
 	}
 
 	// spin up agents
@@ -297,7 +319,10 @@ func (self *worker) update() {
 			// Note all transactions received may not be continuous with transactions
 			// already included in the current mining block. These transactions will
 			// be automatically eliminated.
-			if self.config.Dpos == nil && atomic.LoadInt32(&self.mining) == 0 {
+			if self.config.Dpos != nil && self.sealOnDemand() {
+				// Seal the new transactions right away instead of in the next round
+				self.commitNewWork()
+			} else if self.config.Dpos == nil && atomic.LoadInt32(&self.mining) == 0 {
 				self.currentMu.Lock()
 				txs := make(map[common.Address]types.Transactions)
 				for _, tx := range ev.Txs {
@@ -415,9 +440,10 @@ func (self *worker) SendBftMsg(msg types.ConsensusMsg) {
 		}})
 }
 
-func (self *worker) SendBftPeerChangeMsg(urls []string) {
+func (self *worker) SendBftPeerChangeMsg(witnesses []common.Address, urls []string) {
 	self.mux.Post(core.BftPeerChangeEvent{
-		Urls: urls,
+		Witnesses: witnesses,
+		Urls:      urls,
 	})
 }
 
@@ -534,8 +560,12 @@ func (self *worker) commitNewWork() {
 	blockheaderjson, _ := json.Marshal(work.Block.Header())
 	blocktxjson, _ := json.Marshal(work.Block.Transactions())
 	log.Debug("worker", "func", "commitNewWork", "block header", string(blockheaderjson), "block tx", string(blocktxjson))
+
+	// Nothing to seal if we're mining on demand and there are no transactions.
+	skip := self.onDemand && work.tcount == 0
+
 	// We only care about logging if we're actually mining.
-	if atomic.LoadInt32(&self.mining) == 1 {
+	if atomic.LoadInt32(&self.mining) == 1 && !skip {
 		log.Info("Commit new mining work", "number", work.Block.Number(), "txs", work.tcount, "elapsed", common.PrettyDuration(time.Since(tstart)))
 		self.unconfirmed.Shift(work.Block.NumberU64() - 1)
 	}
@@ -547,6 +577,13 @@ func (self *worker) commitNewWork() {
 		log.Debug("Failed to prepare header for mining", "preErr", preErr)
 		return
 	}
+	if skip {
+		log.Trace("No pending transactions, skip sealing", "number", work.Block.Number())
+		return
+	}
+	if self.onDemand && atomic.LoadInt32(&self.mining) == 1 {
+		self.sealing = work.Block.Header()
+	}
 
 	// updateSnapshot() is time consuming. If push() before updateSnapshot(), Gvnt may be
 	// stop for concurrent map iteration and map write. After push(), a block generated
@@ -0,0 +1,104 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/vntchain/go-vnt/accounts"
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/consensus/dpos"
+	"github.com/vntchain/go-vnt/core"
+	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/core/vm"
+	"github.com/vntchain/go-vnt/crypto"
+	"github.com/vntchain/go-vnt/event"
+	"github.com/vntchain/go-vnt/vntdb"
+)
+
+// testBackend implements Backend on top of a local chain and transaction pool.
+type testBackend struct {
+	db     vntdb.Database
+	chain  *core.BlockChain
+	txPool *core.TxPool
+}
+
+func (b *testBackend) AccountManager() *accounts.Manager { return nil }
+func (b *testBackend) BlockChain() *core.BlockChain     { return b.chain }
+func (b *testBackend) TxPool() *core.TxPool             { return b.txPool }
+func (b *testBackend) ChainDb() vntdb.Database          { return b.db }
+
+// Tests that a developer chain mining on demand doesn't produce empty blocks,
+// but seals a new transaction as soon as it enters the pool.
+func TestSealOnDemand(t *testing.T) {
+	var (
+		key, _  = crypto.GenerateKey()
+		witness = crypto.PubkeyToAddress(key.PublicKey)
+		db      = vntdb.NewMemDatabase()
+		genesis = core.DeveloperGenesisBlock(1, witness)
+	)
+	genesis.MustCommit(db)
+
+	engine := dpos.New(genesis.Config.Dpos, db)
+	engine.Authorize(witness, func(account accounts.Account, hash []byte) ([]byte, error) {
+		return crypto.Sign(hash, key)
+	})
+	chain, err := core.NewBlockChain(db, nil, genesis.Config, engine, vm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+
+	poolConfig := core.DefaultTxPoolConfig
+	poolConfig.Journal = ""
+	txPool := core.NewTxPool(poolConfig, genesis.Config, chain)
+	defer txPool.Stop()
+
+	heads := make(chan core.ChainHeadEvent, 16)
+	sub := chain.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	miner := New(&testBackend{db, chain, txPool}, genesis.Config, new(event.TypeMux), engine)
+	miner.SetOnDemand(true)
+	miner.Start(witness)
+	defer miner.Stop()
+
+	// Without transactions the rounds pass without any block
+	select {
+	case ev := <-heads:
+		t.Fatalf("empty block %d sealed", ev.Block.NumberU64())
+	case <-time.After(3 * time.Second):
+	}
+
+	tx, err := types.SignTx(types.NewTransaction(0, common.HexToAddress("0x1234"), big.NewInt(1), 21000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := txPool.AddLocal(tx); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	select {
+	case ev := <-heads:
+		if txs := ev.Block.Transactions(); len(txs) != 1 || txs[0].Hash() != tx.Hash() {
+			t.Fatalf("sealed block transactions mismatch: have %v, want [%x]", txs, tx.Hash())
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatalf("transaction not sealed right away")
+	}
+}
@@ -75,6 +75,113 @@ func (api *PrivateAdminAPI) RemovePeer(url string) (bool, error) {
 	return true, nil
 }
 
+// AddTrustedPeer allows a remote node to always connect, even if slots are full.
+// The node is redialled whenever the connection is lost.
+func (api *PrivateAdminAPI) AddTrustedPeer(url string) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	node, err := vntp2p.ParseNode(url)
+	if err != nil {
+		return false, fmt.Errorf("invalid enode: %v", err)
+	}
+	server.AddTrustedPeer(node)
+	return true, nil
+}
+
+// RemoveTrustedPeer removes a remote node from the trusted peer set, but it
+// does not disconnect it automatically.
+func (api *PrivateAdminAPI) RemoveTrustedPeer(url string) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	node, err := vntp2p.ParseNode(url)
+	if err != nil {
+		return false, fmt.Errorf("invalid enode: %v", err)
+	}
+	server.RemoveTrustedPeer(node)
+	return true, nil
+}
+
+// Ban disconnects and bans a peer ID, IP address or IP network in CIDR notation
+// for the given number of seconds. Bans are kept across restarts.
+func (api *PrivateAdminAPI) Ban(target string, seconds uint64) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	if err := server.Ban(target, time.Duration(seconds)*time.Second, "admin"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Unban lifts the ban of a peer ID, IP address or IP network.
+func (api *PrivateAdminAPI) Unban(target string) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	if err := server.Unban(target); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Bans returns the active bans of peers and IP networks.
+func (api *PrivateAdminAPI) Bans() ([]*vntp2p.Ban, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	return server.Bans(), nil
+}
+
+// AllowPeer adds a node url or peer ID to the allow-list of a permissioned
+// network, the list is kept in the permissioned nodes file.
+func (api *PrivateAdminAPI) AllowPeer(entry string) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	if err := server.AllowPeer(entry); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DisallowPeer removes a node url or peer ID from the permissioned nodes file
+// and disconnects the peer.
+func (api *PrivateAdminAPI) DisallowPeer(entry string) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	if err := server.DisallowPeer(entry); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// AllowList returns the allow-list of a permissioned network.
+func (api *PrivateAdminAPI) AllowList() (*vntp2p.AllowListInfo, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	return server.AllowList()
+}
+
 // PeerEvents creates an RPC subscription which receives peer events from the
 // node's p2p.Server
 func (api *PrivateAdminAPI) PeerEvents(ctx context.Context) (*rpc.Subscription, error) {
@@ -40,6 +40,8 @@ const (
 	datadirStaticNodes     = "static-nodes.json"  // Path within the datadir to the static node list
 	datadirTrustedNodes    = "trusted-nodes.json" // Path within the datadir to the trusted node list
 	datadirNodeDatabase    = "nodes"              // Path within the datadir to store the node infos
+
+	datadirPermissionedNodes = "permissioned-nodes.json" // Path within the datadir to the allow-list of a permissioned network
 )
 
 // Config represents a small collection of configuration values to fine tune the
@@ -65,6 +67,11 @@ type Config struct {
 	// in memory.
 	DataDir string
 
+	// DBEngine is the engine the databases are kept with, one of vntdb.Engines.
+	// Existing databases are opened with the engine they were created with if
+	// it's empty, and new ones are kept by LevelDB.
+	DBEngine string `toml:",omitempty"`
+
 	// Configuration of peer-to-peer networking.
 	P2P vntp2p.Config
 
@@ -84,6 +91,10 @@ type Config struct {
 	// NoUSB disables hardware wallet monitoring and connectivity.
 	NoUSB bool `toml:",omitempty"`
 
+	// NodeKeyPassphrase encrypts the node key stored in the datadir with the
+	// scrypt scheme of the keystore. The key is stored in plain if it's empty.
+	NodeKeyPassphrase string `toml:"-"`
+
 	// IPCPath is the requested location to place the IPC endpoint. If the path is
 	// a simple file name, it is placed inside the data directory (or on the root
 	// pipe path on Windows), whereas if it's a resolvable path name (absolute or
@@ -293,7 +304,8 @@ func (c *Config) instanceDir() string {
 
 // NodeKey retrieves the currently configured private key of the node, checking
 // first any manually set key, falling back to the one found in the configured
-// data folder. If no key can be found, a new one is generated.
+// data folder. A key left in the p2p datastore by older versions is moved to
+// the data folder. If no key can be found, a new one is generated.
 func (c *Config) NodeKey() *ecdsa.PrivateKey {
 	// Use any specifically configured key.
 	if c.P2P.PrivateKey != nil {
@@ -308,22 +320,42 @@ func (c *Config) NodeKey() *ecdsa.PrivateKey {
 		return key
 	}
 
-	keyfile := c.resolvePath(datadirPrivateKey)
-	if key, err := crypto.LoadECDSA(keyfile); err == nil {
+	instanceDir := filepath.Join(c.DataDir, c.name())
+	keyfile := filepath.Join(instanceDir, datadirPrivateKey)
+
+	// Older versions ran with the key kept in the p2p datastore, it is the
+	// identity known to the network and moved to the key file, unless that
+	// holds a different key.
+	datastore := c.P2P.NodeDatabase
+	if datastore == "" {
+		datastore = c.DataDir
+	}
+	key, err := vntp2p.MigrateNodeKey(datastore, c.DBEngine, keyfile, c.NodeKeyPassphrase)
+	if err != nil {
+		log.Crit(fmt.Sprintf("Failed to migrate node key: %v", err))
+	}
+	if key != nil {
+		return key
+	}
+	keyfile = c.resolvePath(datadirPrivateKey)
+	if _, err := os.Stat(keyfile); err == nil {
+		key, err := vntp2p.LoadNodeKey(keyfile, c.NodeKeyPassphrase)
+		if err != nil {
+			log.Crit(fmt.Sprintf("Failed to load node key: %v", err))
+		}
 		return key
 	}
 	// No persistent key found, generate and store a new one.
-	key, err := crypto.GenerateKey()
+	key, err = crypto.GenerateKey()
 	if err != nil {
 		log.Crit(fmt.Sprintf("Failed to generate node key: %v", err))
 	}
-	instanceDir := filepath.Join(c.DataDir, c.name())
 	if err := os.MkdirAll(instanceDir, 0700); err != nil {
 		log.Error(fmt.Sprintf("Failed to persist node key: %v", err))
 		return key
 	}
 	keyfile = filepath.Join(instanceDir, datadirPrivateKey)
-	if err := crypto.SaveECDSA(keyfile, key); err != nil {
+	if err := vntp2p.SaveNodeKey(keyfile, key, c.NodeKeyPassphrase); err != nil {
 		log.Error(fmt.Sprintf("Failed to persist node key: %v", err))
 	}
 	return key
@@ -339,6 +371,15 @@ func (c *Config) TrustedNodes() []*vntp2p.Node {
 	return c.parsePersistentNodes(c.resolvePath(datadirTrustedNodes))
 }
 
+// PermissionedNodesFile returns the path of the allow-list of a permissioned
+// network within the data directory, or "" if there is none.
+func (c *Config) PermissionedNodesFile() string {
+	if c.DataDir == "" {
+		return ""
+	}
+	return c.resolvePath(datadirPermissionedNodes)
+}
+
 // parsePersistentNodes parses a list of discovery node URLs loaded from a .json
 // file from within the data directory.
 func (c *Config) parsePersistentNodes(path string) []*vntp2p.Node {
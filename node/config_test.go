@@ -148,3 +148,36 @@ func TestNodeKeyPersistency(t *testing.T) {
 		t.Fatalf("ephemeral node key persisted to disk")
 	}
 }
+
+// Tests that node keys can be stored encrypted.
+func TestNodeKeyEncryption(t *testing.T) {
+	dir, err := ioutil.TempDir("", "node-test")
+	if err != nil {
+		t.Fatalf("failed to create temporary data directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	keyfile := filepath.Join(dir, "unit-test", datadirPrivateKey)
+
+	// Configure a node with a passphrase and ensure the key isn't in plain
+	config := &Config{Name: "unit-test", DataDir: dir, NodeKeyPassphrase: "secret"}
+	key := config.NodeKey()
+	if _, err := crypto.LoadECDSA(keyfile); err == nil {
+		t.Fatalf("encrypted node key loaded as plain key")
+	}
+	loaded, err := p2p.LoadNodeKey(keyfile, "secret")
+	if err != nil {
+		t.Fatalf("failed to load encrypted node key: %v", err)
+	}
+	if !bytes.Equal(crypto.FromECDSA(loaded), crypto.FromECDSA(key)) {
+		t.Fatalf("encrypted node key mismatch")
+	}
+	if _, err := p2p.LoadNodeKey(keyfile, "wrong"); err == nil {
+		t.Fatalf("encrypted node key loaded with wrong passphrase")
+	}
+	// Configure a new node and ensure the same key is used
+	config = &Config{Name: "unit-test", DataDir: dir, NodeKeyPassphrase: "secret"}
+	if !bytes.Equal(crypto.FromECDSA(config.NodeKey()), crypto.FromECDSA(key)) {
+		t.Fatalf("persisted node key mismatch")
+	}
+}
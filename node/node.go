@@ -28,6 +28,7 @@ import (
 
 	"github.com/prometheus/prometheus/util/flock"
 	"github.com/vntchain/go-vnt/accounts"
+	"github.com/vntchain/go-vnt/core/rawdb"
 	"github.com/vntchain/go-vnt/event"
 	"github.com/vntchain/go-vnt/internal/debug"
 	"github.com/vntchain/go-vnt/log"
@@ -157,13 +158,19 @@ func (n *Node) Start() error {
 	// if n.serverConfig.StaticNodes == nil {
 	// 	n.serverConfig.StaticNodes = n.config.StaticNodes()
 	// }
-	// if n.serverConfig.TrustedNodes == nil {
-	// 	n.serverConfig.TrustedNodes = n.config.TrustedNodes()
-	// }
+	if n.serverConfig.TrustedNodes == nil {
+		n.serverConfig.TrustedNodes = n.config.TrustedNodes()
+	}
+	if n.serverConfig.Permissioned && n.serverConfig.PermissionedNodesFile == "" {
+		n.serverConfig.PermissionedNodesFile = n.config.PermissionedNodesFile()
+	}
 	if n.serverConfig.NodeDatabase == "" {
 		//n.serverConfig.NodeDatabase = n.config.NodeDB()
 		n.serverConfig.NodeDatabase = n.config.DataDir
 	}
+	if n.serverConfig.DatabaseEngine == "" {
+		n.serverConfig.DatabaseEngine = n.config.DBEngine
+	}
 
 	running := &vntp2p.Server{Config: n.serverConfig}
 
@@ -586,7 +593,40 @@ func (n *Node) OpenDatabase(name string, cache, handles int) (vntdb.Database, er
 	if n.config.DataDir == "" {
 		return vntdb.NewMemDatabase(), nil
 	}
-	return vntdb.NewLDBDatabase(n.config.resolvePath(name), cache, handles)
+	return vntdb.Open(n.config.DBEngine, n.config.resolvePath(name), cache, handles)
+}
+
+// OpenDatabaseWithFreezer opens an existing database with the given name (or
+// creates one if no previous can be found) from within the node's instance
+// directory, along with its ancient store in freezer. A relative freezer path
+// is resolved inside the database, and an empty one defaults to "ancient". If
+// the node is ephemeral, a memory database without ancient store is returned.
+func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, freezer string) (vntdb.Database, error) {
+	if n.config.DataDir == "" {
+		return vntdb.NewMemDatabase(), nil
+	}
+	return openDatabaseWithFreezer(n.config.DBEngine, n.config.resolvePath(name), cache, handles, freezer)
+}
+
+// openDatabaseWithFreezer opens the database in dir with the given engine,
+// along with its ancient store.
+func openDatabaseWithFreezer(engine, dir string, cache, handles int, freezer string) (vntdb.Database, error) {
+	switch {
+	case freezer == "":
+		freezer = filepath.Join(dir, "ancient")
+	case !filepath.IsAbs(freezer):
+		freezer = filepath.Join(dir, freezer)
+	}
+	kvdb, err := vntdb.Open(engine, dir, cache, handles)
+	if err != nil {
+		return nil, err
+	}
+	db, err := rawdb.NewDatabaseWithFreezer(kvdb, freezer)
+	if err != nil {
+		kvdb.Close()
+		return nil, err
+	}
+	return db, nil
 }
 
 // ResolvePath returns the absolute path of a resource in the instance directory.
@@ -43,13 +43,24 @@ func (ctx *ServiceContext) OpenDatabase(name string, cache int, handles int) (vn
 	if ctx.config.DataDir == "" {
 		return vntdb.NewMemDatabase(), nil
 	}
-	db, err := vntdb.NewLDBDatabase(ctx.config.resolvePath(name), cache, handles)
+	db, err := vntdb.Open(ctx.config.DBEngine, ctx.config.resolvePath(name), cache, handles)
 	if err != nil {
 		return nil, err
 	}
 	return db, nil
 }
 
+// OpenDatabaseWithFreezer opens an existing database with the given name (or
+// creates one if no previous can be found) from within the node's data
+// directory, along with its ancient store in freezer. If the node is an
+// ephemeral one, a memory database without ancient store is returned.
+func (ctx *ServiceContext) OpenDatabaseWithFreezer(name string, cache int, handles int, freezer string) (vntdb.Database, error) {
+	if ctx.config.DataDir == "" {
+		return vntdb.NewMemDatabase(), nil
+	}
+	return openDatabaseWithFreezer(ctx.config.DBEngine, ctx.config.resolvePath(name), cache, handles, freezer)
+}
+
 // ResolvePath resolves a user path into the data directory if that was relative
 // and if the user actually uses persistent storage. It will return an empty string
 // for emphemeral storage and the user's own input for absolute paths.
@@ -0,0 +1,32 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import "github.com/vntchain/go-vnt/common"
+
+// Checkpoint is a trusted block of a DPoS chain with the witness list in charge
+// at it. Syncing nodes use it as trust anchor: chains conflicting with it are
+// refused, and the witness lists of the blocks before it need no proof.
+type Checkpoint struct {
+	Number    uint64           `json:"number"`
+	Hash      common.Hash      `json:"hash"`
+	Witnesses []common.Address `json:"witnesses"`
+}
+
+// TrustedCheckpoints associates the checkpoints embedded for known networks
+// with the genesis hash of the chain they belong to.
+var TrustedCheckpoints = map[common.Hash]*Checkpoint{}
@@ -79,6 +79,8 @@ type Database struct {
 	nodesSize     common.StorageSize // Storage size of the nodes cache (exc. flushlist)
 	preimagesSize common.StorageSize // Storage size of the preimages cache
 
+	onFlush func(hash common.Hash) // Called with each node before it's written to disk
+
 	lock sync.RWMutex
 }
 
@@ -282,6 +284,15 @@ func (db *Database) dereference(child common.Hash, parent common.Hash) {
 	}
 }
 
+// SetFlushCallback sets the function called with the hash of every node before
+// it's flushed to disk by Cap or Commit, nil to clear it.
+func (db *Database) SetFlushCallback(fn func(hash common.Hash)) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	db.onFlush = fn
+}
+
 // Cap iteratively flushes old but still referenced trie nodes until the total
 // memory usage goes below the given threshold.
 func (db *Database) Cap(limit common.StorageSize) error {
@@ -323,6 +334,9 @@ func (db *Database) Cap(limit common.StorageSize) error {
 	for size > limit && oldest != (common.Hash{}) {
 		// Fetch the oldest referenced node and push into the batch
 		node := db.nodes[oldest]
+		if db.onFlush != nil {
+			db.onFlush(oldest)
+		}
 		if err := batch.Put(oldest[:], node.blob); err != nil {
 			db.lock.RUnlock()
 			return err
@@ -465,6 +479,9 @@ func (db *Database) commit(hash common.Hash, batch vntdb.Batch) error {
 			return err
 		}
 	}
+	if db.onFlush != nil {
+		db.onFlush(hash)
+	}
 	if err := batch.Put(hash[:], node.blob); err != nil {
 		return err
 	}
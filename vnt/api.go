@@ -253,6 +253,30 @@ func (api *PrivateAdminAPI) ImportChain(file string) (bool, error) {
 	return true, nil
 }
 
+// maxCheckpointLookback is the number of blocks below the head searched for a
+// finalized block to checkpoint.
+const maxCheckpointLookback = 128
+
+// Checkpoint creates a checkpoint of the latest finalized block, proven by the
+// commit certificate of its witnesses. Trusted keys can sign it with
+// `gvnt checkpoint sign`.
+func (api *PrivateAdminAPI) Checkpoint() (*core.SignedCheckpoint, error) {
+	engine, ok := api.vnt.engine.(interface {
+		VerifyCertificate(header *types.Header, witnesses []common.Address) error
+	})
+	if !ok {
+		return nil, errors.New("consensus engine doesn't certify blocks")
+	}
+	header := api.vnt.blockchain.CurrentHeader()
+	for i := 0; i < maxCheckpointLookback && header != nil && header.Number.Sign() > 0; i++ {
+		if engine.VerifyCertificate(header, header.Witnesses) == nil {
+			return core.NewCertifiedCheckpoint(header)
+		}
+		header = api.vnt.blockchain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	}
+	return nil, errors.New("no finalized block near the head")
+}
+
 // PublicDebugAPI is the collection of VNT full node APIs exposed
 // over the public debugging endpoint.
 type PublicDebugAPI struct {
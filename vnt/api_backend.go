@@ -128,6 +128,10 @@ func (b *VntAPIBackend) GetTd(blockHash common.Hash) *big.Int {
 	return b.vnt.blockchain.GetTdByHash(blockHash)
 }
 
+func (b *VntAPIBackend) GetVMConfig() *vm.Config {
+	return b.vnt.blockchain.GetVMConfig()
+}
+
 func (b *VntAPIBackend) GetVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header, vmCfg vm.Config) (vm.VM, func() error, error) {
 	state.SetBalance(msg.From(), math.MaxBig256)
 	vmError := func() error { return nil }
@@ -46,6 +46,7 @@ import (
 	"github.com/vntchain/go-vnt/vnt/downloader"
 	"github.com/vntchain/go-vnt/vnt/filters"
 	"github.com/vntchain/go-vnt/vnt/gasprice"
+	"github.com/vntchain/go-vnt/vnt/verifier"
 	"github.com/vntchain/go-vnt/vntdb"
 	"github.com/vntchain/go-vnt/vntp2p"
 )
@@ -74,6 +75,8 @@ type VNT struct {
 	// DB interfaces
 	chainDb vntdb.Database // Block chain database
 
+	verifier *verifier.Verifier // Registry of verified contract sources
+
 	eventMux       *event.TypeMux
 	engine         consensus.Engine
 	accountManager *accounts.Manager
@@ -107,7 +110,7 @@ func New(ctx *node.ServiceContext, config *Config, node *node.Node) (*VNT, error
 	if !config.SyncMode.IsValid() {
 		return nil, fmt.Errorf("invalid sync mode %d", config.SyncMode)
 	}
-	chainDb, err := CreateDB(ctx, config, "chaindata")
+	chainDb, err := CreateAncientDB(ctx, config, "chaindata")
 	if err != nil {
 		return nil, err
 	}
@@ -143,7 +146,7 @@ func New(ctx *node.ServiceContext, config *Config, node *node.Node) (*VNT, error
 	}
 	var (
 		vmConfig    = vm.Config{EnablePreimageRecording: config.EnablePreimageRecording}
-		cacheConfig = &core.CacheConfig{Disabled: config.NoPruning, TrieNodeLimit: config.TrieCache, TrieTimeLimit: config.TrieTimeout}
+		cacheConfig = &core.CacheConfig{Disabled: config.NoPruning, TrieNodeLimit: config.TrieCache, TrieTimeLimit: config.TrieTimeout, PruneInterval: config.PruneInterval, Snapshot: config.Snapshot}
 	)
 	vnt.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, vnt.chainConfig, vnt.engine, vmConfig)
 	if err != nil {
@@ -155,6 +158,13 @@ func New(ctx *node.ServiceContext, config *Config, node *node.Node) (*VNT, error
 		vnt.blockchain.SetHead(compat.RewindTo)
 		rawdb.WriteChainConfig(chainDb, genesisHash, chainConfig)
 	}
+	checkpoint, err := core.LoadCheckpoint(config.CheckpointFile, config.CheckpointSigners, config.CheckpointThreshold, config.CheckpointTrustCert, rawdb.ReadHeader(chainDb, genesisHash, 0), vnt.engine)
+	if err != nil {
+		return nil, err
+	}
+	if err := vnt.blockchain.SetCheckpoint(checkpoint); err != nil {
+		return nil, err
+	}
 	vnt.bloomIndexer.Start(vnt.blockchain)
 
 	if config.TxPool.Journal != "" {
@@ -162,11 +172,20 @@ func New(ctx *node.ServiceContext, config *Config, node *node.Node) (*VNT, error
 	}
 	vnt.txPool = core.NewTxPool(config.TxPool, vnt.chainConfig, vnt.blockchain)
 
+	var verifierDb vntdb.Database = vntdb.NewMemDatabase()
+	if config.Verifier.Persist {
+		if verifierDb, err = ctx.OpenDatabase("verifier", 16, 16); err != nil {
+			return nil, err
+		}
+	}
+	vnt.verifier = verifier.New(config.Verifier, vnt.blockchain, verifier.NewToolchain(config.Verifier), verifierDb)
+
 	if vnt.protocolManager, err = NewProtocolManager(vnt.chainConfig, config.SyncMode, config.NetworkId, vnt.eventMux, vnt.txPool, vnt.engine, vnt.blockchain, chainDb, node); err != nil {
 		return nil, err
 	}
 	vnt.miner = miner.New(vnt, vnt.chainConfig, vnt.EventMux(), vnt.engine)
 	vnt.miner.SetExtra(makeExtraData(config.ExtraData))
+	vnt.miner.SetOnDemand(config.MinerOnDemand)
 
 	vnt.APIBackend = &VntAPIBackend{vnt, nil}
 	gpoParams := config.GPO
@@ -202,7 +221,20 @@ func CreateDB(ctx *node.ServiceContext, config *Config, name string) (vntdb.Data
 	if err != nil {
 		return nil, err
 	}
-	if db, ok := db.(*vntdb.LDBDatabase); ok {
+	if db, ok := db.(vntdb.Store); ok {
+		db.Meter("vnt/db/chaindata/")
+	}
+	return db, nil
+}
+
+// CreateAncientDB creates the chain database of a full node, along with the
+// ancient store its finalized blocks are moved into.
+func CreateAncientDB(ctx *node.ServiceContext, config *Config, name string) (vntdb.Database, error) {
+	db, err := ctx.OpenDatabaseWithFreezer(name, config.DatabaseCache, config.DatabaseHandles, config.DatabaseFreezer)
+	if err != nil {
+		return nil, err
+	}
+	if db, ok := rawdb.DiskDB(db).(vntdb.Store); ok {
 		db.Meter("vnt/db/chaindata/")
 	}
 	return db, nil
@@ -277,6 +309,15 @@ func (s *VNT) APIs() []rpc.API {
 			Version:   "1.0",
 			Service:   s.netRPCService,
 			Public:    true,
+		}, {
+			Namespace: "vnt",
+			Version:   "1.0",
+			Service:   verifier.NewPublicVerifierAPI(s.verifier),
+			Public:    true,
+		}, {
+			Namespace: "admin",
+			Version:   "1.0",
+			Service:   verifier.NewPrivateVerifierAPI(s.verifier),
 		},
 	}...)
 }
@@ -385,6 +426,17 @@ func (s *VNT) Start(srvr *vntp2p.Server) error {
 		}
 		maxPeers -= s.config.LightPeers
 	}
+	// Follow the registry of a permissioned network
+	if s.config.PermissionRegistry != nil {
+		if !srvr.Permissioned {
+			return fmt.Errorf("permission registry set on a network which is not permissioned")
+		}
+		registry, err := newPermissionRegistry(*s.config.PermissionRegistry, s.blockchain)
+		if err != nil {
+			return err
+		}
+		go s.permissionLoop(srvr, registry)
+	}
 	// Start the networking layer and the light server if requested
 	s.protocolManager.Start(maxPeers)
 	if s.lesServer != nil {
@@ -406,6 +458,7 @@ func (s *VNT) Stop() error {
 	s.miner.Stop()
 	s.eventMux.Stop()
 
+	s.verifier.Close()
 	s.chainDb.Close()
 	close(s.shutdownChan)
 
@@ -0,0 +1,360 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vnt
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	libp2p "github.com/libp2p/go-libp2p-peer"
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/crypto"
+	"github.com/vntchain/go-vnt/log"
+	"github.com/vntchain/go-vnt/vntp2p"
+)
+
+const (
+	bftMeshDegree = 6 // Number of non-witness peers BFT messages are relayed to
+
+	maxSeenBftMsgs = 4096 // Maximum BFT message hashes to remember for deduplication
+
+	// bftMeshCheckInterval is the interval of the witness link health checks.
+	bftMeshCheckInterval = 15 * time.Second
+
+	// bftDirectTimeout is how long a witness may only be heard of through relays
+	// before its direct connection is considered stale and reestablished. It is
+	// also how long a witness counts as relayed after its last relayed message.
+	bftDirectTimeout = time.Minute
+)
+
+// Reachability of a witness, as reported in the witness mesh view.
+const (
+	WitnessSelf        = "self"        // The witness is the local node
+	WitnessDirect      = "direct"      // The witness is connected directly
+	WitnessRelayed     = "relayed"     // The witness is only heard of through relays
+	WitnessUnreachable = "unreachable" // Nothing was heard of the witness recently
+)
+
+var errBftMsgSignature = errors.New("invalid bft message signature")
+
+// WitnessInfo represents the reachability of a witness.
+type WitnessInfo struct {
+	Address     *common.Address `json:"address,omitempty"`     // Witness address, if known
+	Peer        string          `json:"peer"`                  // Node id of the witness
+	Status      string          `json:"status"`                // Reachability of the witness
+	LastDirect  *time.Time      `json:"lastDirect,omitempty"`  // Last message or connection from the witness itself
+	LastRelayed *time.Time      `json:"lastRelayed,omitempty"` // Last message of the witness relayed by another peer
+}
+
+// BftMeshInfo represents a short summary of the BFT message overlay.
+type BftMeshInfo struct {
+	Degree    int            `json:"degree"`    // Target number of mesh peers
+	Mesh      []string       `json:"mesh"`      // Node ids of the mesh peers
+	Witnesses []*WitnessInfo `json:"witnesses"` // Reachability of the current witnesses
+}
+
+type witnessState struct {
+	address     *common.Address
+	peer        libp2p.ID
+	self        bool
+	lastDirect  time.Time
+	lastRelayed time.Time
+	status      string
+}
+
+// bftMesh deduplicates the BFT messages and tracks the reachability of the
+// witnesses. The peers messages are relayed to are kept in the peer set.
+type bftMesh struct {
+	seenMsgs *lru.Cache // Hashes of the BFT messages already handled
+
+	witnesses []*witnessState
+	byAddress map[common.Address]*witnessState
+	lock      sync.RWMutex
+}
+
+func newBftMesh() *bftMesh {
+	seenMsgs, _ := lru.New(maxSeenBftMsgs)
+	return &bftMesh{
+		seenMsgs:  seenMsgs,
+		byAddress: make(map[common.Address]*witnessState),
+	}
+}
+
+// seen reports whether a BFT message was already handled.
+func (m *bftMesh) seen(hash common.Hash) bool {
+	return m.seenMsgs.Contains(hash)
+}
+
+// markSeen records a BFT message and reports whether it is new.
+func (m *bftMesh) markSeen(hash common.Hash) bool {
+	seen, _ := m.seenMsgs.ContainsOrAdd(hash, struct{}{})
+	return !seen
+}
+
+// reset replaces the tracked witnesses. addresses may be nil, otherwise it is
+// parallel to ids.
+func (m *bftMesh) reset(addresses []common.Address, ids []libp2p.ID, self libp2p.ID) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	old := make(map[libp2p.ID]*witnessState)
+	for _, w := range m.witnesses {
+		old[w.peer] = w
+	}
+	m.witnesses = m.witnesses[:0]
+	m.byAddress = make(map[common.Address]*witnessState)
+	for i, id := range ids {
+		w := old[id]
+		if w == nil {
+			w = &witnessState{peer: id, self: id == self, lastDirect: time.Now()}
+		}
+		if addresses != nil {
+			address := addresses[i]
+			w.address = &address
+			m.byAddress[address] = w
+		}
+		m.witnesses = append(m.witnesses, w)
+	}
+}
+
+// isWitness reports whether the address belongs to a tracked witness.
+func (m *bftMesh) isWitness(address common.Address) bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	_, ok := m.byAddress[address]
+	return ok
+}
+
+// touchPeer records the connection of a witness peer or a message received
+// from it, restarting the staleness timeout of the connection.
+func (m *bftMesh) touchPeer(id libp2p.ID) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, w := range m.witnesses {
+		if w.peer == id {
+			w.lastDirect = time.Now()
+		}
+	}
+}
+
+// witnessSeen records a message signed by a witness which was delivered first
+// by the given peer.
+func (m *bftMesh) witnessSeen(address common.Address, from libp2p.ID) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if w := m.byAddress[address]; w != nil && w.peer != from {
+		w.lastRelayed = time.Now()
+	}
+}
+
+// check updates the reachability of the witnesses and returns the witness peers
+// whose direct connections are stale.
+func (m *bftMesh) check(connected func(libp2p.ID) bool) []libp2p.ID {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	var stale []libp2p.ID
+	for _, w := range m.witnesses {
+		status := m.status(w, connected(w.peer))
+		if status == WitnessRelayed && connected(w.peer) {
+			// Others hear from the witness but our link stays silent, reconnect
+			stale = append(stale, w.peer)
+			w.lastDirect = time.Now()
+		}
+		if status != w.status {
+			if status == WitnessUnreachable {
+				log.Warn("BFT witness unreachable", "peer", w.peer, "address", w.address)
+			} else {
+				log.Debug("BFT witness reachability changed", "peer", w.peer, "address", w.address, "status", status)
+			}
+			w.status = status
+		}
+	}
+	return stale
+}
+
+// status computes the reachability of a witness. The caller must hold the lock.
+func (m *bftMesh) status(w *witnessState, connected bool) string {
+	switch {
+	case w.self:
+		return WitnessSelf
+	case connected && w.lastRelayed.Sub(w.lastDirect) < bftDirectTimeout:
+		return WitnessDirect
+	case time.Since(w.lastRelayed) < bftDirectTimeout:
+		return WitnessRelayed
+	default:
+		return WitnessUnreachable
+	}
+}
+
+// info returns the reachability of the witnesses.
+func (m *bftMesh) info(connected func(libp2p.ID) bool) []*WitnessInfo {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	infos := make([]*WitnessInfo, 0, len(m.witnesses))
+	for _, w := range m.witnesses {
+		info := &WitnessInfo{
+			Address: w.address,
+			Peer:    w.peer.Pretty(),
+			Status:  m.status(w, connected(w.peer)),
+		}
+		if !w.self {
+			lastDirect := w.lastDirect
+			info.LastDirect = &lastDirect
+		}
+		if !w.lastRelayed.IsZero() {
+			lastRelayed := w.lastRelayed
+			info.LastRelayed = &lastRelayed
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// handleBftMsg delivers a BFT message received from a peer to the consensus
+// engine, and relays it if it carries the valid signature of a witness.
+// Messages already seen are dropped. The hash of a message doesn't cover its
+// signature, so it's only marked as seen once the signature is verified, lest
+// a forged copy shadows the genuine message.
+func (pm *ProtocolManager) handleBftMsg(p *peer, msg types.ConsensusMsg) error {
+	hash := msg.Hash()
+	p.MarkBftMsg(hash)
+	pm.mesh.touchPeer(p.id)
+	if pm.mesh.seen(hash) {
+		return nil
+	}
+	signer, err := pm.bftMsgSigner(msg)
+	if err != nil {
+		return errResp(ErrDecode, "bft msg %x: %v", hash, err)
+	}
+	if !pm.mesh.markSeen(hash) {
+		return nil
+	}
+	pm.postRecBftEvent(msg)
+
+	if !pm.isWitness(signer, msg.GetBlockNum()) {
+		log.Debug("Not relaying BFT message of unknown witness", "type", msg.Type(), "hash", hash, "signer", signer)
+		return nil
+	}
+	pm.mesh.witnessSeen(signer, p.id)
+	pm.relayBftMsg(types.BftMsg{BftType: msg.Type(), Msg: msg})
+	return nil
+}
+
+// relayBftMsg queues a BFT message to the witnesses and mesh peers which don't
+// know it yet.
+func (pm *ProtocolManager) relayBftMsg(bftMsg types.BftMsg) {
+	peers := pm.peers.PeersWithoutBftMsg(bftMsg.Msg.Hash())
+	for _, peer := range peers {
+		peer.AsyncSendBftMsg(bftMsg)
+	}
+	log.Trace("Relayed BFT message", "type", bftMsg.BftType, "hash", bftMsg.Msg.Hash(), "recipients", len(peers))
+}
+
+// bftMsgSigner recovers the witness which signed a BFT message. Pre-prepare
+// messages are signed by the sealer of the proposed block.
+func (pm *ProtocolManager) bftMsgSigner(msg types.ConsensusMsg) (common.Address, error) {
+	var (
+		claimed common.Address
+		sig     []byte
+	)
+	switch msg := msg.(type) {
+	case *types.PreprepareMsg:
+		if msg.Block == nil {
+			return common.Address{}, errors.New("pre-prepare without block")
+		}
+		return pm.engine.Author(msg.Block.Header())
+	case *types.PrepareMsg:
+		claimed, sig = msg.PrepareAddr, msg.PrepareSig
+	case *types.CommitMsg:
+		claimed, sig = msg.Commiter, msg.CommitSig
+	default:
+		return common.Address{}, errors.New("unknown bft message")
+	}
+	pubkey, err := crypto.SigToPub(msg.Hash().Bytes(), sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if crypto.PubkeyToAddress(*pubkey) != claimed {
+		return common.Address{}, errBftMsgSignature
+	}
+	return claimed, nil
+}
+
+// isWitness reports whether the address is a witness at the head of the local
+// chain, at the parent of the block the message is about, or in the latest
+// witness list announced by the consensus engine.
+func (pm *ProtocolManager) isWitness(address common.Address, number *big.Int) bool {
+	headers := []*types.Header{pm.blockchain.CurrentHeader()}
+	if number != nil && number.Sign() > 0 {
+		headers = append(headers, pm.blockchain.GetHeaderByNumber(number.Uint64()-1))
+	}
+	for _, header := range headers {
+		if header == nil {
+			continue
+		}
+		for _, witness := range header.Witnesses {
+			if witness == address {
+				return true
+			}
+		}
+	}
+	return pm.mesh.isWitness(address)
+}
+
+// bftMeshLoop periodically checks the health of the witness connections. Stale
+// connections are dropped, the witnesses are reserved peers and get redialled.
+func (pm *ProtocolManager) bftMeshLoop() {
+	ticker := time.NewTicker(bftMeshCheckInterval)
+	defer ticker.Stop()
+
+	connected := func(id libp2p.ID) bool { return pm.peers.Peer(id) != nil }
+	for {
+		select {
+		case <-ticker.C:
+			for _, id := range pm.mesh.check(connected) {
+				if p := pm.peers.Peer(id); p != nil {
+					p.Log().Debug("Reconnecting stale BFT witness")
+					p.Peer.Disconnect(vntp2p.DiscUselessPeer)
+				}
+			}
+		case <-pm.quitSync:
+			return
+		}
+	}
+}
+
+// BftMeshInfo retrieves the witness mesh view.
+func (pm *ProtocolManager) BftMeshInfo() *BftMeshInfo {
+	info := &BftMeshInfo{
+		Degree:    bftMeshDegree,
+		Mesh:      []string{},
+		Witnesses: pm.mesh.info(func(id libp2p.ID) bool { return pm.peers.Peer(id) != nil }),
+	}
+	for _, id := range pm.peers.MeshPeers() {
+		info.Mesh = append(info.Mesh, id.Pretty())
+	}
+	return info
+}
@@ -0,0 +1,315 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vnt
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	libp2p "github.com/libp2p/go-libp2p-peer"
+	"github.com/vntchain/go-vnt/accounts/abi/bind/backends"
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core"
+	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/crypto"
+	"github.com/vntchain/go-vnt/event"
+	set "gopkg.in/fatih/set.v0"
+)
+
+// newTestBftPeer creates a peer which only queues the BFT messages relayed to
+// it, without any connection behind.
+func newTestBftPeer(id libp2p.ID) *peer {
+	return &peer{
+		id:            id,
+		knownBftMsgs:  set.New(),
+		queuedBftMsgs: make(chan types.BftMsg, maxQueuedBftMsgs),
+		term:          make(chan struct{}),
+	}
+}
+
+// newTestBftManager creates a protocol manager with the given witness and other
+// peers connected, and the witness keys tracked by the mesh.
+func newTestBftManager(t *testing.T, witnesses []*ecdsa.PrivateKey, witnessPeers []libp2p.ID, others int) *ProtocolManager {
+	sim := backends.NewSimulatedBackend(core.GenesisAlloc{})
+	pm := &ProtocolManager{
+		blockchain: sim.Blockchain(),
+		eventMux:   new(event.TypeMux),
+		peers:      newPeerSet(),
+		mesh:       newBftMesh(),
+	}
+	var addresses []common.Address
+	for _, key := range witnesses {
+		addresses = append(addresses, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	for _, id := range witnessPeers {
+		pm.peers.peers[id] = newTestBftPeer(id)
+		pm.peers.bftPeers[id] = struct{}{}
+	}
+	for i := 0; i < others; i++ {
+		id := libp2p.ID(fmt.Sprintf("other-%d", i))
+		pm.peers.peers[id] = newTestBftPeer(id)
+	}
+	pm.peers.fillMesh()
+	pm.mesh.reset(addresses, witnessPeers, "")
+	return pm
+}
+
+// signedPrepare creates a prepare message signed by key.
+func signedPrepare(t *testing.T, key *ecdsa.PrivateKey, number int64) *types.PrepareMsg {
+	msg := &types.PrepareMsg{
+		PrepareAddr: crypto.PubkeyToAddress(key.PublicKey),
+		BlockNumber: big.NewInt(number),
+		BlockHash:   common.Hash{byte(number)},
+	}
+	sig, err := crypto.Sign(msg.Hash().Bytes(), key)
+	if err != nil {
+		t.Fatalf("failed to sign prepare message: %v", err)
+	}
+	msg.PrepareSig = sig
+	return msg
+}
+
+// queued returns the number of BFT messages queued for relaying to a peer.
+func queued(p *peer) int {
+	return len(p.queuedBftMsgs)
+}
+
+// Tests that BFT messages are only reported new once.
+func TestBftMeshDedup(t *testing.T) {
+	mesh := newBftMesh()
+	hash := common.Hash{0x01}
+
+	if mesh.seen(hash) {
+		t.Fatalf("unhandled message reported seen")
+	}
+	if !mesh.markSeen(hash) {
+		t.Fatalf("first message not reported new")
+	}
+	if !mesh.seen(hash) || mesh.markSeen(hash) {
+		t.Fatalf("duplicate message reported new")
+	}
+}
+
+// Tests that validly signed witness messages are delivered and relayed once,
+// to the peers which don't know them yet.
+func TestHandleBftMsgRelay(t *testing.T) {
+	witness, _ := crypto.GenerateKey()
+	pm := newTestBftManager(t, []*ecdsa.PrivateKey{witness}, []libp2p.ID{"witness"}, 3)
+
+	// The mux delivers synchronously, collect the events aside
+	sub := pm.eventMux.Subscribe(core.RecBftMsgEvent{})
+	defer sub.Unsubscribe()
+	delivered := make(chan core.RecBftMsgEvent, 2)
+	go func() {
+		for ev := range sub.Chan() {
+			delivered <- ev.Data.(core.RecBftMsgEvent)
+		}
+	}()
+
+	msg := signedPrepare(t, witness, 1)
+	from := pm.peers.Peer("other-0")
+	if err := pm.handleBftMsg(from, msg); err != nil {
+		t.Fatalf("failed to handle message: %v", err)
+	}
+	select {
+	case ev := <-delivered:
+		if hash := ev.BftMsg.Msg.Hash(); hash != msg.Hash() {
+			t.Errorf("delivered message mismatch: have %x, want %x", hash, msg.Hash())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("message not delivered")
+	}
+	for id, p := range pm.peers.peers {
+		want := 1
+		if p == from {
+			want = 0
+		}
+		if have := queued(p); have != want {
+			t.Errorf("peer %s: relayed message count mismatch: have %d, want %d", id, have, want)
+		}
+	}
+	// Duplicates are neither delivered nor relayed
+	if err := pm.handleBftMsg(pm.peers.Peer("other-1"), msg); err != nil {
+		t.Fatalf("failed to handle duplicate message: %v", err)
+	}
+	select {
+	case <-delivered:
+		t.Errorf("duplicate message delivered")
+	case <-time.After(50 * time.Millisecond):
+	}
+	for id, p := range pm.peers.peers {
+		if queued(p) > 1 {
+			t.Errorf("peer %s: duplicate message relayed", id)
+		}
+	}
+}
+
+// Tests that messages signed by unknown witnesses are delivered but not relayed.
+func TestHandleBftMsgUnknownWitness(t *testing.T) {
+	witness, _ := crypto.GenerateKey()
+	stranger, _ := crypto.GenerateKey()
+	pm := newTestBftManager(t, []*ecdsa.PrivateKey{witness}, []libp2p.ID{"witness"}, 3)
+
+	if err := pm.handleBftMsg(pm.peers.Peer("other-0"), signedPrepare(t, stranger, 1)); err != nil {
+		t.Fatalf("failed to handle message: %v", err)
+	}
+	for id, p := range pm.peers.peers {
+		if queued(p) != 0 {
+			t.Errorf("peer %s: message of unknown witness relayed", id)
+		}
+	}
+}
+
+// Tests that a forged copy of a message is rejected without shadowing the
+// genuine one, which only differs by its signature.
+func TestHandleBftMsgForgedCopy(t *testing.T) {
+	witness, _ := crypto.GenerateKey()
+	forger, _ := crypto.GenerateKey()
+	pm := newTestBftManager(t, []*ecdsa.PrivateKey{witness}, []libp2p.ID{"witness"}, 3)
+
+	msg := signedPrepare(t, witness, 1)
+	forged := *msg
+	forged.PrepareSig, _ = crypto.Sign(msg.Hash().Bytes(), forger)
+	if forged.Hash() != msg.Hash() {
+		t.Fatalf("forged copy hash mismatch")
+	}
+	if err := pm.handleBftMsg(pm.peers.Peer("other-0"), &forged); err == nil {
+		t.Fatalf("forged message accepted")
+	}
+	garbage := *msg
+	garbage.PrepareSig = []byte{0x01, 0x02}
+	if err := pm.handleBftMsg(pm.peers.Peer("other-0"), &garbage); err == nil {
+		t.Fatalf("message with garbage signature accepted")
+	}
+	if err := pm.handleBftMsg(pm.peers.Peer("other-1"), msg); err != nil {
+		t.Fatalf("failed to handle genuine message: %v", err)
+	}
+	if queued(pm.peers.Peer("witness")) != 1 {
+		t.Errorf("genuine message not relayed after forged copy")
+	}
+}
+
+// Tests the reachability transitions of the witnesses, and the reconnection of
+// stale direct links.
+func TestBftMeshCheck(t *testing.T) {
+	mesh := newBftMesh()
+	addresses := []common.Address{{0x01}, {0x02}}
+	mesh.reset(addresses, []libp2p.ID{"self", "witness"}, "self")
+
+	connected := true
+	isConnected := func(id libp2p.ID) bool { return connected }
+	status := func() string {
+		for _, info := range mesh.info(isConnected) {
+			if info.Peer == libp2p.ID("witness").Pretty() {
+				return info.Status
+			}
+		}
+		return ""
+	}
+	w := mesh.byAddress[addresses[1]]
+
+	// Freshly connected witnesses are direct, the local node is itself
+	if stale := mesh.check(isConnected); len(stale) != 0 {
+		t.Fatalf("fresh link reported stale: %v", stale)
+	}
+	if have := status(); have != WitnessDirect {
+		t.Fatalf("status mismatch: have %s, want %s", have, WitnessDirect)
+	}
+	if have := mesh.byAddress[addresses[0]].status; have != WitnessSelf {
+		t.Errorf("local status mismatch: have %s, want %s", have, WitnessSelf)
+	}
+	// Messages only heard of through relays for long mark the link stale
+	w.lastDirect = time.Now().Add(-2 * bftDirectTimeout)
+	mesh.witnessSeen(addresses[1], "other")
+	if have := status(); have != WitnessRelayed {
+		t.Fatalf("status mismatch: have %s, want %s", have, WitnessRelayed)
+	}
+	stale := mesh.check(isConnected)
+	if len(stale) != 1 || stale[0] != "witness" {
+		t.Fatalf("stale links mismatch: have %v, want [witness]", stale)
+	}
+	if w.status != WitnessRelayed {
+		t.Errorf("checked status mismatch: have %s, want %s", w.status, WitnessRelayed)
+	}
+	// The reconnection restarts the staleness timeout
+	if have := status(); have != WitnessDirect {
+		t.Errorf("status after reconnection mismatch: have %s, want %s", have, WitnessDirect)
+	}
+	// Disconnected witnesses heard of through relays are relayed, then unreachable
+	connected = false
+	if have := status(); have != WitnessRelayed {
+		t.Errorf("disconnected status mismatch: have %s, want %s", have, WitnessRelayed)
+	}
+	if stale := mesh.check(isConnected); len(stale) != 0 {
+		t.Errorf("disconnected link reported stale: %v", stale)
+	}
+	w.lastRelayed = time.Now().Add(-2 * bftDirectTimeout)
+	mesh.check(isConnected)
+	if w.status != WitnessUnreachable {
+		t.Errorf("status mismatch: have %s, want %s", w.status, WitnessUnreachable)
+	}
+	// Messages delivered by the witness itself don't count as relayed
+	connected = true
+	mesh.touchPeer("witness")
+	mesh.witnessSeen(addresses[1], "witness")
+	if have := status(); have != WitnessDirect {
+		t.Errorf("status mismatch: have %s, want %s", have, WitnessDirect)
+	}
+}
+
+// Tests that BFT messages are only relayed to the witnesses and the mesh peers.
+func TestPeersWithoutBftMsg(t *testing.T) {
+	witnesses := []libp2p.ID{"witness-0", "witness-1"}
+	pm := newTestBftManager(t, nil, witnesses, 2*bftMeshDegree)
+	ps := pm.peers
+
+	if len(ps.meshPeers) != bftMeshDegree {
+		t.Fatalf("mesh size mismatch: have %d, want %d", len(ps.meshPeers), bftMeshDegree)
+	}
+	hash := common.Hash{0x01}
+	ps.Peer("witness-0").MarkBftMsg(hash)
+
+	peers := ps.PeersWithoutBftMsg(hash)
+	if want := len(ps.bftPeers) + len(ps.meshPeers) - 1; len(peers) != want {
+		t.Errorf("relay count mismatch: have %d, want %d", len(peers), want)
+	}
+	for _, p := range peers {
+		witness, mesh := ps.BftRole(p.id)
+		if !witness && !mesh {
+			t.Errorf("peer %s: relayed outside the witnesses and mesh", p.id)
+		}
+		if p.id == "witness-0" {
+			t.Errorf("peer %s: relayed known message", p.id)
+		}
+	}
+	// Dropped mesh peers are replaced by other connected ones
+	for id := range ps.meshPeers {
+		delete(ps.peers, id)
+		delete(ps.meshPeers, id)
+		break
+	}
+	ps.fillMesh()
+	if len(ps.meshPeers) != bftMeshDegree {
+		t.Errorf("refilled mesh size mismatch: have %d, want %d", len(ps.meshPeers), bftMeshDegree)
+	}
+	if len(ps.PeersWithoutBftMsg(common.Hash{0x02})) != len(ps.bftPeers)+len(ps.meshPeers) {
+		t.Errorf("relay count exceeds witnesses and mesh")
+	}
+}
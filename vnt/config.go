@@ -28,6 +28,7 @@ import (
 	"github.com/vntchain/go-vnt/params"
 	"github.com/vntchain/go-vnt/vnt/downloader"
 	"github.com/vntchain/go-vnt/vnt/gasprice"
+	"github.com/vntchain/go-vnt/vnt/verifier"
 )
 
 // DefaultConfig contains default settings for use on the VNT main net.
@@ -45,6 +46,7 @@ var DefaultConfig = Config{
 		Blocks:     20,
 		Percentile: 60,
 	},
+	Verifier: verifier.DefaultConfig,
 }
 
 func init() {
@@ -68,6 +70,13 @@ type Config struct {
 	SyncMode  downloader.SyncMode
 	NoPruning bool
 
+	// Blocks between prunings of the stale state (0 = never), only the state of
+	// the finality window is kept on disk
+	PruneInterval uint64 `toml:",omitempty"`
+
+	// Whether state reads are served from a flat snapshot
+	Snapshot bool `toml:",omitempty"`
+
 	// Light client options
 	LightServ  int `toml:",omitempty"` // Maximum percentage of time allowed for serving LES requests
 	LightPeers int `toml:",omitempty"` // Maximum number of LES client peers
@@ -76,14 +85,16 @@ type Config struct {
 	SkipBcVersionCheck bool `toml:"-"`
 	DatabaseHandles    int  `toml:"-"`
 	DatabaseCache      int
+	DatabaseFreezer    string // Directory of the ancient store, relative to the chain database
 	TrieCache          int
 	TrieTimeout        time.Duration
 
 	// Mining-related options
-	Etherbase    common.Address `toml:",omitempty"`
-	MinerThreads int            `toml:",omitempty"`
-	ExtraData    []byte         `toml:",omitempty"`
-	GasPrice     *big.Int
+	Etherbase     common.Address `toml:",omitempty"`
+	MinerThreads  int            `toml:",omitempty"`
+	MinerOnDemand bool           `toml:",omitempty"` // Only seal blocks when transactions are pending
+	ExtraData     []byte         `toml:",omitempty"`
+	GasPrice      *big.Int
 
 	// Transaction pool options
 	TxPool core.TxPoolConfig
@@ -94,6 +105,22 @@ type Config struct {
 	// Enables tracking of SHA3 preimages in the VM
 	EnablePreimageRecording bool
 
+	// Contract source verification options
+	Verifier verifier.Config
+
+	// PermissionRegistry is the contract listing the nodes allowed in a
+	// permissioned network, it is read at every new block.
+	PermissionRegistry *common.Address `toml:",omitempty"`
+
+	// Trusted checkpoint options. The checkpoint file has to be signed by
+	// CheckpointThreshold of the CheckpointSigners. An unsigned file carrying
+	// the commit certificate of the checkpoint block is only accepted with
+	// CheckpointTrustCert, if certified by the genesis or embedded witnesses.
+	CheckpointFile      string           `toml:",omitempty"`
+	CheckpointSigners   []common.Address `toml:",omitempty"`
+	CheckpointThreshold int              `toml:",omitempty"`
+	CheckpointTrustCert bool             `toml:",omitempty"`
+
 	// Miscellaneous options
 	DocRoot string `toml:"-"`
 }
@@ -92,8 +92,16 @@ var (
 	errCancelContentProcessing = errors.New("content processing canceled (requested)")
 	errNoSyncActive            = errors.New("no sync active")
 	errTooOld                  = errors.New("peer doesn't speak recent enough protocol version (need version >= 62)")
+	errCheckpointMismatch      = errors.New("peer chain conflicts with trusted checkpoint")
+	errBehindCheckpoint        = errors.New("peer chain doesn't reach trusted checkpoint")
 )
 
+// IsTimeout reports whether a peer was dropped for not delivering in time, as
+// opposed to delivering invalid data.
+func IsTimeout(reason error) bool {
+	return reason == errTimeout || reason == errStallingPeer || reason == errEmptyHeaderSet
+}
+
 type Downloader struct {
 	mode SyncMode       // Synchronisation mode defining the strategy used (per sync cycle)
 	mux  *event.TypeMux // Event multiplexer to announce sync operation events
@@ -113,6 +121,10 @@ type Downloader struct {
 
 	lightchain LightChain
 	blockchain BlockChain
+	witnesses  witnessVerifier // Checks the witnesses of fast synced headers, nil if unsupported
+
+	witnessProofs map[common.Hash]*types.Header // Parents of witness list updates awaiting a state proof
+	witnessLock   sync.Mutex                    // Protects witnessProofs
 
 	// Callbacks
 	dropPeer peerDropFn // Drops a peer for misbehaving
@@ -173,6 +185,16 @@ type LightChain interface {
 	Rollback([]common.Hash)
 }
 
+// witnessChain is implemented by light chains following the witness list of
+// the chain, whose headers can be verified without their ancestors.
+type witnessChain interface {
+	// TracksWitnesses reports whether a header carries the followed witness list.
+	TracksWitnesses(*types.Header) bool
+
+	// VerifyWitnessedHeader checks the commit certificate of such a header.
+	VerifyWitnessedHeader(*types.Header) error
+}
+
 // BlockChain encapsulates functions required to sync a (full or fast) blockchain.
 type BlockChain interface {
 	LightChain
@@ -230,6 +252,9 @@ func New(mode SyncMode, stateDb vntdb.Database, mux *event.TypeMux, chain BlockC
 		},
 		trackStateReq: make(chan *stateReq),
 	}
+	if c, ok := chain.(engineChain); ok {
+		dl.witnesses, _ = c.Engine().(witnessVerifier)
+	}
 	go dl.qosTuner()
 	go dl.stateFetcher()
 	return dl
@@ -323,14 +348,14 @@ func (d *Downloader) Synchronise(id libp2p.ID, head common.Hash, td *big.Int, mo
 
 	case errTimeout, errBadPeer, errStallingPeer,
 		errEmptyHeaderSet, errPeersUnavailable, errTooOld,
-		errInvalidAncestor, errInvalidChain:
+		errInvalidAncestor, errInvalidChain, errCheckpointMismatch:
 		log.Warn("Synchronisation failed, dropping peer", "peer", id, "err", err)
 		if d.dropPeer == nil {
 			// The dropPeer method is nil when `--copydb` is used for a local copy.
 			// Timeouts can occur if e.g. compaction hits at the wrong time, and can be ignored
 			log.Warn("Downloader wants to drop peer, but peerdrop-function is not set", "peer", id)
 		} else {
-			d.dropPeer(id)
+			d.dropPeer(id, err)
 		}
 	default:
 		log.Warn("Synchronisation failed, retrying", "err", err)
@@ -433,6 +458,15 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, td *big.I
 	if err != nil {
 		return err
 	}
+	// Blocks before the checkpoint are not verified, make sure they lead to it
+	if cp := d.checkpoint(); cp != nil && d.mode != FullSync && origin < cp.Number {
+		if height < cp.Number {
+			return errBehindCheckpoint
+		}
+		if err := d.fetchCheckpoint(p, cp); err != nil {
+			return err
+		}
+	}
 	d.syncStatsLock.Lock()
 	if d.syncStatsChainHeight <= origin || d.syncStatsChainOrigin > origin {
 		d.syncStatsChainOrigin = origin
@@ -456,6 +490,7 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, td *big.I
 	if d.mode == FastSync && pivot != 0 {
 		d.committed = 0
 	}
+	d.resetWitnessProofs()
 	// Initiate the sync using a concurrent header and content retrieval algorithm
 	d.queue.Prepare(origin+1, d.mode)
 	if d.syncInitHook != nil {
@@ -838,6 +873,10 @@ func (d *Downloader) fetchHeaders(p *peerConnection, from uint64, pivot uint64)
 
 			// If we received a skeleton batch, resolve internals concurrently
 			if skeleton {
+				if err := d.verifySkeleton(headers); err != nil {
+					p.log.Debug("Skeleton witnesses invalid", "err", err)
+					return errInvalidChain
+				}
 				filled, proced, err := d.fillHeaderSkeleton(from, headers)
 				if err != nil {
 					p.log.Debug("Skeleton chain invalid", "err", err)
@@ -868,7 +907,7 @@ func (d *Downloader) fetchHeaders(p *peerConnection, from uint64, pivot uint64)
 			// Header retrieval timed out, consider the peer bad and drop
 			p.log.Debug("Header request timed out", "elapsed", ttl)
 			headerTimeoutMeter.Mark(1)
-			d.dropPeer(p.id)
+			d.dropPeer(p.id, errTimeout)
 
 			// Finish the sync gracefully instead of dumping the gathered data though
 			for _, ch := range []chan bool{d.bodyWakeCh, d.receiptWakeCh} {
@@ -886,6 +925,24 @@ func (d *Downloader) fetchHeaders(p *peerConnection, from uint64, pivot uint64)
 	}
 }
 
+// verifySkeleton checks the commit certificates of the skeleton headers of the
+// witness list followed by a light chain before filling the gaps in between.
+func (d *Downloader) verifySkeleton(skeleton []*types.Header) error {
+	wc, ok := d.lightchain.(witnessChain)
+	if d.mode != LightSync || !ok {
+		return nil
+	}
+	for _, header := range skeleton {
+		if !wc.TracksWitnesses(header) {
+			continue
+		}
+		if err := wc.VerifyWitnessedHeader(header); err != nil {
+			return fmt.Errorf("skeleton header #%d [%x…]: %v", header.Number, header.Hash().Bytes()[:4], err)
+		}
+	}
+	return nil
+}
+
 // fillHeaderSkeleton concurrently retrieves headers from all our available peers
 // and maps them to the provided skeleton header chain.
 //
@@ -1088,7 +1145,7 @@ func (d *Downloader) fetchParts(errCancel error, deliveryCh chan dataPack, deliv
 							// Timeouts can occur if e.g. compaction hits at the wrong time, and can be ignored
 							peer.log.Warn("Downloader wants to drop peer, but peerdrop-function is not set", "peer", pid)
 						} else {
-							d.dropPeer(pid)
+							d.dropPeer(pid, errStallingPeer)
 						}
 					}
 				}
@@ -1271,6 +1328,11 @@ func (d *Downloader) processHeaders(origin uint64, pivot uint64, td *big.Int) er
 					if chunk[len(chunk)-1].Number.Uint64()+uint64(fsHeaderForceVerify) > pivot {
 						frequency = 1
 					}
+					if d.mode == FastSync {
+						if err := d.verifyWitnesses(chunk); err != nil {
+							return err
+						}
+					}
 					if n, err := d.lightchain.InsertHeaderChain(chunk, frequency); err != nil {
 						// If some headers were inserted, add them too to the rollback list
 						if n > 0 {
@@ -1423,6 +1485,30 @@ func (d *Downloader) processFastSyncContent(latest *types.Header) error {
 			}
 		}
 		P, beforeP, afterP := splitAroundPivot(pivot, results)
+
+		// Witness list updates below the pivot are proven by the state of their
+		// parent, which interrupts the state retrieval of the pivot
+		proofs := beforeP
+		if P != nil {
+			proofs = append(beforeP[:len(beforeP):len(beforeP)], P)
+		}
+		if proven, err := d.proveWitnesses(proofs); err != nil {
+			return err
+		} else if proven {
+			root := latest.Root
+			if oldPivot != nil {
+				root = oldPivot.Header.Root
+			}
+			stateSync.Cancel()
+
+			stateSync = d.syncState(root)
+			defer stateSync.Cancel()
+			go func() {
+				if err := stateSync.Wait(); err != nil && err != errCancelStateFetch {
+					d.queue.Close() // wake up WaitResults
+				}
+			}()
+		}
 		if err := d.commitFastSyncData(beforeP, stateSync); err != nil {
 			return err
 		}
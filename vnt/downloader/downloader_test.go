@@ -438,7 +438,7 @@ func (dl *downloadTester) newSlowPeer(id libp2p.ID, version int, hashes []common
 }
 
 // dropPeer simulates a hard peer removal from the connection pool.
-func (dl *downloadTester) dropPeer(id libp2p.ID) {
+func (dl *downloadTester) dropPeer(id libp2p.ID, reason error) {
 	dl.lock.Lock()
 	defer dl.lock.Unlock()
 
@@ -23,8 +23,9 @@ import (
 	"github.com/vntchain/go-vnt/core/types"
 )
 
-// peerDropFn is a callback type for dropping a peer detected as malicious.
-type peerDropFn func(id libp2p.ID)
+// peerDropFn is a callback type for dropping a peer detected as malicious or
+// useless, along with the reason it's dropped for.
+type peerDropFn func(id libp2p.ID, reason error)
 
 // dataPack is a data message returned by a peer for some query.
 type dataPack interface {
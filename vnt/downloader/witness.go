@@ -0,0 +1,201 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package downloader
+
+import (
+	"errors"
+	"time"
+
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/consensus"
+	"github.com/vntchain/go-vnt/core/state"
+	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/log"
+	"github.com/vntchain/go-vnt/params"
+)
+
+// Fast sync doesn't execute the blocks below the pivot, so their witness lists
+// can't be checked against the election state. Instead, every header has to
+// carry the commit certificate of its witnesses, and every witness list has to
+// follow from the one of the local chain. The pivot is thus always a certified
+// header, whose state is verified against its root while it is retrieved. A
+// new list which isn't endorsed by the previous witnesses can only be proven
+// by the state of its parent: below the pivot, that state is retrieved and
+// checked before the update is committed, above it the blocks are executed.
+
+// errWitnessProofRequired is returned by the parent state retriever of a fast
+// synced header, whose state is not available.
+var errWitnessProofRequired = errors.New("witness list update needs parent state")
+
+// witnessVerifier is implemented by consensus engines able to check witness
+// lists and commit certificates without executing the blocks.
+type witnessVerifier interface {
+	// VerifyWitnessTransition checks the witness list of the header against
+	// the one of its parent, retrieving the parent state only if needed.
+	VerifyWitnessTransition(header, parent *types.Header, parentState func() (*state.StateDB, error)) error
+
+	// VerifyCertificate checks that 2f+1 witnesses of the set committed to the
+	// header.
+	VerifyCertificate(header *types.Header, witnesses []common.Address) error
+}
+
+// engineChain is implemented by chains exposing their consensus engine.
+type engineChain interface {
+	Engine() consensus.Engine
+}
+
+// checkpointChain is implemented by chains with a trusted checkpoint.
+type checkpointChain interface {
+	Checkpoint() *params.Checkpoint
+}
+
+// checkpoint returns the trusted checkpoint of the local chain, nil if none.
+func (d *Downloader) checkpoint() *params.Checkpoint {
+	if c, ok := d.lightchain.(checkpointChain); ok {
+		return c.Checkpoint()
+	}
+	return nil
+}
+
+// fetchCheckpoint makes sure the chain of the peer contains the checkpoint.
+func (d *Downloader) fetchCheckpoint(p *peerConnection, cp *params.Checkpoint) error {
+	p.log.Debug("Retrieving remote checkpoint header", "number", cp.Number)
+	go p.peer.RequestHeadersByNumber(cp.Number, 1, 0, false)
+
+	ttl := d.requestTTL()
+	timeout := time.After(ttl)
+	for {
+		select {
+		case <-d.cancelCh:
+			return errCancelHeaderFetch
+
+		case packet := <-d.headerCh:
+			// Discard anything not from the origin peer
+			if packet.PeerId() != p.id {
+				log.Debug("Received headers from incorrect peer", "peer", packet.PeerId())
+				break
+			}
+			headers := packet.(*headerPack).headers
+			if len(headers) != 1 {
+				p.log.Debug("Multiple headers for single request", "headers", len(headers))
+				return errBadPeer
+			}
+			if header := headers[0]; header.Hash() != cp.Hash {
+				p.log.Warn("Remote chain conflicts with checkpoint", "number", header.Number, "hash", header.Hash(), "checkpoint", cp.Hash)
+				return errCheckpointMismatch
+			}
+			return nil
+
+		case <-timeout:
+			p.log.Debug("Waiting for checkpoint header timed out", "elapsed", ttl)
+			return errTimeout
+
+		case <-d.bodyCh:
+		case <-d.receiptCh:
+			// Out of bounds delivery, ignore
+		}
+	}
+}
+
+// verifyWitnesses checks the witness lists and commit certificates of a
+// contiguous batch of fast synced headers. Witness list updates which need the
+// state of their parent are recorded to be proven before they are committed.
+func (d *Downloader) verifyWitnesses(headers []*types.Header) error {
+	if d.witnesses == nil || len(headers) == 0 {
+		return nil
+	}
+	parent := d.lightchain.GetHeaderByHash(headers[0].ParentHash)
+	if parent == nil {
+		return errInvalidChain
+	}
+	noState := func() (*state.StateDB, error) { return nil, errWitnessProofRequired }
+
+	cp := d.checkpoint()
+	for _, header := range headers {
+		if header.ParentHash != parent.Hash() {
+			return errInvalidChain
+		}
+		// Blocks up to the trusted checkpoint need no proof
+		if cp != nil && header.Number.Uint64() <= cp.Number {
+			parent = header
+			continue
+		}
+		switch err := d.witnesses.VerifyWitnessTransition(header, parent, noState); err {
+		case nil:
+		case errWitnessProofRequired:
+			d.witnessLock.Lock()
+			d.witnessProofs[header.Hash()] = parent
+			d.witnessLock.Unlock()
+		default:
+			log.Debug("Invalid witness list", "number", header.Number, "hash", header.Hash(), "err", err)
+			return errInvalidChain
+		}
+		if err := d.witnesses.VerifyCertificate(header, header.Witnesses); err != nil {
+			log.Debug("Invalid commit certificate", "number", header.Number, "hash", header.Hash(), "err", err)
+			return errInvalidChain
+		}
+		parent = header
+	}
+	return nil
+}
+
+// resetWitnessProofs drops the witness list updates awaiting a proof.
+func (d *Downloader) resetWitnessProofs() {
+	d.witnessLock.Lock()
+	defer d.witnessLock.Unlock()
+
+	d.witnessProofs = make(map[common.Hash]*types.Header)
+}
+
+// proveWitnesses checks the witness list updates among the fast synced results
+// against the state of their parents, retrieving it from the network. It fails
+// if the state can't be retrieved, so the sync is retried instead of committing
+// unproven witnesses. The return value reports whether any state was retrieved,
+// which cancels the running state sync.
+func (d *Downloader) proveWitnesses(results []*fetchResult) (bool, error) {
+	proven := false
+	for _, result := range results {
+		header := result.Header
+
+		d.witnessLock.Lock()
+		parent := d.witnessProofs[header.Hash()]
+		d.witnessLock.Unlock()
+		if parent == nil {
+			continue
+		}
+		log.Info("Retrieving state to prove witness list update", "number", header.Number, "hash", header.Hash(), "root", parent.Root)
+		proven = true
+
+		proof := d.syncState(parent.Root)
+		if err := proof.Wait(); err != nil {
+			log.Warn("Failed to retrieve witness proof state", "number", parent.Number, "hash", parent.Hash(), "err", err)
+			return proven, err
+		}
+		db, err := state.New(parent.Root, state.NewDatabase(d.stateDB))
+		if err != nil {
+			return proven, err
+		}
+		if err := d.witnesses.VerifyWitnessTransition(header, parent, func() (*state.StateDB, error) { return db, nil }); err != nil {
+			log.Warn("Unproven witness list update", "number", header.Number, "hash", header.Hash(), "err", err)
+			return proven, errInvalidChain
+		}
+		d.witnessLock.Lock()
+		delete(d.witnessProofs, header.Hash())
+		d.witnessLock.Unlock()
+	}
+	return proven, nil
+}
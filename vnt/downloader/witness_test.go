@@ -0,0 +1,177 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package downloader
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/state"
+	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/event"
+	"github.com/vntchain/go-vnt/params"
+)
+
+// witnessTester fakes the witness checks of a consensus engine.
+type witnessTester struct {
+	unendorsed uint64 // Block whose witness list needs the parent state
+	unproven   uint64 // Block whose witness list contradicts the parent state
+	forged     uint64 // Block without a valid commit certificate
+}
+
+func (w *witnessTester) VerifyWitnessTransition(header, parent *types.Header, parentState func() (*state.StateDB, error)) error {
+	if header.ParentHash != parent.Hash() {
+		return errors.New("wrong parent")
+	}
+	if n := header.Number.Uint64(); n == w.unendorsed || n == w.unproven {
+		db, err := parentState()
+		if err != nil {
+			return err
+		}
+		if db.IntermediateRoot(false) != parent.Root {
+			return errors.New("wrong parent state")
+		}
+		if n == w.unproven {
+			return errors.New("witness list not elected")
+		}
+	}
+	return nil
+}
+
+func (w *witnessTester) VerifyCertificate(header *types.Header, witnesses []common.Address) error {
+	if header.Number.Uint64() == w.forged {
+		return errors.New("forged certificate")
+	}
+	return nil
+}
+
+// checkpointTester adds a trusted checkpoint to the local chain of a tester.
+type checkpointTester struct {
+	*downloadTester
+	checkpoint *params.Checkpoint
+}
+
+func (c *checkpointTester) Checkpoint() *params.Checkpoint { return c.checkpoint }
+
+// setCheckpoint recreates the downloader of the tester on top of a chain with
+// the trusted checkpoint.
+func (dl *downloadTester) setCheckpoint(cp *params.Checkpoint) {
+	dl.downloader.Terminate()
+	dl.downloader = New(FullSync, dl.stateDb, new(event.TypeMux), &checkpointTester{dl, cp}, nil, dl.dropPeer)
+}
+
+// Tests that an unendorsed witness list update below the pivot is proven by
+// the state of its parent, without moving the pivot.
+func TestFastSyncWitnessProof(t *testing.T) {
+	t.Parallel()
+
+	tester := newTester()
+	defer tester.terminate()
+	tester.downloader.witnesses = &witnessTester{unendorsed: 300}
+
+	targetBlocks := blockCacheItems - 15
+	hashes, headers, blocks, receipts := tester.makeChain(targetBlocks, 0, tester.genesis, nil, false)
+	tester.newPeer("peer", 63, hashes, headers, blocks, receipts)
+
+	if err := tester.sync("peer", nil, FastSync); err != nil {
+		t.Fatalf("failed to synchronise blocks: %v", err)
+	}
+	assertOwnChain(t, tester, targetBlocks+1)
+
+	// The state proving the update was retrieved
+	parent := blocks[hashes[len(hashes)-300]]
+	if parent.NumberU64() != 299 {
+		t.Fatalf("parent number mismatch: have %v, want 299", parent.NumberU64())
+	}
+	if _, err := state.New(parent.Root(), state.NewDatabase(tester.stateDb)); err != nil {
+		t.Errorf("proof state missing: %v", err)
+	}
+}
+
+// Tests that fast sync fails on a witness list update contradicting the state
+// of its parent, instead of committing it.
+func TestFastSyncUnprovenWitnesses(t *testing.T) {
+	t.Parallel()
+
+	tester := newTester()
+	defer tester.terminate()
+	tester.downloader.witnesses = &witnessTester{unproven: 300}
+
+	targetBlocks := blockCacheItems - 15
+	hashes, headers, blocks, receipts := tester.makeChain(targetBlocks, 0, tester.genesis, nil, false)
+	tester.newPeer("peer", 63, hashes, headers, blocks, receipts)
+
+	if err := tester.sync("peer", nil, FastSync); err != errInvalidChain {
+		t.Fatalf("synchronisation error mismatch: have %v, want %v", err, errInvalidChain)
+	}
+	if rs := len(tester.ownReceipts); rs > 300 {
+		t.Errorf("unproven update committed: have %v receipts", rs)
+	}
+}
+
+// Tests that fast sync rejects headers without a valid commit certificate.
+func TestFastSyncForgedCertificate(t *testing.T) {
+	t.Parallel()
+
+	tester := newTester()
+	defer tester.terminate()
+	tester.downloader.witnesses = &witnessTester{forged: 100}
+
+	targetBlocks := blockCacheItems - 15
+	hashes, headers, blocks, receipts := tester.makeChain(targetBlocks, 0, tester.genesis, nil, false)
+	tester.newPeer("peer", 63, hashes, headers, blocks, receipts)
+
+	if err := tester.sync("peer", nil, FastSync); err != errInvalidChain {
+		t.Fatalf("synchronisation error mismatch: have %v, want %v", err, errInvalidChain)
+	}
+	if hs := len(tester.ownHeaders); hs > 100 {
+		t.Errorf("headers above the forged certificate imported: have %v", hs)
+	}
+}
+
+// Tests that witness list updates before the trusted checkpoint need no proof,
+// and that chains conflicting with the checkpoint are refused.
+func TestFastSyncCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	tester := newTester()
+	defer tester.terminate()
+
+	targetBlocks := blockCacheItems - 15
+	hashes, headers, blocks, receipts := tester.makeChain(targetBlocks, 0, tester.genesis, nil, false)
+
+	checkpoint := blocks[hashes[len(hashes)-301]].Header()
+	if checkpoint.Number.Uint64() != 300 {
+		t.Fatalf("checkpoint number mismatch: have %v, want 300", checkpoint.Number)
+	}
+	// A peer conflicting with the checkpoint is refused
+	tester.setCheckpoint(&params.Checkpoint{Number: 300, Hash: common.Hash{1}})
+	tester.downloader.witnesses = &witnessTester{}
+	tester.newPeer("peer", 63, hashes, headers, blocks, receipts)
+	if err := tester.sync("peer", nil, FastSync); err != errCheckpointMismatch {
+		t.Fatalf("synchronisation error mismatch: have %v, want %v", err, errCheckpointMismatch)
+	}
+	// The update before the checkpoint doesn't move the pivot
+	tester.setCheckpoint(&params.Checkpoint{Number: 300, Hash: checkpoint.Hash()})
+	tester.downloader.witnesses = &witnessTester{unendorsed: 200}
+	tester.newPeer("peer", 63, hashes, headers, blocks, receipts)
+	if err := tester.sync("peer", nil, FastSync); err != nil {
+		t.Fatalf("failed to synchronise blocks: %v", err)
+	}
+	assertOwnChain(t, tester, targetBlocks+1)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/vntchain/go-vnt/core"
 	"github.com/vntchain/go-vnt/vnt/downloader"
 	"github.com/vntchain/go-vnt/vnt/gasprice"
+	"github.com/vntchain/go-vnt/vnt/verifier"
 )
 
 var _ = (*configMarshaling)(nil)
@@ -19,36 +20,56 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		Genesis                 *core.Genesis `toml:",omitempty"`
 		NetworkId               uint64
 		SyncMode                downloader.SyncMode
-		LightServ               int  `toml:",omitempty"`
-		LightPeers              int  `toml:",omitempty"`
-		SkipBcVersionCheck      bool `toml:"-"`
-		DatabaseHandles         int  `toml:"-"`
+		PruneInterval           uint64 `toml:",omitempty"`
+		Snapshot                bool   `toml:",omitempty"`
+		LightServ               int    `toml:",omitempty"`
+		LightPeers              int    `toml:",omitempty"`
+		SkipBcVersionCheck      bool   `toml:"-"`
+		DatabaseHandles         int    `toml:"-"`
 		DatabaseCache           int
+		DatabaseFreezer         string
 		Etherbase               common.Address `toml:",omitempty"`
 		MinerThreads            int            `toml:",omitempty"`
+		MinerOnDemand           bool           `toml:",omitempty"`
 		ExtraData               hexutil.Bytes  `toml:",omitempty"`
 		GasPrice                *big.Int
 		TxPool                  core.TxPoolConfig
 		GPO                     gasprice.Config
 		EnablePreimageRecording bool
-		DocRoot                 string `toml:"-"`
+		Verifier                verifier.Config
+		PermissionRegistry      *common.Address  `toml:",omitempty"`
+		CheckpointFile          string           `toml:",omitempty"`
+		CheckpointSigners       []common.Address `toml:",omitempty"`
+		CheckpointThreshold     int              `toml:",omitempty"`
+		CheckpointTrustCert     bool             `toml:",omitempty"`
+		DocRoot                 string           `toml:"-"`
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
 	enc.NetworkId = c.NetworkId
 	enc.SyncMode = c.SyncMode
+	enc.PruneInterval = c.PruneInterval
+	enc.Snapshot = c.Snapshot
 	enc.LightServ = c.LightServ
 	enc.LightPeers = c.LightPeers
 	enc.SkipBcVersionCheck = c.SkipBcVersionCheck
 	enc.DatabaseHandles = c.DatabaseHandles
 	enc.DatabaseCache = c.DatabaseCache
+	enc.DatabaseFreezer = c.DatabaseFreezer
 	enc.Etherbase = c.Etherbase
 	enc.MinerThreads = c.MinerThreads
+	enc.MinerOnDemand = c.MinerOnDemand
 	enc.ExtraData = c.ExtraData
 	enc.GasPrice = c.GasPrice
 	enc.TxPool = c.TxPool
 	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
+	enc.Verifier = c.Verifier
+	enc.PermissionRegistry = c.PermissionRegistry
+	enc.CheckpointFile = c.CheckpointFile
+	enc.CheckpointSigners = c.CheckpointSigners
+	enc.CheckpointThreshold = c.CheckpointThreshold
+	enc.CheckpointTrustCert = c.CheckpointTrustCert
 	enc.DocRoot = c.DocRoot
 	return &enc, nil
 }
@@ -58,19 +79,29 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		Genesis                 *core.Genesis `toml:",omitempty"`
 		NetworkId               *uint64
 		SyncMode                *downloader.SyncMode
-		LightServ               *int  `toml:",omitempty"`
-		LightPeers              *int  `toml:",omitempty"`
-		SkipBcVersionCheck      *bool `toml:"-"`
-		DatabaseHandles         *int  `toml:"-"`
+		PruneInterval           *uint64 `toml:",omitempty"`
+		Snapshot                *bool   `toml:",omitempty"`
+		LightServ               *int    `toml:",omitempty"`
+		LightPeers              *int    `toml:",omitempty"`
+		SkipBcVersionCheck      *bool   `toml:"-"`
+		DatabaseHandles         *int    `toml:"-"`
 		DatabaseCache           *int
+		DatabaseFreezer         *string
 		Etherbase               *common.Address `toml:",omitempty"`
 		MinerThreads            *int            `toml:",omitempty"`
+		MinerOnDemand           *bool           `toml:",omitempty"`
 		ExtraData               *hexutil.Bytes  `toml:",omitempty"`
 		GasPrice                *big.Int
 		TxPool                  *core.TxPoolConfig
 		GPO                     *gasprice.Config
 		EnablePreimageRecording *bool
-		DocRoot                 *string `toml:"-"`
+		Verifier                *verifier.Config
+		PermissionRegistry      *common.Address  `toml:",omitempty"`
+		CheckpointFile          *string          `toml:",omitempty"`
+		CheckpointSigners       []common.Address `toml:",omitempty"`
+		CheckpointThreshold     *int             `toml:",omitempty"`
+		CheckpointTrustCert     *bool            `toml:",omitempty"`
+		DocRoot                 *string          `toml:"-"`
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -85,6 +116,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.SyncMode != nil {
 		c.SyncMode = *dec.SyncMode
 	}
+	if dec.PruneInterval != nil {
+		c.PruneInterval = *dec.PruneInterval
+	}
+	if dec.Snapshot != nil {
+		c.Snapshot = *dec.Snapshot
+	}
 	if dec.LightServ != nil {
 		c.LightServ = *dec.LightServ
 	}
@@ -100,12 +137,18 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.DatabaseCache != nil {
 		c.DatabaseCache = *dec.DatabaseCache
 	}
+	if dec.DatabaseFreezer != nil {
+		c.DatabaseFreezer = *dec.DatabaseFreezer
+	}
 	if dec.Etherbase != nil {
 		c.Etherbase = *dec.Etherbase
 	}
 	if dec.MinerThreads != nil {
 		c.MinerThreads = *dec.MinerThreads
 	}
+	if dec.MinerOnDemand != nil {
+		c.MinerOnDemand = *dec.MinerOnDemand
+	}
 	if dec.ExtraData != nil {
 		c.ExtraData = *dec.ExtraData
 	}
@@ -121,6 +164,24 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.EnablePreimageRecording != nil {
 		c.EnablePreimageRecording = *dec.EnablePreimageRecording
 	}
+	if dec.Verifier != nil {
+		c.Verifier = *dec.Verifier
+	}
+	if dec.PermissionRegistry != nil {
+		c.PermissionRegistry = dec.PermissionRegistry
+	}
+	if dec.CheckpointFile != nil {
+		c.CheckpointFile = *dec.CheckpointFile
+	}
+	if dec.CheckpointSigners != nil {
+		c.CheckpointSigners = dec.CheckpointSigners
+	}
+	if dec.CheckpointThreshold != nil {
+		c.CheckpointThreshold = *dec.CheckpointThreshold
+	}
+	if dec.CheckpointTrustCert != nil {
+		c.CheckpointTrustCert = *dec.CheckpointTrustCert
+	}
 	if dec.DocRoot != nil {
 		c.DocRoot = *dec.DocRoot
 	}
@@ -17,7 +17,6 @@
 package vnt
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -60,8 +59,19 @@ var (
 // not compatible (low protocol version restrictions and high requirements).
 var errIncompatibleConfig = errors.New("incompatible configuration")
 
+// protocolError is a breach of the protocol by the remote peer, as opposed to
+// local failures like closed connections or full queues.
+type protocolError struct {
+	code    errCode
+	message string
+}
+
+func (e *protocolError) Error() string {
+	return fmt.Sprintf("%v - %v", e.code, e.message)
+}
+
 func errResp(code errCode, format string, v ...interface{}) error {
-	return fmt.Errorf("%v - %v", code, fmt.Sprintf(format, v...))
+	return &protocolError{code: code, message: fmt.Sprintf(format, v...)}
 }
 
 type ProtocolManager struct {
@@ -71,6 +81,7 @@ type ProtocolManager struct {
 	acceptTxs uint32 // Flag whether we're considered synchronised (enables transaction processing)
 
 	txpool      txPool
+	engine      consensus.Engine
 	blockchain  *core.BlockChain
 	chainconfig *params.ChainConfig
 	maxPeers    int
@@ -78,6 +89,7 @@ type ProtocolManager struct {
 	downloader *downloader.Downloader
 	fetcher    *fetcher.Fetcher
 	peers      *peerSet
+	mesh       *bftMesh
 	node       *node.Node
 
 	SubProtocols []vntp2p.Protocol
@@ -108,9 +120,11 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 		networkId:   networkId,
 		eventMux:    mux,
 		txpool:      txpool,
+		engine:      engine,
 		blockchain:  blockchain,
 		chainconfig: config,
 		peers:       newPeerSet(),
+		mesh:        newBftMesh(),
 		newPeerCh:   make(chan *peer),
 		noMorePeers: make(chan struct{}),
 		txsyncCh:    make(chan *txsync),
@@ -135,9 +149,11 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 		// Compatible; initialise the sub-protocol
 		version := version // Closure for the run
 		manager.SubProtocols = append(manager.SubProtocols, vntp2p.Protocol{
-			Name:    ProtocolName,
-			Version: version,
-			Length:  ProtocolLengths[i],
+			Name:       ProtocolName,
+			Version:    version,
+			Length:     ProtocolLengths[i],
+			MaxMsgSize: ProtocolMaxMsgSize,
+			RateLimits: ProtocolRateLimits,
 			Run: func(p *vntp2p.Peer, rw vntp2p.MsgReadWriter) error {
 				peer := manager.newPeer(int(version), p, rw)
 				select {
@@ -154,7 +170,9 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 			},
 			PeerInfo: func(id libp2p.ID) interface{} {
 				if p := manager.peers.Peer(id); p != nil {
-					return p.Info()
+					info := p.Info()
+					info.Witness, info.Mesh = manager.peers.BftRole(id)
+					return info
 				}
 				return nil
 			},
@@ -164,7 +182,7 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 		return nil, errIncompatibleConfig
 	}
 	// Construct the different synchronisation mechanisms
-	manager.downloader = downloader.New(mode, chaindb, manager.eventMux, blockchain, nil, manager.removePeer)
+	manager.downloader = downloader.New(mode, chaindb, manager.eventMux, blockchain, nil, manager.dropSyncPeer)
 
 	validator := func(header *types.Header) error {
 		return engine.VerifyHeader(blockchain, header, true)
@@ -181,11 +199,33 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 		atomic.StoreUint32(&manager.acceptTxs, 1) // Mark initial sync done on any fetcher import
 		return manager.blockchain.InsertChain(blocks)
 	}
-	manager.fetcher = fetcher.New(blockchain.GetBlockByHash, validator, manager.BroadcastBlock, heighter, inserter, manager.removePeer)
+	manager.fetcher = fetcher.New(blockchain.GetBlockByHash, validator, manager.BroadcastBlock, heighter, inserter, manager.dropBadPeer)
 
 	return manager, nil
 }
 
+// dropBadPeer lowers the score of a peer which delivered invalid chain data and
+// removes it.
+func (pm *ProtocolManager) dropBadPeer(id libp2p.ID) {
+	if peer := pm.peers.Peer(id); peer != nil {
+		peer.Peer.Report(vntp2p.ScoreInvalidBlock)
+	}
+	pm.removePeer(id)
+}
+
+// dropSyncPeer lowers the score of a peer the downloader gave up on and removes
+// it. Slow peers are dropped as well, but they only lose the score of a timeout.
+func (pm *ProtocolManager) dropSyncPeer(id libp2p.ID, reason error) {
+	if downloader.IsTimeout(reason) {
+		if peer := pm.peers.Peer(id); peer != nil {
+			peer.Peer.Report(vntp2p.ScoreTimeout)
+		}
+		pm.removePeer(id)
+		return
+	}
+	pm.dropBadPeer(id)
+}
+
 func (pm *ProtocolManager) removePeer(id libp2p.ID) {
 	// Short circuit if the peer was already removed
 	peer := pm.peers.Peer(id)
@@ -205,34 +245,55 @@ func (pm *ProtocolManager) removePeer(id libp2p.ID) {
 	}
 }
 
-// resetBftPeer update current bft peer connection. If node not has connection
-// will them, will connecting to them. url format is:
+// resetBftPeer update current bft peer connection. The witnesses are reserved
+// peers of the p2p server, which keeps them connected even if the peer slots
+// are full. witnesses holds the addresses of the urls, or is nil if they are
+// not known. url format is:
 // /ip4/192.168.102.2/tcp/5216/ipfs/1kHBzN17vVE75rwZA7vKAFfxUYS8XMh6QBYS6JWF13xHGX9
-func (pm *ProtocolManager) resetBftPeer(urls []string) {
+func (pm *ProtocolManager) resetBftPeer(witnesses []common.Address, urls []string) {
+	if len(witnesses) != len(urls) {
+		witnesses = nil
+	}
 	pm.peers.lock.Lock()
-	defer pm.peers.lock.Unlock()
 
 	// Clean old records
 	pm.peers.bftPeers = make(map[libp2p.ID]struct{})
 
-	// Add new records, and addPeer if not connect
-	selfID := pm.node.Server().NodeInfo().ID
-	for _, url := range urls {
+	// Add new records, and reserve connections for them
+	var (
+		selfID    = pm.node.Server().NodeInfo().ID
+		self      libp2p.ID
+		reserved  []*vntp2p.Node
+		ids       []libp2p.ID
+		addresses []common.Address
+	)
+	for i, url := range urls {
 		node, err := vntp2p.ParseNode(url)
 		if err != nil {
 			log.Error("invalid vnode:", "error", err)
 			continue
 		}
+		ids = append(ids, node.Id)
+		if witnesses != nil {
+			addresses = append(addresses, witnesses[i])
+		}
 		if node.Id.ToString() == selfID {
+			self = node.Id
 			continue
 		}
 
 		pm.peers.bftPeers[node.Id] = struct{}{}
+		delete(pm.peers.meshPeers, node.Id)
+		reserved = append(reserved, node)
 		if _, exists := pm.peers.peers[node.Id]; !exists {
 			log.Debug("Reset bft peer, connecting to", "peer", url)
-			go pm.node.Server().AddPeer(context.Background(), node)
 		}
 	}
+	pm.peers.fillMesh()
+	pm.peers.lock.Unlock()
+
+	pm.mesh.reset(addresses, ids, self)
+	pm.node.Server().SetReservedPeers(reserved)
 }
 
 func (pm *ProtocolManager) Start(maxPeers int) {
@@ -254,6 +315,7 @@ func (pm *ProtocolManager) Start(maxPeers int) {
 	go pm.syncer()
 	go pm.txsyncLoop()
 	go pm.bftPeerLoop()
+	go pm.bftMeshLoop()
 }
 
 func (pm *ProtocolManager) Stop() {
@@ -290,11 +352,10 @@ func (pm *ProtocolManager) newPeer(pv int, p *vntp2p.Peer, rw vntp2p.MsgReadWrit
 // handle is the callback invoked to manage the life cycle of an vnt peer. When
 // this function terminates, the peer is disconnected.
 func (pm *ProtocolManager) handle(p *peer) error {
-	// Ignore maxPeers if this is a trusted peer
-
-	// if pm.peers.Len() >= pm.maxPeers && !p.Peer.Info().Network.Trusted {
-	// 	return vntp2p.DiscTooManyPeers
-	// }
+	// Ignore maxPeers if this is a trusted or reserved peer
+	if info := p.Peer.Info(); pm.peers.Len() >= pm.maxPeers && !info.Network.Trusted && !info.Network.Reserved {
+		return vntp2p.DiscTooManyPeers
+	}
 
 	// p.Log().Debug("VNT peer connected", "name", p.Name())
 
@@ -319,6 +380,7 @@ func (pm *ProtocolManager) handle(p *peer) error {
 		return err
 	}
 	defer pm.removePeer(p.id)
+	pm.mesh.touchPeer(p.id)
 
 	// Register the peer in the downloader. If the downloader considers it banned, we disconnect
 	if err := pm.downloader.RegisterPeer(p.id, p.version, p); err != nil {
@@ -337,6 +399,7 @@ func (pm *ProtocolManager) handle(p *peer) error {
 		// Start a timer to disconnect if the peer doesn't reply in time
 		p.forkDrop = time.AfterFunc(daoChallengeTimeout, func() {
 			p.Log().Debug("Timed out DAO fork-check, dropping")
+			p.Peer.Report(vntp2p.ScoreTimeout)
 			pm.removePeer(p.id)
 		})
 		// Make sure it's cleaned up if the peer dies off
@@ -358,12 +421,25 @@ func (pm *ProtocolManager) handle(p *peer) error {
 
 // handleMsg is invoked whenever an inbound message is received from a remote
 // peer. The remote connection is torn down upon returning any error.
-func (pm *ProtocolManager) handleMsg(p *peer) error {
+func (pm *ProtocolManager) handleMsg(p *peer) (err error) {
 	// Read the next message from the remote peer, and ensure it's fully consumed
 	msg, err := p.rw.ReadMsg()
 	if err != nil {
 		return err
 	}
+	// Lower the score of peers sending malformed or unexpected messages, local
+	// failures like closed connections or full queues are not their fault
+	defer func() {
+		if _, ok := err.(*protocolError); !ok {
+			return
+		}
+		switch msg.Body.Type {
+		case BftPreprepareMsg, BftPrepareMsg, BftCommitMsg:
+			p.Peer.Report(vntp2p.ScoreInvalidBftMsg)
+		default:
+			p.Peer.Report(vntp2p.ScoreInvalidMsg)
+		}
+	}()
 	size := msg.GetBodySize()
 	if size > ProtocolMaxMsgSize {
 		return errResp(ErrMsgTooLarge, "%v > %v", size, ProtocolMaxMsgSize)
@@ -502,6 +578,8 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			err := pm.downloader.DeliverHeaders(p.id, headers)
 			if err != nil {
 				log.Debug("Failed to deliver headers", "err", err)
+			} else if len(headers) > 0 {
+				p.Peer.Report(vntp2p.ScoreUsefulBlock)
 			}
 		}
 
@@ -509,7 +587,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		// Decode the retrieval message
 		msgStream := rlp.NewStream(msg.Body.Payload, uint64(msg.Body.PayloadSize))
 		if _, err := msgStream.List(); err != nil {
-			return err
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
 		}
 		// Gather blocks until the fetch or network limits is reached
 		var (
@@ -552,13 +630,15 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		err := pm.downloader.DeliverBodies(p.id, transactions)
 		if err != nil {
 			log.Debug("Failed to deliver bodies", "err", err)
+		} else if len(transactions) > 0 {
+			p.Peer.Report(vntp2p.ScoreUsefulBlock)
 		}
 
 	case p.version >= vnt63 && msg.Body.Type == GetNodeDataMsg:
 		// Decode the retrieval message
 		msgStream := rlp.NewStream(msg.Body.Payload, uint64(msg.Body.PayloadSize))
 		if _, err := msgStream.List(); err != nil {
-			return err
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
 		}
 		// Gather state data until the fetch or network limits is reached
 		var (
@@ -596,7 +676,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		// Decode the retrieval message
 		msgStream := rlp.NewStream(msg.Body.Payload, uint64(msg.Body.PayloadSize))
 		if _, err := msgStream.List(); err != nil {
-			return err
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
 		}
 		// Gather state data until the fetch or network limits is reached
 		var (
@@ -672,6 +752,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 	case msg.Body.Type == NewBlockMsg:
 		// This message is forbid. The peer is malicious and will be removed.
 		log.Info("Receive NewBlockMsg from", "peer", p.id)
+		p.Peer.Report(vntp2p.ScoreInvalidMsg)
 		pm.removePeer(p.id)
 
 	case msg.Body.Type == TxMsg:
@@ -698,21 +779,21 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			log.Error("Decode bftMsg Error", "err", err)
 			return errResp(ErrDecode, "msg %v: %v", msg, err)
 		}
-		pm.postRecBftEvent(&bftMsg)
+		return pm.handleBftMsg(p, &bftMsg)
 	case msg.Body.Type == BftPrepareMsg:
 		bftMsg := types.PrepareMsg{}
 		if err := msg.Decode(&bftMsg); err != nil {
 			log.Error("Decode bftMsg Error", "err", err)
 			return errResp(ErrDecode, "msg %v: %v", msg, err)
 		}
-		pm.postRecBftEvent(&bftMsg)
+		return pm.handleBftMsg(p, &bftMsg)
 	case msg.Body.Type == BftCommitMsg:
 		bftMsg := types.CommitMsg{}
 		if err := msg.Decode(&bftMsg); err != nil {
 			log.Error("Decode bftMsg Error", "err", err)
 			return errResp(ErrDecode, "msg %v: %v", msg, err)
 		}
-		pm.postRecBftEvent(&bftMsg)
+		return pm.handleBftMsg(p, &bftMsg)
 	default:
 		return errResp(ErrInvalidMsgCode, "%v", msg.Body.Type)
 	}
@@ -784,15 +865,12 @@ func (pm *ProtocolManager) BroadcastTxs(txs types.Transactions) {
 	}
 }
 
+// BroadcastBftMsg propagates a BFT message of the local witness to the
+// connected witnesses and the mesh peers, which relay it further.
 func (pm *ProtocolManager) BroadcastBftMsg(bftMsg types.BftMsg) {
 	log.Debug("BroadcastBftMsg", "type", bftMsg.BftType, "hash", bftMsg.Msg.Hash())
-	peers := pm.peers.PeersForBft()
-	for _, peer := range peers {
-		err := peer.SendBftMsg(bftMsg)
-		if err != nil {
-			log.Error("SendBftMsg ", "error", err)
-		}
-	}
+	pm.mesh.markSeen(bftMsg.Msg.Hash())
+	pm.relayBftMsg(bftMsg)
 }
 
 // Mined broadcast loop
@@ -835,7 +913,7 @@ func (pm *ProtocolManager) bftPeerLoop() {
 		switch ev := obj.Data.(type) {
 		case core.BftPeerChangeEvent:
 			log.Trace("Receive BftPeerChangeEvent")
-			pm.resetBftPeer(ev.Urls) // First propagate block to peers
+			pm.resetBftPeer(ev.Witnesses, ev.Urls) // First propagate block to peers
 		}
 	}
 }
@@ -848,6 +926,7 @@ type NodeInfo struct {
 	Genesis    common.Hash         `json:"genesis"`    // SHA3 hash of the host's genesis block
 	Config     *params.ChainConfig `json:"config"`     // Chain configuration for the fork rules
 	Head       common.Hash         `json:"head"`       // SHA3 hash of the host's best owned block
+	BftMesh    *BftMeshInfo        `json:"bftMesh"`    // Witness mesh view of the BFT message overlay
 }
 
 // NodeInfo retrieves some protocol metadata about the running host node.
@@ -859,5 +938,6 @@ func (pm *ProtocolManager) NodeInfo() *NodeInfo {
 		Genesis:    pm.blockchain.Genesis().Hash(),
 		Config:     pm.blockchain.Config(),
 		Head:       currentBlock.Hash(),
+		BftMesh:    pm.BftMeshInfo(),
 	}
 }
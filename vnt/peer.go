@@ -38,8 +38,9 @@ var (
 )
 
 const (
-	maxKnownTxs    = 32768 // Maximum transactions hashes to keep in the known list (prevent DOS)
-	maxKnownBlocks = 1024  // Maximum block hashes to keep in the known list (prevent DOS)
+	maxKnownTxs     = 32768 // Maximum transactions hashes to keep in the known list (prevent DOS)
+	maxKnownBlocks  = 1024  // Maximum block hashes to keep in the known list (prevent DOS)
+	maxKnownBftMsgs = 4096  // Maximum BFT message hashes to keep in the known list (prevent DOS)
 
 	// maxQueuedTxs is the maximum number of transaction lists to queue up before
 	// dropping broadcasts. This is a sensitive number as a transaction list might
@@ -56,6 +57,11 @@ const (
 	// above some healthy uncle limit, so use that.
 	maxQueuedAnns = 4
 
+	// maxQueuedBftMsgs is the maximum number of BFT messages to queue up before
+	// dropping broadcasts. A round has three messages of every witness, queueing
+	// more than a few rounds is pointless.
+	maxQueuedBftMsgs = 256
+
 	handshakeTimeout = 5 * time.Second
 )
 
@@ -65,6 +71,8 @@ type PeerInfo struct {
 	Version    int      `json:"version"`    // VNT protocol version negotiated
 	Difficulty *big.Int `json:"difficulty"` // Total difficulty of the peer's blockchain
 	Head       string   `json:"head"`       // SHA3 hash of the peer's best owned block
+	Witness    bool     `json:"witness"`    // Whether the peer is one of the current witnesses
+	Mesh       bool     `json:"mesh"`       // Whether BFT messages are relayed to the peer as a mesh neighbour
 }
 
 // propEvent is a block propagation, waiting for its turn in the broadcast queue.
@@ -91,24 +99,28 @@ type peer struct {
 	td   *big.Int
 	lock sync.RWMutex
 
-	knownTxs    *set.Set                  // Set of transaction hashes known to be known by this peer
-	knownBlocks *set.Set                  // Set of block hashes known to be known by this peer
-	queuedTxs   chan []*types.Transaction // Queue of transactions to broadcast to the peer
-	queuedAnns  chan *annoEvent           // Queue of blocks to announce to the peer
-	term        chan struct{}             // Termination channel to stop the broadcaster
+	knownTxs      *set.Set                  // Set of transaction hashes known to be known by this peer
+	knownBlocks   *set.Set                  // Set of block hashes known to be known by this peer
+	knownBftMsgs  *set.Set                  // Set of BFT message hashes known to be known by this peer
+	queuedTxs     chan []*types.Transaction // Queue of transactions to broadcast to the peer
+	queuedAnns    chan *annoEvent           // Queue of blocks to announce to the peer
+	queuedBftMsgs chan types.BftMsg         // Queue of BFT messages to relay to the peer
+	term          chan struct{}             // Termination channel to stop the broadcaster
 }
 
 func newPeer(version int, p *vntp2p.Peer, rw vntp2p.MsgReadWriter) *peer {
 	return &peer{
-		Peer:        p,
-		rw:          rw,
-		version:     version,
-		id:          p.RemoteID(), //fmt.Sprintf("%x", p.ID().Bytes()[:8]),
-		knownTxs:    set.New(),
-		knownBlocks: set.New(),
-		queuedTxs:   make(chan []*types.Transaction, maxQueuedTxs),
-		queuedAnns:  make(chan *annoEvent, maxQueuedAnns),
-		term:        make(chan struct{}),
+		Peer:          p,
+		rw:            rw,
+		version:       version,
+		id:            p.RemoteID(), //fmt.Sprintf("%x", p.ID().Bytes()[:8]),
+		knownTxs:      set.New(),
+		knownBlocks:   set.New(),
+		knownBftMsgs:  set.New(),
+		queuedTxs:     make(chan []*types.Transaction, maxQueuedTxs),
+		queuedAnns:    make(chan *annoEvent, maxQueuedAnns),
+		queuedBftMsgs: make(chan types.BftMsg, maxQueuedBftMsgs),
+		term:          make(chan struct{}),
 	}
 }
 
@@ -136,6 +148,12 @@ func (p *peer) broadcast() {
 			}
 			p.Log().Trace("Announced block", "number", anno.block.Number(), "hash", anno.block.Hash(), "peer", p.id)
 
+		case msg := <-p.queuedBftMsgs:
+			if err := p.SendBftMsg(msg); err != nil {
+				return
+			}
+			p.Log().Trace("Relayed BFT message", "type", msg.BftType, "hash", msg.Msg.Hash())
+
 		case <-p.term:
 			return
 		}
@@ -187,6 +205,16 @@ func (p *peer) MarkBlock(hash common.Hash) {
 	p.knownBlocks.Add(hash)
 }
 
+// MarkBftMsg marks a BFT message as known for the peer, ensuring that it will
+// never be relayed to this particular peer.
+func (p *peer) MarkBftMsg(hash common.Hash) {
+	// If we reached the memory allowance, drop a previously known message hash
+	for p.knownBftMsgs.Size() >= maxKnownBftMsgs {
+		p.knownBftMsgs.Pop()
+	}
+	p.knownBftMsgs.Add(hash)
+}
+
 // MarkTransaction marks a transaction as known for the peer, ensuring that it
 // will never be propagated to this particular peer.
 func (p *peer) MarkTransaction(hash common.Hash) {
@@ -268,7 +296,8 @@ func (p *peer) SendReceiptsRLP(receipts []rlp.RawValue) error {
 	return vntp2p.Send(p.rw, ProtocolName, ReceiptsMsg, receipts)
 }
 
-// SendBftMsg
+// SendBftMsg sends a BFT message to the peer and includes its hash in the
+// known BFT message set.
 func (p *peer) SendBftMsg(bftMsg types.BftMsg) error {
 	var msgType vntp2p.MessageType
 	switch bftMsg.BftType {
@@ -279,9 +308,21 @@ func (p *peer) SendBftMsg(bftMsg types.BftMsg) error {
 	case types.BftCommitMessage:
 		msgType = BftCommitMsg
 	}
+	p.MarkBftMsg(bftMsg.Msg.Hash())
 	return vntp2p.Send(p.rw, ProtocolName, msgType, bftMsg.Msg)
 }
 
+// AsyncSendBftMsg queues a BFT message for relaying to the remote peer. If the
+// peer's broadcast queue is full, the message is silently dropped.
+func (p *peer) AsyncSendBftMsg(bftMsg types.BftMsg) {
+	select {
+	case p.queuedBftMsgs <- bftMsg:
+		p.MarkBftMsg(bftMsg.Msg.Hash())
+	default:
+		p.Log().Debug("Dropping BFT message relay", "type", bftMsg.BftType, "hash", bftMsg.Msg.Hash())
+	}
+}
+
 // RequestOneHeader is a wrapper around the header query functions to fetch a
 // single header. It is used solely by the fetcher.
 func (p *peer) RequestOneHeader(hash common.Hash) error {
@@ -396,17 +437,19 @@ func (p *peer) String() string {
 // peerSet represents the collection of active peers currently participating in
 // the VNT sub-protocol.
 type peerSet struct {
-	peers    map[libp2p.ID]*peer
-	bftPeers map[libp2p.ID]struct{}
-	lock     sync.RWMutex
-	closed   bool
+	peers     map[libp2p.ID]*peer
+	bftPeers  map[libp2p.ID]struct{} // Witnesses, BFT messages are always relayed to them
+	meshPeers map[libp2p.ID]struct{} // Random other peers relaying BFT messages
+	lock      sync.RWMutex
+	closed    bool
 }
 
 // newPeerSet creates a new peer set to track the active participants.
 func newPeerSet() *peerSet {
 	return &peerSet{
-		peers:    make(map[libp2p.ID]*peer),
-		bftPeers: make(map[libp2p.ID]struct{}),
+		peers:     make(map[libp2p.ID]*peer),
+		bftPeers:  make(map[libp2p.ID]struct{}),
+		meshPeers: make(map[libp2p.ID]struct{}),
 	}
 }
 
@@ -424,6 +467,7 @@ func (ps *peerSet) Register(p *peer) error {
 		return errAlreadyRegistered
 	}
 	ps.peers[p.id] = p
+	ps.fillMesh()
 	go p.broadcast()
 
 	return nil
@@ -440,6 +484,8 @@ func (ps *peerSet) Unregister(id libp2p.ID) error {
 		return errNotRegistered
 	}
 	delete(ps.peers, id)
+	delete(ps.meshPeers, id)
+	ps.fillMesh()
 	p.close()
 
 	return nil
@@ -491,20 +537,58 @@ func (ps *peerSet) PeersWithoutTx(hash common.Hash) []*peer {
 	return list
 }
 
-func (ps *peerSet) PeersForBft() []*peer {
+// PeersWithoutBftMsg retrieves a list of the connected witnesses and mesh peers
+// that do not have a given BFT message in their set of known hashes.
+func (ps *peerSet) PeersWithoutBftMsg(hash common.Hash) []*peer {
 	ps.lock.RLock()
 	defer ps.lock.RUnlock()
 
-	list := make([]*peer, 0, len(ps.bftPeers))
-	for p, _ := range ps.bftPeers {
-		// 该处需要判断是不是bft节点
-		if peer, exists := ps.peers[p]; exists {
-			list = append(list, peer)
+	list := make([]*peer, 0, len(ps.bftPeers)+len(ps.meshPeers))
+	for _, relays := range []map[libp2p.ID]struct{}{ps.bftPeers, ps.meshPeers} {
+		for id := range relays {
+			if p, exists := ps.peers[id]; exists && !p.knownBftMsgs.Has(hash) {
+				list = append(list, p)
+			}
 		}
 	}
 	return list
 }
 
+// BftRole reports whether a peer is a witness and whether it is a mesh peer.
+func (ps *peerSet) BftRole(id libp2p.ID) (witness bool, mesh bool) {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	_, witness = ps.bftPeers[id]
+	_, mesh = ps.meshPeers[id]
+	return witness, mesh
+}
+
+// MeshPeers returns the ids of the mesh peers.
+func (ps *peerSet) MeshPeers() []libp2p.ID {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	list := make([]libp2p.ID, 0, len(ps.meshPeers))
+	for id := range ps.meshPeers {
+		list = append(list, id)
+	}
+	return list
+}
+
+// fillMesh tops up the mesh with random connected peers which are not witnesses.
+// The caller must hold the lock.
+func (ps *peerSet) fillMesh() {
+	for id := range ps.peers {
+		if len(ps.meshPeers) >= bftMeshDegree {
+			return
+		}
+		if _, witness := ps.bftPeers[id]; !witness {
+			ps.meshPeers[id] = struct{}{}
+		}
+	}
+}
+
 // BestPeer retrieves the known peer with the currently highest total difficulty.
 func (ps *peerSet) BestPeer() *peer {
 	ps.lock.RLock()
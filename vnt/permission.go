@@ -0,0 +1,127 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vnt
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/vntchain/go-vnt/accounts/abi"
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core"
+	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/core/vm"
+	"github.com/vntchain/go-vnt/log"
+	"github.com/vntchain/go-vnt/vntp2p"
+)
+
+// permissionRegistryABI is the interface of the registry contract of a
+// permissioned network. GetNodes returns the allowed node urls or peer IDs,
+// separated by commas.
+const permissionRegistryABI = `[{"name":"GetNodes","constant":true,"inputs":[],"outputs":[{"name":"","type":"string"}],"type":"function"}]`
+
+// registryCallGas caps the gas of a registry read.
+const registryCallGas = 50000000
+
+// permissionRegistry reads the allow-list of a permissioned network from the
+// registry contract.
+type permissionRegistry struct {
+	address common.Address
+	abi     abi.ABI
+	chain   *core.BlockChain
+}
+
+func newPermissionRegistry(address common.Address, chain *core.BlockChain) (*permissionRegistry, error) {
+	registryABI, err := abi.JSON(strings.NewReader(permissionRegistryABI))
+	if err != nil {
+		return nil, err
+	}
+	return &permissionRegistry{address: address, abi: registryABI, chain: chain}, nil
+}
+
+// nodes calls the registry in the state of the block.
+func (r *permissionRegistry) nodes(header *types.Header) ([]string, error) {
+	statedb, err := r.chain.StateAt(header.Root)
+	if err != nil {
+		return nil, err
+	}
+	if len(statedb.GetCode(r.address)) == 0 {
+		return nil, fmt.Errorf("no registry contract at %x", r.address)
+	}
+	input, err := r.abi.Pack("GetNodes")
+	if err != nil {
+		return nil, err
+	}
+	msg := types.NewMessage(common.Address{}, &r.address, 0, new(big.Int), registryCallGas, new(big.Int), input, false)
+	context := core.NewVMContext(msg, header, r.chain, nil)
+	res, _, failed, err := core.ApplyMessage(core.GetVM(msg, context, statedb, r.chain.Config(), vm.Config{}), msg, new(core.GasPool).AddGas(math.MaxUint64))
+	if err != nil {
+		return nil, err
+	}
+	if failed {
+		return nil, fmt.Errorf("registry call failed")
+	}
+	var list string
+	if err := r.abi.Unpack(&list, "GetNodes", res); err != nil {
+		return nil, err
+	}
+	var nodes []string
+	for _, entry := range strings.Split(list, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			nodes = append(nodes, entry)
+		}
+	}
+	return nodes, nil
+}
+
+// permissionLoop feeds the allow-list of the registry to the p2p server at
+// every new block. The previous list stays in force while the registry can't
+// be read.
+func (s *VNT) permissionLoop(srvr *vntp2p.Server, registry *permissionRegistry) {
+	heads := make(chan core.ChainHeadEvent, 16)
+	sub := s.blockchain.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	var lastErr string
+	update := func(header *types.Header) {
+		nodes, err := registry.nodes(header)
+		if err != nil {
+			if err.Error() != lastErr {
+				log.Warn("Failed to read permission registry", "number", header.Number, "registry", registry.address, "err", err)
+				lastErr = err.Error()
+			}
+			return
+		}
+		lastErr = ""
+		if err := srvr.SetRegistryPeers(nodes); err != nil {
+			log.Warn("Failed to update permissioned peers", "err", err)
+		}
+	}
+	update(s.blockchain.CurrentHeader())
+	for {
+		select {
+		case ev := <-heads:
+			update(ev.Block.Header())
+		case <-sub.Err():
+			return
+		case <-s.shutdownChan:
+			return
+		}
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/vntchain/go-vnt/core/types"
 	"github.com/vntchain/go-vnt/event"
 	"github.com/vntchain/go-vnt/rlp"
+	"github.com/vntchain/go-vnt/vntp2p"
 )
 
 // Constants to match up protocol versions and messages
@@ -45,6 +46,17 @@ var ProtocolLengths = []uint64{20, 8}
 
 const ProtocolMaxMsgSize = 10 * 1024 * 1024 // Maximum cap on the size of a protocol message
 
+// ProtocolRateLimits are the message rates a peer may send of the request and
+// gossip messages. Consensus messages are not limited.
+var ProtocolRateLimits = map[vntp2p.MessageType]vntp2p.RateLimit{
+	NewBlockHashesMsg:  {Rate: 20, Burst: 100},
+	TxMsg:              {Rate: 50, Burst: 200},
+	GetBlockHeadersMsg: {Rate: 20, Burst: 50},
+	GetBlockBodiesMsg:  {Rate: 20, Burst: 50},
+	GetNodeDataMsg:     {Rate: 20, Burst: 50},
+	GetReceiptsMsg:     {Rate: 20, Burst: 50},
+}
+
 // vnt protocol message codes
 const (
 	// Protocol messages belonging to vnt/62
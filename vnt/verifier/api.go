@@ -0,0 +1,56 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package verifier
+
+import (
+	"context"
+
+	"github.com/vntchain/go-vnt/common"
+)
+
+// PublicVerifierAPI provides an API to query verified contract sources.
+type PublicVerifierAPI struct {
+	v *Verifier
+}
+
+// NewPublicVerifierAPI creates a new API to query verified contracts.
+func NewPublicVerifierAPI(v *Verifier) *PublicVerifierAPI {
+	return &PublicVerifierAPI{v}
+}
+
+// GetContractMetadata returns the verified source and abi of the contract at
+// the given address, or nil if it was not verified.
+func (api *PublicVerifierAPI) GetContractMetadata(ctx context.Context, address common.Address) (*Metadata, error) {
+	return api.v.Metadata(address)
+}
+
+// PrivateVerifierAPI provides an API to submit contract sources. Verifying a
+// source runs the local toolchain, so it is only exposed to node operators.
+type PrivateVerifierAPI struct {
+	v *Verifier
+}
+
+// NewPrivateVerifierAPI creates a new API to submit contract sources.
+func NewPrivateVerifierAPI(v *Verifier) *PrivateVerifierAPI {
+	return &PrivateVerifierAPI{v}
+}
+
+// VerifyContract packages the submitted source and stores it as the verified
+// source of the contract if the result matches the deployed code.
+func (api *PrivateVerifierAPI) VerifyContract(ctx context.Context, src Source) (*Metadata, error) {
+	return api.v.Verify(&src)
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package verifier
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// toolchain compiles sources by running wasmgen, which drives clang and the
+// wasm linker.
+type toolchain struct {
+	config Config
+}
+
+// NewToolchain returns the compiler running the wasmgen packaging pipeline
+// with the tools configured.
+func NewToolchain(config Config) Compiler {
+	return &toolchain{config: config}
+}
+
+func (t *toolchain) Versions() (string, string, error) {
+	out, err := output(t.config.Timeout, t.config.Wasmgen, "version")
+	if err != nil {
+		return "", "", err
+	}
+	wasmgen := strings.TrimSpace(out)
+
+	if out, err = output(t.config.Timeout, t.config.Clang, "--version"); err != nil {
+		return "", "", err
+	}
+	clang, err := parseClangVersion(out)
+	if err != nil {
+		return "", "", err
+	}
+	return wasmgen, clang, nil
+}
+
+func (t *toolchain) Compile(source []byte) ([]byte, []byte, error) {
+	dir, err := ioutil.TempDir("", "vnt-verifier")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		codePath = filepath.Join(dir, "contract.c")
+		outDir   = filepath.Join(dir, "output")
+	)
+	if err := ioutil.WriteFile(codePath, source, 0644); err != nil {
+		return nil, nil, err
+	}
+	if _, err := output(t.config.Timeout, t.config.Wasmgen, "compile", "-nopack",
+		"-code", codePath, "-output", outDir, "-include", t.config.Include,
+		"-clang", t.config.Clang, "-wasm-ld", t.config.WasmLd); err != nil {
+		return nil, nil, err
+	}
+	code, err := ioutil.ReadFile(filepath.Join(outDir, "contract.wasm"))
+	if err != nil {
+		return nil, nil, err
+	}
+	abiJSON, err := ioutil.ReadFile(filepath.Join(outDir, "abi.json"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return code, abiJSON, nil
+}
+
+// output runs a tool, returning its standard output. The tool and everything
+// it started are killed if it runs longer than timeout.
+func output(timeout time.Duration, name string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("%s failed: %v", filepath.Base(name), err)
+	}
+	timer := time.AfterFunc(timeout, func() { killProcessGroup(cmd) })
+	err := cmd.Wait()
+	if !timer.Stop() {
+		return "", fmt.Errorf("%s timed out after %v", filepath.Base(name), timeout)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %v: %s", filepath.Base(name), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// parseClangVersion extracts the version from the output of clang --version,
+// whose first line looks like "clang version 7.0.0 (tags/RELEASE_700/final)".
+func parseClangVersion(out string) (string, error) {
+	fields := strings.Fields(strings.SplitN(out, "\n", 2)[0])
+	for i, field := range fields {
+		if field == "version" && i+1 < len(fields) {
+			return fields[i+1], nil
+		}
+	}
+	return "", fmt.Errorf("unknown clang version %q", out)
+}
@@ -0,0 +1,290 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package verifier implements a registry of verified wasm contract sources.
+//
+// A submitted C source is packaged again with the local wasmgen and clang
+// toolchain, the resulting code and abi have to be byte for byte identical to
+// the ones deployed on chain before the source is accepted.
+package verifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/state"
+	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/core/wavm"
+	"github.com/vntchain/go-vnt/core/wavm/utils"
+	"github.com/vntchain/go-vnt/log"
+	"github.com/vntchain/go-vnt/vntdb"
+)
+
+var (
+	// ErrDisabled is returned if sources are submitted to a node which does not
+	// verify contracts.
+	ErrDisabled = errors.New("contract verification disabled")
+
+	// ErrNoContract is returned if there is no wasm contract at the address.
+	ErrNoContract = errors.New("no contract at address")
+
+	// ErrCodeMismatch is returned if the packaged source differs from the code
+	// deployed on chain.
+	ErrCodeMismatch = errors.New("compiled code does not match the deployed code")
+
+	// ErrAbiMismatch is returned if the abi generated from the source differs
+	// from the one deployed on chain.
+	ErrAbiMismatch = errors.New("generated abi does not match the deployed abi")
+
+	// ErrForbiddenInclude is returned if the source includes anything but the
+	// contract library.
+	ErrForbiddenInclude = errors.New("only vntlib.h may be included")
+
+	// ErrCompileFailed is returned if the source could not be packaged. The
+	// compiler output is not returned, it could reveal files of the node.
+	ErrCompileFailed = errors.New("compilation failed")
+)
+
+// metadataPrefix + address -> json encoded metadata
+var metadataPrefix = []byte("verified-")
+
+// Config are the configuration parameters of the contract verifier.
+type Config struct {
+	Enabled bool   // Whether contract sources can be submitted
+	Persist bool   // Whether verified contracts are kept across restarts
+	Wasmgen string `toml:",omitempty"` // Path of the wasmgen tool
+	Clang   string `toml:",omitempty"` // Path of the clang compiler
+	WasmLd  string `toml:",omitempty"` // Path of the wasm linker
+	Include string `toml:",omitempty"` // Directory containing vntlib.h
+
+	Timeout time.Duration // Maximum time packaging a source may take
+}
+
+// DefaultConfig contains the default settings of the contract verifier.
+var DefaultConfig = Config{
+	Wasmgen: "wasmgen",
+	Clang:   "clang",
+	WasmLd:  "wasm-ld",
+	Timeout: time.Minute,
+}
+
+// Backend gives access to the state the deployed contracts are read from.
+type Backend interface {
+	CurrentBlock() *types.Block
+	State() (*state.StateDB, error)
+}
+
+// Compiler packages a contract source the same way wasmgen does for deployment.
+type Compiler interface {
+	// Versions returns the versions of wasmgen and clang used for compiling.
+	Versions() (wasmgen string, clang string, err error)
+
+	// Compile packages the C source, returning the wasm code and the abi.
+	Compile(source []byte) (code []byte, abi []byte, err error)
+}
+
+// Source is a contract source submitted for verification.
+type Source struct {
+	Address        common.Address `json:"address"`
+	Source         string         `json:"source"`
+	WasmgenVersion string         `json:"wasmgenVersion"`
+	ClangVersion   string         `json:"clangVersion"`
+}
+
+// Metadata describes a verified contract.
+type Metadata struct {
+	Address        common.Address  `json:"address"`
+	CodeHash       common.Hash     `json:"codeHash"`
+	Source         string          `json:"source"`
+	Abi            json.RawMessage `json:"abi"`
+	WasmgenVersion string          `json:"wasmgenVersion"`
+	ClangVersion   string          `json:"clangVersion"`
+	VerifiedAt     uint64          `json:"verifiedAt"` // Block number the contract was verified at
+}
+
+// Verifier checks submitted contract sources and keeps the verified ones.
+type Verifier struct {
+	config   Config
+	backend  Backend
+	compiler Compiler
+	db       vntdb.Database
+
+	lock sync.Mutex // Serializes compilations, they are expensive
+}
+
+// New creates a contract verifier storing the verified contracts in db.
+func New(config Config, backend Backend, compiler Compiler, db vntdb.Database) *Verifier {
+	return &Verifier{
+		config:   config,
+		backend:  backend,
+		compiler: compiler,
+		db:       db,
+	}
+}
+
+// Verify packages the source and compares it with the contract deployed at
+// the address, the metadata is stored if they match.
+func (v *Verifier) Verify(src *Source) (*Metadata, error) {
+	if !v.config.Enabled {
+		return nil, ErrDisabled
+	}
+	statedb, err := v.backend.State()
+	if err != nil {
+		return nil, err
+	}
+	number := v.backend.CurrentBlock().NumberU64()
+	deployed, err := deployedContract(statedb, src.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	wasmgenVersion, clangVersion, err := v.compiler.Versions()
+	if err != nil {
+		return nil, err
+	}
+	if src.WasmgenVersion != wasmgenVersion {
+		return nil, fmt.Errorf("wasmgen version mismatch: have %s, want %s", src.WasmgenVersion, wasmgenVersion)
+	}
+	if src.ClangVersion != clangVersion {
+		return nil, fmt.Errorf("clang version mismatch: have %s, want %s", src.ClangVersion, clangVersion)
+	}
+	if err := checkIncludes(src.Source); err != nil {
+		return nil, err
+	}
+	code, abiJSON, err := v.compiler.Compile([]byte(src.Source))
+	if err != nil {
+		log.Debug("Failed to compile contract source", "address", src.Address, "err", err)
+		return nil, ErrCompileFailed
+	}
+	if !bytes.Equal(code, deployed.Code) {
+		return nil, ErrCodeMismatch
+	}
+	if !bytes.Equal(abiJSON, deployed.Abi) {
+		return nil, ErrAbiMismatch
+	}
+	meta := &Metadata{
+		Address:        src.Address,
+		CodeHash:       statedb.GetCodeHash(src.Address),
+		Source:         src.Source,
+		Abi:            json.RawMessage(abiJSON),
+		WasmgenVersion: wasmgenVersion,
+		ClangVersion:   clangVersion,
+		VerifiedAt:     number,
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.db.Put(metadataKey(src.Address), data); err != nil {
+		return nil, err
+	}
+	log.Info("Verified contract source", "address", src.Address, "codehash", meta.CodeHash)
+	return meta, nil
+}
+
+// Metadata returns the metadata of a verified contract, or nil if the contract
+// at the address was not verified. Metadata of code which is no longer deployed
+// at the address is dropped.
+func (v *Verifier) Metadata(address common.Address) (*Metadata, error) {
+	data, _ := v.db.Get(metadataKey(address))
+	if len(data) == 0 {
+		return nil, nil
+	}
+	meta := new(Metadata)
+	if err := json.Unmarshal(data, meta); err != nil {
+		return nil, err
+	}
+	statedb, err := v.backend.State()
+	if err != nil {
+		return nil, err
+	}
+	if hash := statedb.GetCodeHash(address); hash != meta.CodeHash {
+		log.Info("Dropping outdated contract source", "address", address, "verified", meta.CodeHash, "deployed", hash)
+		if err := v.db.Delete(metadataKey(address)); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	return meta, nil
+}
+
+// Close releases the storage of the verifier.
+func (v *Verifier) Close() {
+	v.db.Close()
+}
+
+// deployedContract extracts the wasm code and abi from the container stored at
+// the address.
+func deployedContract(statedb *state.StateDB, address common.Address) (*wavm.WasmCode, error) {
+	code := statedb.GetCode(address)
+	if len(code) == 0 {
+		return nil, ErrNoContract
+	}
+	decompress, err := utils.DeCompress(code)
+	if err != nil {
+		return nil, err
+	}
+	deployed, _, err := wavm.UnpackDeployPayload(decompress)
+	if err != nil {
+		return nil, err
+	}
+	return deployed, nil
+}
+
+var (
+	// lineSpliceRE matches the backslash-newline sequences joining lines
+	lineSpliceRE = regexp.MustCompile(`\\\r?\n`)
+
+	// commentRE matches the comments of a source, which may hide directives
+	commentRE = regexp.MustCompile(`(?s)/\*.*?\*/|//[^\n]*`)
+
+	// includeRE matches the directives reading other files into the source
+	includeRE = regexp.MustCompile(`^\s*(?:#|%:)\s*(include|include_next|import|embed)\b\s*(.*)$`)
+)
+
+// checkIncludes makes sure the source can't read any file of the node except
+// the contract library.
+func checkIncludes(source string) error {
+	source = lineSpliceRE.ReplaceAllString(source, "")
+	source = commentRE.ReplaceAllString(source, " ")
+	if strings.Contains(source, "__has_include") {
+		return ErrForbiddenInclude
+	}
+	for _, line := range strings.Split(source, "\n") {
+		match := includeRE.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		if file := strings.TrimSpace(match[2]); file != `"vntlib.h"` && file != "<vntlib.h>" {
+			return ErrForbiddenInclude
+		}
+	}
+	return nil
+}
+
+func metadataKey(address common.Address) []byte {
+	return append(append([]byte{}, metadataPrefix...), address.Bytes()...)
+}
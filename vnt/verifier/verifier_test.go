@@ -0,0 +1,245 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package verifier
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/state"
+	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/core/wavm"
+	"github.com/vntchain/go-vnt/core/wavm/utils"
+	"github.com/vntchain/go-vnt/vntdb"
+)
+
+type testBackend struct {
+	statedb *state.StateDB
+}
+
+func (b *testBackend) CurrentBlock() *types.Block {
+	return types.NewBlockWithHeader(&types.Header{Number: big.NewInt(7)})
+}
+
+func (b *testBackend) State() (*state.StateDB, error) {
+	return b.statedb, nil
+}
+
+// testCompiler returns fixed output for the source "erc20".
+type testCompiler struct {
+	code, abi []byte
+}
+
+func (c *testCompiler) Versions() (string, string, error) {
+	return "0.6.0", "7.0.0", nil
+}
+
+func (c *testCompiler) Compile(source []byte) ([]byte, []byte, error) {
+	if string(source) != "erc20" {
+		return nil, nil, errors.New("compile error")
+	}
+	return c.code, c.abi, nil
+}
+
+func newTestVerifier(t *testing.T, enabled bool) (*Verifier, *testCompiler, common.Address) {
+	code, err := ioutil.ReadFile("../../core/wavm/testdata/erc20/erc20.wasm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	abiJSON, err := ioutil.ReadFile("../../core/wavm/testdata/erc20/abi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Store the contract the way the virtual machine does after creation
+	container, err := json.Marshal(wavm.WasmCode{Code: code, Abi: abiJSON, Compiled: []byte("[]")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(vntdb.NewMemDatabase()))
+	address := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	statedb.SetCode(address, utils.Compress(container))
+
+	config := DefaultConfig
+	config.Enabled = enabled
+	compiler := &testCompiler{code: code, abi: abiJSON}
+	return New(config, &testBackend{statedb}, compiler, vntdb.NewMemDatabase()), compiler, address
+}
+
+func TestVerify(t *testing.T) {
+	v, _, address := newTestVerifier(t, true)
+
+	if meta, _ := v.Metadata(address); meta != nil {
+		t.Fatalf("unverified contract has metadata: %v", meta)
+	}
+	src := &Source{Address: address, Source: "erc20", WasmgenVersion: "0.6.0", ClangVersion: "7.0.0"}
+	meta, err := v.Verify(src)
+	if err != nil {
+		t.Fatalf("failed to verify contract: %v", err)
+	}
+	if meta.VerifiedAt != 7 {
+		t.Errorf("verified at mismatch: have %d, want 7", meta.VerifiedAt)
+	}
+	stored, err := v.Metadata(address)
+	if err != nil || stored == nil {
+		t.Fatalf("failed to read metadata: %v", err)
+	}
+	if stored.Source != "erc20" || stored.CodeHash != meta.CodeHash || len(stored.Abi) == 0 {
+		t.Errorf("stored metadata mismatch: have %+v, want %+v", stored, meta)
+	}
+}
+
+func TestVerifyMismatch(t *testing.T) {
+	v, compiler, address := newTestVerifier(t, true)
+
+	tests := []struct {
+		src  Source
+		code []byte
+		err  error
+	}{
+		{Source{Address: common.Address{}, Source: "erc20", WasmgenVersion: "0.6.0", ClangVersion: "7.0.0"}, nil, ErrNoContract},
+		{Source{Address: address, Source: "erc20", WasmgenVersion: "0.5.0", ClangVersion: "7.0.0"}, nil, nil},
+		{Source{Address: address, Source: "erc20", WasmgenVersion: "0.6.0", ClangVersion: "6.0.0"}, nil, nil},
+		{Source{Address: address, Source: "other", WasmgenVersion: "0.6.0", ClangVersion: "7.0.0"}, nil, nil},
+		{Source{Address: address, Source: "erc20", WasmgenVersion: "0.6.0", ClangVersion: "7.0.0"}, []byte{0x00, 0x61, 0x73, 0x6d}, ErrCodeMismatch},
+	}
+	code := compiler.code
+	for i, tt := range tests {
+		compiler.code = code
+		if tt.code != nil {
+			compiler.code = tt.code
+		}
+		_, err := v.Verify(&tt.src)
+		if err == nil || (tt.err != nil && err != tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	if meta, _ := v.Metadata(address); meta != nil {
+		t.Errorf("failed verification stored metadata: %v", meta)
+	}
+}
+
+func TestVerifyCompileFailed(t *testing.T) {
+	v, _, address := newTestVerifier(t, true)
+
+	src := &Source{Address: address, Source: "other", WasmgenVersion: "0.6.0", ClangVersion: "7.0.0"}
+	if _, err := v.Verify(src); err != ErrCompileFailed {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrCompileFailed)
+	}
+	src.Source = "#include \"/etc/passwd\"\n"
+	if _, err := v.Verify(src); err != ErrForbiddenInclude {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrForbiddenInclude)
+	}
+}
+
+func TestMetadataOutdated(t *testing.T) {
+	v, _, address := newTestVerifier(t, true)
+
+	src := &Source{Address: address, Source: "erc20", WasmgenVersion: "0.6.0", ClangVersion: "7.0.0"}
+	if _, err := v.Verify(src); err != nil {
+		t.Fatalf("failed to verify contract: %v", err)
+	}
+	// Replace the code, the source no longer describes the contract
+	statedb, _ := v.backend.State()
+	statedb.SetCode(address, []byte{0x01})
+	if meta, err := v.Metadata(address); meta != nil || err != nil {
+		t.Fatalf("outdated metadata returned: %v, %v", meta, err)
+	}
+	if data, _ := v.db.Get(metadataKey(address)); len(data) != 0 {
+		t.Errorf("outdated metadata not dropped")
+	}
+}
+
+func TestCheckIncludes(t *testing.T) {
+	tests := []struct {
+		source string
+		ok     bool
+	}{
+		{"#include \"vntlib.h\"\nint a;", true},
+		{"  #  include <vntlib.h>\n", true},
+		{"char *s = \"#include <stdio.h>\";", true},
+		{"#include \"/etc/passwd\"", false},
+		{"#include <stdio.h>", false},
+		{"#import \"secret.h\"", false},
+		{"#include_next <vntlib.h> \"x\"", false},
+		{"%:include \"secret.h\"", false},
+		{"#/* hidden */include \"secret.h\"", false},
+		{"#inc\\\nlude \"secret.h\"", false},
+		{"#define F \"/etc/passwd\"\n#include F", false},
+		{"#if __has_include(\"/etc/passwd\")\n#endif", false},
+	}
+	for i, tt := range tests {
+		if err := checkIncludes(tt.source); (err == nil) != tt.ok {
+			t.Errorf("test %d: include check mismatch for %q: %v", i, tt.source, err)
+		}
+	}
+}
+
+func TestOutputTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no sleep command")
+	}
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("no sleep command")
+	}
+	start := time.Now()
+	if _, err := output(100*time.Millisecond, sleep, "10"); err == nil {
+		t.Fatal("expected timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("tool not killed on timeout, took %v", elapsed)
+	}
+	if out, err := output(time.Minute, sleep, "0"); err != nil || out != "" {
+		t.Errorf("unexpected result: %q, %v", out, err)
+	}
+}
+
+func TestVerifyDisabled(t *testing.T) {
+	v, _, address := newTestVerifier(t, false)
+
+	src := &Source{Address: address, Source: "erc20", WasmgenVersion: "0.6.0", ClangVersion: "7.0.0"}
+	if _, err := v.Verify(src); err != ErrDisabled {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrDisabled)
+	}
+}
+
+func TestParseClangVersion(t *testing.T) {
+	tests := []struct {
+		out     string
+		version string
+	}{
+		{"clang version 7.0.0 (tags/RELEASE_700/final)\nTarget: x86_64-pc-linux-gnu\n", "7.0.0"},
+		{"Ubuntu clang version 14.0.0-1ubuntu1\n", "14.0.0-1ubuntu1"},
+		{"Apple LLVM version 10.0.0 (clang-1000.10.44.4)\n", "10.0.0"},
+	}
+	for i, tt := range tests {
+		version, err := parseClangVersion(tt.out)
+		if err != nil || version != tt.version {
+			t.Errorf("test %d: version mismatch: have %s (%v), want %s", i, version, err, tt.version)
+		}
+	}
+	if _, err := parseClangVersion("gcc"); err == nil {
+		t.Error("expected error for unknown output")
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/common/hexutil"
+	"github.com/vntchain/go-vnt/core/state"
+	"github.com/vntchain/go-vnt/core/types"
+	"github.com/vntchain/go-vnt/core/vm/election"
+	"github.com/vntchain/go-vnt/crypto"
+	"github.com/vntchain/go-vnt/rlp"
+	"github.com/vntchain/go-vnt/trie"
+	"github.com/vntchain/go-vnt/vntdb"
+)
+
+var (
+	errProofMismatch = errors.New("proof mismatch")
+	errProofKeys     = errors.New("proof storage keys mismatch")
+)
+
+// AccountResult is the Merkle proof of an account, along with the proofs of some
+// of its storage slots.
+type AccountResult struct {
+	Address      common.Address
+	AccountProof [][]byte
+	Balance      *big.Int
+	CodeHash     common.Hash
+	Nonce        uint64
+	StorageHash  common.Hash
+	StorageProof []StorageResult
+
+	keys keyDeriver // Derives the storage keys the proof has to hold, nil if unknown
+}
+
+// keyDeriver derives the storage keys expected in a proof, reading the values
+// needed to derive them through get.
+type keyDeriver func(get func(key common.Hash) common.Hash) []common.Hash
+
+// StorageResult is the Merkle proof of a storage slot.
+type StorageResult struct {
+	Key   common.Hash
+	Value *big.Int
+	Proof [][]byte
+}
+
+type rpcAccountResult struct {
+	Address      common.Address     `json:"address"`
+	AccountProof []hexutil.Bytes    `json:"accountProof"`
+	Balance      *hexutil.Big       `json:"balance"`
+	CodeHash     common.Hash        `json:"codeHash"`
+	Nonce        hexutil.Uint64     `json:"nonce"`
+	StorageHash  common.Hash        `json:"storageHash"`
+	StorageProof []rpcStorageResult `json:"storageProof"`
+}
+
+type rpcStorageResult struct {
+	Key   common.Hash     `json:"key"`
+	Value *hexutil.Big    `json:"value"`
+	Proof []hexutil.Bytes `json:"proof"`
+}
+
+// GetProof returns the Merkle proof of the given account and of its storage keys.
+// The block number can be nil, in which case the proof is taken from the latest known block.
+//
+// The proofs are served by the core namespace, as core_getProof, along with the
+// rest of the chain API; there is no vnt_getProof.
+func (ec *Client) GetProof(ctx context.Context, account common.Address, keys []common.Hash, blockNumber *big.Int) (*AccountResult, error) {
+	if keys == nil {
+		keys = []common.Hash{}
+	}
+	derive := func(func(common.Hash) common.Hash) []common.Hash { return keys }
+	return ec.getProof(ctx, "core_getProof", account, derive, account, keys, toBlockNumArg(blockNumber))
+}
+
+// GetCandidateProof returns the Merkle proof of the candidate record of the given
+// account, held in the storage of the election contract.
+// The block number can be nil, in which case the proof is taken from the latest known block.
+func (ec *Client) GetCandidateProof(ctx context.Context, account common.Address, blockNumber *big.Int) (*AccountResult, error) {
+	derive := func(get func(common.Hash) common.Hash) []common.Hash { return election.CandidateKeys(account, get) }
+	return ec.getProof(ctx, "core_getCandidateProof", election.ContractAddr(), derive, account, toBlockNumArg(blockNumber))
+}
+
+// GetVoterProof returns the Merkle proof of the voter record of the given account,
+// held in the storage of the election contract.
+// The block number can be nil, in which case the proof is taken from the latest known block.
+func (ec *Client) GetVoterProof(ctx context.Context, account common.Address, blockNumber *big.Int) (*AccountResult, error) {
+	derive := func(get func(common.Hash) common.Hash) []common.Hash { return election.VoterKeys(account, get) }
+	return ec.getProof(ctx, "core_getVoterProof", election.ContractAddr(), derive, account, toBlockNumArg(blockNumber))
+}
+
+// GetStakeProof returns the Merkle proof of the stake record of the given account,
+// held in the storage of the election contract.
+// The block number can be nil, in which case the proof is taken from the latest known block.
+func (ec *Client) GetStakeProof(ctx context.Context, account common.Address, blockNumber *big.Int) (*AccountResult, error) {
+	derive := func(get func(common.Hash) common.Hash) []common.Hash { return election.StakeKeys(account, get) }
+	return ec.getProof(ctx, "core_getStakeProof", election.ContractAddr(), derive, account, toBlockNumArg(blockNumber))
+}
+
+// getProof retrieves the proof of the given account, whose storage keys are
+// derived by keys once the proof is verified.
+func (ec *Client) getProof(ctx context.Context, method string, account common.Address, keys keyDeriver, args ...interface{}) (*AccountResult, error) {
+	var res rpcAccountResult
+	if err := ec.c.CallContext(ctx, &res, method, args...); err != nil {
+		return nil, err
+	}
+	if res.Address != account {
+		return nil, fmt.Errorf("proof of account %x, want %x", res.Address, account)
+	}
+	result := &AccountResult{
+		Address:      res.Address,
+		AccountProof: toByteSlices(res.AccountProof),
+		Balance:      (*big.Int)(res.Balance),
+		CodeHash:     res.CodeHash,
+		Nonce:        uint64(res.Nonce),
+		StorageHash:  res.StorageHash,
+		StorageProof: make([]StorageResult, len(res.StorageProof)),
+		keys:         keys,
+	}
+	if result.Balance == nil {
+		return nil, errors.New("missing balance in proof")
+	}
+	for i, sp := range res.StorageProof {
+		if sp.Value == nil {
+			return nil, fmt.Errorf("missing value of storage key %x in proof", sp.Key)
+		}
+		result.StorageProof[i] = StorageResult{
+			Key:   sp.Key,
+			Value: (*big.Int)(sp.Value),
+			Proof: toByteSlices(sp.Proof),
+		}
+	}
+	return result, nil
+}
+
+func toByteSlices(b []hexutil.Bytes) [][]byte {
+	r := make([][]byte, len(b))
+	for i := range b {
+		r[i] = b[i]
+	}
+	return r
+}
+
+// VerifyProof checks the proofs of result against the state root of a trusted
+// header: the account has to hold the returned nonce, balance, code hash and
+// storage hash, and its storage slots the returned values. A missing account
+// is proven empty, along with all its storage. The storage keys of results
+// retrieved by the client are not chosen by the server: they have to be the
+// requested ones, or the ones derived from the prefix encoding of the election
+// record and the proven values.
+func VerifyProof(root common.Hash, result *AccountResult) error {
+	if err := verifyAccountProof(root, result); err != nil {
+		return err
+	}
+	if result.keys == nil {
+		return nil
+	}
+	values := make(map[common.Hash]common.Hash, len(result.StorageProof))
+	for _, sp := range result.StorageProof {
+		values[sp.Key] = common.BigToHash(sp.Value)
+	}
+	missing := false
+	keys := result.keys(func(key common.Hash) common.Hash {
+		value, ok := values[key]
+		missing = missing || !ok
+		return value
+	})
+	if missing || len(keys) != len(result.StorageProof) {
+		return errProofKeys
+	}
+	for i, key := range keys {
+		if result.StorageProof[i].Key != key {
+			return fmt.Errorf("storage key %x: %v", result.StorageProof[i].Key, errProofKeys)
+		}
+	}
+	return nil
+}
+
+// verifyAccountProof checks the proofs of the account and of the storage slots
+// of result against the state root.
+func verifyAccountProof(root common.Hash, result *AccountResult) error {
+	value, err := verifyProof(root, crypto.Keccak256(result.Address.Bytes()), result.AccountProof)
+	if err != nil {
+		return fmt.Errorf("account %x: %v", result.Address, err)
+	}
+	if value == nil {
+		if result.Nonce != 0 || result.Balance.Sign() != 0 || result.CodeHash != (common.Hash{}) || result.StorageHash != (common.Hash{}) {
+			return fmt.Errorf("account %x: %v", result.Address, errProofMismatch)
+		}
+		for _, sp := range result.StorageProof {
+			if sp.Value.Sign() != 0 {
+				return fmt.Errorf("storage key %x: %v", sp.Key, errProofMismatch)
+			}
+		}
+		return nil
+	}
+	var account state.Account
+	if err := rlp.DecodeBytes(value, &account); err != nil {
+		return fmt.Errorf("account %x: %v", result.Address, err)
+	}
+	if account.Nonce != result.Nonce || account.Balance.Cmp(result.Balance) != 0 ||
+		common.BytesToHash(account.CodeHash) != result.CodeHash || account.Root != result.StorageHash {
+		return fmt.Errorf("account %x: %v", result.Address, errProofMismatch)
+	}
+	for _, sp := range result.StorageProof {
+		value, err := verifyProof(account.Root, crypto.Keccak256(sp.Key.Bytes()), sp.Proof)
+		if err != nil {
+			return fmt.Errorf("storage key %x: %v", sp.Key, err)
+		}
+		stored := new(big.Int)
+		if value != nil {
+			_, content, _, err := rlp.Split(value)
+			if err != nil {
+				return fmt.Errorf("storage key %x: %v", sp.Key, err)
+			}
+			stored.SetBytes(content)
+		}
+		if stored.Cmp(sp.Value) != 0 {
+			return fmt.Errorf("storage key %x: %v", sp.Key, errProofMismatch)
+		}
+	}
+	return nil
+}
+
+// verifyProof returns the value of key proven by the nodes of proof under root,
+// nil if the proof shows the key absent. Empty tries hold no key, without any
+// node to prove it.
+func verifyProof(root common.Hash, key []byte, proof [][]byte) ([]byte, error) {
+	if root == types.EmptyRootHash {
+		return nil, nil
+	}
+	proofDb := vntdb.NewMemDatabase()
+	for _, node := range proof {
+		proofDb.Put(crypto.Keccak256(node), node)
+	}
+	value, _, err := trie.VerifyProof(root, key, proofDb)
+	return value, err
+}
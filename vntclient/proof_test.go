@@ -0,0 +1,141 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntclient
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/core/state"
+	"github.com/vntchain/go-vnt/core/vm/election"
+	"github.com/vntchain/go-vnt/vntdb"
+)
+
+// proveAccount builds the proofs of an account and of its storage keys, the way
+// the core_getProof API does.
+func proveAccount(t *testing.T, statedb *state.StateDB, addr common.Address, keys ...common.Hash) *AccountResult {
+	proof, err := statedb.GetProof(addr)
+	if err != nil {
+		t.Fatalf("failed to prove account: %v", err)
+	}
+	result := &AccountResult{
+		Address:      addr,
+		AccountProof: proof,
+		Balance:      statedb.GetBalance(addr),
+		CodeHash:     statedb.GetCodeHash(addr),
+		Nonce:        statedb.GetNonce(addr),
+	}
+	storageTrie := statedb.StorageTrie(addr)
+	if storageTrie != nil {
+		result.StorageHash = storageTrie.Hash()
+	}
+	for _, key := range keys {
+		sp := StorageResult{Key: key, Value: statedb.GetState(addr, key).Big()}
+		if storageTrie != nil {
+			if sp.Proof, err = statedb.GetStorageProof(addr, key); err != nil {
+				t.Fatalf("failed to prove storage: %v", err)
+			}
+		}
+		result.StorageProof = append(result.StorageProof, sp)
+	}
+	return result
+}
+
+// Tests that proofs are verified against the state root, and that tampered
+// results are rejected.
+func TestVerifyProof(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(vntdb.NewMemDatabase()))
+	for i := byte(1); i < 16; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		statedb.SetNonce(addr, uint64(i))
+		statedb.AddBalance(addr, big.NewInt(int64(i)))
+		statedb.SetState(addr, common.Hash{i}, common.Hash{0, i})
+	}
+	statedb.AddBalance(common.BytesToAddress([]byte{0x20}), common.Big1)
+	root, _ := statedb.Commit(false)
+	statedb, _ = state.New(root, statedb.Database())
+
+	for _, result := range []*AccountResult{
+		proveAccount(t, statedb, common.BytesToAddress([]byte{3}), common.Hash{3}, common.Hash{4}),
+		proveAccount(t, statedb, common.BytesToAddress([]byte{0x20}), common.Hash{1}),
+		proveAccount(t, statedb, common.BytesToAddress([]byte{0xff}), common.Hash{1}),
+	} {
+		if err := VerifyProof(root, result); err != nil {
+			t.Errorf("account %x: failed to verify proof: %v", result.Address, err)
+		}
+	}
+	tamper := []func(*AccountResult){
+		func(r *AccountResult) { r.Balance = big.NewInt(100) },
+		func(r *AccountResult) { r.Nonce++ },
+		func(r *AccountResult) { r.StorageProof[0].Value = big.NewInt(1) },
+		func(r *AccountResult) { r.StorageProof[1].Value = big.NewInt(1) },
+		func(r *AccountResult) { r.AccountProof = r.AccountProof[:len(r.AccountProof)-1] },
+		func(r *AccountResult) { r.StorageProof[0].Proof = nil },
+	}
+	for i, fn := range tamper {
+		result := proveAccount(t, statedb, common.BytesToAddress([]byte{3}), common.Hash{3}, common.Hash{4})
+		fn(result)
+		if err := VerifyProof(root, result); err == nil {
+			t.Errorf("tamper %d: verified tampered proof", i)
+		}
+	}
+	missing := proveAccount(t, statedb, common.BytesToAddress([]byte{0xff}), common.Hash{1})
+	missing.StorageProof[0].Value = big.NewInt(1)
+	if err := VerifyProof(root, missing); err == nil {
+		t.Errorf("verified storage of missing account")
+	}
+}
+
+// Tests that the storage keys of an election record proof are derived by the
+// client, rejecting proofs of other keys chosen by the server.
+func TestVerifyElectionProofKeys(t *testing.T) {
+	witnesses := []common.Address{common.BytesToAddress([]byte{1}), common.BytesToAddress([]byte{2})}
+	storage, err := election.GenesisStorage(witnesses, [][]byte{[]byte("/ip4/127.0.0.1/tcp/3001"), []byte("/ip4/127.0.0.1/tcp/3002")})
+	if err != nil {
+		t.Fatalf("failed to create election storage: %v", err)
+	}
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(vntdb.NewMemDatabase()))
+	for key, value := range storage {
+		statedb.SetState(election.ContractAddr(), key, value)
+	}
+	root, _ := statedb.Commit(false)
+	statedb, _ = state.New(root, statedb.Database())
+
+	prove := func(keys []common.Hash) *AccountResult {
+		result := proveAccount(t, statedb, election.ContractAddr(), keys...)
+		result.keys = func(get func(common.Hash) common.Hash) []common.Hash {
+			return election.CandidateKeys(witnesses[0], get)
+		}
+		return result
+	}
+	keys := election.CandidateStorageKeys(statedb, witnesses[0])
+	if err := VerifyProof(root, prove(keys)); err != nil {
+		t.Fatalf("failed to verify candidate proof: %v", err)
+	}
+	other := election.CandidateStorageKeys(statedb, witnesses[1])
+	for i, keys := range [][]common.Hash{
+		keys[:len(keys)-1],
+		append(append([]common.Hash{}, keys...), other[0]),
+		other,
+		nil,
+	} {
+		if err := VerifyProof(root, prove(keys)); err == nil {
+			t.Errorf("keys %d: verified proof of other keys", i)
+		}
+	}
+}
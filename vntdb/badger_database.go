@@ -0,0 +1,574 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntdb
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger"
+	"github.com/dgraph-io/badger/y"
+	lerrors "github.com/syndtr/goleveldb/leveldb/errors"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"github.com/vntchain/go-vnt/log"
+	"github.com/vntchain/go-vnt/metrics"
+)
+
+const (
+	badgerGCInterval     = 5 * time.Minute // Interval between the garbage collections of the value log
+	badgerGCDiscardRatio = 0.5             // Share of stale data in a value log file to rewrite it
+)
+
+// badgerKeyPrefix is prepended to the keys stored in Badger. It leaves room for
+// the empty key, which Badger rejects, and keeps the keys apart from the ones
+// Badger reserves for itself.
+var badgerKeyPrefix = []byte("k")
+
+// BadgerDatabase is a database kept by Badger, an embedded key-value store
+// separating the values from the LSM tree of the keys.
+type BadgerDatabase struct {
+	fn string     // filename for reporting
+	db *badger.DB // Badger instance
+
+	compTimeMeter    metrics.Meter // Meter for measuring the total time spent in value log garbage collection
+	compReadMeter    metrics.Meter // Meter for measuring the data read during compaction, unreported by Badger
+	compWriteMeter   metrics.Meter // Meter for measuring the data written during compaction, unreported by Badger
+	writeDelayNMeter metrics.Meter // Meter for measuring the writes blocked on full memtables
+	writeDelayMeter  metrics.Meter // Meter for measuring the write delay duration, unreported by Badger
+	diskReadMeter    metrics.Meter // Meter for measuring the effective amount of data read
+	diskWriteMeter   metrics.Meter // Meter for measuring the effective amount of data written
+
+	quitLock sync.Mutex      // Mutex protecting the quit channel access
+	quitChan chan chan error // Quit channel to stop the metrics collection before closing the database
+
+	gcLock sync.Mutex    // Lock serializing the value log garbage collections
+	gcQuit chan struct{} // Quit channel to stop the value log garbage collection
+	gcDone chan struct{} // Channel closed once the value log garbage collection stopped
+
+	log log.Logger // Contextual logger tracking the database path
+}
+
+// NewBadgerDatabase returns a Badger wrapped object.
+func NewBadgerDatabase(file string, cache int, handles int) (*BadgerDatabase, error) {
+	logger := log.New("database", file)
+
+	// Ensure we have some minimal caching and file guarantees
+	if cache < 16 {
+		cache = 16
+	}
+	if handles < 16 {
+		handles = 16
+	}
+	logger.Info("Allocated cache and file handles", "cache", cache, "handles", handles)
+
+	// Two memtables are used like the write buffers of LevelDB, the tables are
+	// memory mapped and don't hold file handles
+	tables := int64(cache/4) << 20
+	if tables > 64<<20 {
+		tables = 64 << 20
+	}
+	opts := badger.DefaultOptions(file).
+		WithSyncWrites(false).
+		WithTruncate(true).
+		WithNumMemtables(2).
+		WithMaxTableSize(tables).
+		WithEventLogging(false).
+		WithLogger(badgerLogger{logger})
+
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, err
+	}
+	bdb := &BadgerDatabase{
+		fn:     file,
+		db:     db,
+		gcQuit: make(chan struct{}),
+		gcDone: make(chan struct{}),
+		log:    logger,
+	}
+	go bdb.collect(badgerGCInterval)
+	return bdb, nil
+}
+
+// Path returns the path to the database directory.
+func (db *BadgerDatabase) Path() string {
+	return db.fn
+}
+
+// Put puts the given key / value to the queue
+func (db *BadgerDatabase) Put(key []byte, value []byte) error {
+	return db.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(badgerKey(key), value)
+	})
+}
+
+func (db *BadgerDatabase) Has(key []byte) (bool, error) {
+	err := db.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(badgerKey(key))
+		return err
+	})
+	if err == badger.ErrKeyNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Get returns the given key if it's present. Missing keys are reported with
+// the error of LevelDB.
+func (db *BadgerDatabase) Get(key []byte) ([]byte, error) {
+	var dat []byte
+	err := db.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(badgerKey(key))
+		if err != nil {
+			return err
+		}
+		dat, err = item.ValueCopy(nil)
+		return err
+	})
+	if err == badger.ErrKeyNotFound {
+		return nil, lerrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dat, nil
+}
+
+// Delete deletes the key from the queue and database
+func (db *BadgerDatabase) Delete(key []byte) error {
+	return db.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(badgerKey(key))
+	})
+}
+
+func (db *BadgerDatabase) NewIterator() iterator.Iterator {
+	return db.NewIteratorWithPrefix(nil)
+}
+
+// NewIteratorWithPrefix returns a iterator to iterate over subset of database content with a particular prefix.
+func (db *BadgerDatabase) NewIteratorWithPrefix(prefix []byte) iterator.Iterator {
+	return &badgerIterator{
+		txn:    db.db.NewTransaction(false),
+		prefix: badgerKey(prefix),
+		dir:    -1,
+	}
+}
+
+// Compact flattens the LSM tree and garbage collects the value log. Badger
+// compacts the whole database, the range is ignored.
+func (db *BadgerDatabase) Compact(start []byte, limit []byte) error {
+	if err := db.db.Flatten(2); err != nil {
+		return err
+	}
+	return db.gc()
+}
+
+func (db *BadgerDatabase) Close() {
+	// Stop the metrics collection to avoid internal database races
+	db.quitLock.Lock()
+	defer db.quitLock.Unlock()
+
+	if db.quitChan != nil {
+		errc := make(chan error)
+		db.quitChan <- errc
+		if err := <-errc; err != nil {
+			db.log.Error("Metrics collection failed", "err", err)
+		}
+		db.quitChan = nil
+	}
+	close(db.gcQuit)
+	<-db.gcDone
+
+	err := db.db.Close()
+	if err == nil {
+		db.log.Info("Database closed")
+	} else {
+		db.log.Error("Failed to close database", "err", err)
+	}
+}
+
+// collect periodically garbage collects the value log, until the database is
+// closed.
+func (db *BadgerDatabase) collect(refresh time.Duration) {
+	defer close(db.gcDone)
+
+	for {
+		select {
+		case <-db.gcQuit:
+			return
+		case <-time.After(refresh):
+			if err := db.gc(); err != nil {
+				db.log.Error("Value log garbage collection failed", "err", err)
+			}
+		}
+	}
+}
+
+// gc rewrites the value log files holding mostly stale data, until none is
+// left.
+func (db *BadgerDatabase) gc() error {
+	db.gcLock.Lock()
+	defer db.gcLock.Unlock()
+
+	start := time.Now()
+	defer func() {
+		if db.compTimeMeter != nil {
+			db.compTimeMeter.Mark(int64(time.Since(start)))
+		}
+	}()
+	for {
+		switch err := db.db.RunValueLogGC(badgerGCDiscardRatio); err {
+		case nil:
+		case badger.ErrNoRewrite, badger.ErrRejected:
+			return nil
+		default:
+			return err
+		}
+	}
+}
+
+// Meter configures the database metrics collectors under the names of the
+// LevelDB ones.
+func (db *BadgerDatabase) Meter(prefix string) {
+	if metrics.Enabled {
+		// Initialize all the metrics collector at the requested prefix
+		db.compTimeMeter = metrics.NewRegisteredMeter(prefix+"compact/time", nil)
+		db.compReadMeter = metrics.NewRegisteredMeter(prefix+"compact/input", nil)
+		db.compWriteMeter = metrics.NewRegisteredMeter(prefix+"compact/output", nil)
+		db.diskReadMeter = metrics.NewRegisteredMeter(prefix+"disk/read", nil)
+		db.diskWriteMeter = metrics.NewRegisteredMeter(prefix+"disk/write", nil)
+	}
+	// Initialize write delay metrics no matter we are in metric mode or not.
+	db.writeDelayMeter = metrics.NewRegisteredMeter(prefix+"compact/writedelay/duration", nil)
+	db.writeDelayNMeter = metrics.NewRegisteredMeter(prefix+"compact/writedelay/counter", nil)
+
+	// Create a quit channel for the periodic collector and run it
+	db.quitLock.Lock()
+	db.quitChan = make(chan chan error)
+	db.quitLock.Unlock()
+
+	go db.meter(3 * time.Second)
+}
+
+// meter periodically retrieves the counters of Badger and reports them to the
+// metrics subsystem, under the names of the LevelDB ones. Badger compacts its
+// LSM tree without reporting the data moved nor the write delays, only the
+// writes blocked on full memtables. Its counters are shared by all the Badger
+// databases of the process.
+func (db *BadgerDatabase) meter(refresh time.Duration) {
+	var (
+		iostats         [2]int64
+		blocked         int64
+		lastWriteDelayN time.Time
+		errc            chan error
+	)
+	for errc == nil {
+		nRead, nWrite := y.NumBytesRead.Value(), y.NumBytesWritten.Value()
+		if db.diskReadMeter != nil {
+			db.diskReadMeter.Mark(nRead - iostats[0])
+		}
+		if db.diskWriteMeter != nil {
+			db.diskWriteMeter.Mark(nWrite - iostats[1])
+		}
+		iostats[0], iostats[1] = nRead, nWrite
+
+		delayN := y.NumBlockedPuts.Value()
+		if db.writeDelayNMeter != nil {
+			db.writeDelayNMeter.Mark(delayN - blocked)
+			// If the write delay number been collected in the last minute exceeds the predefined threshold,
+			// print a warning log here.
+			// If a warning that db performance is laggy has been displayed,
+			// any subsequent warnings will be withhold for 1 minute to don't overwhelm the user.
+			if int(db.writeDelayNMeter.Rate1()) > writeDelayNThreshold &&
+				time.Now().After(lastWriteDelayN.Add(writeDelayWarningThrottler)) {
+				db.log.Warn("Write delay number exceeds the threshold (200 per second) in the last minute")
+				lastWriteDelayN = time.Now()
+			}
+		}
+		blocked = delayN
+
+		// Sleep a bit, then repeat the stats collection
+		select {
+		case errc = <-db.quitChan:
+			// Quit requesting, stop hammering the database
+		case <-time.After(refresh):
+			// Timeout, gather a new set of stats
+		}
+	}
+	errc <- nil
+}
+
+func (db *BadgerDatabase) NewBatch() Batch {
+	return &badgerBatch{db: db.db}
+}
+
+// badgerWrite is a write buffered in a batch, a deletion if value is nil.
+type badgerWrite struct {
+	key   []byte
+	value []byte
+}
+
+// badgerBatch buffers the writes until they're committed. Batches too large for
+// a single Badger transaction are committed in several.
+type badgerBatch struct {
+	db     *badger.DB
+	writes []badgerWrite
+	size   int
+}
+
+func (b *badgerBatch) Put(key, value []byte) error {
+	b.writes = append(b.writes, badgerWrite{badgerKey(key), append([]byte{}, value...)})
+	b.size += len(value)
+	return nil
+}
+
+func (b *badgerBatch) Delete(key []byte) error {
+	b.writes = append(b.writes, badgerWrite{key: badgerKey(key)})
+	b.size++
+	return nil
+}
+
+func (b *badgerBatch) Write() error {
+	wb := b.db.NewWriteBatch()
+	defer wb.Cancel()
+
+	for _, w := range b.writes {
+		var err error
+		if w.value == nil {
+			err = wb.Delete(w.key)
+		} else {
+			err = wb.Set(w.key, w.value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return wb.Flush()
+}
+
+func (b *badgerBatch) ValueSize() int {
+	return b.size
+}
+
+func (b *badgerBatch) Reset() {
+	b.writes = b.writes[:0]
+	b.size = 0
+}
+
+// badgerKey returns the key stored in Badger for a database key.
+func badgerKey(key []byte) []byte {
+	return append(append(make([]byte, 0, len(badgerKeyPrefix)+len(key)), badgerKeyPrefix...), key...)
+}
+
+// badgerIterator iterates over the keys with a prefix in a read-only Badger
+// transaction, in the fashion of the LevelDB iterators. Badger iterates in a
+// single direction, the iterator is reopened when moving the other way.
+type badgerIterator struct {
+	txn     *badger.Txn
+	it      *badger.Iterator
+	reverse bool   // Whether it iterates backwards
+	prefix  []byte // Prefix of the Badger keys iterated over
+
+	key, value []byte
+	dir        int // -1 before the first key, 1 past the last key, 0 on a key
+	err        error
+
+	released bool
+	releaser util.Releaser
+}
+
+// open (re)opens the Badger iterator in the given direction.
+func (it *badgerIterator) open(reverse bool) {
+	if it.it != nil {
+		it.it.Close()
+	}
+	// Badger leaves the keys out of the prefix invalid, the reverse iterators
+	// have to see past it to seek from its end
+	opts := badger.DefaultIteratorOptions
+	if opts.Reverse = reverse; !reverse {
+		opts.Prefix = it.prefix
+	}
+	it.it, it.reverse = it.txn.NewIterator(opts), reverse
+}
+
+// load reads the entry the Badger iterator is at, if it's within the prefix.
+func (it *badgerIterator) load() bool {
+	if !it.it.ValidForPrefix(it.prefix) {
+		it.key, it.value = nil, nil
+		if it.reverse {
+			it.dir = -1
+		} else {
+			it.dir = 1
+		}
+		return false
+	}
+	item := it.it.Item()
+	value, err := item.ValueCopy(nil)
+	if err != nil {
+		it.err = err
+		it.key, it.value, it.dir = nil, nil, 1
+		return false
+	}
+	it.key, it.value, it.dir = item.KeyCopy(nil)[len(badgerKeyPrefix):], value, 0
+	return true
+}
+
+func (it *badgerIterator) First() bool {
+	if it.released || it.err != nil {
+		return false
+	}
+	it.open(false)
+	it.it.Seek(it.prefix)
+	return it.load()
+}
+
+func (it *badgerIterator) Last() bool {
+	if it.released || it.err != nil {
+		return false
+	}
+	it.open(true)
+	if limit := util.BytesPrefix(it.prefix).Limit; limit != nil {
+		// Reverse seeks land on the greatest key not past the one sought
+		if it.it.Seek(limit); it.it.Valid() && bytes.Equal(it.it.Item().Key(), limit) {
+			it.it.Next()
+		}
+	} else {
+		it.it.Rewind()
+	}
+	return it.load()
+}
+
+func (it *badgerIterator) Seek(key []byte) bool {
+	if it.released || it.err != nil {
+		return false
+	}
+	it.open(false)
+	if key = badgerKey(key); bytes.Compare(key, it.prefix) < 0 {
+		key = it.prefix
+	}
+	it.it.Seek(key)
+	return it.load()
+}
+
+func (it *badgerIterator) Next() bool {
+	if it.released || it.err != nil {
+		return false
+	}
+	switch it.dir {
+	case -1:
+		return it.First()
+	case 1:
+		return false
+	}
+	it.step(false)
+	return it.load()
+}
+
+func (it *badgerIterator) Prev() bool {
+	if it.released || it.err != nil {
+		return false
+	}
+	switch it.dir {
+	case -1:
+		return false
+	case 1:
+		return it.Last()
+	}
+	it.step(true)
+	return it.load()
+}
+
+// step moves the Badger iterator past the current key in the given direction.
+func (it *badgerIterator) step(reverse bool) {
+	if it.reverse == reverse {
+		it.it.Next()
+		return
+	}
+	current := badgerKey(it.key)
+	it.open(reverse)
+	if it.it.Seek(current); it.it.Valid() && bytes.Equal(it.it.Item().Key(), current) {
+		it.it.Next()
+	}
+}
+
+func (it *badgerIterator) Key() []byte {
+	return it.key
+}
+
+func (it *badgerIterator) Value() []byte {
+	return it.value
+}
+
+func (it *badgerIterator) Valid() bool {
+	return !it.released && it.err == nil && it.dir == 0
+}
+
+func (it *badgerIterator) Error() error {
+	return it.err
+}
+
+func (it *badgerIterator) Release() {
+	if it.released {
+		return
+	}
+	if it.it != nil {
+		it.it.Close()
+	}
+	it.txn.Discard()
+	it.key, it.value, it.released = nil, nil, true
+
+	if it.releaser != nil {
+		it.releaser.Release()
+		it.releaser = nil
+	}
+}
+
+func (it *badgerIterator) SetReleaser(releaser util.Releaser) {
+	if it.released {
+		panic(util.ErrReleased)
+	}
+	if it.releaser != nil && releaser != nil {
+		panic(util.ErrHasReleaser)
+	}
+	it.releaser = releaser
+}
+
+// badgerLogger forwards the logs of Badger to the database logger, one level
+// below theirs as Badger is verbose.
+type badgerLogger struct {
+	log log.Logger
+}
+
+func (l badgerLogger) Errorf(format string, args ...interface{}) {
+	l.log.Error(strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+func (l badgerLogger) Warningf(format string, args ...interface{}) {
+	l.log.Warn(strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+func (l badgerLogger) Infof(format string, args ...interface{}) {
+	l.log.Debug(strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+func (l badgerLogger) Debugf(format string, args ...interface{}) {
+	l.log.Trace(strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
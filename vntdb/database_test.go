@@ -188,3 +188,76 @@ func testParallelPutGet(db vntdb.Database, t *testing.T) {
 	}
 	pending.Wait()
 }
+
+func newTestBadgerDB() (*vntdb.BadgerDatabase, func()) {
+	dirname, err := ioutil.TempDir(os.TempDir(), "vntdb_badger_test_")
+	if err != nil {
+		panic("failed to create test file: " + err.Error())
+	}
+	db, err := vntdb.NewBadgerDatabase(dirname, 0, 0)
+	if err != nil {
+		panic("failed to create test database: " + err.Error())
+	}
+
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dirname)
+	}
+}
+
+func TestBadger_PutGet(t *testing.T) {
+	db, remove := newTestBadgerDB()
+	defer remove()
+	testPutGet(db, t)
+}
+
+func TestBadger_ParallelPutGet(t *testing.T) {
+	db, remove := newTestBadgerDB()
+	defer remove()
+	testParallelPutGet(db, t)
+}
+
+// Tests that Badger iterates over the keys like LevelDB does, in both directions.
+func TestBadger_Iterator(t *testing.T) {
+	bdb, remove := newTestBadgerDB()
+	defer remove()
+	ldb, lremove := newTestLDB()
+	defer lremove()
+
+	batch := bdb.NewBatch()
+	for _, key := range []string{"", "a", "a\x00", "a\xff", "ab", "b", "b\xff", "c"} {
+		batch.Put([]byte(key), []byte("v"+key))
+		ldb.Put([]byte(key), []byte("v"+key))
+	}
+	batch.Delete([]byte("ab"))
+	ldb.Delete([]byte("ab"))
+	if err := batch.Write(); err != nil {
+		t.Fatalf("failed to write batch: %v", err)
+	}
+	for _, prefix := range []string{"", "a", "b", "d"} {
+		have, want := bdb.NewIteratorWithPrefix([]byte(prefix)), ldb.NewIteratorWithPrefix([]byte(prefix))
+		check := func(step string, hok, wok bool) {
+			if hok != wok || !bytes.Equal(have.Key(), want.Key()) || !bytes.Equal(have.Value(), want.Value()) {
+				t.Fatalf("prefix %q, %s: have %v %q=%q, want %v %q=%q", prefix, step, hok, have.Key(), have.Value(), wok, want.Key(), want.Value())
+			}
+		}
+		for i := 0; i < 6; i++ {
+			check(fmt.Sprintf("next %d", i), have.Next(), want.Next())
+		}
+		for i := 0; i < 6; i++ {
+			check(fmt.Sprintf("prev %d", i), have.Prev(), want.Prev())
+		}
+		check("last", have.Last(), want.Last())
+		check("prev after last", have.Prev(), want.Prev())
+		check("next after prev", have.Next(), want.Next())
+		check("seek", have.Seek([]byte("a\x01")), want.Seek([]byte("a\x01")))
+		check("prev after seek", have.Prev(), want.Prev())
+		check("first", have.First(), want.First())
+
+		have.Release()
+		want.Release()
+		if err := have.Error(); err != nil {
+			t.Fatalf("prefix %q: iterator failed: %v", prefix, err)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntdb
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/log"
+)
+
+// Engines the databases can be kept on disk with.
+const (
+	EngineLevelDB = "leveldb"
+	EngineBadger  = "badger"
+)
+
+// Engines lists the supported database engines.
+var Engines = []string{EngineLevelDB, EngineBadger}
+
+// engineFile is the file recording the engine of a database in its directory.
+const engineFile = "ENGINE"
+
+// Suffixes of the paths used while converting a database: the copy in the new
+// engine, the database being replaced, and the journal marking a complete copy
+// whose swap has to be finished.
+const (
+	convertSuffix = ".convert"
+	oldSuffix     = ".old"
+	journalSuffix = ".convert.journal"
+)
+
+// Open opens the database in file with the given engine, creating it if there
+// is none. The engine of a new database is recorded in its directory, and an
+// existing database is refused if it's kept by another engine. If engine is
+// empty, the recorded one is used, and new databases are kept by LevelDB. An
+// interrupted conversion of the database is finished first.
+func Open(engine, file string, cache int, handles int) (Store, error) {
+	if err := finishConvert(file); err != nil {
+		return nil, err
+	}
+	recorded, err := DatabaseEngine(file)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case engine == "" && recorded == "":
+		engine = EngineLevelDB
+	case engine == "":
+		engine = recorded
+	case recorded != "" && recorded != engine:
+		return nil, fmt.Errorf("database %s is kept by %s, not %s", file, recorded, engine)
+	}
+	var db Store
+	switch engine {
+	case EngineLevelDB:
+		db, err = NewLDBDatabase(file, cache, handles)
+	case EngineBadger:
+		db, err = NewBadgerDatabase(file, cache, handles)
+	default:
+		return nil, fmt.Errorf("unknown database engine %q", engine)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if recorded == "" {
+		if err := ioutil.WriteFile(filepath.Join(file, engineFile), []byte(engine+"\n"), 0644); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// DatabaseEngine returns the engine keeping the database in file, or an empty
+// string if there's no database yet. Databases created before their engine was
+// recorded are LevelDB ones.
+func DatabaseEngine(file string) (string, error) {
+	blob, err := ioutil.ReadFile(filepath.Join(file, engineFile))
+	if err == nil {
+		return strings.TrimSpace(string(blob)), nil
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+	if _, err := os.Stat(filepath.Join(file, "CURRENT")); err == nil {
+		return EngineLevelDB, nil
+	}
+	return "", nil
+}
+
+// Convert moves the database in file over to the given engine, returning the
+// number of entries copied. The entries are copied into a new database next to
+// it, which replaces the old one once complete, so an interrupted copy leaves
+// the database untouched. The directories kept inside the database, like the
+// ancient store of the chain, are moved over to the new one. A journal marks
+// the complete copy, an interrupted swap is finished by the next Open.
+func Convert(file, engine string, cache int, handles int) (int, error) {
+	if err := finishConvert(file); err != nil {
+		return 0, err
+	}
+	recorded, err := DatabaseEngine(file)
+	if err != nil {
+		return 0, err
+	}
+	if recorded == "" {
+		return 0, fmt.Errorf("no database in %s", file)
+	}
+	if recorded == engine {
+		return 0, nil
+	}
+	var (
+		tmp = file + convertSuffix
+		old = file + oldSuffix
+	)
+	if _, err := os.Stat(old); err == nil {
+		return 0, fmt.Errorf("stale database %s in the way", old)
+	}
+	if err := os.RemoveAll(tmp); err != nil {
+		return 0, err
+	}
+	src, err := Open(recorded, file, cache/2, handles/2)
+	if err != nil {
+		return 0, err
+	}
+	dst, err := Open(engine, tmp, cache/2, handles/2)
+	if err != nil {
+		src.Close()
+		return 0, err
+	}
+	copied, err := copyDatabase(dst, src)
+	src.Close()
+	dst.Close()
+	if err != nil {
+		os.RemoveAll(tmp)
+		return copied, err
+	}
+	// Swap the databases once the copy is journaled as complete
+	if err := ioutil.WriteFile(file+journalSuffix, []byte(engine+"\n"), 0644); err != nil {
+		os.RemoveAll(tmp)
+		return copied, err
+	}
+	return copied, finishConvert(file)
+}
+
+// finishConvert swaps a database with its journaled complete copy, keeping along
+// anything else stored in the directory, e.g. the ancient store of the chain
+// database. Every step can be interrupted and resumed. Without a journal, the
+// database is only checked not to be stranded by a conversion interrupted
+// before it was journaled.
+func finishConvert(file string) error {
+	var (
+		tmp     = file + convertSuffix
+		old     = file + oldSuffix
+		journal = file + journalSuffix
+	)
+	if _, err := os.Stat(journal); os.IsNotExist(err) {
+		if !exists(file) && exists(old) {
+			return fmt.Errorf("database %s was moved to %s by an interrupted conversion, move it back", file, old)
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+	log.Info("Finishing database conversion", "database", file)
+	if exists(tmp) {
+		if exists(file) {
+			if err := os.Rename(file, old); err != nil {
+				return err
+			}
+		}
+		if err := os.Rename(tmp, file); err != nil {
+			return err
+		}
+	}
+	entries, err := ioutil.ReadDir(old)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && !exists(filepath.Join(file, entry.Name())) {
+			if err := os.Rename(filepath.Join(old, entry.Name()), filepath.Join(file, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	if err := os.RemoveAll(old); err != nil {
+		return err
+	}
+	return os.Remove(journal)
+}
+
+// exists reports whether the path exists.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// copyDatabase copies all the entries of src into dst.
+func copyDatabase(dst, src Store) (int, error) {
+	var (
+		start  = time.Now()
+		logged = time.Now()
+		copied int
+	)
+	it := src.NewIterator()
+	defer it.Release()
+
+	batch := dst.NewBatch()
+	for it.Next() {
+		if err := batch.Put(it.Key(), it.Value()); err != nil {
+			return copied, err
+		}
+		if batch.ValueSize() > IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return copied, err
+			}
+			batch.Reset()
+		}
+		if copied++; time.Since(logged) > 8*time.Second {
+			log.Info("Converting database", "database", src.Path(), "entries", copied, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return copied, err
+	}
+	return copied, batch.Write()
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntdb_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vntchain/go-vnt/vntdb"
+)
+
+// Tests that the engine of a database is recorded, that it's refused by the
+// other engines, and that converting it keeps its content.
+func TestOpenConvert(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vntdb_engine_test_")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "chaindata")
+
+	db, err := vntdb.Open("", file, 0, 0)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	for i := byte(0); i < 100; i++ {
+		db.Put([]byte{i}, bytes.Repeat([]byte{i}, int(i)))
+	}
+	db.Close()
+	os.MkdirAll(filepath.Join(file, "ancient"), 0755)
+
+	if engine, _ := vntdb.DatabaseEngine(file); engine != vntdb.EngineLevelDB {
+		t.Fatalf("recorded engine mismatch: have %q, want %q", engine, vntdb.EngineLevelDB)
+	}
+	if _, err := vntdb.Open(vntdb.EngineBadger, file, 0, 0); err == nil {
+		t.Fatalf("LevelDB database opened by Badger")
+	}
+	if copied, err := vntdb.Convert(file, vntdb.EngineBadger, 0, 0); err != nil || copied != 100 {
+		t.Fatalf("entries converted mismatch: have %d (%v), want 100", copied, err)
+	}
+	if engine, _ := vntdb.DatabaseEngine(file); engine != vntdb.EngineBadger {
+		t.Fatalf("converted engine mismatch: have %q, want %q", engine, vntdb.EngineBadger)
+	}
+	if _, err := os.Stat(filepath.Join(file, "ancient")); err != nil {
+		t.Errorf("directory inside database lost: %v", err)
+	}
+	if _, err := vntdb.Open(vntdb.EngineLevelDB, file, 0, 0); err == nil {
+		t.Fatalf("Badger database opened by LevelDB")
+	}
+	if db, err = vntdb.Open("", file, 0, 0); err != nil {
+		t.Fatalf("failed to reopen database: %v", err)
+	}
+	defer db.Close()
+
+	if _, ok := db.(*vntdb.BadgerDatabase); !ok {
+		t.Fatalf("database engine mismatch: have %T", db)
+	}
+	for i := byte(0); i < 100; i++ {
+		if value, err := db.Get([]byte{i}); err != nil || !bytes.Equal(value, bytes.Repeat([]byte{i}, int(i))) {
+			t.Fatalf("entry %d mismatch: have %x (%v)", i, value, err)
+		}
+	}
+}
+
+// Tests that a conversion interrupted while swapping the databases is finished
+// by the next Open, and that a database stranded by an unjournaled swap is
+// reported instead of silently recreated.
+func TestConvertRecovery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vntdb_engine_test_")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "chaindata")
+
+	// Create a database and its complete copy, then crash between the renames
+	db, err := vntdb.Open("", file, 0, 0)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	db.Put([]byte("key"), []byte("value"))
+	db.Close()
+	os.MkdirAll(filepath.Join(file, "ancient"), 0755)
+
+	cpy, err := vntdb.Open(vntdb.EngineBadger, file+".convert", 0, 0)
+	if err != nil {
+		t.Fatalf("failed to create copy: %v", err)
+	}
+	cpy.Put([]byte("key"), []byte("value"))
+	cpy.Close()
+
+	if err := ioutil.WriteFile(file+".convert.journal", []byte(vntdb.EngineBadger+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(file, file+".old"); err != nil {
+		t.Fatal(err)
+	}
+	// Reopening finishes the swap
+	if db, err = vntdb.Open("", file, 0, 0); err != nil {
+		t.Fatalf("failed to reopen database: %v", err)
+	}
+	if _, ok := db.(*vntdb.BadgerDatabase); !ok {
+		t.Errorf("database engine mismatch: have %T", db)
+	}
+	if value, err := db.Get([]byte("key")); err != nil || string(value) != "value" {
+		t.Errorf("entry mismatch: have %q (%v), want %q", value, err, "value")
+	}
+	db.Close()
+
+	if _, err := os.Stat(filepath.Join(file, "ancient")); err != nil {
+		t.Errorf("directory inside database lost: %v", err)
+	}
+	for _, path := range []string{file + ".old", file + ".convert", file + ".convert.journal"} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("conversion leftover %s: %v", path, err)
+		}
+	}
+	// Without a journal, a database moved away is reported
+	if err := os.Rename(file, file+".old"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vntdb.Open("", file, 0, 0); err == nil {
+		t.Fatalf("stranded database not reported")
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("empty database created next to stranded one: %v", err)
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntp2p
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	ds "github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	libp2p "github.com/libp2p/go-libp2p-peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/vntchain/go-vnt/log"
+	"github.com/whyrusleeping/base32"
+)
+
+// Bans are stored in the vntp2p datastore under banPrefix, keyed by the base32
+// encoded target.
+const banPrefix = "/ban"
+
+var errNotBanned = errors.New("not banned")
+
+// Ban is a banned peer ID or IP network.
+type Ban struct {
+	Target  string    `json:"target"` // Peer ID or IP network in CIDR notation
+	Expires time.Time `json:"expires"`
+	Reason  string    `json:"reason"`
+
+	id     libp2p.ID
+	ipnet  *net.IPNet
+	stored bool
+}
+
+// parseBanTarget accepts a peer ID, an IP address or an IP network.
+func parseBanTarget(target string) (libp2p.ID, *net.IPNet, error) {
+	if strings.Contains(target, "/") {
+		_, ipnet, err := net.ParseCIDR(target)
+		return "", ipnet, err
+	}
+	if ip := net.ParseIP(target); ip != nil {
+		bits := 8 * net.IPv6len
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 8*net.IPv4len
+		}
+		return "", &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	id, err := libp2p.IDB58Decode(target)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid ban target %q: not a peer id or ip address", target)
+	}
+	return id, nil, nil
+}
+
+// banList holds the banned peers and networks, expired bans are dropped
+// lazily.
+type banList struct {
+	lock  sync.RWMutex
+	db    *Datastore // nil if bans are not persisted
+	peers map[libp2p.ID]*Ban
+	nets  map[string]*Ban
+}
+
+func newBanList(db *Datastore) *banList {
+	l := &banList{
+		db:    db,
+		peers: make(map[libp2p.ID]*Ban),
+		nets:  make(map[string]*Ban),
+	}
+	if db != nil {
+		l.load()
+	}
+	return l
+}
+
+func banKey(target string) ds.Key {
+	return ds.NewKey(banPrefix).ChildString(base32.RawStdEncoding.EncodeToString([]byte(target)))
+}
+
+// load reads the stored bans, deleting the expired ones.
+func (l *banList) load() {
+	results, err := l.db.Query(query.Query{Prefix: banPrefix})
+	if err != nil {
+		log.Warn("Failed to load bans", "err", err)
+		return
+	}
+	entries, err := results.Rest()
+	if err != nil {
+		log.Warn("Failed to load bans", "err", err)
+		return
+	}
+	now := time.Now()
+	for _, entry := range entries {
+		blob, ok := entry.Value.([]byte)
+		if !ok {
+			continue
+		}
+		ban := new(Ban)
+		if err := json.Unmarshal(blob, ban); err != nil {
+			log.Warn("Invalid stored ban", "key", entry.Key, "err", err)
+			continue
+		}
+		if ban.Expires.Before(now) {
+			l.db.Delete(ds.NewKey(entry.Key))
+			continue
+		}
+		if ban.id, ban.ipnet, err = parseBanTarget(ban.Target); err != nil {
+			log.Warn("Invalid stored ban", "target", ban.Target, "err", err)
+			continue
+		}
+		ban.stored = true
+		l.insert(ban)
+	}
+}
+
+func (l *banList) insert(ban *Ban) {
+	if ban.ipnet != nil {
+		l.nets[ban.Target] = ban
+	} else {
+		l.peers[ban.id] = ban
+	}
+}
+
+// add bans the target for the duration, replacing an existing ban.
+func (l *banList) add(target string, duration time.Duration, reason string) error {
+	id, ipnet, err := parseBanTarget(target)
+	if err != nil {
+		return err
+	}
+	ban := &Ban{Expires: time.Now().Add(duration), Reason: reason, id: id, ipnet: ipnet}
+	if ipnet != nil {
+		ban.Target = ipnet.String()
+	} else {
+		ban.Target = id.Pretty()
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.db != nil {
+		blob, err := json.Marshal(ban)
+		if err != nil {
+			return err
+		}
+		if err := l.db.Put(banKey(ban.Target), blob); err != nil {
+			return err
+		}
+		ban.stored = true
+	}
+	l.insert(ban)
+	return nil
+}
+
+// remove lifts the ban of the target.
+func (l *banList) remove(target string) error {
+	id, ipnet, err := parseBanTarget(target)
+	if err != nil {
+		return err
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	var ban *Ban
+	if ipnet != nil {
+		ban = l.nets[ipnet.String()]
+	} else {
+		ban = l.peers[id]
+	}
+	if ban == nil {
+		return errNotBanned
+	}
+	l.delete(ban)
+	return nil
+}
+
+// delete drops the ban, the lock must be held.
+func (l *banList) delete(ban *Ban) {
+	if ban.ipnet != nil {
+		delete(l.nets, ban.Target)
+	} else {
+		delete(l.peers, ban.id)
+	}
+	if ban.stored {
+		if err := l.db.Delete(banKey(ban.Target)); err != nil {
+			log.Warn("Failed to delete ban", "target", ban.Target, "err", err)
+		}
+	}
+}
+
+// banned returns the active ban of the peer or its address, if any.
+func (l *banList) banned(id libp2p.ID, ip net.IP) *Ban {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	if ban, ok := l.peers[id]; ok {
+		if ban.Expires.After(now) {
+			return ban
+		}
+		l.delete(ban)
+	}
+	if ip == nil {
+		return nil
+	}
+	for _, ban := range l.nets {
+		if !ban.Expires.After(now) {
+			l.delete(ban)
+			continue
+		}
+		if ban.ipnet.Contains(ip) {
+			return ban
+		}
+	}
+	return nil
+}
+
+// list returns the active bans ordered by target.
+func (l *banList) list() []*Ban {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	now := time.Now()
+	bans := make([]*Ban, 0, len(l.peers)+len(l.nets))
+	for _, ban := range l.peers {
+		if ban.Expires.After(now) {
+			bans = append(bans, ban)
+		}
+	}
+	for _, ban := range l.nets {
+		if ban.Expires.After(now) {
+			bans = append(bans, ban)
+		}
+	}
+	sort.Slice(bans, func(i, j int) bool { return bans[i].Target < bans[j].Target })
+	return bans
+}
+
+// remoteIP returns the IP address of the multiaddr, or nil if it has none.
+func remoteIP(addr ma.Multiaddr) net.IP {
+	if addr == nil {
+		return nil
+	}
+	return net.ParseIP(GetIPfromAddr(addr))
+}
+
+// Ban disconnects and bans a peer ID, IP address or IP network in CIDR
+// notation for the duration.
+func (server *Server) Ban(target string, duration time.Duration, reason string) error {
+	if err := server.bans.add(target, duration, reason); err != nil {
+		return err
+	}
+	for _, p := range server.Peers() {
+		if server.bans.banned(p.RemoteID(), remoteIP(p.rw.Conn().RemoteMultiaddr())) != nil {
+			p.Disconnect(DiscBanned)
+		}
+	}
+	return nil
+}
+
+// Unban lifts the ban of a peer ID, IP address or IP network.
+func (server *Server) Unban(target string) error {
+	return server.bans.remove(target)
+}
+
+// Bans returns the active bans.
+func (server *Server) Bans() []*Ban {
+	return server.bans.list()
+}
@@ -0,0 +1,161 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntp2p
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	libp2p "github.com/libp2p/go-libp2p-peer"
+)
+
+const (
+	testBanPeer  = "1kHBzN17vVE75rwZA7vKAFfxUYS8XMh6QBYS6JWF13xHGX9"
+	testBanPeer2 = "1kHaMUmZgTpjGEhxcGATr1UVWy6iKkygFuknWEtW7LiLrev"
+)
+
+func TestParseBanTarget(t *testing.T) {
+	tests := []struct {
+		target string
+		id     bool
+		ipnet  string
+		err    bool
+	}{
+		{target: testBanPeer, id: true},
+		{target: "10.0.0.1", ipnet: "10.0.0.1/32"},
+		{target: "::1", ipnet: "::1/128"},
+		{target: "10.0.0.0/8", ipnet: "10.0.0.0/8"},
+		{target: "10.0.0.0/33", err: true},
+		{target: "not a target", err: true},
+	}
+	for _, tt := range tests {
+		id, ipnet, err := parseBanTarget(tt.target)
+		if (err != nil) != tt.err {
+			t.Errorf("%s: error mismatch: have %v, want error %v", tt.target, err, tt.err)
+			continue
+		}
+		if tt.err {
+			continue
+		}
+		if (id != "") != tt.id {
+			t.Errorf("%s: peer id mismatch: have %q", tt.target, id)
+		}
+		if tt.ipnet != "" && (ipnet == nil || ipnet.String() != tt.ipnet) {
+			t.Errorf("%s: network mismatch: have %v, want %s", tt.target, ipnet, tt.ipnet)
+		}
+	}
+}
+
+// Tests that peers and networks are banned until their bans expire or are
+// lifted.
+func TestBanList(t *testing.T) {
+	l := newBanList(nil)
+	id, _ := libp2p.IDB58Decode(testBanPeer)
+	other, _ := libp2p.IDB58Decode(testBanPeer2)
+
+	if err := l.add(testBanPeer, time.Hour, "test"); err != nil {
+		t.Fatalf("failed to ban peer: %v", err)
+	}
+	if err := l.add("10.0.0.0/8", time.Hour, "test"); err != nil {
+		t.Fatalf("failed to ban network: %v", err)
+	}
+	if ban := l.banned(id, nil); ban == nil || ban.Reason != "test" {
+		t.Errorf("banned peer not reported: %v", ban)
+	}
+	if ban := l.banned(other, net.ParseIP("10.1.2.3")); ban == nil || ban.Target != "10.0.0.0/8" {
+		t.Errorf("peer of banned network not reported: %v", ban)
+	}
+	if ban := l.banned(other, net.ParseIP("192.168.0.1")); ban != nil {
+		t.Errorf("unbanned peer reported: %v", ban)
+	}
+	if bans := l.list(); len(bans) != 2 || bans[0].Target != "10.0.0.0/8" {
+		t.Errorf("ban list mismatch: %v", bans)
+	}
+	// Lifted bans
+	if err := l.remove("10.0.0.0/8"); err != nil {
+		t.Fatalf("failed to lift ban: %v", err)
+	}
+	if err := l.remove("10.0.0.0/8"); err != errNotBanned {
+		t.Errorf("lifted twice: have %v, want %v", err, errNotBanned)
+	}
+	if ban := l.banned(other, net.ParseIP("10.1.2.3")); ban != nil {
+		t.Errorf("lifted ban reported: %v", ban)
+	}
+	// Expired bans
+	if err := l.add(testBanPeer, -time.Second, "expired"); err != nil {
+		t.Fatalf("failed to ban peer: %v", err)
+	}
+	if bans := l.list(); len(bans) != 0 {
+		t.Errorf("expired bans listed: %v", bans)
+	}
+	if ban := l.banned(id, nil); ban != nil {
+		t.Errorf("expired ban reported: %v", ban)
+	}
+	if len(l.peers) != 0 {
+		t.Errorf("expired ban kept")
+	}
+}
+
+// Tests that bans are persisted, and that expired and lifted ones are dropped
+// from the store.
+func TestBanListPersistence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vnt-ban-test")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := newDatastore(dir, "")
+	if err != nil {
+		t.Fatalf("failed to open datastore: %v", err)
+	}
+	defer db.Close()
+
+	l := newBanList(db)
+	for _, target := range []string{testBanPeer, "10.0.0.0/8", "192.168.0.1"} {
+		if err := l.add(target, time.Hour, "test"); err != nil {
+			t.Fatalf("failed to ban %s: %v", target, err)
+		}
+	}
+	if err := l.add(testBanPeer2, 50*time.Millisecond, "short"); err != nil {
+		t.Fatalf("failed to ban %s: %v", testBanPeer2, err)
+	}
+	if err := l.remove("192.168.0.1"); err != nil {
+		t.Fatalf("failed to lift ban: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	reloaded := newBanList(db)
+	bans := reloaded.list()
+	if len(bans) != 2 || bans[0].Target != "10.0.0.0/8" || bans[1].Target != testBanPeer {
+		t.Fatalf("reloaded bans mismatch: %v", bans)
+	}
+	id, _ := libp2p.IDB58Decode(testBanPeer)
+	if ban := reloaded.banned(id, nil); ban == nil || ban.Reason != "test" {
+		t.Errorf("reloaded peer ban not reported: %v", ban)
+	}
+	// The expired ban was deleted from the store on reload
+	if has, _ := db.Has(banKey(testBanPeer2)); has {
+		t.Errorf("expired ban kept in store")
+	}
+	if has, _ := db.Has(banKey("192.168.0.1/32")); has {
+		t.Errorf("lifted ban kept in store")
+	}
+}
@@ -0,0 +1,406 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntp2p
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	peer "github.com/libp2p/go-libp2p-peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/vntchain/go-vnt/common/hexutil"
+	"github.com/vntchain/go-vnt/crypto"
+	"github.com/vntchain/go-vnt/log"
+	"github.com/vntchain/go-vnt/rlp"
+)
+
+// Bootstrap lists are signed lists of node urls, published in a json file or
+// in a tree of DNS TXT records. Their sequence number must increase with every
+// update, older lists are ignored.
+//
+// The DNS tree is content addressed, every entry is found at the hash of its
+// text below the domain:
+//
+//	<domain>        vnt-root:v1 e=<hash> seq=<seq> sig=<signature>
+//	<hash>.<domain> vnt-branch:<hash>,<hash>,...
+//	<hash>.<domain> vnt-node:<url>
+//
+// The root points to the top entry, branches list their children. Nodes are
+// listed in tree order and the signature of the root covers the whole list.
+const (
+	treeRootPrefix   = "vnt-root:v1"
+	treeBranchPrefix = "vnt-branch:"
+	treeNodePrefix   = "vnt-node:"
+
+	maxBranchChildren = 13   // Hashes fitting in the 255 bytes of a TXT string
+	maxTreeEntries    = 5000 // Entries fetched at most from a DNS tree
+
+	bootstrapRefreshInterval = 30 * time.Minute
+	bootstrapFetchTimeout    = time.Minute
+
+	// Addresses of list nodes outlive a few failed refreshes.
+	bootstrapAddrTTL = 4 * bootstrapRefreshInterval
+)
+
+var (
+	errListSignature = errors.New("invalid node list signature")
+	treeHashEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+)
+
+// NodeList is a signed list of node urls.
+type NodeList struct {
+	Seq   uint64        `json:"seq"`
+	Nodes []string      `json:"nodes"`
+	Sig   hexutil.Bytes `json:"sig"`
+}
+
+func (l *NodeList) sigHash() []byte {
+	enc, _ := rlp.EncodeToBytes([]interface{}{l.Seq, l.Nodes})
+	return crypto.Keccak256(enc)
+}
+
+// Sign signs the list with the key.
+func (l *NodeList) Sign(key *ecdsa.PrivateKey) (err error) {
+	l.Sig, err = crypto.Sign(l.sigHash(), key)
+	return err
+}
+
+// Verify checks that the list was signed by the key.
+func (l *NodeList) Verify(pubkey *ecdsa.PublicKey) error {
+	signer, err := crypto.SigToPub(l.sigHash(), l.Sig)
+	if err != nil {
+		return err
+	}
+	if crypto.PubkeyToAddress(*signer) != crypto.PubkeyToAddress(*pubkey) {
+		return errListSignature
+	}
+	return nil
+}
+
+// parse returns the valid nodes of the list.
+func (l *NodeList) parse() []*Node {
+	nodes := make([]*Node, 0, len(l.Nodes))
+	for _, rawurl := range l.Nodes {
+		node, err := ParseNode(rawurl)
+		if err != nil {
+			log.Warn("Invalid url in bootstrap list", "url", rawurl, "err", err)
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// BootstrapSource is a source of signed bootstrap node lists.
+type BootstrapSource interface {
+	// Fetch retrieves the current list and verifies its signature.
+	Fetch(ctx context.Context) (*NodeList, error)
+	String() string
+}
+
+// ParseBootstrapSource parses the url of a bootstrap list, either
+// dns://<pubkey>@<domain> or file://<pubkey>@<path>. The public key of the
+// list signer is given in compressed hex form.
+func ParseBootstrapSource(rawurl string) (BootstrapSource, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	if u.User == nil {
+		return nil, fmt.Errorf("bootstrap list %q: missing signer public key", rawurl)
+	}
+	key, err := hex.DecodeString(strings.TrimPrefix(u.User.Username(), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("bootstrap list %q: invalid public key: %v", rawurl, err)
+	}
+	pubkey, err := crypto.DecompressPubkey(key)
+	if err != nil {
+		return nil, fmt.Errorf("bootstrap list %q: invalid public key: %v", rawurl, err)
+	}
+	switch u.Scheme {
+	case "dns":
+		if u.Host == "" {
+			return nil, fmt.Errorf("bootstrap list %q: missing domain", rawurl)
+		}
+		return NewDNSSource(u.Host, pubkey, nil), nil
+	case "file":
+		if u.Path == "" {
+			return nil, fmt.Errorf("bootstrap list %q: missing path", rawurl)
+		}
+		return NewFileSource(u.Path, pubkey), nil
+	default:
+		return nil, fmt.Errorf("bootstrap list %q: unknown scheme %q", rawurl, u.Scheme)
+	}
+}
+
+type fileSource struct {
+	path   string
+	pubkey *ecdsa.PublicKey
+}
+
+// NewFileSource creates a source reading a json encoded NodeList from a file.
+func NewFileSource(path string, pubkey *ecdsa.PublicKey) BootstrapSource {
+	return &fileSource{path: path, pubkey: pubkey}
+}
+
+func (s *fileSource) Fetch(ctx context.Context) (*NodeList, error) {
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	list := new(NodeList)
+	if err := json.Unmarshal(data, list); err != nil {
+		return nil, err
+	}
+	if err := list.Verify(s.pubkey); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (s *fileSource) String() string {
+	return "file://" + s.path
+}
+
+// Resolver looks up DNS TXT records, it is implemented by net.Resolver.
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+type dnsSource struct {
+	domain   string
+	pubkey   *ecdsa.PublicKey
+	resolver Resolver
+}
+
+// NewDNSSource creates a source reading the DNS tree published at the domain.
+// The system resolver is used if resolver is nil.
+func NewDNSSource(domain string, pubkey *ecdsa.PublicKey, resolver Resolver) BootstrapSource {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &dnsSource{domain: domain, pubkey: pubkey, resolver: resolver}
+}
+
+func (s *dnsSource) Fetch(ctx context.Context) (*NodeList, error) {
+	top, list, err := s.fetchRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		entries int
+		walk    func(hash string) error
+	)
+	walk = func(hash string) error {
+		if entries++; entries > maxTreeEntries {
+			return fmt.Errorf("tree has more than %d entries", maxTreeEntries)
+		}
+		entry, err := s.lookup(ctx, hash+"."+s.domain, treeBranchPrefix, treeNodePrefix)
+		if err != nil {
+			return err
+		}
+		if treeHash(entry) != hash {
+			return fmt.Errorf("entry %s.%s: hash mismatch", hash, s.domain)
+		}
+		if strings.HasPrefix(entry, treeNodePrefix) {
+			list.Nodes = append(list.Nodes, strings.TrimPrefix(entry, treeNodePrefix))
+			return nil
+		}
+		for _, child := range strings.Split(strings.TrimPrefix(entry, treeBranchPrefix), ",") {
+			if child == "" {
+				continue
+			}
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(top); err != nil {
+		return nil, err
+	}
+	if err := list.Verify(s.pubkey); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// fetchRoot resolves the root of the tree, returning the hash of the top entry
+// and the list without its nodes.
+func (s *dnsSource) fetchRoot(ctx context.Context) (string, *NodeList, error) {
+	root, err := s.lookup(ctx, s.domain, treeRootPrefix+" ")
+	if err != nil {
+		return "", nil, err
+	}
+	var (
+		top  string
+		list = new(NodeList)
+	)
+	for _, field := range strings.Fields(strings.TrimPrefix(root, treeRootPrefix)) {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "e":
+			top = kv[1]
+		case "seq":
+			if list.Seq, err = strconv.ParseUint(kv[1], 10, 64); err != nil {
+				return "", nil, fmt.Errorf("root of %s: invalid seq: %v", s.domain, err)
+			}
+		case "sig":
+			if list.Sig, err = base64.RawURLEncoding.DecodeString(kv[1]); err != nil {
+				return "", nil, fmt.Errorf("root of %s: invalid sig: %v", s.domain, err)
+			}
+		}
+	}
+	if top == "" || list.Sig == nil {
+		return "", nil, fmt.Errorf("root of %s: incomplete record", s.domain)
+	}
+	return top, list, nil
+}
+
+// lookup returns the first TXT record of the name with one of the prefixes.
+func (s *dnsSource) lookup(ctx context.Context, name string, prefixes ...string) (string, error) {
+	txts, err := s.resolver.LookupTXT(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	for _, txt := range txts {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(txt, prefix) {
+				return txt, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no tree entry at %s", name)
+}
+
+func (s *dnsSource) String() string {
+	return "dns://" + s.domain
+}
+
+// treeHash returns the name of a tree entry.
+func treeHash(entry string) string {
+	return treeHashEncoding.EncodeToString(crypto.Keccak256([]byte(entry))[:16])
+}
+
+// MakeDNSTree returns the TXT records publishing the signed list below the
+// domain, keyed by their full names.
+func MakeDNSTree(domain string, list *NodeList) map[string]string {
+	records := make(map[string]string)
+	add := func(entry string) string {
+		hash := treeHash(entry)
+		records[hash+"."+domain] = entry
+		return hash
+	}
+	level := make([]string, 0, len(list.Nodes))
+	for _, rawurl := range list.Nodes {
+		level = append(level, add(treeNodePrefix+rawurl))
+	}
+	if len(level) == 0 {
+		level = append(level, add(treeBranchPrefix))
+	}
+	// Group the entries into branches until a single one is left
+	for len(level) != 1 {
+		var next []string
+		for i := 0; i < len(level); i += maxBranchChildren {
+			end := i + maxBranchChildren
+			if end > len(level) {
+				end = len(level)
+			}
+			next = append(next, add(treeBranchPrefix+strings.Join(level[i:end], ",")))
+		}
+		level = next
+	}
+	records[domain] = fmt.Sprintf("%s e=%s seq=%d sig=%s", treeRootPrefix, level[0], list.Seq, base64.RawURLEncoding.EncodeToString(list.Sig))
+	return records
+}
+
+// bootstrapLoop periodically fetches the bootstrap lists, the nodes of the
+// latest lists are added to the dht and dialled like bootstrap nodes.
+func (server *Server) bootstrapLoop(ctx context.Context, sources []BootstrapSource) {
+	defer server.loopWG.Done()
+
+	var (
+		timer = time.NewTimer(0)
+		seqs  = make(map[BootstrapSource]uint64)
+		lists = make(map[BootstrapSource][]*Node)
+	)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+		case <-server.quit:
+			return
+		}
+		changed := false
+		for _, source := range sources {
+			fetchCtx, cancel := context.WithTimeout(ctx, bootstrapFetchTimeout)
+			list, err := source.Fetch(fetchCtx)
+			cancel()
+			if err != nil {
+				log.Warn("Failed to fetch bootstrap list", "source", source, "err", err)
+				continue
+			}
+			if seq, ok := seqs[source]; ok && list.Seq <= seq {
+				continue
+			}
+			seqs[source], lists[source] = list.Seq, list.parse()
+			log.Info("Updated bootstrap list", "source", source, "seq", list.Seq, "nodes", len(lists[source]))
+			changed = true
+		}
+		if changed {
+			var nodes []*Node
+			for _, source := range sources {
+				nodes = append(nodes, lists[source]...)
+			}
+			server.setListNodes(ctx, nodes)
+		}
+		timer.Reset(bootstrapRefreshInterval)
+	}
+}
+
+// setListNodes feeds the nodes of the bootstrap lists into the dht and hands
+// them to the dialer, replacing the previous ones.
+func (server *Server) setListNodes(ctx context.Context, nodes []*Node) {
+	ids := make([]peer.ID, 0, len(nodes))
+	for _, node := range nodes {
+		if node.Id == server.host.ID() {
+			continue
+		}
+		server.host.Peerstore().AddAddrs(node.Id, []ma.Multiaddr{node.Addr}, bootstrapAddrTTL)
+		server.table.Update(ctx, node.Id)
+		ids = append(ids, node.Id)
+	}
+	select {
+	case server.setlistnodes <- ids:
+	case <-server.quit:
+	}
+}
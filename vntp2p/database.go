@@ -6,48 +6,52 @@ import (
 	ds "github.com/ipfs/go-datastore"
 	"github.com/ipfs/go-datastore/query"
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/vntdb"
 )
 
-// LevelDB vntdb object
-type LevelDB struct {
+// Datastore is the dht datastore, kept by one of the vntdb engines.
+type Datastore struct {
 	path string
-	db   *leveldb.DB
+	db   vntdb.Store
 }
 
-// 最好使用单例，因为leveldb只能有一个打开句柄，而且这个句柄是线程安全的。
-var vntdb *LevelDB
+// 最好使用单例，因为数据库只能有一个打开句柄，而且这个句柄是线程安全的。
+var datastore *Datastore
 
-// GetDatastore singleton design pattern
-func GetDatastore(path string) (*LevelDB, error) {
-	if vntdb != nil && vntdb.path == path {
-		return vntdb, nil
+// GetDatastore singleton design pattern. The datastore is opened with the
+// given engine, or the one it was created with if empty.
+func GetDatastore(path, engine string) (*Datastore, error) {
+	if datastore != nil && datastore.path == path {
+		return datastore, nil
 	}
-	vntdb, err := newDatastore(path)
+	db, err := newDatastore(path, engine)
 	if err != nil {
 		return nil, err
 	}
-	return vntdb, nil
+	datastore = db
+	return datastore, nil
 }
 
-func newDatastore(path string) (*LevelDB, error) {
-	db, err := leveldb.OpenFile(path, nil)
+func newDatastore(path, engine string) (*Datastore, error) {
+	db, err := vntdb.Open(engine, path, 0, 0)
 	if err != nil {
 		return nil, err
 	}
-	return &LevelDB{
+	return &Datastore{
 		path: path,
 		db:   db,
 	}, nil
 }
 
 // Put implement Put() of ds.Batching interface
-func (d *LevelDB) Put(key ds.Key, value interface{}) (err error) {
+func (d *Datastore) Put(key ds.Key, value interface{}) (err error) {
 	byteKey := []byte(key.String())
 	byteVal, ok := value.([]byte)
 	if !ok {
 		return ds.ErrInvalidType
 	}
-	err = d.db.Put(byteKey, byteVal, nil)
+	err = d.db.Put(byteKey, byteVal)
 	if err != nil {
 		fmt.Printf("leveldb put error = %s\n", err)
 		return err
@@ -56,9 +60,9 @@ func (d *LevelDB) Put(key ds.Key, value interface{}) (err error) {
 }
 
 // Get implement Get() of ds.Batching interface
-func (d *LevelDB) Get(key ds.Key) (value interface{}, err error) {
+func (d *Datastore) Get(key ds.Key) (value interface{}, err error) {
 	byteKey := []byte(key.String())
-	byteVal, err := d.db.Get(byteKey, nil)
+	byteVal, err := d.db.Get(byteKey)
 	if err == leveldb.ErrNotFound {
 		return nil, ds.ErrNotFound
 	}
@@ -69,9 +73,9 @@ func (d *LevelDB) Get(key ds.Key) (value interface{}, err error) {
 }
 
 // Has implement Has() of ds.Batching interface
-func (d *LevelDB) Has(key ds.Key) (exists bool, err error) {
+func (d *Datastore) Has(key ds.Key) (exists bool, err error) {
 	byteKey := []byte(key.String())
-	exists, err = d.db.Has(byteKey, nil)
+	exists, err = d.db.Has(byteKey)
 	if err != nil {
 		return false, err
 	}
@@ -79,9 +83,9 @@ func (d *LevelDB) Has(key ds.Key) (exists bool, err error) {
 }
 
 // Delete implement Delete() of ds.Batching interface
-func (d *LevelDB) Delete(key ds.Key) (err error) {
+func (d *Datastore) Delete(key ds.Key) (err error) {
 	byteKey := []byte(key.String())
-	err = d.db.Delete(byteKey, nil)
+	err = d.db.Delete(byteKey)
 	if err != nil {
 		return err
 	}
@@ -89,12 +93,14 @@ func (d *LevelDB) Delete(key ds.Key) (err error) {
 }
 
 // Query implement Query() of ds.Batching interface
-func (d *LevelDB) Query(q query.Query) (query.Results, error) {
+func (d *Datastore) Query(q query.Query) (query.Results, error) {
 	var re []query.Entry
-	iter := d.db.NewIterator(nil, nil)
+	iter := d.db.NewIterator()
+	defer iter.Release()
 	for iter.Next() {
 		keyByte := iter.Key()
-		valueByte := iter.Value()
+		// The iterator reuses its buffers, so the value has to be copied
+		valueByte := common.CopyBytes(iter.Value())
 		re = append(re, query.Entry{Key: string(keyByte), Value: valueByte})
 	}
 	r := query.ResultsWithEntries(q, re)
@@ -103,16 +109,13 @@ func (d *LevelDB) Query(q query.Query) (query.Results, error) {
 }
 
 // Close implement Close() of ds.Batching interface
-func (d *LevelDB) Close() error {
-	err := d.db.Close()
-	if err != nil {
-		return err
-	}
+func (d *Datastore) Close() error {
+	d.db.Close()
 	d = nil //GC
 	return nil
 }
 
 // Batch implement Batch() of ds.Batching interface
-func (d *LevelDB) Batch() (ds.Batch, error) {
+func (d *Datastore) Batch() (ds.Batch, error) {
 	return ds.NewBasicBatch(d), nil
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"github.com/vntchain/go-vnt/log"
 	"github.com/libp2p/go-libp2p-peer"
+	"math/rand"
 	"time"
 )
 
@@ -18,12 +19,25 @@ var (
 	errNotWhitelisted   = errors.New("not contained in netrestrict whitelist")
 )
 
+const (
+	// Trusted and reserved peers are redialled after failures with an
+	// exponential backoff, starting at redialBackoff.
+	redialBackoff    = 5 * time.Second
+	maxRedialBackoff = 5 * time.Minute
+)
+
 type taskstate struct {
 	maxDynDials int
+	discovery   bool // Whether random nodes of the table are dialled
 	table       DhtTable
 	bootnodes   []peer.ID
+	listnodes   []peer.ID // Nodes of the bootstrap lists, dialled in the dynamic slots
 	static      map[peer.ID]*dialTask
 	dailmap     map[peer.ID]dialFlag
+
+	// persistent holds the trusted and reserved peers, which are kept
+	// connected regardless of MaxPeers
+	persistent map[peer.ID]*dialTask
 }
 
 type task interface {
@@ -34,6 +48,11 @@ type dialTask struct {
 	flag   dialFlag
 	target peer.ID
 	pid    string
+
+	// failures counts the dials since the peer was last connected, next is
+	// the earliest time of the next dial
+	failures int
+	next     time.Time
 }
 
 type lookupTask struct {
@@ -53,18 +72,22 @@ func (t *taskstate) newTasks(peers map[peer.ID]*Peer) []task {
 		}
 		t.dailmap[n] = flag
 		// fmt.Println("begin to Add: ", n)
-		newtasks = append(newtasks, &dialTask{target: n, pid: PID})
+		newtasks = append(newtasks, &dialTask{flag: flag, target: n, pid: PID})
 		return true
 	}
 
+	// Dynamic slots are taken by the dynamic peers and dials
 	needdail := t.maxDynDials
-	// dail
-
 	for _, flag := range t.dailmap {
 		if flag&dynDialedDail != 0 {
 			needdail--
 		}
 	}
+	for _, p := range peers {
+		if p.is(dynDialedDail) {
+			needdail--
+		}
+	}
 
 	// newtasks = append(newtasks, &dailTask{})
 	for id, task := range t.static {
@@ -79,6 +102,18 @@ func (t *taskstate) newTasks(peers map[peer.ID]*Peer) []task {
 		}
 	}
 
+	now := time.Now()
+	for id, task := range t.persistent {
+		err := t.checkDial(id, peers)
+		switch {
+		case err == errAlreadyConnected:
+			task.failures, task.next = 0, time.Time{}
+		case err == nil && !now.Before(task.next):
+			t.dailmap[id] = task.flag
+			newtasks = append(newtasks, task)
+		}
+	}
+
 	for _, bootnode := range t.bootnodes {
 		// fmt.Println("bootnode: ", bootnode)
 		// for k, _ := range t.dailmap {
@@ -93,10 +128,20 @@ func (t *taskstate) newTasks(peers map[peer.ID]*Peer) []task {
 			needdail--
 		}
 	}
+	// List nodes fill the free dynamic slots, in random order so that the
+	// load spreads over the nodes of the lists
+	for _, i := range rand.Perm(len(t.listnodes)) {
+		if needdail <= 0 {
+			break
+		}
+		if addDial(dynDialedDail, t.listnodes[i]) {
+			needdail--
+		}
+	}
 
 	randomDail := needdail / 2
 
-	if randomDail > 0 {
+	if t.discovery && randomDail > 0 {
 		randompeerlist := t.table.RandomPeer()
 		for i := 0; i < randomDail && i < len(randompeerlist); i++ {
 			if addDial(dynDialedDail, randompeerlist[i]) {
@@ -129,6 +174,11 @@ func (s *taskstate) checkDial(n peer.ID, peers map[peer.ID]*Peer) error {
 	return nil
 }
 
+// setListNodes replaces the nodes of the bootstrap lists.
+func (s *taskstate) setListNodes(ids []peer.ID) {
+	s.listnodes = ids
+}
+
 func (s *taskstate) removeStatic(n *Node) {
 	delete(s.static, n.Id)
 }
@@ -140,6 +190,11 @@ func (s *taskstate) taskDone(t task) {
 		// log.Debug("taskDone", "dialTask", t.target)
 		// fmt.Println("taskDone dialTask", t.target)
 		delete(s.dailmap, t.target)
+		if t.flag&(trustedDail|reservedDail) != 0 {
+			// Connected peers reset the counter in newTasks
+			t.next = time.Now().Add(redialDelay(t.failures))
+			t.failures++
+		}
 	case *lookupTask:
 		log.Debug("taskDone", "lookupTask")
 	}
@@ -149,12 +204,47 @@ func (s *taskstate) addStatic(n *Node) {
 	s.static[n.Id] = &dialTask{flag: staticDialedDail, target: n.Id, pid: PID}
 }
 
-func newTaskState(maxdail int, bootnodes []peer.ID, dht DhtTable) *taskstate {
+// addPersistent keeps dialling the node, flag is either trustedDail or
+// reservedDail.
+func (s *taskstate) addPersistent(n *Node, flag dialFlag) {
+	if task, ok := s.persistent[n.Id]; ok {
+		task.flag |= flag
+		return
+	}
+	s.persistent[n.Id] = &dialTask{flag: flag, target: n.Id, pid: PID}
+}
+
+// removePersistent stops dialling the node unless it is kept for another
+// reason.
+func (s *taskstate) removePersistent(id peer.ID, flag dialFlag) {
+	task, ok := s.persistent[id]
+	if !ok {
+		return
+	}
+	if task.flag &^= flag; task.flag&(trustedDail|reservedDail) == 0 {
+		delete(s.persistent, id)
+	}
+}
+
+// redialDelay returns the backoff after the given number of failed dials.
+func redialDelay(failures int) time.Duration {
+	if failures > 10 {
+		return maxRedialBackoff
+	}
+	if delay := redialBackoff << uint(failures); delay < maxRedialBackoff {
+		return delay
+	}
+	return maxRedialBackoff
+}
+
+func newTaskState(maxdail int, discovery bool, bootnodes []peer.ID, dht DhtTable) *taskstate {
 	s := &taskstate{
 		maxDynDials: maxdail,
+		discovery:   discovery,
 		bootnodes:   make([]peer.ID, len(bootnodes)),
 		dailmap:     make(map[peer.ID]dialFlag),
 		static:      make(map[peer.ID]*dialTask),
+		persistent:  make(map[peer.ID]*dialTask),
 		table:       dht,
 	}
 
@@ -186,7 +276,7 @@ func (t *dialTask) checkTarget() bool {
 }
 
 func (t *dialTask) dial(ctx context.Context, server *Server, target peer.ID, pid string) error {
-	return server.SetupStream(ctx, target, pid)
+	return server.setupStream(ctx, target, pid, t.flag)
 }
 
 func (t *lookupTask) Do(ctx context.Context, server *Server) {
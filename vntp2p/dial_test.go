@@ -0,0 +1,102 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntp2p
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	peer "github.com/libp2p/go-libp2p-peer"
+)
+
+func TestRedialDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 0, want: 5 * time.Second},
+		{failures: 1, want: 10 * time.Second},
+		{failures: 2, want: 20 * time.Second},
+		{failures: 3, want: 40 * time.Second},
+		{failures: 4, want: 80 * time.Second},
+		{failures: 5, want: 160 * time.Second},
+		{failures: 6, want: maxRedialBackoff},
+		{failures: 10, want: maxRedialBackoff},
+		{failures: 11, want: maxRedialBackoff},
+		// Shifts past the width of the duration don't wrap around
+		{failures: 40, want: maxRedialBackoff},
+		{failures: 64, want: maxRedialBackoff},
+		{failures: 1 << 20, want: maxRedialBackoff},
+	}
+	for _, tt := range tests {
+		if have := redialDelay(tt.failures); have != tt.want {
+			t.Errorf("failures %d: delay mismatch: have %v, want %v", tt.failures, have, tt.want)
+		}
+	}
+	// The backoff never shrinks with more failures
+	for i := 1; i < 100; i++ {
+		if redialDelay(i) < redialDelay(i-1) {
+			t.Errorf("failures %d: delay %v below previous %v", i, redialDelay(i), redialDelay(i-1))
+		}
+	}
+}
+
+// Tests that the nodes of the bootstrap lists are only dialled in the free
+// dynamic slots.
+func TestListNodeDials(t *testing.T) {
+	s := newTaskState(3, false, nil, nil)
+	var ids []peer.ID
+	for i := 0; i < 10; i++ {
+		ids = append(ids, peer.ID(fmt.Sprintf("node-%d", i)))
+	}
+	s.setListNodes(ids)
+
+	dials := func(peers map[peer.ID]*Peer) []*dialTask {
+		var dials []*dialTask
+		for _, task := range s.newTasks(peers) {
+			if task, ok := task.(*dialTask); ok {
+				dials = append(dials, task)
+			}
+		}
+		return dials
+	}
+	first := dials(nil)
+	if len(first) != 3 {
+		t.Fatalf("dial count mismatch: have %d, want 3", len(first))
+	}
+	for _, task := range first {
+		if task.flag != dynDialedDail {
+			t.Errorf("list node %s dialled with flag %d, want %d", task.target, task.flag, dynDialedDail)
+		}
+	}
+	// Running dials take the slots
+	if more := dials(nil); len(more) != 0 {
+		t.Errorf("dialled %d nodes without free slots", len(more))
+	}
+	// So do the dynamic peers once connected
+	peers := make(map[peer.ID]*Peer)
+	for i, task := range first {
+		s.taskDone(task)
+		if i < 2 {
+			peers[task.target] = &Peer{flags: dynDialedDail}
+		}
+	}
+	if more := dials(peers); len(more) != 1 {
+		t.Errorf("dial count with two dynamic peers mismatch: have %d, want 1", len(more))
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntp2p
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/vntchain/go-vnt/rlp"
+)
+
+// Binary frame layout, all integers are unsigned varints:
+//
+//	version(1 byte) | flags(1 byte) | len(protocol) | protocol | code | len(payload) | payload
+//
+// The payload is the rlp encoded message, optionally snappy compressed. Nodes
+// negotiate the binary framing by opening their streams with FramedPID, the
+// legacy json framing is kept for streams opened with PID.
+const (
+	frameVersion = 1
+
+	frameFlagSnappy = 1 << 0
+
+	// DefaultMaxMsgSize is the size limit of protocols which don't set one.
+	DefaultMaxMsgSize = 10 * 1024 * 1024
+
+	maxProtocolNameLength = 32
+
+	// Payloads below this size are never compressed.
+	compressionThreshold = 256
+)
+
+var (
+	errFrameVersion = errors.New("unsupported frame version")
+	errMsgTooLarge  = errors.New("message too large")
+)
+
+// maxMsgSize returns the size limit of the protocol.
+func (p *Protocol) maxMsgSize() uint32 {
+	if p.MaxMsgSize == 0 {
+		return DefaultMaxMsgSize
+	}
+	return p.MaxMsgSize
+}
+
+// writeFrame writes the message as a single binary frame, the caller must make
+// sure the payload is within the limit of the protocol.
+func writeFrame(w io.Writer, msg Msg, compress bool) error {
+	payload, err := ioutil.ReadAll(msg.Body.Payload)
+	if err != nil {
+		return err
+	}
+	var flags byte
+	if compress && len(payload) >= compressionThreshold {
+		if compressed := snappy.Encode(nil, payload); len(compressed) < len(payload) {
+			payload, flags = compressed, flags|frameFlagSnappy
+		}
+	}
+	frame := make([]byte, 2, 2+3*binary.MaxVarintLen64+len(msg.Body.ProtocolID)+len(payload))
+	frame[0], frame[1] = frameVersion, flags
+	frame = appendUvarint(frame, uint64(len(msg.Body.ProtocolID)))
+	frame = append(frame, msg.Body.ProtocolID...)
+	frame = appendUvarint(frame, uint64(msg.Body.Type))
+	frame = appendUvarint(frame, uint64(len(payload)))
+	frame = append(frame, payload...)
+
+	_, err = w.Write(frame)
+	return err
+}
+
+// readFrame reads a binary frame, rejecting it before allocating the payload if
+// it exceeds the limit of its protocol.
+func readFrame(r *bufio.Reader, limit func(protocol string) uint32) (Msg, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return Msg{}, err
+	}
+	if head[0] != frameVersion {
+		return Msg{}, errFrameVersion
+	}
+	nameLen, err := binary.ReadUvarint(r)
+	if err != nil {
+		return Msg{}, err
+	}
+	if nameLen > maxProtocolNameLength {
+		return Msg{}, fmt.Errorf("protocol name too long: %d", nameLen)
+	}
+	name := make([]byte, nameLen)
+	if _, err := io.ReadFull(r, name); err != nil {
+		return Msg{}, err
+	}
+	code, err := binary.ReadUvarint(r)
+	if err != nil {
+		return Msg{}, err
+	}
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return Msg{}, err
+	}
+	max := limit(string(name))
+	if size > uint64(max) {
+		return Msg{}, fmt.Errorf("%v: %d > %d", errMsgTooLarge, size, max)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return Msg{}, err
+	}
+	if head[1]&frameFlagSnappy != 0 {
+		decodedLen, err := snappy.DecodedLen(payload)
+		if err != nil {
+			return Msg{}, err
+		}
+		if decodedLen > int(max) {
+			return Msg{}, fmt.Errorf("%v: %d > %d", errMsgTooLarge, decodedLen, max)
+		}
+		if payload, err = snappy.Decode(nil, payload); err != nil {
+			return Msg{}, err
+		}
+	}
+	return newMsg(string(name), MessageType(code), payload), nil
+}
+
+// newMsg assembles a received message, the header carries the payload size.
+func newMsg(protocol string, code MessageType, payload []byte) Msg {
+	var header MsgHeader
+	binary.LittleEndian.PutUint32(header[:], uint32(len(payload)))
+	return Msg{
+		Header: header,
+		Body: MsgBody{
+			ProtocolID:  protocol,
+			Type:        code,
+			ReceivedAt:  time.Now(),
+			PayloadSize: uint32(len(payload)),
+			Payload:     bytes.NewReader(payload),
+		},
+	}
+}
+
+// readLegacyFrame reads a length prefixed json message. The json encoding
+// carries the base64 encoded rlp buffer, so the frame may be about twice the
+// size of the payload.
+func readLegacyFrame(r io.Reader, max uint32) (Msg, error) {
+	var header MsgHeader
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return Msg{}, err
+	}
+	bodySize := binary.LittleEndian.Uint32(header[:])
+	if limit := 2*uint64(max) + 64*1024; uint64(bodySize) > limit {
+		return Msg{}, fmt.Errorf("%v: %d > %d", errMsgTooLarge, bodySize, limit)
+	}
+	msgBodyByte := make([]byte, bodySize)
+	if _, err := io.ReadFull(r, msgBodyByte); err != nil {
+		return Msg{}, err
+	}
+	msgBody := &MsgBody{Payload: &rlp.EncReader{}}
+	if err := json.Unmarshal(msgBodyByte, msgBody); err != nil {
+		return Msg{}, err
+	}
+	if msgBody.PayloadSize > max {
+		return Msg{}, fmt.Errorf("%v: %d > %d", errMsgTooLarge, msgBody.PayloadSize, max)
+	}
+	msgBody.ReceivedAt = time.Now()
+	binary.LittleEndian.PutUint32(header[:], msgBody.PayloadSize)
+	return Msg{Header: header, Body: *msgBody}, nil
+}
+
+func appendUvarint(b []byte, x uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], x)
+	return append(b, buf[:n]...)
+}
@@ -0,0 +1,198 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntp2p
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/vntchain/go-vnt/rlp"
+)
+
+func testFrameMsg(protocol string, code MessageType, payload []byte) Msg {
+	return Msg{Body: MsgBody{
+		ProtocolID:  protocol,
+		Type:        code,
+		PayloadSize: uint32(len(payload)),
+		Payload:     bytes.NewReader(payload),
+	}}
+}
+
+func isTooLarge(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), errMsgTooLarge.Error())
+}
+
+// Tests that messages survive a binary frame round trip, compressed only if
+// requested and worth it.
+func TestFrameRoundTrip(t *testing.T) {
+	tests := []struct {
+		payload    []byte
+		compress   bool
+		compressed bool
+	}{
+		{payload: []byte{}, compress: false},
+		{payload: []byte("short payload"), compress: true},
+		{payload: bytes.Repeat([]byte("vnt"), 1000), compress: false},
+		{payload: bytes.Repeat([]byte("vnt"), 1000), compress: true, compressed: true},
+	}
+	limit := func(string) uint32 { return DefaultMaxMsgSize }
+	for i, tt := range tests {
+		var buf bytes.Buffer
+		if err := writeFrame(&buf, testFrameMsg("vnt", 7, tt.payload), tt.compress); err != nil {
+			t.Fatalf("test %d: failed to write frame: %v", i, err)
+		}
+		if compressed := buf.Bytes()[1]&frameFlagSnappy != 0; compressed != tt.compressed {
+			t.Errorf("test %d: compression mismatch: have %v, want %v", i, compressed, tt.compressed)
+		}
+		msg, err := readFrame(bufio.NewReader(&buf), limit)
+		if err != nil {
+			t.Fatalf("test %d: failed to read frame: %v", i, err)
+		}
+		if msg.Body.ProtocolID != "vnt" || msg.Body.Type != 7 {
+			t.Errorf("test %d: header mismatch: have %s/%d, want vnt/7", i, msg.Body.ProtocolID, msg.Body.Type)
+		}
+		payload, _ := ioutil.ReadAll(msg.Body.Payload)
+		if !bytes.Equal(payload, tt.payload) || msg.Body.PayloadSize != uint32(len(tt.payload)) {
+			t.Errorf("test %d: payload mismatch: have %d bytes, want %d", i, len(payload), len(tt.payload))
+		}
+		if size := binary.LittleEndian.Uint32(msg.Header[:]); size != uint32(len(tt.payload)) {
+			t.Errorf("test %d: header size mismatch: have %d, want %d", i, size, len(tt.payload))
+		}
+	}
+}
+
+// Tests that frames past the limit of their protocol are rejected from their
+// length prefix, without reading the payload.
+func TestFrameOversize(t *testing.T) {
+	frame := []byte{frameVersion, 0}
+	frame = appendUvarint(frame, 3)
+	frame = append(frame, "vnt"...)
+	frame = appendUvarint(frame, 1)
+	frame = appendUvarint(frame, 1<<40)
+
+	var protocol string
+	limit := func(name string) uint32 {
+		protocol = name
+		return 1024
+	}
+	if _, err := readFrame(bufio.NewReader(bytes.NewReader(frame)), limit); !isTooLarge(err) {
+		t.Errorf("oversize frame error mismatch: have %v, want %v", err, errMsgTooLarge)
+	}
+	if protocol != "vnt" {
+		t.Errorf("limit protocol mismatch: have %q, want vnt", protocol)
+	}
+	// Frames within the limit but truncated aren't mistaken for oversize ones
+	frame = []byte{frameVersion, 0}
+	frame = appendUvarint(frame, 3)
+	frame = append(frame, "vnt"...)
+	frame = appendUvarint(frame, 1)
+	frame = appendUvarint(frame, 512)
+	if _, err := readFrame(bufio.NewReader(bytes.NewReader(frame)), limit); err == nil || isTooLarge(err) {
+		t.Errorf("truncated frame error mismatch: have %v", err)
+	}
+	// Overlong protocol names and unknown versions
+	frame = []byte{frameVersion, 0}
+	frame = appendUvarint(frame, maxProtocolNameLength+1)
+	if _, err := readFrame(bufio.NewReader(bytes.NewReader(frame)), limit); err == nil {
+		t.Errorf("overlong protocol name accepted")
+	}
+	if _, err := readFrame(bufio.NewReader(bytes.NewReader([]byte{frameVersion + 1, 0})), limit); err != errFrameVersion {
+		t.Errorf("version error mismatch: have %v, want %v", err, errFrameVersion)
+	}
+}
+
+// Tests that compressed frames are rejected if their snappy header declares a
+// decoded size past the limit, before decompressing them.
+func TestFrameSnappyOversize(t *testing.T) {
+	var max uint32 = 1024
+
+	// A genuine compressed payload which inflates past the limit
+	payload := snappy.Encode(nil, bytes.Repeat([]byte{0}, int(max)+1))
+	frame := []byte{frameVersion, frameFlagSnappy}
+	frame = appendUvarint(frame, 3)
+	frame = append(frame, "vnt"...)
+	frame = appendUvarint(frame, 1)
+	frame = appendUvarint(frame, uint64(len(payload)))
+	frame = append(frame, payload...)
+
+	limit := func(string) uint32 { return max }
+	if _, err := readFrame(bufio.NewReader(bytes.NewReader(frame)), limit); !isTooLarge(err) {
+		t.Errorf("inflating frame error mismatch: have %v, want %v", err, errMsgTooLarge)
+	}
+	// A bare snappy header declaring a huge size
+	payload = appendUvarint(nil, 1<<31)
+	frame = []byte{frameVersion, frameFlagSnappy}
+	frame = appendUvarint(frame, 3)
+	frame = append(frame, "vnt"...)
+	frame = appendUvarint(frame, 1)
+	frame = appendUvarint(frame, uint64(len(payload)))
+	frame = append(frame, payload...)
+	if _, err := readFrame(bufio.NewReader(bytes.NewReader(frame)), limit); !isTooLarge(err) {
+		t.Errorf("declared size error mismatch: have %v, want %v", err, errMsgTooLarge)
+	}
+}
+
+// legacyFrame encodes a message in the legacy json framing.
+func legacyFrame(t *testing.T, body MsgBody) []byte {
+	blob, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("failed to encode legacy frame: %v", err)
+	}
+	frame := make([]byte, MessageHeaderLength)
+	binary.LittleEndian.PutUint32(frame, uint32(len(blob)))
+	return append(frame, blob...)
+}
+
+// Tests that legacy frames are read back, and rejected past their size limits.
+func TestLegacyFrame(t *testing.T) {
+	var max uint32 = 1024
+
+	size, r, err := rlp.EncodeToReader([]uint64{1, 2, 3})
+	if err != nil {
+		t.Fatalf("failed to encode payload: %v", err)
+	}
+	frame := legacyFrame(t, MsgBody{ProtocolID: "vnt", Type: 3, PayloadSize: uint32(size), Payload: r})
+	msg, err := readLegacyFrame(bytes.NewReader(frame), max)
+	if err != nil {
+		t.Fatalf("failed to read legacy frame: %v", err)
+	}
+	var decoded []uint64
+	if err := msg.Decode(&decoded); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if msg.Body.ProtocolID != "vnt" || msg.Body.Type != 3 || len(decoded) != 3 || decoded[2] != 3 {
+		t.Errorf("legacy message mismatch: %s/%d %v", msg.Body.ProtocolID, msg.Body.Type, decoded)
+	}
+	// Length prefixes past the json overhead of the limit
+	var header [MessageHeaderLength]byte
+	binary.LittleEndian.PutUint32(header[:], 2*max+64*1024+1)
+	if _, err := readLegacyFrame(bytes.NewReader(header[:]), max); !isTooLarge(err) {
+		t.Errorf("oversize length prefix error mismatch: have %v, want %v", err, errMsgTooLarge)
+	}
+	// Declared payloads past the limit
+	_, r, _ = rlp.EncodeToReader([]uint64{1})
+	frame = legacyFrame(t, MsgBody{ProtocolID: "vnt", Type: 3, PayloadSize: max + 1, Payload: r})
+	if _, err := readLegacyFrame(bytes.NewReader(frame), max); !isTooLarge(err) {
+		t.Errorf("oversize payload error mismatch: have %v, want %v", err, errMsgTooLarge)
+	}
+}
@@ -0,0 +1,346 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntp2p
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	inet "github.com/libp2p/go-libp2p-net"
+	protocol "github.com/libp2p/go-libp2p-protocol"
+	"github.com/vntchain/go-vnt/log"
+	"github.com/vntchain/go-vnt/rlp"
+)
+
+// Peers supporting HandshakePID exchange their capabilities on a control stream
+// and run each matched protocol on a stream of its own, e.g. /vnt/63. Every
+// stream is flow controlled by the muxer independently, so a slow protocol
+// can't hold up the messages of another one. Peers which don't support it fall
+// back to a single stream shared by all protocols.
+const (
+	// HandshakePID is the control protocol negotiating the capabilities
+	HandshakePID = "/p2p/handshake/1.0.0"
+
+	baseProtocolVersion = 1
+	handshakeMsg        = 0x00
+
+	handshakeTimeout = 5 * time.Second
+	maxHandshakeSize = 64 * 1024
+)
+
+// Cap is the structure of a peer capability.
+type Cap struct {
+	Name    string
+	Version uint
+}
+
+func (cap Cap) String() string {
+	return fmt.Sprintf("%s/%d", cap.Name, cap.Version)
+}
+
+type capsByNameAndVersion []Cap
+
+func (cs capsByNameAndVersion) Len() int      { return len(cs) }
+func (cs capsByNameAndVersion) Swap(i, j int) { cs[i], cs[j] = cs[j], cs[i] }
+func (cs capsByNameAndVersion) Less(i, j int) bool {
+	return cs[i].Name < cs[j].Name || (cs[i].Name == cs[j].Name && cs[i].Version < cs[j].Version)
+}
+
+// protoHandshake is the message exchanged on the control stream.
+type protoHandshake struct {
+	Version uint64
+	Name    string
+	Caps    []Cap
+
+	// Ignore additional fields (for forward compatibility).
+	Rest []rlp.RawValue `rlp:"tail"`
+}
+
+// protocolID returns the libp2p protocol the sub-protocol runs on.
+func protocolID(p Protocol) protocol.ID {
+	return protocol.ID(fmt.Sprintf("/%s/%d", p.Name, p.Version))
+}
+
+// parseProtocolID is the inverse of protocolID.
+func parseProtocolID(id protocol.ID) (string, uint, error) {
+	parts := strings.Split(string(id), "/")
+	if len(parts) != 3 || parts[0] != "" {
+		return "", 0, fmt.Errorf("invalid protocol id %s", id)
+	}
+	version, err := strconv.ParseUint(parts[2], 10, 32)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid protocol id %s", id)
+	}
+	return parts[1], uint(version), nil
+}
+
+func (server *Server) ourHandshake() *protoHandshake {
+	hs := &protoHandshake{Version: baseProtocolVersion, Name: server.Name}
+	for _, p := range server.Protocols {
+		hs.Caps = append(hs.Caps, Cap{p.Name, p.Version})
+	}
+	return hs
+}
+
+// matchProtocols returns the highest version of each protocol supported by both
+// sides.
+func matchProtocols(protocols []Protocol, caps []Cap) []Protocol {
+	best := make(map[string]Protocol)
+	for _, cap := range caps {
+		for _, proto := range protocols {
+			if proto.Name != cap.Name || proto.Version != cap.Version {
+				continue
+			}
+			if old, ok := best[proto.Name]; !ok || old.Version < proto.Version {
+				best[proto.Name] = proto
+			}
+		}
+	}
+	matched := make([]Protocol, 0, len(best))
+	for _, proto := range best {
+		matched = append(matched, proto)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Name < matched[j].Name })
+	return matched
+}
+
+// frameWriter writes messages as binary frames.
+type frameWriter struct {
+	w io.Writer
+}
+
+func (fw frameWriter) WriteMsg(msg Msg) error {
+	return writeFrame(fw.w, msg, false)
+}
+
+func writeHandshake(s inet.Stream, hs *protoHandshake) error {
+	s.SetWriteDeadline(time.Now().Add(handshakeTimeout))
+	defer s.SetWriteDeadline(time.Time{})
+	return Send(frameWriter{s}, "p2p", handshakeMsg, hs)
+}
+
+func readHandshake(s inet.Stream, r *bufio.Reader) (*protoHandshake, error) {
+	s.SetReadDeadline(time.Now().Add(handshakeTimeout))
+	defer s.SetReadDeadline(time.Time{})
+
+	msg, err := readFrame(r, func(string) uint32 { return maxHandshakeSize })
+	if err != nil {
+		return nil, err
+	}
+	if msg.Body.Type != handshakeMsg {
+		return nil, newPeerError(errInvalidMsgCode, "expected handshake, got %x", msg.Body.Type)
+	}
+	var hs protoHandshake
+	if err := msg.Decode(&hs); err != nil {
+		return nil, newPeerError(errInvalidMsg, "(code %x) (size %d) %v", msg.Body.Type, msg.Body.PayloadSize, err)
+	}
+	return &hs, nil
+}
+
+// handleHandshakeStream answers the handshake of a dialing peer. The peer is
+// registered before replying, so that it is known once the remote side opens
+// the protocol streams.
+func (server *Server) handleHandshakeStream(s inet.Stream) {
+	if !server.permitted(s.Conn().RemotePeer()) {
+		log.Debug("Rejected peer", "peer", s.Conn().RemotePeer(), "err", errNotAllowed)
+		s.Reset()
+		return
+	}
+	// Limit the number of inbound handshakes in progress
+	select {
+	case server.pending <- struct{}{}:
+	default:
+		log.Debug("Rejected peer", "peer", s.Conn().RemotePeer(), "err", "too many pending peers")
+		s.Reset()
+		return
+	}
+	r := bufio.NewReader(s)
+	their, err := readHandshake(s, r)
+	if err != nil {
+		<-server.pending
+		log.Debug("Failed to read handshake", "peer", s.Conn().RemotePeer(), "err", err)
+		s.Reset()
+		return
+	}
+	p, err := server.addMultiplexedPeer(s, their, inboundDail)
+	<-server.pending
+	if err != nil {
+		log.Debug("Rejected peer", "peer", s.Conn().RemotePeer(), "err", err)
+		s.Reset()
+		return
+	}
+	if err := writeHandshake(s, server.ourHandshake()); err != nil {
+		p.fail(err)
+		return
+	}
+	p.readControl(r)
+}
+
+// setupProtocols performs the handshake on the control stream opened by us and
+// opens a stream for each matched protocol.
+func (server *Server) setupProtocols(ctx context.Context, s inet.Stream, flags dialFlag) error {
+	r := bufio.NewReader(s)
+	if err := writeHandshake(s, server.ourHandshake()); err != nil {
+		s.Reset()
+		return err
+	}
+	their, err := readHandshake(s, r)
+	if err != nil {
+		s.Reset()
+		return err
+	}
+	p, err := server.addMultiplexedPeer(s, their, flags)
+	if err != nil {
+		s.Reset()
+		return err
+	}
+	for _, m := range p.messenger {
+		ps, err := server.host.NewStream(ctx, p.RemoteID(), protocolID(m.protocol))
+		if err != nil {
+			p.fail(err)
+			return err
+		}
+		if err := p.attach(m.protocol.Name, m.protocol.Version, ps); err != nil {
+			ps.Reset()
+			p.fail(err)
+			return err
+		}
+	}
+	go p.readControl(r)
+	return nil
+}
+
+// addMultiplexedPeer registers the peer of a control stream with the protocols
+// supported by both sides.
+func (server *Server) addMultiplexedPeer(s inet.Stream, their *protoHandshake, flags dialFlag) (*Peer, error) {
+	matched := matchProtocols(server.Protocols, their.Caps)
+	if len(matched) == 0 {
+		return nil, DiscUselessPeer
+	}
+	if err := server.dispatch(&Stream{Conn: s, Protocols: matched, flags: flags}, server.addpeer); err != nil {
+		return nil, err
+	}
+	// The peer may already be connected through another stream
+	p := server.peer(s.Conn().RemotePeer())
+	if p == nil || p.rw != s {
+		return nil, DiscAlreadyConnected
+	}
+	p.caps = their.Caps
+	return p, nil
+}
+
+// handleProtocolStream attaches a protocol stream opened by the remote side to
+// its peer.
+func (server *Server) handleProtocolStream(s inet.Stream) {
+	name, version, err := parseProtocolID(s.Protocol())
+	if err == nil {
+		p := server.peer(s.Conn().RemotePeer())
+		if p == nil {
+			err = fmt.Errorf("unknown peer %s", s.Conn().RemotePeer())
+		} else {
+			err = p.attach(name, version, s)
+		}
+	}
+	if err != nil {
+		log.Debug("Rejected protocol stream", "protocol", s.Protocol(), "err", err)
+		s.Reset()
+	}
+}
+
+// attach runs the protocol on the stream, which must be the only one of the
+// protocol.
+func (p *Peer) attach(name string, version uint, s inet.Stream) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	m, ok := p.messenger[name]
+	if !ok || m.protocol.Version != version {
+		return fmt.Errorf("protocol %s/%d not negotiated", name, version)
+	}
+	if m.w != nil {
+		return fmt.Errorf("protocol %s/%d already running", name, version)
+	}
+	select {
+	case <-p.closed:
+		return DiscQuitting
+	default:
+	}
+	m.w = s
+	p.streams = append(p.streams, s)
+	p.startProtocol(m)
+	go p.readProtocol(m, s)
+	return nil
+}
+
+// readProtocol delivers the messages of a protocol stream to its messenger.
+func (p *Peer) readProtocol(m *VNTMessenger, s inet.Stream) {
+	r := bufio.NewReader(s)
+	for {
+		msg, err := readFrame(r, func(string) uint32 { return m.protocol.maxMsgSize() })
+		if err == nil && msg.Body.ProtocolID != m.protocol.Name {
+			err = newPeerError(errInvalidMsg, "%s message on %s stream", msg.Body.ProtocolID, m.protocol.Name)
+		}
+		if err != nil {
+			select {
+			case m.err <- err:
+			case <-p.closed:
+			}
+			return
+		}
+		m.received(msg)
+		ok, err := m.limit(msg)
+		if err != nil {
+			select {
+			case m.err <- err:
+			case <-p.closed:
+			}
+			return
+		}
+		if !ok {
+			continue
+		}
+		select {
+		case m.in <- msg:
+		case <-p.closed:
+			return
+		}
+	}
+}
+
+// readControl waits for the remote side to close the control stream.
+func (p *Peer) readControl(r io.Reader) {
+	_, err := io.Copy(ioutil.Discard, r)
+	if err == nil {
+		err = io.EOF
+	}
+	p.fail(err)
+}
+
+// fail terminates the peer with the error.
+func (p *Peer) fail(err error) {
+	select {
+	case p.err <- err:
+	case <-p.closed:
+	}
+}
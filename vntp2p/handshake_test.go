@@ -0,0 +1,302 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntp2p
+
+import (
+	"bufio"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	inet "github.com/libp2p/go-libp2p-net"
+	libp2p "github.com/libp2p/go-libp2p-peer"
+	protocol "github.com/libp2p/go-libp2p-protocol"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// testConn is the connection of a test stream, it only knows the remote peer.
+type testConn struct {
+	inet.Conn
+	remote libp2p.ID
+	addr   ma.Multiaddr
+}
+
+func (c *testConn) RemotePeer() libp2p.ID         { return c.remote }
+func (c *testConn) RemoteMultiaddr() ma.Multiaddr { return c.addr }
+
+// testStream is a stream over an in-memory pipe which records whether it was
+// reset.
+type testStream struct {
+	pipe     net.Conn
+	protocol protocol.ID
+	conn     *testConn
+
+	once  sync.Once
+	reset chan struct{}
+}
+
+func newTestStream(c net.Conn, proto protocol.ID, remote libp2p.ID) *testStream {
+	return &testStream{pipe: c, protocol: proto, conn: &testConn{remote: remote}, reset: make(chan struct{})}
+}
+
+func (s *testStream) Read(b []byte) (int, error)         { return s.pipe.Read(b) }
+func (s *testStream) Write(b []byte) (int, error)        { return s.pipe.Write(b) }
+func (s *testStream) Close() error                       { return s.pipe.Close() }
+func (s *testStream) SetDeadline(t time.Time) error      { return s.pipe.SetDeadline(t) }
+func (s *testStream) SetReadDeadline(t time.Time) error  { return s.pipe.SetReadDeadline(t) }
+func (s *testStream) SetWriteDeadline(t time.Time) error { return s.pipe.SetWriteDeadline(t) }
+func (s *testStream) Protocol() protocol.ID              { return s.protocol }
+func (s *testStream) SetProtocol(id protocol.ID)         { s.protocol = id }
+func (s *testStream) Conn() inet.Conn                    { return s.conn }
+
+func (s *testStream) Reset() error {
+	s.once.Do(func() { close(s.reset) })
+	return s.pipe.Close()
+}
+
+// wasReset reports whether the stream is reset within a short while.
+func (s *testStream) wasReset() bool {
+	select {
+	case <-s.reset:
+		return true
+	case <-time.After(time.Second):
+		return false
+	}
+}
+
+// newHandshakeTestServer creates a server with a minimal run loop, which adds
+// every dispatched peer without starting it.
+func newHandshakeTestServer(protocols []Protocol) *Server {
+	server := &Server{
+		Config:     Config{Name: "test", Protocols: protocols},
+		quit:       make(chan struct{}),
+		addpeer:    make(chan *Stream),
+		peerOp:     make(chan peerOpFunc),
+		peerOpDone: make(chan struct{}),
+		pending:    make(chan struct{}, 1),
+	}
+	go func() {
+		peers := make(map[libp2p.ID]*Peer)
+		for {
+			select {
+			case s := <-server.addpeer:
+				id := s.Conn.Conn().RemotePeer()
+				if _, ok := peers[id]; !ok {
+					p := newPeer(s, false)
+					p.server = server
+					peers[id] = p
+				}
+				s.cont <- nil
+			case op := <-server.peerOp:
+				op(peers)
+				server.peerOpDone <- struct{}{}
+			case <-server.quit:
+				return
+			}
+		}
+	}()
+	return server
+}
+
+// testProtocols returns protocols which block until the peer is closed, and
+// report on started whenever one is run.
+func testProtocols(started chan<- string, caps ...Cap) []Protocol {
+	var protocols []Protocol
+	for _, cap := range caps {
+		name := cap.Name
+		protocols = append(protocols, Protocol{
+			Name:    cap.Name,
+			Version: cap.Version,
+			Length:  1,
+			Run: func(peer *Peer, rw MsgReadWriter) error {
+				started <- name
+				<-peer.closed
+				return nil
+			},
+		})
+	}
+	return protocols
+}
+
+func TestProtocolID(t *testing.T) {
+	tests := []struct {
+		proto Protocol
+		id    protocol.ID
+	}{
+		{proto: Protocol{Name: "vnt", Version: 63}, id: "/vnt/63"},
+		{proto: Protocol{Name: "les", Version: 2}, id: "/les/2"},
+		{proto: Protocol{Name: "bft", Version: 0}, id: "/bft/0"},
+	}
+	for _, tt := range tests {
+		id := protocolID(tt.proto)
+		if id != tt.id {
+			t.Errorf("%s: protocol id mismatch: have %s, want %s", tt.proto.Name, id, tt.id)
+		}
+		name, version, err := parseProtocolID(id)
+		if err != nil || name != tt.proto.Name || version != tt.proto.Version {
+			t.Errorf("%s: parsed protocol mismatch: have %s/%d (%v), want %s/%d", id, name, version, err, tt.proto.Name, tt.proto.Version)
+		}
+	}
+	for _, id := range []protocol.ID{"", "vnt/63", "/vnt", "/vnt/", "/vnt/x", "/vnt/-1", "/vnt/63/1", HandshakePID, PID} {
+		if name, version, err := parseProtocolID(id); err == nil {
+			t.Errorf("%s: invalid protocol id parsed as %s/%d", id, name, version)
+		}
+	}
+}
+
+// Tests that the handshake negotiates the highest common version of each
+// protocol, and that the protocol streams are attached to the negotiated
+// protocols only.
+func TestHandshakeStream(t *testing.T) {
+	started := make(chan string, 4)
+	server := newHandshakeTestServer(testProtocols(started, Cap{"vnt", 62}, Cap{"vnt", 63}, Cap{"les", 2}))
+	defer close(server.quit)
+
+	local, remote := net.Pipe()
+	defer remote.Close()
+	s := newTestStream(local, HandshakePID, "remote")
+	go server.handleHandshakeStream(s)
+
+	ours := &protoHandshake{Version: baseProtocolVersion, Name: "remote", Caps: []Cap{{"vnt", 62}, {"vnt", 63}, {"bft", 1}}}
+	rs := newTestStream(remote, HandshakePID, "test")
+	if err := writeHandshake(rs, ours); err != nil {
+		t.Fatalf("failed to write handshake: %v", err)
+	}
+	their, err := readHandshake(rs, bufio.NewReader(rs))
+	if err != nil {
+		t.Fatalf("failed to read handshake: %v", err)
+	}
+	if want := server.ourHandshake(); !reflect.DeepEqual(their.Caps, want.Caps) || their.Name != "test" {
+		t.Errorf("handshake mismatch: have %s %v, want %s %v", their.Name, their.Caps, want.Name, want.Caps)
+	}
+	p := server.peer("remote")
+	if p == nil {
+		t.Fatalf("peer not registered")
+	}
+	if !reflect.DeepEqual(p.caps, ours.Caps) {
+		t.Errorf("peer caps mismatch: have %v, want %v", p.caps, ours.Caps)
+	}
+	if len(p.messenger) != 1 || p.messenger["vnt"] == nil || p.messenger["vnt"].protocol.Version != 63 {
+		t.Fatalf("negotiated protocols mismatch: have %v", p.messenger)
+	}
+	// Protocol streams of other versions or protocols are rejected
+	for _, id := range []protocol.ID{"/vnt/62", "/les/2", "/bft/1", "/vnt"} {
+		local, remote := net.Pipe()
+		defer remote.Close()
+		ps := newTestStream(local, id, "remote")
+		server.handleProtocolStream(ps)
+		if !ps.wasReset() {
+			t.Errorf("%s: stream of unnegotiated protocol accepted", id)
+		}
+	}
+	// The negotiated protocol is run once on its stream
+	local, remote = net.Pipe()
+	defer remote.Close()
+	ps := newTestStream(local, "/vnt/63", "remote")
+	server.handleProtocolStream(ps)
+	select {
+	case name := <-started:
+		if name != "vnt" {
+			t.Errorf("started protocol mismatch: have %s, want vnt", name)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("negotiated protocol not started")
+	}
+	if p.messenger["vnt"].w != ps {
+		t.Errorf("protocol stream not attached")
+	}
+	local, remote = net.Pipe()
+	defer remote.Close()
+	dup := newTestStream(local, "/vnt/63", "remote")
+	server.handleProtocolStream(dup)
+	if !dup.wasReset() {
+		t.Errorf("second stream of running protocol accepted")
+	}
+	select {
+	case name := <-started:
+		t.Errorf("protocol %s started twice", name)
+	default:
+	}
+	close(p.closed)
+}
+
+// Tests that handshakes without any common protocol version, or which are not
+// handshakes at all, reset the control stream without registering the peer.
+func TestHandshakeStreamMismatch(t *testing.T) {
+	tests := []struct {
+		name string
+		code MessageType
+		caps []Cap
+	}{
+		{name: "version", code: handshakeMsg, caps: []Cap{{"vnt", 61}, {"vnt", 64}}},
+		{name: "caps", code: handshakeMsg, caps: []Cap{{"les", 63}, {"bft", 1}}},
+		{name: "empty", code: handshakeMsg},
+		{name: "code", code: handshakeMsg + 1, caps: []Cap{{"vnt", 63}}},
+	}
+	started := make(chan string, 1)
+	server := newHandshakeTestServer(testProtocols(started, Cap{"vnt", 63}))
+	defer close(server.quit)
+
+	for _, tt := range tests {
+		local, remote := net.Pipe()
+		s := newTestStream(local, HandshakePID, "remote")
+		go server.handleHandshakeStream(s)
+
+		rs := newTestStream(remote, HandshakePID, "test")
+		hs := &protoHandshake{Version: baseProtocolVersion, Name: "remote", Caps: tt.caps}
+		if err := Send(frameWriter{rs}, "p2p", tt.code, hs); err != nil {
+			t.Fatalf("%s: failed to write handshake: %v", tt.name, err)
+		}
+		if !s.wasReset() {
+			t.Errorf("%s: mismatching handshake accepted", tt.name)
+		}
+		if _, err := readHandshake(rs, bufio.NewReader(rs)); err == nil {
+			t.Errorf("%s: handshake answered", tt.name)
+		}
+		remote.Close()
+
+		if p := server.peer("remote"); p != nil {
+			t.Errorf("%s: mismatching peer registered", tt.name)
+		}
+		if len(server.pending) != 0 {
+			t.Errorf("%s: pending handshake slot not released", tt.name)
+		}
+	}
+}
+
+// Tests that protocol streams opened before the handshake registered their
+// peer are rejected.
+func TestProtocolStreamBeforeHandshake(t *testing.T) {
+	started := make(chan string, 1)
+	server := newHandshakeTestServer(testProtocols(started, Cap{"vnt", 63}))
+	defer close(server.quit)
+
+	local, remote := net.Pipe()
+	defer remote.Close()
+	s := newTestStream(local, "/vnt/63", "remote")
+	server.handleProtocolStream(s)
+	if !s.wasReset() {
+		t.Fatalf("stream of unknown peer accepted")
+	}
+	select {
+	case <-started:
+		t.Errorf("protocol started without handshake")
+	default:
+	}
+}
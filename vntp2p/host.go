@@ -2,7 +2,6 @@ package vntp2p
 
 import (
 	"context"
-	"encoding/hex"
 	"encoding/json"
 	"path/filepath"
 	"time"
@@ -13,7 +12,6 @@ import (
 
 	"crypto/ecdsa"
 
-	"github.com/vntchain/go-vnt/crypto"
 	ds "github.com/ipfs/go-datastore"
 	libp2p "github.com/libp2p/go-libp2p"
 	pstore "github.com/libp2p/go-libp2p-peerstore"
@@ -40,21 +38,34 @@ import (
 
 const (
 	// PID vnt protocol basic id
-	PID                 = "/p2p/1.0.0"
+	PID = "/p2p/1.0.0"
+	// FramedPID vnt protocol id using the binary message framing
+	FramedPID           = "/p2p/2.0.0"
 	persistDataInterval = 10 * time.Second
+
+	// datastoreDir is the directory of the dht datastore within the datadir
+	datastoreDir = "vntdb"
 )
 
 const BootnodeCon = 1
 
+// DatastorePath returns the path of the dht datastore within datadir.
+func DatastorePath(datadir string) string {
+	return filepath.Join(datadir, datastoreDir)
+}
+
 type blankValidator struct{}
 
 func (blankValidator) Validate(_ string, _ []byte) error        { return nil }
 func (blankValidator) Select(_ string, _ [][]byte) (int, error) { return 0, nil }
 
-func recoverPersistentData(vdb *LevelDB) *dht.PersistentData {
+// persistentDataKey is the datastore key of the dht.PersistentData, the dht
+// stores its records base32 encoded.
+var persistentDataKey = ds.NewKey(base32.RawStdEncoding.EncodeToString([]byte("/PersistentData")))
+
+func recoverPersistentData(vdb *Datastore) *dht.PersistentData {
 	pd := &dht.PersistentData{}
-	pdKey := ds.NewKey(base32.RawStdEncoding.EncodeToString([]byte("/PersistentData")))
-	pdValue, err := vdb.Get(pdKey)
+	pdValue, err := vdb.Get(persistentDataKey)
 	if err != nil {
 		// don't need to care about err != nil
 		return nil
@@ -68,39 +79,40 @@ func recoverPersistentData(vdb *LevelDB) *dht.PersistentData {
 	return pd
 }
 
-// ConstructDHT create Kademlia DHT
-func ConstructDHT(ctx context.Context, listenstring string, nodekey *ecdsa.PrivateKey, datadir string, restrictList []*net.IPNet, natm libp2p.Option) (*dht.IpfsDHT, p2phost.Host, error) {
+// savePersistentData overwrites the persisted data.
+func savePersistentData(vdb *Datastore, pd *dht.PersistentData) error {
+	pdByte, err := json.Marshal(pd)
+	if err != nil {
+		return err
+	}
+	return vdb.Put(persistentDataKey, pdByte)
+}
+
+// ConstructDHT create Kademlia DHT. The host runs with nodekey as its identity,
+// or with a random one if it's nil. Keys persisted by older versions are not
+// recovered, callers have to move them out with MigrateNodeKey first. The
+// datastore in datadir is kept by the given database engine. Extra options are
+// passed on to the host, e.g. to replace its transports.
+func ConstructDHT(ctx context.Context, listenstring string, nodekey *ecdsa.PrivateKey, datadir, engine string, restrictList []*net.IPNet, natm libp2p.Option, opts ...libp2p.Option) (*dht.IpfsDHT, p2phost.Host, error) {
 
 	var pd *dht.PersistentData
-	var vntp2pDB *LevelDB
+	var vntp2pDB *Datastore
 	var err error
 	// if datadir is empty, it means don't need persistentation
 	if datadir != "" {
-		dbpath := filepath.Join(datadir, "vntdb")
-		vntp2pDB, err = GetDatastore(dbpath)
+		dbpath := DatastorePath(datadir)
+		vntp2pDB, err = GetDatastore(dbpath, engine)
 		if err != nil {
 			log.Error("ConstructDHT", "getDatastore error", err, "dbpath", dbpath)
 			return nil, nil, err
 		}
 		pd = recoverPersistentData(vntp2pDB)
 	}
-	if nodekey == nil && pd != nil {
-		// try to recover nodekey from database
-		k := string(pd.PrivKey)
-		bDump, err := hex.DecodeString(k)
-		if err != nil {
-			log.Error("ConstructDHT", "decode key error", err)
-			return nil, nil, err
-		}
-		privKey, err := crypto.ToECDSA(bDump)
-		if err != nil {
-			log.Error("ConstructDHT", "toECDSA error", err)
-			return nil, nil, err
-		}
-		nodekey = privKey
-	} // host private key recover finished
+	if pd != nil && len(pd.PrivKey) > 0 {
+		log.Warn("Node key found in the datastore, it is not used and will be dropped")
+	}
 
-	host, err := constructPeerHost(ctx, listenstring, nodekey, restrictList, natm)
+	host, err := constructPeerHost(ctx, listenstring, nodekey, restrictList, natm, opts...)
 	if err != nil {
 		log.Error("ConstructDHT", "constructPeerHost error", err)
 		return nil, nil, err
@@ -152,6 +164,8 @@ func loop(vdht *dht.IpfsDHT) {
 // persist data unified entrance, both for bootnode and membernode
 func persistDataPeriodly(vdht *dht.IpfsDHT) {
 	pd := vdht.GetPersistentData()
+	// The node key is stored on its own, never in the datastore
+	pd.PrivKey = nil
 	/* fmt.Printf("host privKey is: %v \n", string(pd.PrivKey))
 	fmt.Printf("peerInfos is: \n")
 	for i := range pd.PeerInfos {
@@ -190,7 +204,7 @@ func recoverPeerInfosAndBuckets(ctx context.Context, pd *dht.PersistentData, hos
 	}
 }
 
-func constructPeerHost(ctx context.Context, listenstring string, nodekey *ecdsa.PrivateKey, restrictList []*net.IPNet, natm libp2p.Option) (p2phost.Host, error) {
+func constructPeerHost(ctx context.Context, listenstring string, nodekey *ecdsa.PrivateKey, restrictList []*net.IPNet, natm libp2p.Option, opts ...libp2p.Option) (p2phost.Host, error) {
 	var options []libp2p.Option
 	if nodekey != nil {
 		options = append(options, libp2p.ListenAddrStrings(listenstring), libp2p.Identity(nodekey))
@@ -202,6 +216,7 @@ func constructPeerHost(ctx context.Context, listenstring string, nodekey *ecdsa.
 	if natm != nil {
 		options = append(options, natm)
 	}
+	options = append(options, opts...)
 
 	return libp2p.New(ctx, options...)
 }
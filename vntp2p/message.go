@@ -48,7 +48,7 @@ type Msg struct {
 	Body   MsgBody
 }
 
-// MsgHeader store the size of MsgBody
+// MsgHeader store the size of the message payload
 type MsgHeader [MessageHeaderLength]byte
 
 // MsgBody message body
@@ -104,17 +104,10 @@ func Send(w MsgWriter, protocolID string, msgType MessageType, data interface{})
 		PayloadSize: uint32(size),
 		Payload:     r,
 	}
-	msgBodyByte, err := json.Marshal(msgBody)
-	if err != nil {
-		log.Error("Send()", "marshal msgBody error", err)
-		return err
-	}
-	msgBodySize := len(msgBodyByte)
-	msgHeaderByte := make([]byte, MessageHeaderLength)
-	binary.LittleEndian.PutUint32(msgHeaderByte, uint32(msgBodySize))
-
+	// The header carries the payload size, the wire framing is left to the
+	// messenger
 	var msgHeader MsgHeader
-	copy(msgHeader[:], msgHeaderByte)
+	binary.LittleEndian.PutUint32(msgHeader[:], uint32(size))
 
 	msg := Msg{
 		Header: msgHeader,
@@ -152,21 +145,44 @@ type VNTMessenger struct {
 	protocol Protocol
 	in       chan Msg
 	err      chan error
+	closed   <-chan struct{}
 	w        inet.Stream
+	framed   bool // Whether the stream uses the binary framing
+	compress bool // Whether binary frames may be compressed
+	limiter  *msgLimiter
+	traffic  *trafficCounter
+	peer     *Peer
 }
 
 // WriteMsg implement MsgReadWriter interface
 func (rw *VNTMessenger) WriteMsg(msg Msg) (err error) {
+	defer func() {
+		if err == nil {
+			rw.traffic.countOut(msg.Body.PayloadSize)
+			rw.sendEvent(PeerEventTypeMsgSend, msg)
+		}
+	}()
 	//if uint64(msg.Body.Type) >= rw.Length {
 	//	return newPeerError(errInvalidMsgCode, "not handled")
 	//}
 	// 暂时先不管主动关闭需要告知对方的情况，目前聚焦于发送消息这件基本工作
-	msgHeaderByte := msg.Header[:]
+	if max := rw.protocol.maxMsgSize(); msg.Body.PayloadSize > max {
+		return fmt.Errorf("%v: %d > %d", errMsgTooLarge, msg.Body.PayloadSize, max)
+	}
+	if rw.framed {
+		if err := writeFrame(rw.w, msg, rw.compress); err != nil {
+			log.Error("WriteMsg()", "write frame error", err)
+			return err
+		}
+		return nil
+	}
 	msgBodyByte, err := json.Marshal(msg.Body)
 	if err != nil {
 		log.Error("WriteMsg()", "marshal msgbody error", err)
 		return err
 	}
+	msgHeaderByte := make([]byte, MessageHeaderLength)
+	binary.LittleEndian.PutUint32(msgHeaderByte, uint32(len(msgBodyByte)))
 	m := append(msgHeaderByte, msgBodyByte...)
 	//log.Info("yhx-test", "MESSAGE", string(m))
 
@@ -178,6 +194,50 @@ func (rw *VNTMessenger) WriteMsg(msg Msg) (err error) {
 	return nil
 }
 
+// received accounts a message read from the remote peer.
+func (rw *VNTMessenger) received(msg Msg) {
+	rw.traffic.countIn(msg.Body.PayloadSize)
+	rw.sendEvent(PeerEventTypeMsgRecv, msg)
+}
+
+// limit applies the rate limits to a message read from the remote peer. Messages
+// over the limits are counted and reported to the peer score, limit returns
+// false if the message must be dropped and DiscRateLimited if the peer keeps
+// exceeding the limits.
+func (rw *VNTMessenger) limit(msg Msg) (bool, error) {
+	if rw.limiter.allow(msg.Body.Type) {
+		return true, nil
+	}
+	rw.traffic.countDropped()
+	if rw.peer != nil {
+		log.Debug("Dropped message over rate limit", "peer", rw.peer.RemoteID(), "protocol", rw.protocol.Name, "code", msg.Body.Type)
+		rw.peer.Report(ScoreRateLimited)
+	}
+	if rw.limiter.drop() {
+		return false, DiscRateLimited
+	}
+	return false, nil
+}
+
+// sendEvent emits a message event if the server has message events enabled.
+func (rw *VNTMessenger) sendEvent(typ PeerEventType, msg Msg) {
+	if rw.peer == nil || rw.peer.events == nil {
+		return
+	}
+	var (
+		code = uint64(msg.Body.Type)
+		size = msg.Body.PayloadSize
+	)
+	rw.peer.events.Send(&PeerEvent{
+		Type:     typ,
+		Peer:     rw.peer.RemoteID(),
+		Time:     time.Now(),
+		Protocol: rw.protocol.Name,
+		MsgCode:  &code,
+		MsgSize:  &size,
+	})
+}
+
 // ReadMsg implement MsgReadWriter interface
 func (rw *VNTMessenger) ReadMsg() (Msg, error) {
 	select {
@@ -186,6 +246,8 @@ func (rw *VNTMessenger) ReadMsg() (Msg, error) {
 		return msg, nil
 	case err := <-rw.err:
 		return Msg{}, err
+	case <-rw.closed:
+		return Msg{}, DiscQuitting
 	}
 }
 
@@ -0,0 +1,131 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntp2p
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/vntchain/go-vnt/metrics"
+)
+
+// Traffic is accounted in message payload bytes, before compression, so that
+// the figures are the same for all framings.
+var (
+	peersGauge      = metrics.NewRegisteredGauge("p2p/peers", nil)
+	peerAddMeter    = metrics.NewRegisteredMeter("p2p/peers/add", nil)
+	peerDropMeter   = metrics.NewRegisteredMeter("p2p/peers/drop", nil)
+	peerRejectMeter = metrics.NewRegisteredMeter("p2p/peers/reject", nil)
+	peerBanMeter    = metrics.NewRegisteredMeter("p2p/peers/ban", nil)
+
+	ingressPacketsMeter = metrics.NewRegisteredMeter("p2p/in/packets", nil)
+	ingressTrafficMeter = metrics.NewRegisteredMeter("p2p/in/traffic", nil)
+	ingressDroppedMeter = metrics.NewRegisteredMeter("p2p/in/dropped", nil)
+	egressPacketsMeter  = metrics.NewRegisteredMeter("p2p/out/packets", nil)
+	egressTrafficMeter  = metrics.NewRegisteredMeter("p2p/out/traffic", nil)
+
+	pingTimer = metrics.NewRegisteredTimer("p2p/ping", nil)
+)
+
+// protocolMeters are the traffic meters of a protocol, registered as
+// p2p/<protocol>/{in,out}/{packets,traffic} and p2p/<protocol>/in/dropped.
+type protocolMeters struct {
+	inPackets, inTraffic   metrics.Meter
+	outPackets, outTraffic metrics.Meter
+	inDropped              metrics.Meter
+}
+
+var (
+	protocolMetersLock sync.Mutex
+	protocolMetersMap  = make(map[string]*protocolMeters)
+)
+
+// meters returns the traffic meters of the protocol.
+func meters(protocol string) *protocolMeters {
+	protocolMetersLock.Lock()
+	defer protocolMetersLock.Unlock()
+
+	m, ok := protocolMetersMap[protocol]
+	if !ok {
+		prefix := "p2p/" + protocol
+		m = &protocolMeters{
+			inPackets:  metrics.GetOrRegisterMeter(prefix+"/in/packets", nil),
+			inTraffic:  metrics.GetOrRegisterMeter(prefix+"/in/traffic", nil),
+			outPackets: metrics.GetOrRegisterMeter(prefix+"/out/packets", nil),
+			outTraffic: metrics.GetOrRegisterMeter(prefix+"/out/traffic", nil),
+			inDropped:  metrics.GetOrRegisterMeter(prefix+"/in/dropped", nil),
+		}
+		protocolMetersMap[protocol] = m
+	}
+	return m
+}
+
+// TrafficInfo represents the messages exchanged with a peer over a protocol.
+type TrafficInfo struct {
+	InMsgs   uint64 `json:"inMsgs"`   // Messages received
+	InBytes  uint64 `json:"inBytes"`  // Payload bytes received
+	OutMsgs  uint64 `json:"outMsgs"`  // Messages sent
+	OutBytes uint64 `json:"outBytes"` // Payload bytes sent
+	Dropped  uint64 `json:"dropped"`  // Messages dropped over the rate limits
+}
+
+// trafficCounter counts the messages of a protocol of a peer and feeds the
+// registry meters, it is updated atomically.
+type trafficCounter struct {
+	inMsgs, inBytes   uint64
+	outMsgs, outBytes uint64
+	dropped           uint64
+	meters            *protocolMeters
+}
+
+func newTrafficCounter(protocol string) *trafficCounter {
+	return &trafficCounter{meters: meters(protocol)}
+}
+
+func (c *trafficCounter) countIn(size uint32) {
+	atomic.AddUint64(&c.inMsgs, 1)
+	atomic.AddUint64(&c.inBytes, uint64(size))
+	c.meters.inPackets.Mark(1)
+	c.meters.inTraffic.Mark(int64(size))
+	ingressPacketsMeter.Mark(1)
+	ingressTrafficMeter.Mark(int64(size))
+}
+
+func (c *trafficCounter) countOut(size uint32) {
+	atomic.AddUint64(&c.outMsgs, 1)
+	atomic.AddUint64(&c.outBytes, uint64(size))
+	c.meters.outPackets.Mark(1)
+	c.meters.outTraffic.Mark(int64(size))
+	egressPacketsMeter.Mark(1)
+	egressTrafficMeter.Mark(int64(size))
+}
+
+func (c *trafficCounter) countDropped() {
+	atomic.AddUint64(&c.dropped, 1)
+	c.meters.inDropped.Mark(1)
+	ingressDroppedMeter.Mark(1)
+}
+
+func (c *trafficCounter) info() *TrafficInfo {
+	return &TrafficInfo{
+		InMsgs:   atomic.LoadUint64(&c.inMsgs),
+		InBytes:  atomic.LoadUint64(&c.inBytes),
+		OutMsgs:  atomic.LoadUint64(&c.outMsgs),
+		OutBytes: atomic.LoadUint64(&c.outBytes),
+		Dropped:  atomic.LoadUint64(&c.dropped),
+	}
+}
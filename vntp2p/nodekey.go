@@ -0,0 +1,125 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntp2p
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pborman/uuid"
+	"github.com/vntchain/go-vnt/accounts/keystore"
+	"github.com/vntchain/go-vnt/crypto"
+	"github.com/vntchain/go-vnt/log"
+)
+
+// Node keys are stored in a file of their own, either hex encoded like the
+// files written by crypto.SaveECDSA, or encrypted with the scrypt scheme of
+// the account keystore. The dht datastore never holds the key.
+
+// LoadNodeKey loads the node key from the file, decrypting it with the
+// passphrase if it is encrypted.
+func LoadNodeKey(file, passphrase string) (*ecdsa.PrivateKey, error) {
+	blob, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if blob = bytes.TrimSpace(blob); len(blob) > 0 && blob[0] == '{' {
+		key, err := keystore.DecryptKey(blob, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("can't decrypt node key %s: %v", file, err)
+		}
+		return key.PrivateKey, nil
+	}
+	return crypto.HexToECDSA(string(blob))
+}
+
+// SaveNodeKey writes the node key to the file, encrypted if a passphrase is
+// given. The file is replaced atomically, so a crash doesn't lose the key.
+func SaveNodeKey(file string, key *ecdsa.PrivateKey, passphrase string) error {
+	var blob []byte
+	if passphrase == "" {
+		blob = []byte(hex.EncodeToString(crypto.FromECDSA(key)))
+	} else {
+		k := &keystore.Key{
+			Id:         uuid.NewRandom(),
+			Address:    crypto.PubkeyToAddress(key.PublicKey),
+			PrivateKey: key,
+		}
+		var err error
+		if blob, err = keystore.EncryptKey(k, passphrase, keystore.StandardScryptN, keystore.StandardScryptP); err != nil {
+			return err
+		}
+	}
+	tmp := file + ".tmp"
+	if err := ioutil.WriteFile(tmp, blob, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// MigrateNodeKey moves the node key older versions kept in the dht datastore
+// of the given database engine to the key file. The key is only moved if the
+// key file doesn't exist yet or already holds the same key, a different key
+// file is an error, as either identity may be the one known to the network.
+// It returns nil if the datastore holds no key.
+func MigrateNodeKey(datadir, engine, keyfile, passphrase string) (*ecdsa.PrivateKey, error) {
+	dbpath := filepath.Join(datadir, datastoreDir)
+	if _, err := os.Stat(dbpath); err != nil {
+		return nil, nil
+	}
+	db, err := GetDatastore(dbpath, engine)
+	if err != nil {
+		return nil, err
+	}
+	pd := recoverPersistentData(db)
+	if pd == nil || len(pd.PrivKey) == 0 {
+		return nil, nil
+	}
+	key, err := crypto.HexToECDSA(string(pd.PrivKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid node key in datastore: %v", err)
+	}
+	if _, err := os.Stat(keyfile); err == nil {
+		existing, err := LoadNodeKey(keyfile, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("can't compare node key %s with the key in datastore %s: %v", keyfile, dbpath, err)
+		}
+		if !bytes.Equal(crypto.FromECDSA(existing), crypto.FromECDSA(key)) {
+			return nil, fmt.Errorf("node key %s differs from the key in datastore %s, remove one of them", keyfile, dbpath)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	} else {
+		if err := os.MkdirAll(filepath.Dir(keyfile), 0700); err != nil {
+			return nil, err
+		}
+		if err := SaveNodeKey(keyfile, key, passphrase); err != nil {
+			return nil, err
+		}
+	}
+	pd.PrivKey = nil
+	if err := savePersistentData(db, pd); err != nil {
+		return nil, err
+	}
+	log.Info("Moved node key out of the datastore", "datastore", dbpath, "keyfile", keyfile)
+	return key, nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntp2p
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dht "github.com/libp2p/go-libp2p-kad-dht"
+	"github.com/vntchain/go-vnt/crypto"
+)
+
+// Tests that the key left in the datastore is only moved to the key file if
+// that doesn't exist or holds the same key.
+func TestMigrateNodeKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nodekey-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		keyfile     = filepath.Join(dir, "nodekey")
+		key, _      = crypto.GenerateKey()
+		otherKey, _ = crypto.GenerateKey()
+	)
+	db, err := GetDatastore(filepath.Join(dir, datastoreDir), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := func() {
+		pd := &dht.PersistentData{PrivKey: []byte(hex.EncodeToString(crypto.FromECDSA(key)))}
+		if err := savePersistentData(db, pd); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A different key file stops the migration and keeps both keys
+	store()
+	if err := SaveNodeKey(keyfile, otherKey, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MigrateNodeKey(dir, "", keyfile, ""); err == nil {
+		t.Fatalf("different key file overwritten")
+	}
+	if pd := recoverPersistentData(db); pd == nil || len(pd.PrivKey) == 0 {
+		t.Fatalf("datastore key dropped")
+	}
+	// An equal key file only clears the datastore
+	if err := SaveNodeKey(keyfile, key, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if migrated, err := MigrateNodeKey(dir, "", keyfile, "secret"); err != nil || migrated == nil {
+		t.Fatalf("equal key file not migrated: %v", err)
+	}
+	if pd := recoverPersistentData(db); pd != nil && len(pd.PrivKey) != 0 {
+		t.Fatalf("datastore key kept")
+	}
+	// A missing key file receives the key
+	store()
+	os.Remove(keyfile)
+	if _, err := MigrateNodeKey(dir, "", keyfile, ""); err != nil {
+		t.Fatalf("failed to migrate key: %v", err)
+	}
+	loaded, err := LoadNodeKey(keyfile, "")
+	if err != nil {
+		t.Fatalf("failed to load migrated key: %v", err)
+	}
+	if !bytes.Equal(crypto.FromECDSA(loaded), crypto.FromECDSA(key)) {
+		t.Fatalf("migrated key mismatch")
+	}
+}
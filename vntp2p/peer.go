@@ -6,7 +6,10 @@ import (
 	"bufio"
 	"crypto/ecdsa"
 	"encoding/json"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"net"
 
@@ -36,31 +39,46 @@ const (
 	// PeerEventTypeMsgRecv is the type of event emitted when a
 	// message is received from a peer
 	PeerEventTypeMsgRecv PeerEventType = "msgrecv"
+
+	// PeerEventTypeReject is the type of event emitted when a connecting
+	// peer is rejected, e.g. because of the peer limit or a ban
+	PeerEventTypeReject PeerEventType = "reject"
+
+	// PeerEventTypeBan is the type of event emitted when a peer is banned
+	// for its score
+	PeerEventTypeBan PeerEventType = "ban"
 )
 
 // PeerEvent is an event emitted when peers are either added or dropped from
 // a p2p.Server or when a message is sent or received on a peer connection
 type PeerEvent struct {
-	Type     PeerEventType `json:"type"`
-	Peer     libp2p.ID     `json:"peer"`
-	Error    string        `json:"error,omitempty"`
-	Protocol string        `json:"protocol,omitempty"`
-	MsgCode  *uint64       `json:"msg_code,omitempty"`
-	MsgSize  *uint32       `json:"msg_size,omitempty"`
+	Type          PeerEventType `json:"type"`
+	Peer          libp2p.ID     `json:"peer"`
+	Time          time.Time     `json:"time"`
+	Error         string        `json:"error,omitempty"`
+	RemoteAddress string        `json:"remote_address,omitempty"`
+	Inbound       bool          `json:"inbound,omitempty"`
+	Protocol      string        `json:"protocol,omitempty"`
+	MsgCode       *uint64       `json:"msg_code,omitempty"`
+	MsgSize       *uint32       `json:"msg_size,omitempty"`
 }
 
 type PeerInfo struct {
-	ID      string   `json:"id"`   // Unique node identifier (also the encryption key)
-	Name    string   `json:"name"` // Name of the node, including client type, version, OS, custom data
-	Caps    []string `json:"caps"` // Sum-protocols advertised by this particular peer
+	ID      string        `json:"id"`   // Unique node identifier (also the encryption key)
+	Name    string        `json:"name"` // Name of the node, including client type, version, OS, custom data
+	Caps    []string      `json:"caps"` // Sum-protocols advertised by this particular peer
+	Score   int           `json:"score"`
+	Latency time.Duration `json:"latency"` // Round trip time measured by ping, zero if not known
 	Network struct {
 		LocalAddress  string `json:"localAddress"`  // Local endpoint of the TCP data connection
 		RemoteAddress string `json:"remoteAddress"` // Remote endpoint of the TCP data connection
 		Inbound       bool   `json:"inbound"`
 		Trusted       bool   `json:"trusted"`
+		Reserved      bool   `json:"reserved"`
 		Static        bool   `json:"static"`
 	} `json:"network"`
-	Protocols map[string]interface{} `json:"protocols"` // Sub-protocol specific metadata fields
+	Protocols map[string]interface{}  `json:"protocols"` // Sub-protocol specific metadata fields
+	Traffic   map[string]*TrafficInfo `json:"traffic"`   // Messages exchanged per sub-protocol
 }
 
 type Peer struct {
@@ -68,34 +86,95 @@ type Peer struct {
 	log       log.Logger
 	events    *event.Feed
 	err       chan error
+	closed    chan struct{}
 	messenger map[string]*VNTMessenger // protocolName - vntMessenger
 	wg        sync.WaitGroup
 	// need to add wg
+
+	// Protocols run on streams of their own if the peer was set up through
+	// the control stream rw
+	multiplexed bool
+	caps        []Cap
+	streams     []inet.Stream
+	lock        sync.Mutex
+
+	// flags tell how the peer was connected and whether it is trusted or
+	// reserved, accessed atomically
+	flags dialFlag
+
+	server *Server // keeps the score and bans of the peer
+
+	rtt int64 // Last round trip time measured by ping, accessed atomically
 }
 
-func newPeer(conn *Stream) *Peer {
-	m := make(map[string]*VNTMessenger)
+func newPeer(conn *Stream, compress bool) *Peer {
+	var (
+		m           = make(map[string]*VNTMessenger)
+		multiplexed = conn.Conn.Protocol() == HandshakePID
+		framed      = multiplexed || conn.Conn.Protocol() == FramedPID
+		closed      = make(chan struct{})
+	)
 	for i := range conn.Protocols {
 		proto := conn.Protocols[i]
 		vntMessenger := &VNTMessenger{
 			protocol: proto,
 			in:       make(chan Msg),
-			err:      make(chan error),
-			w:        conn.Conn,
+			err:      make(chan error, 1),
+			closed:   closed,
+			framed:   framed,
+			compress: compress,
+			limiter:  newMsgLimiter(proto.RateLimits),
+			traffic:  newTrafficCounter(proto.Name),
+		}
+		// Protocol streams are attached once they are open
+		if !multiplexed {
+			vntMessenger.w = conn.Conn
 		}
 		m[proto.Name] = vntMessenger
 	}
 
 	p := &Peer{
-		rw:        conn.Conn,
-		log:       log.New(),
-		err:       make(chan error),
-		messenger: m,
+		rw:          conn.Conn,
+		log:         log.New(),
+		err:         make(chan error, len(m)+1),
+		closed:      closed,
+		messenger:   m,
+		multiplexed: multiplexed,
+		flags:       conn.flags,
+	}
+	for _, msger := range m {
+		msger.peer = p
+	}
+	if !multiplexed {
+		for _, proto := range conn.Protocols {
+			p.caps = append(p.caps, Cap{proto.Name, proto.Version})
+		}
 	}
 
 	return p
 }
 
+// maxMsgSize returns the message size limit of the protocol, unknown protocols
+// get the default limit.
+func (p *Peer) maxMsgSize(protocol string) uint32 {
+	if m, ok := p.messenger[protocol]; ok {
+		return m.protocol.maxMsgSize()
+	}
+	return DefaultMaxMsgSize
+}
+
+// maxLegacyMsgSize returns the largest limit of the protocols, json framed
+// messages can only be checked after decoding the protocol.
+func (p *Peer) maxLegacyMsgSize() uint32 {
+	max := uint32(DefaultMaxMsgSize)
+	for _, m := range p.messenger {
+		if size := m.protocol.maxMsgSize(); size > max {
+			max = size
+		}
+	}
+	return max
+}
+
 // LocalID return local PeerID for upper application
 func (p *Peer) LocalID() libp2p.ID {
 	return p.rw.Conn().LocalPeer()
@@ -160,37 +239,79 @@ func (p *Peer) Disconnect(reason DiscReason) {
 	// p.rw.Close()
 }
 
+// Caps returns the capabilities of the remote peer.
+func (p *Peer) Caps() []Cap {
+	caps := make([]Cap, len(p.caps))
+	copy(caps, p.caps)
+	sort.Sort(capsByNameAndVersion(caps))
+	return caps
+}
+
 func (p *Peer) Info() *PeerInfo {
 	info := &PeerInfo{
 		ID: p.RemoteID().String(),
 	}
+	for _, cap := range p.Caps() {
+		info.Caps = append(info.Caps, cap.String())
+	}
 	info.Network.LocalAddress = p.rw.Conn().LocalMultiaddr().String()
 	info.Network.RemoteAddress = p.rw.Conn().RemoteMultiaddr().String()
 
-	// 此处暂时不处理状态
-	// info.Network.Static = p.rw.Conn().RemotePeer()
-	// info.Network.Trusted =
-	// info.Network.Inbound =
+	info.Network.Inbound = p.is(inboundDail)
+	info.Network.Trusted = p.is(trustedDail)
+	info.Network.Reserved = p.is(reservedDail)
+	info.Network.Static = p.is(staticDialedDail)
+	info.Score = p.Score()
+	info.Latency = time.Duration(atomic.LoadInt64(&p.rtt))
+
+	info.Traffic = make(map[string]*TrafficInfo)
+	for name, m := range p.messenger {
+		info.Traffic[name] = m.traffic.info()
+	}
 
 	return info
 }
 
+func (p *Peer) is(f dialFlag) bool {
+	return dialFlag(atomic.LoadInt32((*int32)(&p.flags)))&f != 0
+}
+
+func (p *Peer) set(f dialFlag, val bool) {
+	for {
+		oldFlags := dialFlag(atomic.LoadInt32((*int32)(&p.flags)))
+		flags := oldFlags
+		if val {
+			flags |= f
+		} else {
+			flags &= ^f
+		}
+		if atomic.CompareAndSwapInt32((*int32)(&p.flags), int32(oldFlags), int32(flags)) {
+			return
+		}
+	}
+}
+
 func (p *Peer) run() (remoteRequested bool, err error) {
-	for _, msger := range p.messenger {
-		proto := msger.protocol
-		m := msger
-		go func() {
-			p.wg.Add(1)
-			err := proto.Run(p, m)
-			log.Info("yhx-test", "run protocol error log", err)
-			p.err <- err
-			p.wg.Done()
-		}()
+	if !p.multiplexed {
+		p.lock.Lock()
+		for _, msger := range p.messenger {
+			p.startProtocol(msger)
+		}
+		p.lock.Unlock()
 	}
 
+	if p.server != nil && p.server.host != nil {
+		go p.pingLoop()
+	}
 	err = <-p.err
 	remoteRequested = true
 
+	p.lock.Lock()
+	close(p.closed)
+	for _, s := range p.streams {
+		s.Close()
+	}
+	p.lock.Unlock()
 	p.rw.Close()
 	log.Info("yhx-test remote peer request close, but we need to wait for other protocol", "peerid", p.RemoteID())
 	p.wg.Wait()
@@ -199,9 +320,23 @@ func (p *Peer) run() (remoteRequested bool, err error) {
 	return remoteRequested, err
 }
 
+// startProtocol runs the protocol of the messenger, the lock must be held.
+func (p *Peer) startProtocol(m *VNTMessenger) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		err := m.protocol.Run(p, m)
+		log.Debug("Protocol returned", "protocol", m.protocol.Name, "err", err)
+		p.fail(err)
+	}()
+}
+
 type Stream struct {
 	Conn      inet.Stream
 	Protocols []Protocol
+
+	flags dialFlag
+	cont  chan error // the run loop reports whether the peer was added
 }
 
 //临时测试使用
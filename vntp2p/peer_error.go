@@ -69,6 +69,9 @@ const (
 	DiscUnexpectedIdentity
 	DiscSelf
 	DiscReadTimeout
+	DiscBanned
+	DiscNotAllowed
+	DiscRateLimited
 	DiscSubprotocolError = 0x10
 )
 
@@ -85,6 +88,9 @@ var discReasonToString = [...]string{
 	DiscUnexpectedIdentity:  "unexpected identity",
 	DiscSelf:                "connected to self",
 	DiscReadTimeout:         "read timeout",
+	DiscBanned:              "banned",
+	DiscNotAllowed:          "not in allow-list",
+	DiscRateLimited:         "message rate limit exceeded",
 	DiscSubprotocolError:    "subprotocol error",
 }
 
@@ -0,0 +1,263 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntp2p
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	inet "github.com/libp2p/go-libp2p-net"
+	libp2p "github.com/libp2p/go-libp2p-peer"
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/log"
+)
+
+// In a permissioned network only the peers of the allow-list may connect. The
+// list is the union of the peers kept in the permissioned nodes file, managed
+// by the admin, and the peers of the on-chain registry, if one is followed.
+
+var (
+	errNotPermissioned = errors.New("network is not permissioned")
+	errNotAllowed      = errors.New("peer not in allow-list")
+)
+
+// AllowListInfo represents the allow-list of a permissioned network.
+type AllowListInfo struct {
+	File     []string `json:"file"`     // Peer IDs of the permissioned nodes file
+	Registry []string `json:"registry"` // Peer IDs of the on-chain registry
+}
+
+type allowList struct {
+	lock     sync.RWMutex
+	path     string // Permissioned nodes file, "" if the list is not persisted
+	file     map[libp2p.ID]struct{}
+	registry map[libp2p.ID]struct{}
+}
+
+// loadAllowList reads the allow-list from the file, which is a json array of
+// node urls or peer IDs. A missing file is an empty list.
+func loadAllowList(path string) (*allowList, error) {
+	l := &allowList{
+		path:     path,
+		file:     make(map[libp2p.ID]struct{}),
+		registry: make(map[libp2p.ID]struct{}),
+	}
+	if path == "" {
+		return l, nil
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return l, nil
+	}
+	var entries []string
+	if err := common.LoadJSON(path, &entries); err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		id, err := parseAllowed(entry)
+		if err != nil {
+			log.Warn("Invalid permissioned node", "entry", entry, "err", err)
+			continue
+		}
+		l.file[id] = struct{}{}
+	}
+	return l, nil
+}
+
+// parseAllowed accepts a node url or a peer ID.
+func parseAllowed(entry string) (libp2p.ID, error) {
+	entry = strings.TrimSpace(entry)
+	if strings.HasPrefix(entry, "/") {
+		node, err := ParseNode(entry)
+		if err != nil {
+			return "", err
+		}
+		return node.Id, nil
+	}
+	return libp2p.IDB58Decode(entry)
+}
+
+func (l *allowList) allowed(id libp2p.ID) bool {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	if _, ok := l.file[id]; ok {
+		return true
+	}
+	_, ok := l.registry[id]
+	return ok
+}
+
+// add allows the peer and saves the file list.
+func (l *allowList) add(id libp2p.ID) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.file[id] = struct{}{}
+	return l.save()
+}
+
+// remove drops the peer from the file list and saves it.
+func (l *allowList) remove(id libp2p.ID) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if _, ok := l.file[id]; !ok {
+		return errNotAllowed
+	}
+	delete(l.file, id)
+	return l.save()
+}
+
+// setRegistry replaces the peers of the on-chain registry, reporting whether
+// the list changed.
+func (l *allowList) setRegistry(ids []libp2p.ID) bool {
+	registry := make(map[libp2p.ID]struct{}, len(ids))
+	for _, id := range ids {
+		registry[id] = struct{}{}
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	changed := len(registry) != len(l.registry)
+	for id := range registry {
+		if _, ok := l.registry[id]; !ok {
+			changed = true
+		}
+	}
+	l.registry = registry
+	return changed
+}
+
+// save writes the file list, the lock must be held.
+func (l *allowList) save() error {
+	if l.path == "" {
+		return nil
+	}
+	blob, err := json.MarshalIndent(sortedIDs(l.file), "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(l.path, blob, 0644)
+}
+
+func (l *allowList) info() *AllowListInfo {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	return &AllowListInfo{File: sortedIDs(l.file), Registry: sortedIDs(l.registry)}
+}
+
+func sortedIDs(set map[libp2p.ID]struct{}) []string {
+	ids := make([]string, 0, len(set))
+	for id := range set {
+		ids = append(ids, id.Pretty())
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// permitted reports whether the peer may connect.
+func (server *Server) permitted(id libp2p.ID) bool {
+	return server.allow == nil || server.allow.allowed(id)
+}
+
+// rejectUnpermitted closes the connections of peers which are not allowed as
+// soon as they are established, the dht doesn't talk to them either.
+func (server *Server) rejectUnpermitted(n inet.Network, c inet.Conn) {
+	if !server.permitted(c.RemotePeer()) {
+		log.Debug("Rejected connection of unpermitted peer", "peer", c.RemotePeer(), "addr", c.RemoteMultiaddr())
+		go c.Close()
+	}
+}
+
+// enforceAllowList disconnects the peers which are no longer allowed.
+func (server *Server) enforceAllowList() {
+	for _, p := range server.Peers() {
+		if !server.permitted(p.RemoteID()) {
+			p.Disconnect(DiscNotAllowed)
+		}
+	}
+	for _, c := range server.host.Network().Conns() {
+		if !server.permitted(c.RemotePeer()) {
+			c.Close()
+		}
+	}
+}
+
+// AllowPeer adds a node url or peer ID to the permissioned nodes file.
+func (server *Server) AllowPeer(entry string) error {
+	if server.allow == nil {
+		return errNotPermissioned
+	}
+	id, err := parseAllowed(entry)
+	if err != nil {
+		return err
+	}
+	return server.allow.add(id)
+}
+
+// DisallowPeer removes a node url or peer ID from the permissioned nodes file
+// and disconnects the peer unless the on-chain registry still allows it.
+func (server *Server) DisallowPeer(entry string) error {
+	if server.allow == nil {
+		return errNotPermissioned
+	}
+	id, err := parseAllowed(entry)
+	if err != nil {
+		return err
+	}
+	if err := server.allow.remove(id); err != nil {
+		return err
+	}
+	server.enforceAllowList()
+	return nil
+}
+
+// SetRegistryPeers replaces the node urls or peer IDs allowed by the on-chain
+// registry, peers dropped from the registry are disconnected.
+func (server *Server) SetRegistryPeers(entries []string) error {
+	if server.allow == nil {
+		return errNotPermissioned
+	}
+	ids := make([]libp2p.ID, 0, len(entries))
+	for _, entry := range entries {
+		id, err := parseAllowed(entry)
+		if err != nil {
+			log.Debug("Invalid permissioned node in registry", "entry", entry, "err", err)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	if server.allow.setRegistry(ids) {
+		log.Info("Updated permissioned peers from registry", "count", len(ids))
+		server.enforceAllowList()
+	}
+	return nil
+}
+
+// AllowList returns the allow-list of a permissioned network.
+func (server *Server) AllowList() (*AllowListInfo, error) {
+	if server.allow == nil {
+		return nil, errNotPermissioned
+	}
+	return server.allow.info(), nil
+}
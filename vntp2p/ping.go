@@ -0,0 +1,122 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntp2p
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"sync/atomic"
+	"time"
+
+	inet "github.com/libp2p/go-libp2p-net"
+	"github.com/vntchain/go-vnt/log"
+)
+
+// PingPID is the protocol measuring the round trip time to peers. It is wire
+// compatible with the libp2p ping protocol: the dialer writes 32 random bytes
+// which are echoed by the remote side.
+const PingPID = "/ipfs/ping/1.0.0"
+
+const (
+	pingSize     = 32
+	pingInterval = 15 * time.Second
+	pingTimeout  = 10 * time.Second
+)
+
+var errPingMismatch = errors.New("ping echo mismatch")
+
+// handlePingStream echoes the pings of the remote side until it closes the
+// stream or stays silent for too long.
+func (server *Server) handlePingStream(s inet.Stream) {
+	defer s.Close()
+
+	buf := make([]byte, pingSize)
+	for {
+		s.SetReadDeadline(time.Now().Add(pingInterval + pingTimeout))
+		if _, err := io.ReadFull(s, buf); err != nil {
+			return
+		}
+		if _, err := s.Write(buf); err != nil {
+			return
+		}
+	}
+}
+
+// pingLoop measures the round trip time to the peer until it is closed.
+// Peers which don't support the ping protocol are not pinged.
+func (p *Peer) pingLoop() {
+	var (
+		s      inet.Stream
+		ticker = time.NewTicker(pingInterval)
+	)
+	defer ticker.Stop()
+	defer func() {
+		if s != nil {
+			s.Close()
+		}
+	}()
+	for {
+		if s == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+			stream, err := p.server.host.NewStream(ctx, p.RemoteID(), PingPID)
+			cancel()
+			if err != nil {
+				log.Trace("Failed to open ping stream", "peer", p.RemoteID(), "err", err)
+				return
+			}
+			s = stream
+		}
+		rtt, err := ping(s)
+		if err != nil {
+			log.Trace("Ping failed", "peer", p.RemoteID(), "err", err)
+			s.Reset()
+			s = nil
+		} else {
+			atomic.StoreInt64(&p.rtt, int64(rtt))
+			p.server.host.Peerstore().RecordLatency(p.RemoteID(), rtt)
+			pingTimer.Update(rtt)
+		}
+		select {
+		case <-ticker.C:
+		case <-p.closed:
+			return
+		}
+	}
+}
+
+// ping sends a single ping over the stream and waits for the echo.
+func ping(s inet.Stream) (time.Duration, error) {
+	out, in := make([]byte, pingSize), make([]byte, pingSize)
+	if _, err := rand.Read(out); err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	s.SetDeadline(start.Add(pingTimeout))
+	if _, err := s.Write(out); err != nil {
+		return 0, err
+	}
+	if _, err := io.ReadFull(s, in); err != nil {
+		return 0, err
+	}
+	if !bytes.Equal(in, out) {
+		return 0, errPingMismatch
+	}
+	return time.Since(start), nil
+}
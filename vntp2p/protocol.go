@@ -1,15 +1,11 @@
 package vntp2p
 
 import (
-	"encoding/binary"
-	"encoding/json"
-	"io"
-	"time"
+	"bufio"
 
 	inet "github.com/libp2p/go-libp2p-net"
 	libp2p "github.com/libp2p/go-libp2p-peer"
 	"github.com/vntchain/go-vnt/log"
-	"github.com/vntchain/go-vnt/rlp"
 )
 
 // Protocol 以太坊自带代码，别的地方要用到
@@ -21,10 +17,26 @@ type Protocol struct {
 	Run      func(peer *Peer, rw MsgReadWriter) error
 	NodeInfo func() interface{}
 	PeerInfo func(id libp2p.ID) interface{}
+
+	// MaxMsgSize is the maximum size of a message payload, DefaultMaxMsgSize if zero
+	MaxMsgSize uint32
+
+	// RateLimits limits the rate of messages a peer may send per code. Messages
+	// above the limit are dropped and lower the score of the peer.
+	RateLimits map[MessageType]RateLimit
 }
 
 // HandleStream handle all message which is from anywhere
 func (server *Server) HandleStream(s inet.Stream) {
+	if !server.permitted(s.Conn().RemotePeer()) {
+		log.Debug("HandleStream", "remotePeerID", s.Conn().RemotePeer(), "err", errNotAllowed)
+		s.Reset()
+		return
+	}
+	var (
+		framed = s.Protocol() == FramedPID
+		r      = bufio.NewReader(s)
+	)
 	for {
 		log.Info("yhx-test, stream data comming")
 		peer := server.GetPeerByRemoteID(s)
@@ -32,41 +44,31 @@ func (server *Server) HandleStream(s inet.Stream) {
 			log.Info("HandleStream", "localPeerID", s.Conn().LocalPeer(), "remotePeerID", s.Conn().RemotePeer(), "this remote peer is nil, don't handle it")
 			return
 		}
-		msgHeaderByte := make([]byte, MessageHeaderLength)
-		_, err := io.ReadFull(s, msgHeaderByte)
-		if err != nil {
-			//log.Error("handleStream", "read error", err)
-			notifyError(peer.messenger, err)
-			return
-		}
-		bodySize := binary.LittleEndian.Uint32(msgHeaderByte)
-
-		msgBodyByte := make([]byte, bodySize)
-		_, err = io.ReadFull(s, msgBodyByte)
-		if err != nil {
-			log.Error("handleStream", "read msgBody error", err)
-			notifyError(peer.messenger, err)
-			return
+		var (
+			msg Msg
+			err error
+		)
+		if framed {
+			msg, err = readFrame(r, peer.maxMsgSize)
+		} else {
+			msg, err = readLegacyFrame(r, peer.maxLegacyMsgSize())
 		}
-		msgBody := &MsgBody{Payload: &rlp.EncReader{}}
-		err = json.Unmarshal(msgBodyByte, msgBody)
 		if err != nil {
-			log.Error("handleSteam", "unmarshal msgBody error", err)
+			log.Debug("handleStream", "read msg error", err)
 			notifyError(peer.messenger, err)
 			return
 		}
-		msgBody.ReceivedAt = time.Now()
-		//log.Info("yhx-test", "RECEIVED MESSAGE", msgBody)
 
-		var msgHeader MsgHeader
-		copy(msgHeader[:], msgHeaderByte)
-
-		msg := Msg{
-			Header: msgHeader,
-			Body:   *msgBody,
-		}
-
-		if messenger, ok := peer.messenger[msgBody.ProtocolID]; ok { // this node support protocolID
+		if messenger, ok := peer.messenger[msg.Body.ProtocolID]; ok { // this node support protocolID
+			messenger.received(msg)
+			ok, err := messenger.limit(msg)
+			if err != nil {
+				notifyError(peer.messenger, err)
+				return
+			}
+			if !ok {
+				continue
+			}
 			messenger.in <- msg
 		} else {
 			log.Warn("handleStream", "receive Unknown Message", msg)
@@ -83,6 +85,9 @@ func (server *Server) HandleStream(s inet.Stream) {
 
 func notifyError(messengers map[string]*VNTMessenger, err error) {
 	for _, m := range messengers {
-		m.err <- err
+		select {
+		case m.err <- err:
+		case <-m.closed:
+		}
 	}
 }
@@ -0,0 +1,110 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntp2p
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimit is the rate at which a peer may send messages of a code, Burst
+// messages may arrive at once.
+type RateLimit struct {
+	Rate  float64 // Messages per second
+	Burst int
+}
+
+// tokenBucket refills Rate tokens per second up to Burst.
+type tokenBucket struct {
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) take(now time.Time) bool {
+	b.tokens += now.Sub(b.last).Seconds() * b.limit.Rate
+	if max := float64(b.limit.Burst); b.tokens > max {
+		b.tokens = max
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+const (
+	// A peer having more than maxRateLimitDrops messages of a protocol
+	// dropped within rateLimitWindow is disconnected.
+	maxRateLimitDrops = 100
+	rateLimitWindow   = 10 * time.Second
+)
+
+// msgLimiter enforces the rate limits of a protocol for a single peer.
+type msgLimiter struct {
+	lock    sync.Mutex
+	limits  map[MessageType]RateLimit
+	buckets map[MessageType]*tokenBucket
+
+	drops       int       // Messages dropped since windowStart
+	windowStart time.Time // Start of the current drop window
+}
+
+// newMsgLimiter returns nil if the protocol has no rate limits.
+func newMsgLimiter(limits map[MessageType]RateLimit) *msgLimiter {
+	if len(limits) == 0 {
+		return nil
+	}
+	return &msgLimiter{limits: limits, buckets: make(map[MessageType]*tokenBucket)}
+}
+
+// allow reports whether a message of the code is within the limits.
+func (l *msgLimiter) allow(code MessageType) bool {
+	if l == nil {
+		return true
+	}
+	limit, ok := l.limits[code]
+	if !ok {
+		return true
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	b, ok := l.buckets[code]
+	if !ok {
+		b = &tokenBucket{limit: limit, tokens: float64(limit.Burst), last: now}
+		l.buckets[code] = b
+	}
+	return b.take(now)
+}
+
+// drop records a message refused by the limits and reports whether the peer
+// keeps exceeding them, i.e. more than maxRateLimitDrops messages were
+// dropped within rateLimitWindow.
+func (l *msgLimiter) drop() bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.windowStart) > rateLimitWindow {
+		l.drops, l.windowStart = 0, now
+	}
+	l.drops++
+	return l.drops > maxRateLimitDrops
+}
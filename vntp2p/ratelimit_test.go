@@ -0,0 +1,119 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntp2p
+
+import (
+	"testing"
+	"time"
+)
+
+// Tests that buckets allow bursts and refill at their rate, up to the burst.
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	b := &tokenBucket{limit: RateLimit{Rate: 2, Burst: 3}, tokens: 3, last: now}
+
+	for i := 0; i < 3; i++ {
+		if !b.take(now) {
+			t.Fatalf("message %d of burst refused", i)
+		}
+	}
+	if b.take(now) {
+		t.Fatalf("message past burst allowed")
+	}
+	// Two messages per second
+	if b.take(now.Add(250 * time.Millisecond)) {
+		t.Errorf("message allowed before refill")
+	}
+	if !b.take(now.Add(500 * time.Millisecond)) {
+		t.Errorf("message refused after refill")
+	}
+	// Long pauses don't refill past the burst
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if !b.take(now) {
+			t.Fatalf("message %d of refilled burst refused", i)
+		}
+	}
+	if b.take(now) {
+		t.Errorf("message past refilled burst allowed")
+	}
+}
+
+// Tests that limiters only restrict the limited message codes.
+func TestMsgLimiter(t *testing.T) {
+	if l := newMsgLimiter(nil); l != nil || !l.allow(0) {
+		t.Fatalf("limiter without limits restricts messages")
+	}
+	l := newMsgLimiter(map[MessageType]RateLimit{1: {Rate: 0, Burst: 2}})
+	for i := 0; i < 2; i++ {
+		if !l.allow(1) {
+			t.Fatalf("message %d of burst refused", i)
+		}
+	}
+	if l.allow(1) {
+		t.Errorf("message past burst allowed")
+	}
+	for i := 0; i < 10; i++ {
+		if !l.allow(2) {
+			t.Fatalf("unlimited message %d refused", i)
+		}
+	}
+}
+
+// Tests that peers are only reported as abusive once they keep exceeding the
+// limits within the drop window.
+func TestMsgLimiterDrop(t *testing.T) {
+	l := newMsgLimiter(map[MessageType]RateLimit{1: {Rate: 0, Burst: 0}})
+	for i := 0; i < maxRateLimitDrops; i++ {
+		if l.drop() {
+			t.Fatalf("drop %d reported as abuse", i)
+		}
+	}
+	if !l.drop() {
+		t.Fatalf("sustained drops not reported as abuse")
+	}
+	// Drops of an earlier window are forgotten
+	l.windowStart = time.Now().Add(-2 * rateLimitWindow)
+	if l.drop() {
+		t.Errorf("drop of a new window reported as abuse")
+	}
+}
+
+// Tests that messengers count the messages dropped over the limits and fail
+// on sustained abuse.
+func TestMessengerLimit(t *testing.T) {
+	rw := &VNTMessenger{
+		protocol: Protocol{Name: "ratelimit-test"},
+		limiter:  newMsgLimiter(map[MessageType]RateLimit{1: {Rate: 0, Burst: 1}}),
+		traffic:  newTrafficCounter("ratelimit-test"),
+	}
+	msg := Msg{Body: MsgBody{Type: 1}}
+	if ok, err := rw.limit(msg); !ok || err != nil {
+		t.Fatalf("message within burst refused: %v", err)
+	}
+	for i := 0; i < maxRateLimitDrops; i++ {
+		if ok, err := rw.limit(msg); ok || err != nil {
+			t.Fatalf("message %d over limit: have (%v, %v), want (false, nil)", i, ok, err)
+		}
+	}
+	if _, err := rw.limit(msg); err != DiscRateLimited {
+		t.Fatalf("sustained abuse: have %v, want %v", err, DiscRateLimited)
+	}
+	if dropped := rw.traffic.info().Dropped; dropped != maxRateLimitDrops+1 {
+		t.Errorf("dropped messages mismatch: have %d, want %d", dropped, maxRateLimitDrops+1)
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntp2p
+
+import (
+	"sync"
+	"time"
+
+	libp2p "github.com/libp2p/go-libp2p-peer"
+	"github.com/vntchain/go-vnt/log"
+)
+
+// ScoreEvent is the behaviour of a peer reported by a protocol.
+type ScoreEvent int
+
+const (
+	// ScoreInvalidMsg is reported for malformed or unexpected messages
+	ScoreInvalidMsg ScoreEvent = iota
+	// ScoreInvalidBlock is reported for invalid blocks or chain data
+	ScoreInvalidBlock
+	// ScoreInvalidBftMsg is reported for invalid consensus messages
+	ScoreInvalidBftMsg
+	// ScoreTimeout is reported if a peer didn't answer a request in time
+	ScoreTimeout
+	// ScoreRateLimited is reported if a peer exceeded a message rate limit
+	ScoreRateLimited
+	// ScoreUsefulBlock is reported for blocks or chain data we accepted
+	ScoreUsefulBlock
+)
+
+var scoreEventDeltas = map[ScoreEvent]int{
+	ScoreInvalidMsg:    -10,
+	ScoreInvalidBlock:  -25,
+	ScoreInvalidBftMsg: -20,
+	ScoreTimeout:       -5,
+	ScoreRateLimited:   -2,
+	ScoreUsefulBlock:   1,
+}
+
+func (ev ScoreEvent) String() string {
+	switch ev {
+	case ScoreInvalidMsg:
+		return "invalid message"
+	case ScoreInvalidBlock:
+		return "invalid block"
+	case ScoreInvalidBftMsg:
+		return "invalid bft message"
+	case ScoreTimeout:
+		return "timeout"
+	case ScoreRateLimited:
+		return "rate limited"
+	case ScoreUsefulBlock:
+		return "useful block"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	// Scores are kept within [minScore, maxScore], a peer reaching banScore
+	// is disconnected and banned for scoreBanDuration.
+	minScore         = -100
+	maxScore         = 100
+	banScore         = minScore
+	scoreBanDuration = time.Hour
+
+	// Scores move one point towards zero every scoreDecayInterval, so that
+	// peers recover from occasional failures.
+	scoreDecayInterval = time.Minute
+
+	// Scores which decayed to zero are dropped once the board holds more
+	// than maxScoreEntries peers.
+	maxScoreEntries = 4096
+)
+
+type peerScore struct {
+	value   int
+	updated time.Time
+}
+
+// decay moves the score towards zero for the time passed since its last
+// update.
+func (s *peerScore) decay(now time.Time) {
+	steps := int(now.Sub(s.updated) / scoreDecayInterval)
+	if steps <= 0 {
+		return
+	}
+	switch {
+	case s.value > steps:
+		s.value -= steps
+	case s.value < -steps:
+		s.value += steps
+	default:
+		s.value = 0
+	}
+	s.updated = s.updated.Add(time.Duration(steps) * scoreDecayInterval)
+}
+
+// scoreboard keeps the scores of peers, also across reconnects.
+type scoreboard struct {
+	lock   sync.Mutex
+	scores map[libp2p.ID]*peerScore
+}
+
+func newScoreboard() *scoreboard {
+	return &scoreboard{scores: make(map[libp2p.ID]*peerScore)}
+}
+
+// add applies the event to the score of the peer, returning the new score.
+func (b *scoreboard) add(id libp2p.ID, ev ScoreEvent) int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := time.Now()
+	s, ok := b.scores[id]
+	if !ok {
+		if len(b.scores) >= maxScoreEntries {
+			b.prune(now)
+		}
+		s = &peerScore{updated: now}
+		b.scores[id] = s
+	}
+	s.decay(now)
+	s.value += scoreEventDeltas[ev]
+	if s.value < minScore {
+		s.value = minScore
+	}
+	if s.value > maxScore {
+		s.value = maxScore
+	}
+	return s.value
+}
+
+// score returns the current score of the peer.
+func (b *scoreboard) score(id libp2p.ID) int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	s, ok := b.scores[id]
+	if !ok {
+		return 0
+	}
+	s.decay(time.Now())
+	return s.value
+}
+
+// reset forgets the score of the peer.
+func (b *scoreboard) reset(id libp2p.ID) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	delete(b.scores, id)
+}
+
+func (b *scoreboard) prune(now time.Time) {
+	for id, s := range b.scores {
+		if s.decay(now); s.value == 0 {
+			delete(b.scores, id)
+		}
+	}
+}
+
+// Report feeds the behaviour of the peer into its score. Peers whose score
+// drops to the ban threshold are disconnected and banned for a while, unless
+// they are trusted or reserved.
+func (p *Peer) Report(ev ScoreEvent) {
+	if p.server == nil {
+		return
+	}
+	score := p.server.scores.add(p.RemoteID(), ev)
+	log.Trace("Peer score changed", "peer", p.RemoteID(), "event", ev, "score", score)
+
+	if score > banScore || p.is(trustedDail|reservedDail) {
+		return
+	}
+	log.Debug("Banning misbehaving peer", "peer", p.RemoteID(), "event", ev)
+	reason := "score " + ev.String()
+	if err := p.server.bans.add(p.RemoteID().Pretty(), scoreBanDuration, reason); err != nil {
+		log.Warn("Failed to ban peer", "peer", p.RemoteID(), "err", err)
+	}
+	peerBanMeter.Mark(1)
+	p.server.peerFeed.Send(&PeerEvent{
+		Type:  PeerEventTypeBan,
+		Peer:  p.RemoteID(),
+		Time:  time.Now(),
+		Error: reason,
+	})
+	p.server.scores.reset(p.RemoteID())
+	p.Disconnect(DiscBanned)
+}
+
+// Score returns the current score of the peer.
+func (p *Peer) Score() int {
+	if p.server == nil {
+		return 0
+	}
+	return p.server.scores.score(p.RemoteID())
+}
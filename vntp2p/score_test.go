@@ -0,0 +1,86 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntp2p
+
+import (
+	"testing"
+	"time"
+
+	libp2p "github.com/libp2p/go-libp2p-peer"
+)
+
+// Tests that scores are clamped to their bounds, and that repeated invalid
+// chain data reaches the ban threshold while timeouts take longer.
+func TestScoreboardClamp(t *testing.T) {
+	b := newScoreboard()
+	id := libp2p.ID("peer")
+
+	for i := 0; i < 200; i++ {
+		b.add(id, ScoreUsefulBlock)
+	}
+	if have := b.score(id); have != maxScore {
+		t.Errorf("score above bound: have %d, want %d", have, maxScore)
+	}
+	b.reset(id)
+
+	var score int
+	for i := 1; i <= 4; i++ {
+		score = b.add(id, ScoreInvalidBlock)
+		if (score <= banScore) != (i == 4) {
+			t.Errorf("invalid block %d: score %d against ban score %d", i, score, banScore)
+		}
+	}
+	if score = b.add(id, ScoreInvalidBlock); score != minScore {
+		t.Errorf("score below bound: have %d, want %d", score, minScore)
+	}
+	b.reset(id)
+	if have := b.score(id); have != 0 {
+		t.Errorf("reset score mismatch: have %d, want 0", have)
+	}
+	for i := 1; i < -banScore/-scoreEventDeltas[ScoreTimeout]; i++ {
+		if score = b.add(id, ScoreTimeout); score <= banScore {
+			t.Fatalf("timeout %d: reached ban score %d", i, score)
+		}
+	}
+}
+
+// Tests that scores decay towards zero over time, and that zero scores are
+// pruned once the board is full.
+func TestScoreboardDecay(t *testing.T) {
+	b := newScoreboard()
+
+	tests := []struct {
+		value, steps, want int
+	}{
+		{value: -50, steps: 10, want: -40},
+		{value: 50, steps: 10, want: 40},
+		{value: -5, steps: 10, want: 0},
+		{value: 5, steps: 10, want: 0},
+		{value: 5, steps: 0, want: 5},
+	}
+	for i, tt := range tests {
+		id := libp2p.ID(string(rune('a' + i)))
+		b.scores[id] = &peerScore{value: tt.value, updated: time.Now().Add(-time.Duration(tt.steps)*scoreDecayInterval - time.Second)}
+		if have := b.score(id); have != tt.want {
+			t.Errorf("test %d: decayed score mismatch: have %d, want %d", i, have, tt.want)
+		}
+	}
+	b.prune(time.Now())
+	if len(b.scores) != 3 {
+		t.Errorf("pruned board size mismatch: have %d, want 3", len(b.scores))
+	}
+}
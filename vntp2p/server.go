@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"path/filepath"
 	"sync"
+	"time"
 
 	libp2p "github.com/libp2p/go-libp2p"
 	p2phost "github.com/libp2p/go-libp2p-host"
@@ -26,53 +28,90 @@ import (
 const (
 	maxActiveDialTasks = 16
 	defaultDialRatio   = 3
+
+	// Maximum number of concurrently handshaking inbound connections.
+	defaultMaxPendingPeers = 50
 )
 
 var errServerStopped = errors.New("server stopped")
 
-type dialFlag int
+type dialFlag int32
 
 const (
 	dynDialedDail dialFlag = 1 << iota
 	staticDialedDail
 	inboundDail
 	trustedDail
+	reservedDail
 )
 
 type Config struct {
 	PrivateKey *ecdsa.PrivateKey `toml:"-"`
 
+	// MaxPeers is the maximum number of peers, trusted and reserved peers
+	// are not counted against it.
 	MaxPeers int
 
+	// MaxPendingPeers is the maximum number of inbound peers which can be in
+	// the handshake phase at the same time.
 	MaxPendingPeers int `toml:",omitempty"`
 
 	DialRatio int `toml:",omitempty"`
 
+	// NoDiscovery disables dialling peers found through the dht, only
+	// bootstrap, static and trusted nodes are dialled.
 	NoDiscovery bool
 
 	Name string `toml:"-"`
 
 	BootstrapNodes []*Node
 
+	// BootstrapLists are signed bootstrap node lists which are fetched
+	// periodically, either dns://<pubkey>@<domain> or file://<pubkey>@<path>.
+	BootstrapLists []string `toml:",omitempty"`
+
 	StaticNodes []*Node
 
+	// TrustedNodes are always allowed to connect and are redialled when
+	// they drop.
 	TrustedNodes []*Node
 
 	NetRestrict []*net.IPNet `toml:",omitempty"`
 
+	// Permissioned only lets the peers of the allow-list connect, the ones of
+	// PermissionedNodesFile and of the on-chain registry if one is followed.
+	Permissioned bool `toml:",omitempty"`
+
+	// PermissionedNodesFile is the json file listing the node urls or peer IDs
+	// allowed in a permissioned network, it is updated by the admin RPCs.
+	PermissionedNodesFile string `toml:",omitempty"`
+
 	NodeDatabase string `toml:",omitempty"`
 
+	// DatabaseEngine is the engine keeping the dht datastore in NodeDatabase,
+	// the one it was created with if empty.
+	DatabaseEngine string `toml:",omitempty"`
+
 	Protocols []Protocol `toml:"-"`
 
 	ListenAddr string
 
 	NAT libp2p.Option `toml:",omitempty"`
 
+	// Transport replaces the TCP transport of the host if set, the simulator
+	// uses it to run nodes over an in-memory network.
+	Transport libp2p.Option `toml:"-"`
+
 	// Dialer NodeDialer `toml:"-"`
 
 	// NoDial bool `toml:",omitempty"`
 
-	EnableMsgEvents bool
+	// EnableMsgEvents makes the server emit msgsend and msgrecv events for
+	// every message, on top of the peer lifecycle events.
+	EnableMsgEvents bool `toml:",omitempty"`
+
+	// DisableCompression disables snappy compression of binary framed messages.
+	DisableCompression bool `toml:",omitempty"`
 
 	Logger log.Logger `toml:",omitempty"`
 }
@@ -89,9 +128,14 @@ type Server struct {
 
 	lock sync.Mutex
 
-	quit         chan struct{}
-	addstatic    chan *Node
-	removestatic chan *Node
+	quit          chan struct{}
+	addstatic     chan *Node
+	removestatic  chan *Node
+	addtrusted    chan *Node
+	removetrusted chan *Node
+	setreserved   chan []*Node
+	setlistnodes  chan []peer.ID
+	pending       chan struct{}
 
 	addpeer chan *Stream
 	delpeer chan peerDrop
@@ -100,6 +144,10 @@ type Server struct {
 	peerOpDone chan struct{}
 
 	protomap map[string][]Protocol
+
+	scores *scoreboard
+	bans   *banList
+	allow  *allowList // nil if the network is not permissioned
 }
 
 type peerOpFunc func(map[peer.ID]*Peer)
@@ -134,14 +182,24 @@ func (server *Server) Start() error {
 	server.delpeer = make(chan peerDrop)
 	server.addstatic = make(chan *Node)
 	server.removestatic = make(chan *Node)
+	server.addtrusted = make(chan *Node)
+	server.removetrusted = make(chan *Node)
+	server.setreserved = make(chan []*Node)
+	server.setlistnodes = make(chan []peer.ID)
 	server.quit = make(chan struct{})
 	server.peerOp = make(chan peerOpFunc)
 	server.peerOpDone = make(chan struct{})
+	if server.MaxPendingPeers > 0 {
+		server.pending = make(chan struct{}, server.MaxPendingPeers)
+	} else {
+		server.pending = make(chan struct{}, defaultMaxPendingPeers)
+	}
 
 	// 协议映射初始化
 	server.protomap = make(map[string][]Protocol)
 
 	server.protomap[PID] = server.Protocols
+	server.protomap[FramedPID] = server.Protocols
 
 	// Listen
 	// run
@@ -149,21 +207,65 @@ func (server *Server) Start() error {
 		return fmt.Errorf("P2P Server can't start for no listening")
 	}
 
+	var sources []BootstrapSource
+	for _, rawurl := range server.BootstrapLists {
+		source, err := ParseBootstrapSource(rawurl)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, source)
+	}
+	server.allow = nil
+	if server.Permissioned {
+		allow, err := loadAllowList(server.PermissionedNodesFile)
+		if err != nil {
+			return fmt.Errorf("permissioned nodes file: %v", err)
+		}
+		server.allow = allow
+	}
+
 	listenPort := server.Config.ListenAddr[1:]
 	log.Info("startVNTNode()", "listenPort", listenPort)
 	ctx, cancel := context.WithCancel(context.Background())
 	server.cancel = cancel
 
 	d := server.NodeDatabase
-	vdht, host, err := ConstructDHT(ctx, MakePort(listenPort), nil, d, server.Config.NetRestrict, server.Config.NAT)
+	var opts []libp2p.Option
+	if server.Transport != nil {
+		opts = append(opts, server.Transport)
+	}
+	vdht, host, err := ConstructDHT(ctx, MakePort(listenPort), server.PrivateKey, d, server.DatabaseEngine, server.Config.NetRestrict, server.Config.NAT, opts...)
 	if err != nil {
 		log.Error("startVNTNode()", "constructDHT error", err)
+		cancel()
 		return err
 	}
 
+	if server.allow != nil {
+		host.Network().Notify(&inet.NotifyBundle{ConnectedF: server.rejectUnpermitted})
+	}
+
 	// setStreamHandler can only handle request message
 	// it can not hear response
 	host.SetStreamHandler(PID, server.HandleStream)
+	host.SetStreamHandler(FramedPID, server.HandleStream)
+	host.SetStreamHandler(HandshakePID, server.handleHandshakeStream)
+	host.SetStreamHandler(PingPID, server.handlePingStream)
+	for _, p := range server.Protocols {
+		host.SetStreamHandler(protocolID(p), server.handleProtocolStream)
+	}
+
+	// Bans are kept in the datastore of the dht
+	var db *Datastore
+	if d != "" {
+		if db, err = GetDatastore(filepath.Join(d, datastoreDir), server.DatabaseEngine); err != nil {
+			cancel()
+			host.Close()
+			return err
+		}
+	}
+	server.scores = newScoreboard()
+	server.bans = newBanList(db)
 
 	log.Info("startVNTNode()", "own nodeID", host.ID())
 	server.table = NewDHTTable(vdht, host.ID())
@@ -171,12 +273,16 @@ func (server *Server) Start() error {
 
 	bootnodes := server.LoadConfig(ctx)
 
-	maxdails := server.maxDialedConns()
-
-	taskState := newTaskState(maxdails, bootnodes, server.table)
+	// Without discovery the dynamic slots are only filled from the
+	// bootstrap lists
+	taskState := newTaskState(server.maxDialedConns(), !server.NoDiscovery, bootnodes, server.table)
 
 	server.loopWG.Add(1)
 	go server.run(ctx, taskState)
+	if len(sources) > 0 {
+		server.loopWG.Add(1)
+		go server.bootstrapLoop(ctx, sources)
+	}
 
 	server.running = true
 	return nil
@@ -193,6 +299,9 @@ func (server *Server) LoadConfig(ctx context.Context) []peer.ID {
 
 		bootnodes = append(bootnodes, bootnode.Id)
 	}
+	for _, node := range server.Config.TrustedNodes {
+		server.host.Peerstore().AddAddrs(node.Id, []ma.Multiaddr{node.Addr}, peerstore.PermanentAddrTTL)
+	}
 
 	return bootnodes
 
@@ -206,7 +315,15 @@ func (server *Server) run(ctx context.Context, tasker taskworker) {
 		queuedTasks  []task
 		taskdone     = make(chan task, maxActiveDialTasks)
 		peers        = make(map[peer.ID]*Peer)
+		trusted      = make(map[peer.ID]bool, len(server.TrustedNodes))
+		reserved     = make(map[peer.ID]bool)
 	)
+	// Put trusted nodes into a map to speed up checks.
+	// Trusted and reserved peers are allowed to connect above MaxPeers.
+	for _, n := range server.TrustedNodes {
+		trusted[n.Id] = true
+		tasker.addPersistent(n, trustedDail)
+	}
 
 	delTask := func(t task) {
 		for i := range runningTasks {
@@ -245,16 +362,41 @@ func (server *Server) run(ctx context.Context, tasker taskworker) {
 		case t := <-server.addpeer:
 			remoteID := t.Conn.Conn().RemotePeer()
 			if _, ok := peers[remoteID]; ok { // this peer already exists
+				t.cont <- nil
 				break
 			}
-			p := newPeer(t)
+			if trusted[remoteID] {
+				t.flags |= trustedDail
+			}
+			if reserved[remoteID] {
+				t.flags |= reservedDail
+			}
+			if err := server.checkPeer(t, peers); err != nil {
+				log.Debug("Rejected peer", "id", remoteID, "err", err)
+				peerRejectMeter.Mark(1)
+				// Don't hold up the loop for slow subscribers
+				go server.peerFeed.Send(&PeerEvent{
+					Type:          PeerEventTypeReject,
+					Peer:          remoteID,
+					Time:          time.Now(),
+					Error:         err.Error(),
+					RemoteAddress: t.Conn.Conn().RemoteMultiaddr().String(),
+					Inbound:       t.flags&inboundDail != 0,
+				})
+				t.cont <- err
+				break
+			}
+			p := newPeer(t, !server.DisableCompression)
+			p.server = server
 
 			if server.EnableMsgEvents {
 				p.events = &server.peerFeed
 			}
 			go server.runPeer(p)
 			peers[p.RemoteID()] = p
+			peersGauge.Update(int64(len(peers)))
 			log.Info("yhx-test", "peers", peers)
+			t.cont <- nil
 
 		case t := <-server.addstatic:
 			tasker.addStatic(t)
@@ -264,6 +406,40 @@ func (server *Server) run(ctx context.Context, tasker taskworker) {
 				p.Disconnect(DiscRequested)
 			}
 
+		case n := <-server.addtrusted:
+			// Mark the peer trusted, redialling it whenever it drops
+			trusted[n.Id] = true
+			tasker.addPersistent(n, trustedDail)
+			if p, ok := peers[n.Id]; ok {
+				p.set(trustedDail, true)
+			}
+		case n := <-server.removetrusted:
+			// Unmark the peer, the connection is kept until it drops
+			delete(trusted, n.Id)
+			tasker.removePersistent(n.Id, trustedDail)
+			if p, ok := peers[n.Id]; ok {
+				p.set(trustedDail, false)
+			}
+		case ids := <-server.setlistnodes:
+			tasker.setListNodes(ids)
+
+		case nodes := <-server.setreserved:
+			// Replace the reserved set
+			for id := range reserved {
+				tasker.removePersistent(id, reservedDail)
+				if p, ok := peers[id]; ok {
+					p.set(reservedDail, false)
+				}
+			}
+			reserved = make(map[peer.ID]bool, len(nodes))
+			for _, n := range nodes {
+				reserved[n.Id] = true
+				tasker.addPersistent(n, reservedDail)
+				if p, ok := peers[n.Id]; ok {
+					p.set(reservedDail, true)
+				}
+			}
+
 		case op := <-server.peerOp:
 			// This channel is used by Peers and PeerCount.
 			op(peers)
@@ -274,14 +450,59 @@ func (server *Server) run(ctx context.Context, tasker taskworker) {
 			//log.Debug("Removing p2p peer", "peers", len(peers)-1, "req", "err", pd.err)
 			// fmt.Println("Del peer", pd.RemoteID())
 			delete(peers, pd.RemoteID())
+			peersGauge.Update(int64(len(peers)))
+
+		case <-server.quit:
+			// Disconnect all peers and wait until they are gone, runPeer
+			// doesn't give up sending on delpeer.
+			for _, p := range peers {
+				p.Disconnect(DiscQuitting)
+			}
+			for len(peers) > 0 {
+				pd := <-server.delpeer
+				delete(peers, pd.RemoteID())
+			}
+			peersGauge.Update(0)
+			return
 		}
 	}
 }
 
+// checkPeer decides whether the peer of the stream may be added. Trusted and
+// reserved peers bypass the peer limit.
+func (server *Server) checkPeer(s *Stream, peers map[peer.ID]*Peer) error {
+	switch {
+	case s.flags&(trustedDail|reservedDail) == 0 && len(peers) >= server.MaxPeers:
+		return DiscTooManyPeers
+	case s.Conn.Conn().RemotePeer() == server.host.ID():
+		return DiscSelf
+	case server.bans.banned(s.Conn.Conn().RemotePeer(), remoteIP(s.Conn.Conn().RemoteMultiaddr())) != nil:
+		return DiscBanned
+	case !server.permitted(s.Conn.Conn().RemotePeer()):
+		return DiscNotAllowed
+	default:
+		return nil
+	}
+}
+
+// Stop disconnects all peers and shuts down the host. It blocks until the
+// server is stopped, a stopped server can be started again.
 func (server *Server) Stop() {
+	server.lock.Lock()
+	if !server.running {
+		server.lock.Unlock()
+		return
+	}
 	log.Info("Server is Stopping!")
-	defer server.cancel()
-	return
+	server.running = false
+	close(server.quit)
+	server.lock.Unlock()
+
+	server.cancel()
+	server.loopWG.Wait()
+	if err := server.host.Close(); err != nil {
+		log.Debug("Failed to close host", "err", err)
+	}
 }
 
 func (server *Server) AddPeer(ctx context.Context, node *Node) {
@@ -302,6 +523,40 @@ func (server *Server) RemovePeer(node *Node) {
 	}
 }
 
+// AddTrustedPeer adds the given node to a reserved whitelist which allows the
+// node to always connect, even if the slots are full. The node is redialled
+// with backoff whenever it drops.
+func (server *Server) AddTrustedPeer(node *Node) {
+	server.host.Peerstore().AddAddrs(node.Id, []ma.Multiaddr{node.Addr}, peerstore.PermanentAddrTTL)
+
+	select {
+	case server.addtrusted <- node:
+	case <-server.quit:
+	}
+}
+
+// RemoveTrustedPeer removes the given node from the trusted peer set.
+func (server *Server) RemoveTrustedPeer(node *Node) {
+	select {
+	case server.removetrusted <- node:
+	case <-server.quit:
+	}
+}
+
+// SetReservedPeers replaces the set of reserved peers. Like trusted peers they
+// bypass MaxPeers and are redialled whenever they drop, the set is meant for
+// the peers the consensus engine needs, e.g. the current witnesses.
+func (server *Server) SetReservedPeers(nodes []*Node) {
+	for _, node := range nodes {
+		server.host.Peerstore().AddAddrs(node.Id, []ma.Multiaddr{node.Addr}, peerstore.PermanentAddrTTL)
+	}
+
+	select {
+	case server.setreserved <- nodes:
+	case <-server.quit:
+	}
+}
+
 func (server *Server) SubscribeEvents(ch chan *PeerEvent) event.Subscription {
 	return server.peerFeed.Subscribe(ch)
 }
@@ -347,9 +602,10 @@ func (server *Server) GetPeerByRemoteID(s inet.Stream) *Peer {
 	var p *Peer
 
 	// always try to new this peer
-	err := server.dispatch(&Stream{Conn: s, Protocols: server.protomap[PID]}, server.addpeer)
+	err := server.dispatch(&Stream{Conn: s, Protocols: server.protomap[string(s.Protocol())], flags: inboundDail}, server.addpeer)
 	if err != nil {
-		log.Error("GetPeerByRemoteID()", "new peer error", err)
+		log.Debug("GetPeerByRemoteID()", "new peer error", err)
+		s.Reset()
 		return nil
 	}
 
@@ -370,6 +626,17 @@ func (server *Server) GetPeerByRemoteID(s inet.Stream) *Peer {
 	return p
 }
 
+// peer returns the connected peer with the id, or nil if there is none.
+func (server *Server) peer(id peer.ID) *Peer {
+	var p *Peer
+	select {
+	case server.peerOp <- func(peers map[peer.ID]*Peer) { p = peers[id] }:
+		<-server.peerOpDone
+	case <-server.quit:
+	}
+	return p
+}
+
 func (server *Server) Peers() []*Peer {
 	var ps []*Peer
 	select {
@@ -403,15 +670,33 @@ func (server *Server) maxDialedConns() int {
 }
 
 func (server *Server) SetupStream(ctx context.Context, target peer.ID, pid string) error {
+	return server.setupStream(ctx, target, pid, dynDialedDail)
+}
+
+// setupStream dials the target, flags tell why the peer is dialled.
+func (server *Server) setupStream(ctx context.Context, target peer.ID, pid string, flags dialFlag) error {
+	if server.bans.banned(target, nil) != nil {
+		return DiscBanned
+	}
+	if !server.permitted(target) {
+		return DiscNotAllowed
+	}
 	// log.Info("yhx-test", "SetupStream target", target, "pid", pid)
-	s, err := server.host.NewStream(ctx, target, protocol.ID(pid))
+	pids := []protocol.ID{protocol.ID(pid)}
+	if pid == PID {
+		// Prefer a stream per protocol, then the binary framing, nodes not
+		// supporting either fall back to json
+		pids = []protocol.ID{HandshakePID, FramedPID, PID}
+	}
+	s, err := server.host.NewStream(ctx, target, pids...)
 	if err != nil {
 		// fmt.Println("SetupStream NewStream Error: ", err)
 		return err
 	}
+	if s.Protocol() == HandshakePID {
+		return server.setupProtocols(ctx, s, flags)
+	}
 
-	// handle response message
-	go server.HandleStream(s)
 	/* rw := bufio.NewReadWriter(bufio.NewReader(s), bufio.NewWriter(s))
 	vntMessenger := &VNTMessenger{
 		protocol: Protocol{},
@@ -425,29 +710,44 @@ func (server *Server) SetupStream(ctx context.Context, target peer.ID, pid strin
 		return err
 	} */
 
-	err = server.dispatch(&Stream{Conn: s, Protocols: server.protomap[pid]}, server.addpeer)
+	err = server.dispatch(&Stream{Conn: s, Protocols: server.protomap[string(s.Protocol())], flags: flags}, server.addpeer)
 	if err != nil {
 		fmt.Println("SetupStream dispatch Error: ", err)
+		s.Reset()
 		return err
 	}
+	// handle response message, the peer is registered by now
+	go server.HandleStream(s)
 	return nil
 }
 
 func (server *Server) runPeer(p *Peer) {
+	var (
+		remoteAddr = p.rw.Conn().RemoteMultiaddr().String()
+		inbound    = p.is(inboundDail)
+	)
 	// broadcast peer add
+	peerAddMeter.Mark(1)
 	server.peerFeed.Send(&PeerEvent{
-		Type: PeerEventTypeAdd,
-		Peer: p.RemoteID(),
+		Type:          PeerEventTypeAdd,
+		Peer:          p.RemoteID(),
+		Time:          time.Now(),
+		RemoteAddress: remoteAddr,
+		Inbound:       inbound,
 	})
 
 	// run the protocol
 	remoteRequested, err := p.run()
 
 	// broadcast peer drop
+	peerDropMeter.Mark(1)
 	server.peerFeed.Send(&PeerEvent{
-		Type:  PeerEventTypeDrop,
-		Peer:  p.RemoteID(),
-		Error: err.Error(),
+		Type:          PeerEventTypeDrop,
+		Peer:          p.RemoteID(),
+		Time:          time.Now(),
+		Error:         err.Error(),
+		RemoteAddress: remoteAddr,
+		Inbound:       inbound,
 	})
 
 	// Note: run waits for existing peers to be sent on srv.delpeer
@@ -455,13 +755,21 @@ func (server *Server) runPeer(p *Peer) {
 	server.delpeer <- peerDrop{p, err, remoteRequested}
 }
 
+// dispatch sends the stream to the run loop and waits until it's accepted or
+// rejected.
 func (server *Server) dispatch(s *Stream, stage chan<- *Stream) error {
+	s.cont = make(chan error, 1)
 	select {
 	case stage <- s:
 	case <-server.quit:
 		return errServerStopped
 	}
-	return nil
+	select {
+	case err := <-s.cont:
+		return err
+	case <-server.quit:
+		return errServerStopped
+	}
 }
 
 type NodeInfo struct {
@@ -505,5 +813,8 @@ type taskworker interface {
 	newTasks(map[peer.ID]*Peer) []task
 	addStatic(n *Node)
 	removeStatic(n *Node)
+	addPersistent(n *Node, flag dialFlag)
+	removePersistent(id peer.ID, flag dialFlag)
+	setListNodes(ids []peer.ID)
 	taskDone(t task)
 }
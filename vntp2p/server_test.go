@@ -0,0 +1,94 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package vntp2p
+
+import (
+	"testing"
+	"time"
+
+	p2phost "github.com/libp2p/go-libp2p-host"
+	libp2p "github.com/libp2p/go-libp2p-peer"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// testHost is a host which only knows its own id.
+type testHost struct {
+	p2phost.Host
+	id libp2p.ID
+}
+
+func (h *testHost) ID() libp2p.ID { return h.id }
+
+// Tests that trusted and reserved peers are added beyond the peer limit, but
+// are still subject to bans and the allow-list.
+func TestCheckPeer(t *testing.T) {
+	banned, _ := libp2p.IDB58Decode(testBanPeer)
+	remote, _ := libp2p.IDB58Decode(testBanPeer2)
+
+	bans := newBanList(nil)
+	bans.add(testBanPeer, time.Hour, "test")
+	bans.add("10.0.0.0/8", time.Hour, "test")
+
+	allow, _ := loadAllowList("")
+	allow.add(remote)
+
+	tests := []struct {
+		name   string
+		id     libp2p.ID
+		ip     string
+		flags  dialFlag
+		peers  int
+		allow  *allowList
+		result error
+	}{
+		{name: "free slot", id: remote, peers: 1},
+		{name: "free inbound slot", id: remote, flags: inboundDail, peers: 1},
+		{name: "full", id: remote, peers: 2, result: DiscTooManyPeers},
+		{name: "full inbound", id: remote, flags: inboundDail, peers: 2, result: DiscTooManyPeers},
+		{name: "full static", id: remote, flags: staticDialedDail, peers: 2, result: DiscTooManyPeers},
+		{name: "full trusted", id: remote, flags: trustedDail, peers: 2},
+		{name: "full reserved", id: remote, flags: reservedDail, peers: 2},
+		{name: "full trusted inbound", id: remote, flags: trustedDail | inboundDail, peers: 5},
+		{name: "self", id: "self", flags: trustedDail, result: DiscSelf},
+		{name: "banned peer", id: banned, flags: trustedDail, result: DiscBanned},
+		{name: "banned network", id: remote, ip: "10.1.2.3", flags: reservedDail, result: DiscBanned},
+		{name: "unbanned network", id: remote, ip: "192.168.0.1"},
+		{name: "allowed", id: remote, allow: allow},
+		{name: "not allowed", id: "other", allow: allow, result: DiscNotAllowed},
+		{name: "trusted not allowed", id: "other", flags: trustedDail, peers: 2, allow: allow, result: DiscNotAllowed},
+	}
+	for _, tt := range tests {
+		server := &Server{
+			Config: Config{MaxPeers: 2},
+			host:   &testHost{id: "self"},
+			bans:   bans,
+			allow:  tt.allow,
+		}
+		peers := make(map[libp2p.ID]*Peer)
+		for i := 0; i < tt.peers; i++ {
+			peers[libp2p.ID(string(rune('a'+i)))] = new(Peer)
+		}
+		conn := &testConn{remote: tt.id}
+		if tt.ip != "" {
+			conn.addr = ma.StringCast("/ip4/" + tt.ip + "/tcp/30303")
+		}
+		s := &Stream{Conn: &testStream{conn: conn}, flags: tt.flags}
+		if err := server.checkPeer(s, peers); err != tt.result {
+			t.Errorf("%s: result mismatch: have %v, want %v", tt.name, err, tt.result)
+		}
+	}
+}
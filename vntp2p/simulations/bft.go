@@ -0,0 +1,220 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulations
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	peer "github.com/libp2p/go-libp2p-peer"
+	"github.com/vntchain/go-vnt/vntp2p"
+)
+
+// BftProtocolName is the name of the built-in protocol modelling the BFT mesh
+// of the consensus. Witness nodes vote on a round every bftInterval and all
+// nodes relay the votes they didn't see before to their other peers, so the
+// witnesses don't have to be connected directly. A node commits a round once
+// it has the votes of a quorum of the witnesses.
+const BftProtocolName = "bft"
+
+const (
+	bftInterval = 200 * time.Millisecond
+
+	// Votes more than bftHistory rounds older than the newest one are dropped.
+	bftHistory = 16
+
+	// maxQueuedVotes is the number of votes queued for relaying to a peer,
+	// further votes are dropped until the queue drains.
+	maxQueuedVotes = 256
+)
+
+const voteMsg vntp2p.MessageType = 0
+
+// bftVote is the vote of a witness on a round.
+type bftVote struct {
+	Round   uint64
+	Witness string // Name of the witness node
+}
+
+// BftInfo is the node info of the bft protocol.
+type BftInfo struct {
+	Witness   bool   `json:"witness"`   // Whether the node votes
+	Committed uint64 `json:"committed"` // Rounds committed
+	Last      uint64 `json:"last"`      // Last committed round
+	Relayed   uint64 `json:"relayed"`   // Votes queued for relaying to peers
+}
+
+type bftService struct {
+	node *Node
+
+	lock      sync.Mutex
+	peers     map[peer.ID]chan bftVote
+	votes     map[uint64]map[string]bool // Witnesses seen voting, by round
+	newest    uint64                     // Newest round with votes
+	committed uint64
+	last      uint64
+	relayed   uint64
+}
+
+// NewBftProtocol creates the bft protocol of a node, it votes if the node is
+// configured as a witness.
+func NewBftProtocol(node *Node) vntp2p.Protocol {
+	s := &bftService{
+		node:  node,
+		peers: make(map[peer.ID]chan bftVote),
+		votes: make(map[uint64]map[string]bool),
+	}
+	return vntp2p.Protocol{
+		Name:     BftProtocolName,
+		Version:  1,
+		Length:   1,
+		Run:      s.run,
+		NodeInfo: s.info,
+	}
+}
+
+func (s *bftService) info() interface{} {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return &BftInfo{
+		Witness:   s.node.config.Witness,
+		Committed: s.committed,
+		Last:      s.last,
+		Relayed:   s.relayed,
+	}
+}
+
+// quorum returns the number of votes committing a round, two thirds of the
+// witnesses of the simulation plus one.
+func (s *bftService) quorum() int {
+	witnesses := 0
+	for _, node := range s.node.sim.Nodes() {
+		if node.config.Witness {
+			witnesses++
+		}
+	}
+	return 2*witnesses/3 + 1
+}
+
+// isWitness reports whether the vote is from a witness of the simulation.
+func (s *bftService) isWitness(name string) bool {
+	node := s.node.sim.Node(name)
+	return node != nil && node.config.Witness
+}
+
+// deliver records a vote received from a peer, or cast by the node itself if
+// from is empty, and relays it to the other peers if it is new.
+func (s *bftService) deliver(vote bftVote, from peer.ID) {
+	if !s.isWitness(vote.Witness) {
+		return
+	}
+	quorum := s.quorum()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if vote.Round+bftHistory < s.newest || s.votes[vote.Round][vote.Witness] {
+		return
+	}
+	if vote.Round > s.newest {
+		s.newest = vote.Round
+		for round := range s.votes {
+			if round+bftHistory < s.newest {
+				delete(s.votes, round)
+			}
+		}
+	}
+	voters, ok := s.votes[vote.Round]
+	if !ok {
+		voters = make(map[string]bool)
+		s.votes[vote.Round] = voters
+	}
+	voters[vote.Witness] = true
+	if len(voters) == quorum {
+		s.committed++
+		if vote.Round > s.last {
+			s.last = vote.Round
+		}
+	}
+	for id, queue := range s.peers {
+		if id == from {
+			continue
+		}
+		select {
+		case queue <- vote:
+			s.relayed++
+		default:
+		}
+	}
+}
+
+func (s *bftService) run(p *vntp2p.Peer, rw vntp2p.MsgReadWriter) error {
+	id := p.RemoteID()
+	queue := make(chan bftVote, maxQueuedVotes)
+
+	s.lock.Lock()
+	s.peers[id] = queue
+	s.lock.Unlock()
+	defer func() {
+		s.lock.Lock()
+		delete(s.peers, id)
+		s.lock.Unlock()
+	}()
+
+	errc := make(chan error, 1)
+	go func() {
+		for {
+			msg, err := rw.ReadMsg()
+			if err != nil {
+				errc <- err
+				return
+			}
+			if msg.Body.Type != voteMsg {
+				errc <- fmt.Errorf("unexpected message %d", msg.Body.Type)
+				return
+			}
+			var vote bftVote
+			if err := msg.Decode(&vote); err != nil {
+				errc <- err
+				return
+			}
+			s.deliver(vote, id)
+		}
+	}()
+
+	ticker := time.NewTicker(bftInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			// Votes are only relayed once, so every connection may cast
+			// the vote of the round
+			if s.node.config.Witness {
+				round := uint64(time.Now().UnixNano() / int64(bftInterval))
+				s.deliver(bftVote{Round: round, Witness: s.node.Name}, "")
+			}
+		case vote := <-queue:
+			if err := vntp2p.Send(rw, BftProtocolName, voteMsg, vote); err != nil {
+				return err
+			}
+		case err := <-errc:
+			return err
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vntchain/go-vnt/crypto"
+	"github.com/vntchain/go-vnt/vntp2p"
+)
+
+// mapResolver serves TXT records from a map.
+type mapResolver map[string]string
+
+func (r mapResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if txt, ok := r[name]; ok {
+		return []string{txt}, nil
+	}
+	return nil, fmt.Errorf("no such name %s", name)
+}
+
+func testNodeList(t *testing.T, n int) *vntp2p.NodeList {
+	list := &vntp2p.NodeList{Seq: 3}
+	for i := 0; i < n; i++ {
+		key, _ := crypto.GenerateKey()
+		id := vntp2p.PubkeyID(&key.PublicKey)
+		list.Nodes = append(list.Nodes, fmt.Sprintf("/ip4/10.0.%d.%d/tcp/30303/ipfs/%s", i/256, i%256, id.Pretty()))
+	}
+	return list
+}
+
+func TestBootstrapDNSTree(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	list := testNodeList(t, 40)
+	if err := list.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+	records := mapResolver(vntp2p.MakeDNSTree("nodes.example.org", list))
+
+	fetched, err := vntp2p.NewDNSSource("nodes.example.org", &key.PublicKey, records).Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if fetched.Seq != list.Seq || strings.Join(fetched.Nodes, " ") != strings.Join(list.Nodes, " ") {
+		t.Fatalf("fetched list mismatch: got seq %d, %d nodes", fetched.Seq, len(fetched.Nodes))
+	}
+
+	// Lists signed by another key are rejected
+	other, _ := crypto.GenerateKey()
+	if _, err := vntp2p.NewDNSSource("nodes.example.org", &other.PublicKey, records).Fetch(context.Background()); err == nil {
+		t.Error("list of another signer accepted")
+	}
+
+	// Tampered entries are rejected
+	for name, txt := range records {
+		if strings.HasPrefix(txt, "vnt-node:") {
+			records[name] = strings.Replace(txt, "/tcp/30303/", "/tcp/30304/", 1)
+			break
+		}
+	}
+	if _, err := vntp2p.NewDNSSource("nodes.example.org", &key.PublicKey, records).Fetch(context.Background()); err == nil {
+		t.Error("tampered tree accepted")
+	}
+}
+
+func TestBootstrapFileList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vntp2p-bootstrap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sim := NewSimulation(LinkConfig{})
+	defer sim.Shutdown()
+
+	target, err := sim.AddNode(NodeConfig{Protocols: []string{PingProtocolName}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := crypto.GenerateKey()
+	list := &vntp2p.NodeList{Seq: 1, Nodes: []string{fmt.Sprintf("%s/ipfs/%s", target.Addr, target.ID.Pretty())}}
+	if err := list.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(list)
+	path := filepath.Join(dir, "nodes.json")
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	source := fmt.Sprintf("file://%x@%s", crypto.CompressPubkey(&key.PublicKey), path)
+	if _, err := sim.AddNode(NodeConfig{Protocols: []string{PingProtocolName}, BootstrapLists: []string{source}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.StartAll(); err != nil {
+		t.Fatal(err)
+	}
+	waitPeers(t, sim, map[string]int{"node01": 1, "node02": 1})
+}
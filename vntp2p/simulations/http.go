@@ -0,0 +1,383 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/vntchain/go-vnt/crypto"
+	"github.com/vntchain/go-vnt/vntp2p"
+)
+
+// The simulation is controlled through a REST API:
+//
+//	GET    /                         network info
+//	GET    /nodes                    list nodes
+//	POST   /nodes                    create a node
+//	GET    /nodes/:node              node info
+//	GET    /nodes/:node/peers        peers of a running node
+//	POST   /nodes/:node/start        start a node
+//	POST   /nodes/:node/stop         stop a node
+//	POST   /nodes/:node/conn/:peer   connect a node to a peer
+//	DELETE /nodes/:node/conn/:peer   disconnect a node from a peer
+//	POST   /links                    set the quality of a link
+//	POST   /partition                partition the network
+//	DELETE /partition                heal the network
+//	POST   /scenario                 run a scenario
+
+// NetworkInfo is the state of the simulation.
+type NetworkInfo struct {
+	Nodes []*NodeInfo `json:"nodes"`
+	Conns [][2]string `json:"conns"`
+}
+
+// CreateNodeRequest is the body of a node creation request.
+type CreateNodeRequest struct {
+	NodeConfig
+	Key string `json:"key,omitempty"` // Hex encoded private key, random if empty
+}
+
+// LinkRequest is the body of a link request, it changes all links if no nodes
+// are given.
+type LinkRequest struct {
+	A    string     `json:"a,omitempty"`
+	B    string     `json:"b,omitempty"`
+	Link LinkConfig `json:"link"`
+}
+
+// Server is the HTTP API of a simulation.
+type Server struct {
+	sim    *Simulation
+	router *httprouter.Router
+}
+
+// NewServer returns the HTTP API of the simulation.
+func NewServer(sim *Simulation) *Server {
+	s := &Server{sim: sim, router: httprouter.New()}
+	s.router.GET("/", s.getNetwork)
+	s.router.GET("/nodes", s.getNodes)
+	s.router.POST("/nodes", s.createNode)
+	s.router.GET("/nodes/:node", s.getNode)
+	s.router.GET("/nodes/:node/peers", s.getPeers)
+	s.router.POST("/nodes/:node/start", s.startNode)
+	s.router.POST("/nodes/:node/stop", s.stopNode)
+	s.router.POST("/nodes/:node/conn/:peer", s.connectNode)
+	s.router.DELETE("/nodes/:node/conn/:peer", s.disconnectNode)
+	s.router.POST("/links", s.setLink)
+	s.router.POST("/partition", s.partition)
+	s.router.DELETE("/partition", s.heal)
+	s.router.POST("/scenario", s.runScenario)
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.router.ServeHTTP(w, req)
+}
+
+func (s *Server) getNetwork(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	info := &NetworkInfo{Nodes: make([]*NodeInfo, 0), Conns: s.sim.Conns()}
+	for _, node := range s.sim.Nodes() {
+		info.Nodes = append(info.Nodes, node.Info())
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (s *Server) getNodes(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	infos := make([]*NodeInfo, 0)
+	for _, node := range s.sim.Nodes() {
+		infos = append(infos, node.Info())
+	}
+	writeJSON(w, http.StatusOK, infos)
+}
+
+func (s *Server) createNode(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var body CreateNodeRequest
+	if !readJSON(w, req, &body) {
+		return
+	}
+	if body.Key != "" {
+		key, err := crypto.HexToECDSA(body.Key)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		body.PrivateKey = key
+	}
+	node, err := s.sim.AddNode(body.NodeConfig)
+	if err != nil {
+		status := http.StatusBadRequest
+		if _, ok := err.(*nodeError); ok {
+			status = statusOf(err)
+		}
+		writeError(w, status, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, node.Info())
+}
+
+func (s *Server) getNode(w http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	node, err := s.sim.node(params.ByName("node"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, node.Info())
+}
+
+func (s *Server) getPeers(w http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	peers, err := s.sim.Peers(params.ByName("node"))
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, peers)
+}
+
+func (s *Server) startNode(w http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	s.nodeAction(w, params, s.sim.Start)
+}
+
+func (s *Server) stopNode(w http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	s.nodeAction(w, params, s.sim.Stop)
+}
+
+func (s *Server) nodeAction(w http.ResponseWriter, params httprouter.Params, action func(string) error) {
+	name := params.ByName("node")
+	if err := action(name); err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	s.getNode(w, nil, params)
+}
+
+func (s *Server) connectNode(w http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	s.connAction(w, params, s.sim.Connect)
+}
+
+func (s *Server) disconnectNode(w http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	s.connAction(w, params, s.sim.Disconnect)
+}
+
+func (s *Server) connAction(w http.ResponseWriter, params httprouter.Params, action func(string, string) error) {
+	if err := action(params.ByName("node"), params.ByName("peer")); err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) setLink(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var body LinkRequest
+	if !readJSON(w, req, &body) {
+		return
+	}
+	if body.A == "" && body.B == "" {
+		s.sim.Network().SetDefaultLink(body.Link)
+	} else if err := s.sim.SetLink(body.A, body.B, body.Link); err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) partition(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var groups [][]string
+	if !readJSON(w, req, &groups) {
+		return
+	}
+	if err := s.sim.Partition(groups); err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) heal(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	s.sim.Heal()
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) runScenario(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var scenario Scenario
+	if !readJSON(w, req, &scenario) {
+		return
+	}
+	if err := s.sim.Run(req.Context(), &scenario); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	s.getNetwork(w, req, nil)
+}
+
+// statusOf maps simulation errors to HTTP status codes.
+func statusOf(err error) int {
+	if err, ok := err.(*nodeError); ok {
+		switch err.err {
+		case errNodeNotFound:
+			return http.StatusNotFound
+		case errNodeExists, errNodeRunning, errNodeNotRunning:
+			return http.StatusConflict
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+func readJSON(w http.ResponseWriter, req *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(req.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	http.Error(w, err.Error(), status)
+}
+
+// Client is a client of the simulation HTTP API.
+type Client struct {
+	URL    string
+	client *http.Client
+}
+
+// NewClient returns a client of the API at the base URL.
+func NewClient(url string) *Client {
+	return &Client{URL: url, client: http.DefaultClient}
+}
+
+// GetNetwork returns the nodes and connections of the simulation.
+func (c *Client) GetNetwork() (*NetworkInfo, error) {
+	info := new(NetworkInfo)
+	return info, c.send(http.MethodGet, "/", nil, info)
+}
+
+// GetNodes returns the nodes.
+func (c *Client) GetNodes() ([]*NodeInfo, error) {
+	var nodes []*NodeInfo
+	return nodes, c.send(http.MethodGet, "/nodes", nil, &nodes)
+}
+
+// CreateNode creates a node.
+func (c *Client) CreateNode(config *CreateNodeRequest) (*NodeInfo, error) {
+	node := new(NodeInfo)
+	return node, c.send(http.MethodPost, "/nodes", config, node)
+}
+
+// GetNode returns a node.
+func (c *Client) GetNode(name string) (*NodeInfo, error) {
+	node := new(NodeInfo)
+	return node, c.send(http.MethodGet, "/nodes/"+name, nil, node)
+}
+
+// GetPeers returns the peers of a running node.
+func (c *Client) GetPeers(name string) ([]*vntp2p.PeerInfo, error) {
+	var peers []*vntp2p.PeerInfo
+	return peers, c.send(http.MethodGet, "/nodes/"+name+"/peers", nil, &peers)
+}
+
+// StartNode starts a node.
+func (c *Client) StartNode(name string) error {
+	return c.send(http.MethodPost, "/nodes/"+name+"/start", nil, nil)
+}
+
+// StopNode stops a node.
+func (c *Client) StopNode(name string) error {
+	return c.send(http.MethodPost, "/nodes/"+name+"/stop", nil, nil)
+}
+
+// ConnectNode connects a node to a peer.
+func (c *Client) ConnectNode(name, peer string) error {
+	return c.send(http.MethodPost, "/nodes/"+name+"/conn/"+peer, nil, nil)
+}
+
+// DisconnectNode disconnects a node from a peer.
+func (c *Client) DisconnectNode(name, peer string) error {
+	return c.send(http.MethodDelete, "/nodes/"+name+"/conn/"+peer, nil, nil)
+}
+
+// SetLink sets the quality of the link between two nodes, or of all links if
+// the names are empty.
+func (c *Client) SetLink(a, b string, link LinkConfig) error {
+	return c.send(http.MethodPost, "/links", &LinkRequest{A: a, B: b, Link: link}, nil)
+}
+
+// Partition splits the network into groups of nodes.
+func (c *Client) Partition(groups [][]string) error {
+	return c.send(http.MethodPost, "/partition", groups, nil)
+}
+
+// Heal removes the partitions.
+func (c *Client) Heal() error {
+	return c.send(http.MethodDelete, "/partition", nil, nil)
+}
+
+// RunScenario runs a scenario and returns the resulting network.
+func (c *Client) RunScenario(ctx context.Context, scenario *Scenario) (*NetworkInfo, error) {
+	info := new(NetworkInfo)
+	return info, c.sendContext(ctx, http.MethodPost, "/scenario", scenario, info)
+}
+
+func (c *Client) send(method, path string, in, out interface{}) error {
+	return c.sendContext(context.Background(), method, path, in, out)
+}
+
+func (c *Client) sendContext(ctx context.Context, method, path string, in, out interface{}) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, c.URL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("%s: %s", res.Status, bytes.TrimSpace(msg))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return errors.New("invalid response: " + err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulations
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPNodes(t *testing.T) {
+	sim := NewSimulation(LinkConfig{})
+	defer sim.Shutdown()
+	srv := httptest.NewServer(NewServer(sim))
+	defer srv.Close()
+	client := NewClient(srv.URL)
+
+	for _, name := range []string{"a", "b"} {
+		req := &CreateNodeRequest{NodeConfig: NodeConfig{Name: name, Protocols: []string{PingProtocolName}}}
+		if _, err := client.CreateNode(req); err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+		if err := client.StartNode(name); err != nil {
+			t.Fatalf("failed to start %s: %v", name, err)
+		}
+	}
+	if _, err := client.CreateNode(&CreateNodeRequest{NodeConfig: NodeConfig{Name: "a"}}); err == nil || !strings.HasPrefix(err.Error(), "409") {
+		t.Errorf("duplicate node: got error %v, want 409", err)
+	}
+	if _, err := client.GetNode("c"); err == nil || !strings.HasPrefix(err.Error(), "404") {
+		t.Errorf("unknown node: got error %v, want 404", err)
+	}
+	if err := client.SetLink("a", "b", LinkConfig{Latency: 10 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	if link := sim.Network().Link(sim.Node("a").IP, sim.Node("b").IP); link.Latency != 10*time.Millisecond {
+		t.Errorf("link latency mismatch: got %v, want 10ms", link.Latency)
+	}
+	if err := client.ConnectNode("a", "b"); err != nil {
+		t.Fatal(err)
+	}
+	waitPeers(t, sim, map[string]int{"a": 1, "b": 1})
+
+	network, err := client.GetNetwork()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(network.Nodes) != 2 || len(network.Conns) != 1 {
+		t.Errorf("network mismatch: got %d nodes and %d conns, want 2 and 1", len(network.Nodes), len(network.Conns))
+	}
+	peers, err := client.GetPeers("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 1 || peers[0].Network.RemoteAddress != sim.Node("b").Addr.String() {
+		t.Errorf("peers of a mismatch: got %+v", peers)
+	}
+
+	if err := client.StopNode("b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.StopNode("b"); err == nil || !strings.HasPrefix(err.Error(), "409") {
+		t.Errorf("stopping stopped node: got error %v, want 409", err)
+	}
+	node, err := client.GetNode("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Running {
+		t.Error("stopped node reported running")
+	}
+}
+
+func TestScenario(t *testing.T) {
+	const input = `{
+		"link": {"latency": "5ms"},
+		"nodes": [
+			{"name": "a", "protocols": ["ping"]},
+			{"name": "b", "protocols": ["ping"]},
+			{"name": "c", "protocols": ["ping"]}
+		],
+		"steps": [
+			{"action": "start"},
+			{"action": "connect", "nodes": ["a", "b"]},
+			{"action": "connect", "nodes": ["b", "c"]},
+			{"action": "expect-peers", "nodes": ["b"], "peers": 2, "duration": "20s"},
+			{"action": "partition", "groups": [["a"], ["b", "c"]]},
+			{"action": "expect-peers", "nodes": ["a"], "peers": 0, "duration": "20s"},
+			{"action": "link", "nodes": ["a", "b"], "link": {"latency": "1ms", "loss": 0.1}},
+			{"action": "heal"},
+			{"action": "expect-peers", "nodes": ["a", "c"], "peers": 1, "duration": "20s"}
+		]
+	}`
+	sim := NewSimulation(LinkConfig{})
+	defer sim.Shutdown()
+	srv := httptest.NewServer(NewServer(sim))
+	defer srv.Close()
+
+	var scenario Scenario
+	if err := json.Unmarshal([]byte(input), &scenario); err != nil {
+		t.Fatal(err)
+	}
+	network, err := NewClient(srv.URL).RunScenario(context.Background(), &scenario)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(network.Conns) != 2 {
+		t.Errorf("connections mismatch: got %v, want a-b and b-c", network.Conns)
+	}
+
+	// Failing expectations fail the scenario
+	err = sim.Run(context.Background(), &Scenario{Steps: []Step{
+		{Action: "expect-peers", Nodes: []string{"a"}, Peers: 2, Duration: "100ms"},
+	}})
+	if err == nil || !strings.HasPrefix(err.Error(), "step 1 (expect-peers)") {
+		t.Errorf("unmet expectation: got error %v", err)
+	}
+}
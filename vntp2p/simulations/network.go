@@ -0,0 +1,580 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	libp2p "github.com/libp2p/go-libp2p"
+	peer "github.com/libp2p/go-libp2p-peer"
+	tpt "github.com/libp2p/go-libp2p-transport"
+	tptu "github.com/libp2p/go-libp2p-transport-upgrader"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr-net"
+	mafmt "github.com/whyrusleeping/mafmt"
+)
+
+// The simulated network is a set of in-memory TCP-like connections between
+// addresses in 10.0.0.0/8. Each direction of a connection delivers the
+// written data in order after the latency of the link, writes which are lost
+// are retransmitted like TCP would, which only adds delay.
+
+// minRetransmitDelay is the delay a lost write adds on top of two round
+// trips.
+const minRetransmitDelay = 200 * time.Millisecond
+
+var (
+	errConnRefused = errors.New("connection refused")
+	errUnreachable = errors.New("network is unreachable")
+	errConnReset   = errors.New("connection reset by peer")
+	errConnClosed  = errors.New("use of closed network connection")
+	errListenerUp  = errors.New("address already in use")
+)
+
+// LinkConfig is the quality of the link between two nodes.
+type LinkConfig struct {
+	Latency time.Duration // One-way delay
+	Loss    float64       // Probability of a write being lost
+}
+
+type linkConfigJSON struct {
+	Latency string  `json:"latency,omitempty"`
+	Loss    float64 `json:"loss,omitempty"`
+}
+
+// MarshalJSON encodes the latency as a duration string like "50ms".
+func (l LinkConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(linkConfigJSON{Latency: l.Latency.String(), Loss: l.Loss})
+}
+
+// UnmarshalJSON decodes a link with a duration string as latency.
+func (l *LinkConfig) UnmarshalJSON(input []byte) error {
+	var dec linkConfigJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	*l = LinkConfig{Loss: dec.Loss}
+	if dec.Latency != "" {
+		latency, err := time.ParseDuration(dec.Latency)
+		if err != nil {
+			return fmt.Errorf("invalid latency: %v", err)
+		}
+		l.Latency = latency
+	}
+	if l.Loss < 0 || l.Loss >= 1 {
+		return fmt.Errorf("invalid loss %v, must be in [0, 1)", l.Loss)
+	}
+	return nil
+}
+
+// delay returns the time a write takes to arrive.
+func (l LinkConfig) delay() time.Duration {
+	d := l.Latency
+	if l.Loss > 0 && rand.Float64() < l.Loss {
+		d += minRetransmitDelay + 4*l.Latency
+	}
+	return d
+}
+
+type linkKey [2]string
+
+func makeLinkKey(a, b string) linkKey {
+	if a > b {
+		a, b = b, a
+	}
+	return linkKey{a, b}
+}
+
+// Network is a simulated IP network, libp2p hosts attach to it through the
+// transport of their address.
+type Network struct {
+	lock      sync.Mutex
+	link      LinkConfig // Quality of links without a config of their own
+	links     map[linkKey]LinkConfig
+	groups    map[string]int // Partition group of the addresses, nil if not partitioned
+	listeners map[string]*listener
+	conns     map[*conn]struct{}
+	lastIP    uint32
+	lastPort  int
+}
+
+// NewNetwork creates a network whose links have the given quality.
+func NewNetwork(link LinkConfig) *Network {
+	return &Network{
+		link:      link,
+		links:     make(map[linkKey]LinkConfig),
+		listeners: make(map[string]*listener),
+		conns:     make(map[*conn]struct{}),
+		lastPort:  49151,
+	}
+}
+
+// NewAddress allocates an unused IP address.
+func (n *Network) NewAddress() net.IP {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.lastIP++
+	return net.IPv4(10, byte(n.lastIP>>16), byte(n.lastIP>>8), byte(n.lastIP))
+}
+
+// Transport returns the libp2p option attaching a host to the network with
+// the given address.
+func (n *Network) Transport(ip net.IP) libp2p.Option {
+	return libp2p.Transport(func(u *tptu.Upgrader) *transport {
+		return &transport{net: n, ip: ip.To4(), upgrader: u}
+	})
+}
+
+// SetDefaultLink changes the quality of all links without a config of their
+// own.
+func (n *Network) SetDefaultLink(link LinkConfig) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.link = link
+}
+
+// SetLink changes the quality of the link between two addresses.
+func (n *Network) SetLink(a, b net.IP, link LinkConfig) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.links[makeLinkKey(a.String(), b.String())] = link
+}
+
+// Link returns the quality of the link between two addresses.
+func (n *Network) Link(a, b net.IP) LinkConfig {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return n.linkLocked(a.String(), b.String())
+}
+
+func (n *Network) linkLocked(a, b string) LinkConfig {
+	if link, ok := n.links[makeLinkKey(a, b)]; ok {
+		return link
+	}
+	return n.link
+}
+
+// Partition splits the network into groups which can't reach each other,
+// addresses not in any of the groups form one more group. Connections
+// between the groups are reset.
+func (n *Network) Partition(groups [][]net.IP) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.groups = make(map[string]int)
+	for i, group := range groups {
+		for _, ip := range group {
+			n.groups[ip.String()] = i + 1
+		}
+	}
+	for c := range n.conns {
+		if !n.reachable(c.local.IP.String(), c.remote.IP.String()) {
+			c.reset()
+		}
+	}
+}
+
+// Heal removes the partitions.
+func (n *Network) Heal() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.groups = nil
+}
+
+// reachable reports whether a can connect to b, the lock must be held.
+func (n *Network) reachable(a, b string) bool {
+	if n.groups == nil {
+		return true
+	}
+	return n.groups[a] == n.groups[b]
+}
+
+// delay returns the delay of a write from a to b.
+func (n *Network) delay(a, b string) time.Duration {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return n.linkLocked(a, b).delay()
+}
+
+func (n *Network) listen(ip net.IP, laddr ma.Multiaddr) (*listener, error) {
+	addr, err := manet.ToNetAddr(laddr)
+	if err != nil {
+		return nil, err
+	}
+	tcpaddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return nil, fmt.Errorf("can't listen on %s", laddr)
+	}
+	if !tcpaddr.IP.IsUnspecified() && !tcpaddr.IP.Equal(ip) {
+		return nil, fmt.Errorf("can't listen on %s: not the address of the node", laddr)
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	port := tcpaddr.Port
+	if port == 0 {
+		port = n.nextPort()
+	}
+	l := &listener{
+		net:    n,
+		addr:   &net.TCPAddr{IP: ip, Port: port},
+		conns:  make(chan *conn),
+		closed: make(chan struct{}),
+	}
+	if l.maddr, err = manet.FromNetAddr(l.addr); err != nil {
+		return nil, err
+	}
+	if _, ok := n.listeners[l.addr.String()]; ok {
+		return nil, errListenerUp
+	}
+	n.listeners[l.addr.String()] = l
+	return l, nil
+}
+
+func (n *Network) nextPort() int {
+	if n.lastPort++; n.lastPort > 65535 {
+		n.lastPort = 49152
+	}
+	return n.lastPort
+}
+
+func (n *Network) dial(ctx context.Context, ip net.IP, raddr ma.Multiaddr) (*conn, error) {
+	addr, err := manet.ToNetAddr(raddr)
+	if err != nil {
+		return nil, err
+	}
+	remote, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return nil, fmt.Errorf("can't dial %s", raddr)
+	}
+
+	n.lock.Lock()
+	if !n.reachable(ip.String(), remote.IP.String()) {
+		n.lock.Unlock()
+		return nil, errUnreachable
+	}
+	l, ok := n.listeners[remote.String()]
+	if !ok {
+		n.lock.Unlock()
+		return nil, errConnRefused
+	}
+	local := &net.TCPAddr{IP: ip, Port: n.nextPort()}
+	out, in := newConnPair(n, local, remote)
+	n.conns[out] = struct{}{}
+	n.conns[in] = struct{}{}
+	n.lock.Unlock()
+
+	select {
+	case l.conns <- in:
+		return out, nil
+	case <-l.closed:
+		err = errConnRefused
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	out.Close()
+	in.Close()
+	return nil, err
+}
+
+func (n *Network) forget(c *conn) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	delete(n.conns, c)
+}
+
+// transport is the libp2p transport of a host on the network.
+type transport struct {
+	net      *Network
+	ip       net.IP
+	upgrader *tptu.Upgrader
+}
+
+func (t *transport) CanDial(addr ma.Multiaddr) bool {
+	return mafmt.TCP.Matches(addr)
+}
+
+func (t *transport) Dial(ctx context.Context, raddr ma.Multiaddr, p peer.ID) (tpt.Conn, error) {
+	c, err := t.net.dial(ctx, t.ip, raddr)
+	if err != nil {
+		return nil, err
+	}
+	tc, err := t.upgrader.UpgradeOutbound(ctx, t, c, p)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	return tc, nil
+}
+
+func (t *transport) Listen(laddr ma.Multiaddr) (tpt.Listener, error) {
+	l, err := t.net.listen(t.ip, laddr)
+	if err != nil {
+		return nil, err
+	}
+	return t.upgrader.UpgradeListener(t, l), nil
+}
+
+func (t *transport) Protocols() []int { return []int{ma.P_TCP} }
+func (t *transport) Proxy() bool      { return false }
+func (t *transport) String() string   { return "simulated TCP" }
+
+// listener accepts the connections dialled to its address.
+type listener struct {
+	net    *Network
+	addr   *net.TCPAddr
+	maddr  ma.Multiaddr
+	conns  chan *conn
+	closed chan struct{}
+	once   sync.Once
+}
+
+func (l *listener) Accept() (manet.Conn, error) {
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.closed:
+		return nil, errConnClosed
+	}
+}
+
+func (l *listener) Close() error {
+	l.once.Do(func() {
+		close(l.closed)
+		l.net.lock.Lock()
+		delete(l.net.listeners, l.addr.String())
+		l.net.lock.Unlock()
+	})
+	return nil
+}
+
+func (l *listener) Multiaddr() ma.Multiaddr   { return l.maddr }
+func (l *listener) Addr() net.Addr            { return l.addr }
+func (l *listener) NetListener() net.Listener { return netListener{l} }
+
+// netListener is the net.Listener view of a listener.
+type netListener struct{ l *listener }
+
+func (nl netListener) Accept() (net.Conn, error) { return nl.l.Accept() }
+func (nl netListener) Close() error              { return nl.l.Close() }
+func (nl netListener) Addr() net.Addr            { return nl.l.addr }
+
+// conn is one end of a simulated connection.
+type conn struct {
+	net           *Network
+	in, out       *pipe
+	local, remote *net.TCPAddr
+	once          sync.Once
+}
+
+func newConnPair(n *Network, a, b *net.TCPAddr) (*conn, *conn) {
+	ab, ba := newPipe(), newPipe()
+	return &conn{net: n, in: ba, out: ab, local: a, remote: b},
+		&conn{net: n, in: ab, out: ba, local: b, remote: a}
+}
+
+func (c *conn) Read(b []byte) (int, error) {
+	return c.in.read(b)
+}
+
+func (c *conn) Write(b []byte) (int, error) {
+	return c.out.write(b, c.net.delay(c.local.IP.String(), c.remote.IP.String()))
+}
+
+// Close lets the remote end read the pending data before it gets EOF.
+func (c *conn) Close() error {
+	c.once.Do(func() {
+		c.out.close()
+		c.in.fail(errConnClosed)
+		c.net.forget(c)
+	})
+	return nil
+}
+
+// reset breaks both directions of the connection at once.
+func (c *conn) reset() {
+	c.in.fail(errConnReset)
+	c.out.fail(errConnReset)
+}
+
+func (c *conn) LocalAddr() net.Addr  { return c.local }
+func (c *conn) RemoteAddr() net.Addr { return c.remote }
+
+func (c *conn) LocalMultiaddr() ma.Multiaddr {
+	addr, _ := manet.FromNetAddr(c.local)
+	return addr
+}
+
+func (c *conn) RemoteMultiaddr() ma.Multiaddr {
+	addr, _ := manet.FromNetAddr(c.remote)
+	return addr
+}
+
+func (c *conn) SetDeadline(t time.Time) error {
+	return c.in.setDeadline(t)
+}
+
+func (c *conn) SetReadDeadline(t time.Time) error {
+	return c.in.setDeadline(t)
+}
+
+// SetWriteDeadline does nothing, writes never block.
+func (c *conn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+// timeoutError is returned by reads whose deadline passed.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+type chunk struct {
+	data []byte
+	at   time.Time // Time the data arrives
+}
+
+// pipe is one direction of a connection, written data becomes readable once
+// it arrived.
+type pipe struct {
+	lock     sync.Mutex
+	cond     *sync.Cond
+	chunks   []chunk
+	last     time.Time // Arrival of the last write, data is never reordered
+	eof      bool
+	err      error
+	deadline time.Time
+}
+
+func newPipe() *pipe {
+	p := new(pipe)
+	p.cond = sync.NewCond(&p.lock)
+	return p
+}
+
+func (p *pipe) read(b []byte) (int, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for {
+		if p.err != nil {
+			return 0, p.err
+		}
+		now := time.Now()
+		var wake time.Time
+		if len(p.chunks) > 0 {
+			c := &p.chunks[0]
+			if !c.at.After(now) {
+				n := copy(b, c.data)
+				if c.data = c.data[n:]; len(c.data) == 0 {
+					p.chunks = p.chunks[1:]
+				}
+				return n, nil
+			}
+			wake = c.at
+		} else if p.eof {
+			return 0, io.EOF
+		}
+		if !p.deadline.IsZero() {
+			if !p.deadline.After(now) {
+				return 0, timeoutError{}
+			}
+			if wake.IsZero() || p.deadline.Before(wake) {
+				wake = p.deadline
+			}
+		}
+		if wake.IsZero() {
+			p.cond.Wait()
+			continue
+		}
+		// The timer takes the lock, so it can't fire before we wait
+		timer := time.AfterFunc(wake.Sub(now), func() {
+			p.lock.Lock()
+			p.cond.Broadcast()
+			p.lock.Unlock()
+		})
+		p.cond.Wait()
+		timer.Stop()
+	}
+}
+
+func (p *pipe) write(b []byte, delay time.Duration) (int, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.err != nil {
+		return 0, p.err
+	}
+	if p.eof {
+		return 0, errConnClosed
+	}
+	at := time.Now().Add(delay)
+	if at.Before(p.last) {
+		at = p.last
+	}
+	p.last = at
+	p.chunks = append(p.chunks, chunk{data: append([]byte(nil), b...), at: at})
+	p.cond.Broadcast()
+	return len(b), nil
+}
+
+// close makes the reader see EOF after the pending data.
+func (p *pipe) close() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.eof = true
+	p.cond.Broadcast()
+}
+
+// fail makes reads and writes fail with err, dropping the pending data.
+func (p *pipe) fail(err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.err == nil {
+		p.err = err
+	}
+	p.chunks = nil
+	p.cond.Broadcast()
+}
+
+func (p *pipe) setDeadline(t time.Time) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.deadline = t
+	p.cond.Broadcast()
+	return nil
+}
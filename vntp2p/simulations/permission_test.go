@@ -0,0 +1,89 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulations
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPermissionedNetwork(t *testing.T) {
+	sim := NewSimulation(LinkConfig{})
+	defer sim.Shutdown()
+
+	if _, err := sim.AddNode(NodeConfig{Protocols: []string{PingProtocolName}, Permissioned: true}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := sim.AddNode(NodeConfig{Protocols: []string{PingProtocolName}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sim.StartAll(); err != nil {
+		t.Fatal(err)
+	}
+	server := sim.Node("node01").Server()
+	if err := server.AllowPeer(sim.Node("node02").ID.Pretty()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the allowed node gets in, whichever side dials
+	if err := sim.Connect("node02", "node01"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.Connect("node03", "node01"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.Connect("node01", "node03"); err != nil {
+		t.Fatal(err)
+	}
+	waitPeers(t, sim, map[string]int{"node01": 1, "node02": 1})
+	time.Sleep(time.Second)
+	if n := server.PeerCount(); n != 1 {
+		t.Fatalf("node01 has %d peers, want 1", n)
+	}
+	if n := sim.Node("node03").Server().PeerCount(); n != 0 {
+		t.Fatalf("unpermitted node03 has %d peers", n)
+	}
+
+	// Registry peers are allowed too
+	if err := server.SetRegistryPeers([]string{sim.Node("node03").ID.Pretty()}); err != nil {
+		t.Fatal(err)
+	}
+	waitPeers(t, sim, map[string]int{"node01": 2, "node03": 1})
+
+	// Removed peers are disconnected
+	if err := server.DisallowPeer(sim.Node("node02").ID.Pretty()); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.SetRegistryPeers(nil); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := sim.WaitPeers(ctx, "node01", 0); err != nil {
+		t.Fatal(err)
+	}
+	info, err := server.AllowList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.File) != 0 || len(info.Registry) != 0 {
+		t.Errorf("allow-list not empty: %+v", info)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulations
+
+import (
+	"sync"
+	"time"
+
+	"github.com/vntchain/go-vnt/vntp2p"
+)
+
+// PingProtocolName is the name of the built-in test protocol, nodes running it
+// ping their peers every pingInterval.
+const PingProtocolName = "ping"
+
+const pingInterval = 500 * time.Millisecond
+
+const (
+	pingMsg vntp2p.MessageType = iota
+	pongMsg
+)
+
+// PingInfo is the node info of the ping protocol.
+type PingInfo struct {
+	Pings    uint64            `json:"pings"`    // Pings received
+	Pongs    uint64            `json:"pongs"`    // Pongs received
+	RTT      map[string]string `json:"rtt"`      // Last round trip time per peer
+	Received map[string]uint64 `json:"received"` // Pongs received per peer
+}
+
+type pingService struct {
+	lock     sync.Mutex
+	pings    uint64
+	pongs    uint64
+	rtt      map[string]time.Duration
+	received map[string]uint64
+}
+
+// NewPingProtocol creates the ping protocol of a node.
+func NewPingProtocol(node *Node) vntp2p.Protocol {
+	s := &pingService{
+		rtt:      make(map[string]time.Duration),
+		received: make(map[string]uint64),
+	}
+	return vntp2p.Protocol{
+		Name:     PingProtocolName,
+		Version:  1,
+		Length:   2,
+		Run:      s.run,
+		NodeInfo: s.info,
+	}
+}
+
+func (s *pingService) info() interface{} {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	info := &PingInfo{
+		Pings:    s.pings,
+		Pongs:    s.pongs,
+		RTT:      make(map[string]string, len(s.rtt)),
+		Received: make(map[string]uint64, len(s.received)),
+	}
+	for id, rtt := range s.rtt {
+		info.RTT[id] = rtt.String()
+	}
+	for id, n := range s.received {
+		info.Received[id] = n
+	}
+	return info
+}
+
+func (s *pingService) run(p *vntp2p.Peer, rw vntp2p.MsgReadWriter) error {
+	errc := make(chan error, 1)
+	go func() {
+		for {
+			msg, err := rw.ReadMsg()
+			if err != nil {
+				errc <- err
+				return
+			}
+			var sent uint64
+			if err := msg.Decode(&sent); err != nil {
+				errc <- err
+				return
+			}
+			switch msg.Body.Type {
+			case pingMsg:
+				s.lock.Lock()
+				s.pings++
+				s.lock.Unlock()
+				err = vntp2p.Send(rw, PingProtocolName, pongMsg, sent)
+			case pongMsg:
+				id := p.RemoteID().Pretty()
+				s.lock.Lock()
+				s.pongs++
+				s.received[id]++
+				s.rtt[id] = time.Since(time.Unix(0, int64(sent)))
+				s.lock.Unlock()
+			}
+			if err != nil {
+				errc <- err
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		if err := vntp2p.Send(rw, PingProtocolName, pingMsg, uint64(time.Now().UnixNano())); err != nil {
+			return err
+		}
+		select {
+		case <-ticker.C:
+		case err := <-errc:
+			return err
+		}
+	}
+}
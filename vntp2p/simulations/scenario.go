@@ -0,0 +1,210 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/vntchain/go-vnt/log"
+)
+
+// defaultWaitTimeout is how long expect-peers steps wait if the step doesn't
+// give a duration.
+const defaultWaitTimeout = 30 * time.Second
+
+// Scenario is a scripted simulation. Its nodes are created, unless the
+// simulation already has nodes of the same names, and then the steps run one
+// after the other. Steps fail the scenario if they return an error, so a
+// scenario doubles as a regression test.
+//
+//	{
+//	  "link":  {"latency": "50ms"},
+//	  "nodes": [{"name": "a", "protocols": ["ping"]}, {"name": "b", "protocols": ["ping"]}],
+//	  "steps": [
+//	    {"action": "start"},
+//	    {"action": "connect", "nodes": ["a", "b"]},
+//	    {"action": "expect-peers", "nodes": ["a", "b"], "peers": 1, "duration": "10s"}
+//	  ]
+//	}
+type Scenario struct {
+	Link  *LinkConfig  `json:"link,omitempty"` // Quality of all links, unchanged if nil
+	Nodes []NodeConfig `json:"nodes"`
+	Steps []Step       `json:"steps"`
+}
+
+// Step is a single action of a scenario. The actions are
+//
+//	start, stop         start or stop the nodes, all nodes if none are given
+//	connect, disconnect connect or disconnect the two nodes
+//	link                set the link between the two nodes, or of all links if
+//	                    no nodes are given
+//	partition           split the network into the groups
+//	heal                remove the partitions
+//	sleep               wait for the duration
+//	expect-peers        wait up to the duration until the nodes, all nodes if
+//	                    none are given, have the number of peers
+//	expect-commits      wait up to the duration until the nodes committed the
+//	                    number of further rounds in the bft protocol
+//	expect-head         wait up to the duration until the nodes have the head
+//	                    block in the sync protocol
+type Step struct {
+	Action   string      `json:"action"`
+	Nodes    []string    `json:"nodes,omitempty"`
+	Groups   [][]string  `json:"groups,omitempty"`
+	Link     *LinkConfig `json:"link,omitempty"`
+	Peers    int         `json:"peers,omitempty"`
+	Rounds   uint64      `json:"rounds,omitempty"`
+	Head     uint64      `json:"head,omitempty"`
+	Duration string      `json:"duration,omitempty"`
+}
+
+// LoadScenario reads a JSON encoded scenario from a file.
+func LoadScenario(file string) (*Scenario, error) {
+	blob, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	scenario := new(Scenario)
+	if err := json.Unmarshal(blob, scenario); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %v", file, err)
+	}
+	return scenario, nil
+}
+
+// Run runs the scenario on the simulation.
+func (s *Simulation) Run(ctx context.Context, scenario *Scenario) error {
+	if scenario.Link != nil {
+		s.net.SetDefaultLink(*scenario.Link)
+	}
+	for _, config := range scenario.Nodes {
+		if config.Name != "" && s.Node(config.Name) != nil {
+			continue
+		}
+		if _, err := s.AddNode(config); err != nil {
+			return err
+		}
+	}
+	for i, step := range scenario.Steps {
+		log.Debug("Running scenario step", "step", i+1, "action", step.Action, "nodes", step.Nodes)
+		if err := s.runStep(ctx, &step); err != nil {
+			return fmt.Errorf("step %d (%s): %v", i+1, step.Action, err)
+		}
+	}
+	return nil
+}
+
+func (s *Simulation) runStep(ctx context.Context, step *Step) error {
+	var (
+		duration time.Duration
+		err      error
+	)
+	if step.Duration != "" {
+		if duration, err = time.ParseDuration(step.Duration); err != nil {
+			return err
+		}
+	}
+	nodes := step.Nodes
+	if len(nodes) == 0 {
+		for _, node := range s.Nodes() {
+			nodes = append(nodes, node.Name)
+		}
+	}
+
+	switch step.Action {
+	case "start":
+		for _, name := range nodes {
+			if err := s.Start(name); err != nil {
+				return err
+			}
+		}
+	case "stop":
+		for _, name := range nodes {
+			if err := s.Stop(name); err != nil {
+				return err
+			}
+		}
+	case "connect", "disconnect":
+		if len(step.Nodes) != 2 {
+			return fmt.Errorf("need two nodes, have %d", len(step.Nodes))
+		}
+		if step.Action == "connect" {
+			return s.Connect(step.Nodes[0], step.Nodes[1])
+		}
+		return s.Disconnect(step.Nodes[0], step.Nodes[1])
+	case "link":
+		if step.Link == nil {
+			return fmt.Errorf("no link given")
+		}
+		switch len(step.Nodes) {
+		case 0:
+			s.net.SetDefaultLink(*step.Link)
+		case 2:
+			return s.SetLink(step.Nodes[0], step.Nodes[1], *step.Link)
+		default:
+			return fmt.Errorf("need two nodes or none, have %d", len(step.Nodes))
+		}
+	case "partition":
+		return s.Partition(step.Groups)
+	case "heal":
+		s.Heal()
+	case "sleep":
+		select {
+		case <-time.After(duration):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	case "expect-peers", "expect-commits", "expect-head":
+		if duration == 0 {
+			duration = defaultWaitTimeout
+		}
+		ctx, cancel := context.WithTimeout(ctx, duration)
+		defer cancel()
+
+		// Rounds are counted from the start of the step
+		commits := make(map[string]uint64)
+		if step.Action == "expect-commits" {
+			for _, name := range nodes {
+				info, err := s.bftInfo(name)
+				if err != nil {
+					return err
+				}
+				commits[name] = info.Committed
+			}
+		}
+		for _, name := range nodes {
+			var err error
+			switch step.Action {
+			case "expect-peers":
+				err = s.WaitPeers(ctx, name, step.Peers)
+			case "expect-commits":
+				err = s.WaitCommits(ctx, name, commits[name]+step.Rounds)
+			case "expect-head":
+				err = s.WaitHead(ctx, name, step.Head)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unknown action")
+	}
+	return nil
+}
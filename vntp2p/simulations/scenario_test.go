@@ -0,0 +1,142 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulations
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func runScenario(t *testing.T, sim *Simulation, input string) {
+	var scenario Scenario
+	if err := json.Unmarshal([]byte(input), &scenario); err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.Run(context.Background(), &scenario); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Tests that witnesses which aren't connected directly commit rounds through
+// the relaying mesh, and that a quorum keeps committing while one of them is
+// cut off.
+func TestScenarioBftMesh(t *testing.T) {
+	const input = `{
+		"link": {"latency": "10ms"},
+		"nodes": [
+			{"name": "w1", "protocols": ["bft"], "witness": true},
+			{"name": "w2", "protocols": ["bft"], "witness": true},
+			{"name": "w3", "protocols": ["bft"], "witness": true},
+			{"name": "w4", "protocols": ["bft"], "witness": true},
+			{"name": "o", "protocols": ["bft"]}
+		],
+		"steps": [
+			{"action": "start"},
+			{"action": "connect", "nodes": ["w1", "w2"]},
+			{"action": "connect", "nodes": ["w2", "w3"]},
+			{"action": "connect", "nodes": ["w3", "w4"]},
+			{"action": "connect", "nodes": ["w4", "o"]},
+			{"action": "expect-peers", "nodes": ["w2", "w3", "w4"], "peers": 2, "duration": "20s"},
+			{"action": "expect-commits", "rounds": 5, "duration": "20s"},
+			{"action": "partition", "groups": [["w1"]]},
+			{"action": "expect-peers", "nodes": ["w1"], "peers": 0, "duration": "20s"},
+			{"action": "expect-commits", "nodes": ["w2", "w3", "w4", "o"], "rounds": 5, "duration": "20s"}
+		]
+	}`
+	sim := NewSimulation(LinkConfig{})
+	defer sim.Shutdown()
+	runScenario(t, sim, input)
+
+	// The witness cut off can't reach a quorum on its own
+	info, err := sim.bftInfo("w1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * bftInterval)
+	if after, _ := sim.bftInfo("w1"); after.Committed > info.Committed+1 {
+		t.Errorf("partitioned witness committed %d rounds", after.Committed-info.Committed)
+	}
+	if info, _ := sim.bftInfo("o"); info.Relayed != 0 {
+		t.Errorf("leaf node relayed %d votes", info.Relayed)
+	}
+
+	// It catches up once the partition heals
+	runScenario(t, sim, `{"steps": [
+		{"action": "heal"},
+		{"action": "expect-commits", "nodes": ["w1"], "rounds": 5, "duration": "20s"}
+	]}`)
+}
+
+// Tests that blocks are synced across several hops, also to nodes joining
+// later, and only the missing blocks are fetched.
+func TestScenarioBlockSync(t *testing.T) {
+	const input = `{
+		"link": {"latency": "5ms"},
+		"nodes": [
+			{"name": "a", "protocols": ["sync"], "blocks": 300},
+			{"name": "b", "protocols": ["sync"], "blocks": 100},
+			{"name": "c", "protocols": ["sync"]},
+			{"name": "d", "protocols": ["sync"]}
+		],
+		"steps": [
+			{"action": "start", "nodes": ["a", "b", "c"]},
+			{"action": "connect", "nodes": ["a", "b"]},
+			{"action": "connect", "nodes": ["b", "c"]},
+			{"action": "expect-head", "nodes": ["b", "c"], "head": 300, "duration": "20s"},
+			{"action": "start", "nodes": ["d"]},
+			{"action": "connect", "nodes": ["d", "c"]},
+			{"action": "expect-head", "nodes": ["d"], "head": 300, "duration": "20s"}
+		]
+	}`
+	sim := NewSimulation(LinkConfig{})
+	defer sim.Shutdown()
+	runScenario(t, sim, input)
+
+	for name, want := range map[string]uint64{"a": 0, "b": 200, "c": 300, "d": 300} {
+		info, err := sim.syncInfo(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Head != 300 || info.Imported != want {
+			t.Errorf("%s: head %d, imported %d, want head 300, imported %d", name, info.Head, info.Imported, want)
+		}
+	}
+}
+
+// Tests that blocks not linking to the local chain are refused.
+func TestSyncInsert(t *testing.T) {
+	var (
+		blocks = newSyncService(20).blocks(11, 10)
+		local  = newSyncService(10)
+	)
+	if err := local.insert(blocks[1:]); err != errInvalidChain {
+		t.Errorf("gapped blocks: have %v, want %v", err, errInvalidChain)
+	}
+	forged := append([]syncBlock(nil), blocks...)
+	forged[0].ParentHash[0]++
+	if err := local.insert(forged); err == nil {
+		t.Errorf("forged block accepted")
+	}
+	if err := local.insert(blocks); err != nil {
+		t.Fatalf("valid blocks refused: %v", err)
+	}
+	if head := local.status().Head; head != 20 {
+		t.Errorf("head mismatch: have %d, want 20", head)
+	}
+}
@@ -0,0 +1,559 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package simulations runs networks of vntp2p nodes in a single process. The
+// nodes talk over a simulated network with controllable latency, loss and
+// partitions, which makes it possible to test protocols like consensus, sync
+// or whisper propagation with many nodes on one machine.
+package simulations
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	peer "github.com/libp2p/go-libp2p-peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/vntchain/go-vnt/crypto"
+	"github.com/vntchain/go-vnt/log"
+	"github.com/vntchain/go-vnt/vntp2p"
+)
+
+const (
+	// listenPort is the port all simulated nodes listen on.
+	listenPort = 30303
+
+	defaultMaxPeers = 25
+)
+
+var (
+	errNodeExists     = errors.New("node already exists")
+	errNodeNotFound   = errors.New("node not found")
+	errNodeRunning    = errors.New("node is running")
+	errNodeNotRunning = errors.New("node is not running")
+)
+
+// nodeError is an error concerning a node.
+type nodeError struct {
+	err  error
+	node string
+}
+
+func (e *nodeError) Error() string {
+	return fmt.Sprintf("%v: %s", e.err, e.node)
+}
+
+// ProtocolFunc creates the protocol for a node, it is called once per node.
+type ProtocolFunc func(node *Node) vntp2p.Protocol
+
+var (
+	protocolsLock sync.RWMutex
+	protocols     = map[string]ProtocolFunc{
+		PingProtocolName: NewPingProtocol,
+		BftProtocolName:  NewBftProtocol,
+		SyncProtocolName: NewSyncProtocol,
+	}
+)
+
+// RegisterProtocol makes a protocol available to simulated nodes under the
+// name. It panics if the name is already taken.
+func RegisterProtocol(name string, fn ProtocolFunc) {
+	protocolsLock.Lock()
+	defer protocolsLock.Unlock()
+
+	if _, ok := protocols[name]; ok {
+		panic(fmt.Sprintf("simulation protocol %q registered twice", name))
+	}
+	protocols[name] = fn
+}
+
+func lookupProtocol(name string) (ProtocolFunc, bool) {
+	protocolsLock.RLock()
+	defer protocolsLock.RUnlock()
+
+	fn, ok := protocols[name]
+	return fn, ok
+}
+
+// NodeConfig is the configuration of a simulated node.
+type NodeConfig struct {
+	Name           string            `json:"name"`
+	Protocols      []string          `json:"protocols"`                // Names of registered protocols
+	MaxPeers       int               `json:"maxPeers,omitempty"`       // defaultMaxPeers if zero
+	BootstrapLists []string          `json:"bootstrapLists,omitempty"` // Signed bootstrap lists to follow
+	Permissioned   bool              `json:"permissioned,omitempty"`   // Only allow-listed peers may connect
+	Witness        bool              `json:"witness,omitempty"`        // Whether the node votes in the bft protocol
+	Blocks         uint64            `json:"blocks,omitempty"`         // Blocks the node starts with in the sync protocol
+	PrivateKey     *ecdsa.PrivateKey `json:"-"`                        // Random if nil
+}
+
+// Node is a simulated node.
+type Node struct {
+	Name string
+	ID   peer.ID
+	IP   net.IP
+	Addr ma.Multiaddr // Listen address on the simulated network
+
+	config    NodeConfig
+	protocols []vntp2p.Protocol
+	sim       *Simulation
+
+	lock   sync.Mutex
+	server *vntp2p.Server // nil if the node is not running
+}
+
+// Server returns the p2p server of the node, or nil if it's not running.
+func (n *Node) Server() *vntp2p.Server {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return n.server
+}
+
+// Running reports whether the node is running.
+func (n *Node) Running() bool {
+	return n.Server() != nil
+}
+
+// URL returns the multiaddr other nodes dial the node with.
+func (n *Node) URL() string {
+	return n.Addr.String() + "/ipfs/" + n.ID.Pretty()
+}
+
+// NodeInfo is the state of a node as reported by the API.
+type NodeInfo struct {
+	Name      string                 `json:"name"`
+	ID        string                 `json:"id"`
+	URL       string                 `json:"url"`
+	Running   bool                   `json:"running"`
+	Peers     int                    `json:"peers"`
+	Protocols map[string]interface{} `json:"protocols"`
+}
+
+// Info returns the state of the node.
+func (n *Node) Info() *NodeInfo {
+	info := &NodeInfo{
+		Name:      n.Name,
+		ID:        n.ID.Pretty(),
+		URL:       n.URL(),
+		Protocols: make(map[string]interface{}),
+	}
+	if srv := n.Server(); srv != nil {
+		info.Running = true
+		info.Peers = srv.PeerCount()
+	}
+	for _, proto := range n.protocols {
+		var pinfo interface{} = "unknown"
+		if proto.NodeInfo != nil {
+			pinfo = proto.NodeInfo()
+		}
+		info.Protocols[proto.Name] = pinfo
+	}
+	return info
+}
+
+func (n *Node) start() error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.server != nil {
+		return &nodeError{errNodeRunning, n.Name}
+	}
+	maxPeers := n.config.MaxPeers
+	if maxPeers == 0 {
+		maxPeers = defaultMaxPeers
+	}
+	server := &vntp2p.Server{
+		Config: vntp2p.Config{
+			PrivateKey:  n.config.PrivateKey,
+			Name:        n.Name,
+			MaxPeers:    maxPeers,
+			NoDiscovery: true,
+			ListenAddr:  fmt.Sprintf(":%d", listenPort),
+			Protocols:   n.protocols,
+			Transport:   n.sim.net.Transport(n.IP),
+
+			BootstrapLists: n.config.BootstrapLists,
+			Permissioned:   n.config.Permissioned,
+		},
+	}
+	if err := server.Start(); err != nil {
+		return err
+	}
+	n.server = server
+	return nil
+}
+
+func (n *Node) stop() error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.server == nil {
+		return &nodeError{errNodeNotRunning, n.Name}
+	}
+	n.server.Stop()
+	n.server = nil
+	return nil
+}
+
+// Simulation is a set of nodes on a simulated network.
+type Simulation struct {
+	net *Network
+
+	lock  sync.RWMutex
+	nodes []*Node
+	names map[string]*Node
+}
+
+// NewSimulation creates an empty simulation whose links have the given
+// quality.
+func NewSimulation(link LinkConfig) *Simulation {
+	return &Simulation{
+		net:   NewNetwork(link),
+		names: make(map[string]*Node),
+	}
+}
+
+// Network returns the simulated network.
+func (s *Simulation) Network() *Network {
+	return s.net
+}
+
+// AddNode creates a node, it has to be started before it connects.
+func (s *Simulation) AddNode(config NodeConfig) (*Node, error) {
+	var err error
+	if config.PrivateKey == nil {
+		if config.PrivateKey, err = crypto.GenerateKey(); err != nil {
+			return nil, err
+		}
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if config.Name == "" {
+		config.Name = fmt.Sprintf("node%02d", len(s.nodes)+1)
+	}
+	if _, ok := s.names[config.Name]; ok {
+		return nil, &nodeError{errNodeExists, config.Name}
+	}
+	node := &Node{
+		Name:   config.Name,
+		ID:     vntp2p.PubkeyID(&config.PrivateKey.PublicKey),
+		IP:     s.net.NewAddress(),
+		config: config,
+		sim:    s,
+	}
+	if node.Addr, err = ma.NewMultiaddr(fmt.Sprintf("/ip4/%s/tcp/%d", node.IP, listenPort)); err != nil {
+		return nil, err
+	}
+	for _, name := range config.Protocols {
+		fn, ok := lookupProtocol(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown protocol %q", name)
+		}
+		node.protocols = append(node.protocols, fn(node))
+	}
+	s.nodes = append(s.nodes, node)
+	s.names[node.Name] = node
+	log.Debug("Created simulated node", "name", node.Name, "id", node.ID, "ip", node.IP)
+	return node, nil
+}
+
+// Node returns the node with the name, or nil if there is none.
+func (s *Simulation) Node(name string) *Node {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.names[name]
+}
+
+// Nodes returns the nodes in the order they were added.
+func (s *Simulation) Nodes() []*Node {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return append([]*Node(nil), s.nodes...)
+}
+
+func (s *Simulation) node(name string) (*Node, error) {
+	if node := s.Node(name); node != nil {
+		return node, nil
+	}
+	return nil, &nodeError{errNodeNotFound, name}
+}
+
+func (s *Simulation) runningNode(name string) (*Node, *vntp2p.Server, error) {
+	node, err := s.node(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	srv := node.Server()
+	if srv == nil {
+		return nil, nil, &nodeError{errNodeNotRunning, name}
+	}
+	return node, srv, nil
+}
+
+// Start starts the node.
+func (s *Simulation) Start(name string) error {
+	node, err := s.node(name)
+	if err != nil {
+		return err
+	}
+	return node.start()
+}
+
+// Stop stops the node, its connections are closed.
+func (s *Simulation) Stop(name string) error {
+	node, err := s.node(name)
+	if err != nil {
+		return err
+	}
+	return node.stop()
+}
+
+// StartAll starts all nodes which are not running.
+func (s *Simulation) StartAll() error {
+	for _, node := range s.Nodes() {
+		if node.Running() {
+			continue
+		}
+		if err := node.start(); err != nil {
+			return fmt.Errorf("%s: %v", node.Name, err)
+		}
+	}
+	return nil
+}
+
+// Shutdown stops all nodes.
+func (s *Simulation) Shutdown() {
+	for _, node := range s.Nodes() {
+		node.stop()
+	}
+}
+
+// Connect makes node a static peer of node a, a keeps dialling it until the
+// nodes are connected.
+func (s *Simulation) Connect(a, b string) error {
+	_, srv, err := s.runningNode(a)
+	if err != nil {
+		return err
+	}
+	target, err := s.node(b)
+	if err != nil {
+		return err
+	}
+	srv.AddPeer(context.Background(), &vntp2p.Node{Id: target.ID, Addr: target.Addr})
+	return nil
+}
+
+// Disconnect removes node b from the static peers of a and drops the
+// connection between them.
+func (s *Simulation) Disconnect(a, b string) error {
+	_, srv, err := s.runningNode(a)
+	if err != nil {
+		return err
+	}
+	target, err := s.node(b)
+	if err != nil {
+		return err
+	}
+	srv.RemovePeer(&vntp2p.Node{Id: target.ID, Addr: target.Addr})
+	return nil
+}
+
+// Peers returns the peers of a running node.
+func (s *Simulation) Peers(name string) ([]*vntp2p.PeerInfo, error) {
+	_, srv, err := s.runningNode(name)
+	if err != nil {
+		return nil, err
+	}
+	return srv.PeersInfo(), nil
+}
+
+// WaitPeers waits until the node has count peers.
+func (s *Simulation) WaitPeers(ctx context.Context, name string, count int) error {
+	_, srv, err := s.runningNode(name)
+	if err != nil {
+		return err
+	}
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		n := srv.PeerCount()
+		if n == count {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("%s has %d peers, want %d: %v", name, n, count, ctx.Err())
+		}
+	}
+}
+
+// protocolInfo returns the node info of a protocol of the node.
+func (s *Simulation) protocolInfo(name, protocol string) (interface{}, error) {
+	node, err := s.node(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, proto := range node.protocols {
+		if proto.Name == protocol && proto.NodeInfo != nil {
+			return proto.NodeInfo(), nil
+		}
+	}
+	return nil, fmt.Errorf("%s doesn't run %s", name, protocol)
+}
+
+func (s *Simulation) bftInfo(name string) (*BftInfo, error) {
+	info, err := s.protocolInfo(name, BftProtocolName)
+	if err != nil {
+		return nil, err
+	}
+	return info.(*BftInfo), nil
+}
+
+func (s *Simulation) syncInfo(name string) (*SyncInfo, error) {
+	info, err := s.protocolInfo(name, SyncProtocolName)
+	if err != nil {
+		return nil, err
+	}
+	return info.(*SyncInfo), nil
+}
+
+// WaitCommits waits until the node committed count rounds in the bft protocol.
+func (s *Simulation) WaitCommits(ctx context.Context, name string, count uint64) error {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		info, err := s.bftInfo(name)
+		if err != nil {
+			return err
+		}
+		if info.Committed >= count {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("%s committed %d rounds, want %d: %v", name, info.Committed, count, ctx.Err())
+		}
+	}
+}
+
+// WaitHead waits until the node has the block of the number in the sync
+// protocol.
+func (s *Simulation) WaitHead(ctx context.Context, name string, number uint64) error {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		info, err := s.syncInfo(name)
+		if err != nil {
+			return err
+		}
+		if info.Head >= number {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("%s has head %d, want %d: %v", name, info.Head, number, ctx.Err())
+		}
+	}
+}
+
+// SetLink changes the quality of the link between two nodes.
+func (s *Simulation) SetLink(a, b string, link LinkConfig) error {
+	na, err := s.node(a)
+	if err != nil {
+		return err
+	}
+	nb, err := s.node(b)
+	if err != nil {
+		return err
+	}
+	s.net.SetLink(na.IP, nb.IP, link)
+	return nil
+}
+
+// Partition splits the nodes into groups which can't reach each other, nodes
+// not in any of the groups form one more group.
+func (s *Simulation) Partition(groups [][]string) error {
+	ips := make([][]net.IP, len(groups))
+	for i, group := range groups {
+		for _, name := range group {
+			node, err := s.node(name)
+			if err != nil {
+				return err
+			}
+			ips[i] = append(ips[i], node.IP)
+		}
+	}
+	s.net.Partition(ips)
+	return nil
+}
+
+// Heal removes the partitions, disconnected static peers are redialled.
+func (s *Simulation) Heal() {
+	s.net.Heal()
+}
+
+// Conns returns the connections of the running nodes as pairs of names, each
+// pair is sorted and reported once.
+func (s *Simulation) Conns() [][2]string {
+	var (
+		ids   = make(map[string]string)
+		seen  = make(map[[2]string]bool)
+		conns [][2]string
+	)
+	nodes := s.Nodes()
+	for _, node := range nodes {
+		ids[node.ID.String()] = node.Name
+	}
+	for _, node := range nodes {
+		srv := node.Server()
+		if srv == nil {
+			continue
+		}
+		for _, p := range srv.PeersInfo() {
+			name, ok := ids[p.ID]
+			if !ok {
+				continue
+			}
+			pair := [2]string{node.Name, name}
+			if pair[0] > pair[1] {
+				pair[0], pair[1] = pair[1], pair[0]
+			}
+			if !seen[pair] {
+				seen[pair] = true
+				conns = append(conns, pair)
+			}
+		}
+	}
+	sort.Slice(conns, func(i, j int) bool {
+		if conns[i][0] != conns[j][0] {
+			return conns[i][0] < conns[j][0]
+		}
+		return conns[i][1] < conns[j][1]
+	})
+	return conns
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vntchain/go-vnt/vntp2p"
+)
+
+func newTestSimulation(t *testing.T, n int, link LinkConfig) *Simulation {
+	sim := NewSimulation(link)
+	for i := 0; i < n; i++ {
+		if _, err := sim.AddNode(NodeConfig{Protocols: []string{PingProtocolName}}); err != nil {
+			t.Fatalf("failed to add node: %v", err)
+		}
+	}
+	if err := sim.StartAll(); err != nil {
+		sim.Shutdown()
+		t.Fatalf("failed to start nodes: %v", err)
+	}
+	return sim
+}
+
+func waitPeers(t *testing.T, sim *Simulation, want map[string]int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	for name, count := range want {
+		if err := sim.WaitPeers(ctx, name, count); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func pongs(node *Node) uint64 {
+	return node.Info().Protocols[PingProtocolName].(*PingInfo).Pongs
+}
+
+func TestSimulationPing(t *testing.T) {
+	sim := newTestSimulation(t, 2, LinkConfig{Latency: 20 * time.Millisecond})
+	defer sim.Shutdown()
+
+	if err := sim.Connect("node01", "node02"); err != nil {
+		t.Fatal(err)
+	}
+	waitPeers(t, sim, map[string]int{"node01": 1, "node02": 1})
+
+	deadline := time.Now().Add(10 * time.Second)
+	for _, node := range sim.Nodes() {
+		for pongs(node) == 0 {
+			if time.Now().After(deadline) {
+				t.Fatalf("%s received no pong", node.Name)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+	info := sim.Node("node01").Info().Protocols[PingProtocolName].(*PingInfo)
+	for id, rtt := range info.RTT {
+		if d, _ := time.ParseDuration(rtt); d < 40*time.Millisecond {
+			t.Errorf("round trip to %s took %v, want at least twice the link latency", id, d)
+		}
+	}
+	if conns := sim.Conns(); len(conns) != 1 || conns[0] != [2]string{"node01", "node02"} {
+		t.Errorf("connections mismatch: got %v", conns)
+	}
+}
+
+func TestSimulationPartition(t *testing.T) {
+	sim := newTestSimulation(t, 3, LinkConfig{})
+	defer sim.Shutdown()
+
+	for _, pair := range [][2]string{{"node01", "node02"}, {"node02", "node03"}, {"node01", "node03"}} {
+		if err := sim.Connect(pair[0], pair[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitPeers(t, sim, map[string]int{"node01": 2, "node02": 2, "node03": 2})
+
+	// Cut node03 off, the other two stay connected
+	if err := sim.Partition([][]string{{"node03"}}); err != nil {
+		t.Fatal(err)
+	}
+	waitPeers(t, sim, map[string]int{"node01": 1, "node02": 1, "node03": 0})
+
+	// Static peers are redialled once the partition heals
+	sim.Heal()
+	waitPeers(t, sim, map[string]int{"node01": 2, "node02": 2, "node03": 2})
+}
+
+func TestSimulationStopStart(t *testing.T) {
+	sim := newTestSimulation(t, 2, LinkConfig{})
+	defer sim.Shutdown()
+
+	if err := sim.Connect("node01", "node02"); err != nil {
+		t.Fatal(err)
+	}
+	waitPeers(t, sim, map[string]int{"node01": 1, "node02": 1})
+
+	if err := sim.Stop("node02"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.Stop("node02"); err == nil {
+		t.Error("stopping a stopped node succeeded")
+	}
+	waitPeers(t, sim, map[string]int{"node01": 0})
+
+	if err := sim.Start("node02"); err != nil {
+		t.Fatal(err)
+	}
+	waitPeers(t, sim, map[string]int{"node01": 1, "node02": 1})
+}
+
+func TestSimulationPeerMetrics(t *testing.T) {
+	sim := newTestSimulation(t, 2, LinkConfig{Latency: 20 * time.Millisecond})
+	defer sim.Shutdown()
+
+	events := make(chan *vntp2p.PeerEvent, 16)
+	sub := sim.Node("node01").Server().SubscribeEvents(events)
+	defer sub.Unsubscribe()
+
+	if err := sim.Connect("node01", "node02"); err != nil {
+		t.Fatal(err)
+	}
+	waitPeers(t, sim, map[string]int{"node01": 1, "node02": 1})
+	select {
+	case ev := <-events:
+		if ev.Type != vntp2p.PeerEventTypeAdd || ev.Peer != sim.Node("node02").ID {
+			t.Errorf("unexpected event %+v, want add of node02", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no add event")
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		info := sim.Node("node01").Server().PeersInfo()[0]
+		traffic := info.Traffic[PingProtocolName]
+		if traffic.InMsgs > 0 && traffic.OutMsgs > 0 && info.Latency > 0 {
+			if traffic.InBytes == 0 || traffic.OutBytes == 0 {
+				t.Errorf("no payload bytes counted: %+v", traffic)
+			}
+			if info.Latency < 40*time.Millisecond {
+				t.Errorf("latency %v, want at least twice the link latency", info.Latency)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no traffic or latency measured: %+v, latency %v", traffic, info.Latency)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if err := sim.Disconnect("node01", "node02"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ev := <-events:
+		if ev.Type != vntp2p.PeerEventTypeDrop || ev.Peer != sim.Node("node02").ID {
+			t.Errorf("unexpected event %+v, want drop of node02", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no drop event")
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2026 The go-vnt Authors
+// This file is part of the go-vnt library.
+//
+// The go-vnt library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vnt library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vnt library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulations
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vntchain/go-vnt/common"
+	"github.com/vntchain/go-vnt/crypto"
+	"github.com/vntchain/go-vnt/rlp"
+	"github.com/vntchain/go-vnt/vntp2p"
+)
+
+// SyncProtocolName is the name of the built-in protocol modelling block sync.
+// All nodes share the same deterministic chain, of which a node starts with
+// the configured number of blocks. Nodes announce their head every
+// syncInterval and fetch the blocks they miss from peers with a higher head,
+// checking that they link to their own chain.
+const SyncProtocolName = "sync"
+
+const (
+	syncInterval = 500 * time.Millisecond
+
+	// maxSyncBlocks is the number of blocks requested at once.
+	maxSyncBlocks = 64
+)
+
+const (
+	statusMsg vntp2p.MessageType = iota
+	getBlocksMsg
+	blocksMsg
+)
+
+var errInvalidChain = errors.New("blocks don't link to the chain")
+
+// syncBlock is a block of the simulated chain.
+type syncBlock struct {
+	Number     uint64
+	ParentHash common.Hash
+	Hash       common.Hash
+}
+
+// syncBlockHash returns the hash of the block with the number and parent.
+func syncBlockHash(number uint64, parent common.Hash) common.Hash {
+	enc, _ := rlp.EncodeToBytes([]interface{}{number, parent})
+	return crypto.Keccak256Hash(enc)
+}
+
+type syncStatus struct {
+	Head uint64
+	Hash common.Hash
+}
+
+type getBlocksData struct {
+	From  uint64
+	Count uint64
+}
+
+// SyncInfo is the node info of the sync protocol.
+type SyncInfo struct {
+	Head     uint64 `json:"head"`     // Number of the head block
+	Imported uint64 `json:"imported"` // Blocks fetched from peers
+}
+
+type syncService struct {
+	lock     sync.RWMutex
+	chain    []common.Hash // Block hashes by number, starting at genesis
+	imported uint64
+}
+
+// NewSyncProtocol creates the sync protocol of a node, which starts with the
+// configured number of blocks on top of genesis.
+func NewSyncProtocol(node *Node) vntp2p.Protocol {
+	s := newSyncService(node.config.Blocks)
+	return vntp2p.Protocol{
+		Name:     SyncProtocolName,
+		Version:  1,
+		Length:   3,
+		Run:      s.run,
+		NodeInfo: s.info,
+	}
+}
+
+func newSyncService(blocks uint64) *syncService {
+	s := &syncService{chain: []common.Hash{syncBlockHash(0, common.Hash{})}}
+	for n := uint64(1); n <= blocks; n++ {
+		s.chain = append(s.chain, syncBlockHash(n, s.chain[n-1]))
+	}
+	return s
+}
+
+func (s *syncService) info() interface{} {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return &SyncInfo{Head: uint64(len(s.chain) - 1), Imported: s.imported}
+}
+
+func (s *syncService) status() syncStatus {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	head := uint64(len(s.chain) - 1)
+	return syncStatus{Head: head, Hash: s.chain[head]}
+}
+
+// blocks returns up to maxSyncBlocks blocks of the chain, starting at from.
+func (s *syncService) blocks(from, count uint64) []syncBlock {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if count > maxSyncBlocks {
+		count = maxSyncBlocks
+	}
+	var blocks []syncBlock
+	for n := from; n < from+count && n > 0 && n < uint64(len(s.chain)); n++ {
+		blocks = append(blocks, syncBlock{Number: n, ParentHash: s.chain[n-1], Hash: s.chain[n]})
+	}
+	return blocks
+}
+
+// insert appends the blocks to the chain. Blocks the chain already has are
+// skipped, the others have to link to it.
+func (s *syncService) insert(blocks []syncBlock) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, block := range blocks {
+		if block.Hash != syncBlockHash(block.Number, block.ParentHash) {
+			return fmt.Errorf("block %d: invalid hash", block.Number)
+		}
+		if block.Number < uint64(len(s.chain)) && s.chain[block.Number] == block.Hash {
+			continue
+		}
+		if block.Number != uint64(len(s.chain)) || block.ParentHash != s.chain[block.Number-1] {
+			return errInvalidChain
+		}
+		s.chain = append(s.chain, block.Hash)
+		s.imported++
+	}
+	return nil
+}
+
+func (s *syncService) run(p *vntp2p.Peer, rw vntp2p.MsgReadWriter) error {
+	var wlock sync.Mutex
+	send := func(code vntp2p.MessageType, data interface{}) error {
+		wlock.Lock()
+		defer wlock.Unlock()
+		return vntp2p.Send(rw, SyncProtocolName, code, data)
+	}
+	// request fetches the blocks the peer has beyond the local head
+	request := func(peerHead uint64) error {
+		if head := s.status().Head; peerHead > head {
+			return send(getBlocksMsg, getBlocksData{From: head + 1, Count: peerHead - head})
+		}
+		return nil
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		var peerHead uint64
+		for {
+			msg, err := rw.ReadMsg()
+			if err != nil {
+				errc <- err
+				return
+			}
+			switch msg.Body.Type {
+			case statusMsg:
+				var status syncStatus
+				if err = msg.Decode(&status); err == nil {
+					peerHead = status.Head
+					err = request(peerHead)
+				}
+			case getBlocksMsg:
+				var query getBlocksData
+				if err = msg.Decode(&query); err == nil {
+					err = send(blocksMsg, s.blocks(query.From, query.Count))
+				}
+			case blocksMsg:
+				var blocks []syncBlock
+				if err = msg.Decode(&blocks); err == nil && len(blocks) > 0 {
+					if err = s.insert(blocks); err == nil {
+						err = request(peerHead)
+					}
+				}
+			default:
+				err = fmt.Errorf("unexpected message %d", msg.Body.Type)
+			}
+			if err != nil {
+				errc <- err
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+	for {
+		if err := send(statusMsg, s.status()); err != nil {
+			return err
+		}
+		select {
+		case <-ticker.C:
+		case err := <-errc:
+			return err
+		}
+	}
+}